@@ -2,9 +2,15 @@ package ilp
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math"
+	"strings"
+	"time"
 
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize/convex/lp"
 )
 
 // The abstract MILP problem representation
@@ -16,14 +22,69 @@ type Problem struct {
 	variables   []*Variable
 	constraints []*Constraint
 
+	// constant term of the objective function, which does not depend on any variable
+	objectiveOffset float64
+
+	// the objectives of a multi-objective problem, which are combined into the objective function by SetObjectiveWeights,
+	// or optimized one after another by SolveLexicographic
+	objectives []ObjectiveTerm
+
+	// special ordered sets of type 1, of which at most one variable may be nonzero
+	sos1 []*SOSConstraint
+
 	// the branching heuristic to use for branch-and-bound (defaults to 0 == maxFun)
 	branchingHeuristic BranchHeuristic
 
+	// custom branching strategy, which takes precedence over the branching heuristic if set
+	branchingStrategy BranchingStrategy
+
+	// the direction to explore first when branching on a variable without its own preference (defaults to 0 == down first)
+	branchDirection BranchDirection
+
+	// creates the queue that determines the order in which the enumeration tree is explored, which takes precedence over the node selection strategy if set
+	nodeSelection NodeQueueFactory
+
+	// the order in which the enumeration tree is explored (defaults to 0 == FIFO)
+	nodeSelectionStrategy NodeSelectionStrategy
+
 	// number of workers to solve the milpProblem with
 	workers int
 
 	// instrumentation middleware
 	instrumentation BnbMiddleware
+
+	// configuration of the branch-and-bound procedure
+	config SolverConfig
+
+	// whether to bypass the presolve procedure
+	skipPresolve bool
+
+	// whether to apply equilibration scaling during the presolve procedure
+	equilibrate bool
+
+	// whether to apply geometric mean scaling during the presolve procedure
+	scaleCoefficients bool
+
+	// whether to apply the independent operations of each pass of the presolve procedure concurrently
+	parallelPresolve bool
+
+	// values within this distance of an integer satisfy the integrality constraints (defaults to DefaultIntegralityTolerance)
+	integralityTol float64
+
+	// whether the problem has been solved. It is shared between copies of the problem, as Solve operates on a copy.
+	solved *bool
+
+	// the cuts added since the problem was last solved, along with the incumbent of that solve. Shared between copies of the problem, like solved.
+	cuts *CutManager
+
+	// whether the problem may be modified. Shared between copies of the problem, like solved.
+	lock *problemLock
+
+	// the number of cardinality constraints added, which distinguishes the names of their indicator variables
+	cardinalityConstraints int
+
+	// the number of range constraints added, from which the range IDs of their constraints are drawn
+	rangeConstraints int
 }
 
 // A variable of the MILP problem.
@@ -37,9 +98,34 @@ type Variable struct {
 	// integrality constraint
 	integer bool
 
+	// whether the variable was added as a binary variable, as opposed to a general integer variable
+	isBinary bool
+
+	// whether the integrality constraint of this variable was removed by Problem.Relax
+	relaxed bool
+
+	// the direction to explore first when branching on this variable, overriding the default of the problem if set
+	branchDirection    BranchDirection
+	hasBranchDirection bool
+
+	// variables with a higher priority are preferred for branching over variables that the branching heuristic deems equally good
+	branchingPriority int
+
+	// whether the variable was added to encode a constraint, e.g. the indicators of a cardinality constraint, in which case it is omitted from the Solution
+	internal bool
+
 	// bounds
 	upper float64
 	lower float64
+
+	// the lock of the problem the variable belongs to, which guards the setters of the variable like the methods of the problem (see Problem.Freeze)
+	lock *problemLock
+}
+
+// Term is a term coef * variable of the left-hand side of a constraint, as returned by Constraint.GetExpressions.
+type Term struct {
+	Coef     float64
+	Variable *Variable
 }
 
 // an expression of a variable and an arbitrary float for use in defining constraints
@@ -50,6 +136,9 @@ type expression struct {
 }
 
 type Constraint struct {
+	// optional name for human reference and retrieval, empty if the constraint is unnamed
+	name string
+
 	// these expressions will be summed together to form the left-hand-side of the constraint
 	expressions []expression
 
@@ -59,6 +148,17 @@ type Constraint struct {
 	// an equality constraint by default
 	inequality bool
 
+	// inequalities are of the 'smaller than or equal to' type, unless set
+	greaterThanOrEqual bool
+
+	// optional binary indicator variable of a big-M constraint, along with the value of M.
+	// If set, the term -M * indicator is added to the left-hand side of the constraint.
+	bigMIndicator *Variable
+	bigM          float64
+
+	// the ID of the range constraint (see Constraint.Range) of which this constraint is one of the two sides, or 0 if it is not part of one
+	rangeID int
+
 	// store a reference to the problem
 	problem *Problem
 }
@@ -68,13 +168,110 @@ func NewProblem() Problem {
 	return Problem{
 		workers:         1,
 		instrumentation: dummyMiddleware{},
+		solved:          new(bool),
+		integralityTol:  DefaultIntegralityTolerance,
+		cuts:            &CutManager{},
+		lock:            &problemLock{},
+	}
+}
+
+// Clone returns a deep copy of the problem, which can be modified and solved independently of the original.
+// All variables and constraints are copied, with the constraints referring to the copied variables.
+// Note that the instrumentation middleware, branching strategy and node selection factory are shared with the original.
+// The clone can be modified even if the original is frozen.
+func (p *Problem) Clone() *Problem {
+	clone := *p
+	clone.solved = new(bool)
+	clone.cuts = &CutManager{}
+	clone.lock = &problemLock{}
+
+	variables := make(map[*Variable]*Variable, len(p.variables))
+	clone.variables = make([]*Variable, len(p.variables))
+	for i, v := range p.variables {
+		copied := *v
+		copied.lock = clone.lock
+		clone.variables[i] = &copied
+		variables[v] = &copied
+	}
+
+	clone.constraints = make([]*Constraint, len(p.constraints))
+	for i, c := range p.constraints {
+		copied := *c
+		copied.problem = &clone
+		copied.expressions = make([]expression, len(c.expressions))
+		for j, e := range c.expressions {
+			copied.expressions[j] = expression{coef: e.coef, variable: variables[e.variable]}
+		}
+		if c.bigMIndicator != nil {
+			copied.bigMIndicator = variables[c.bigMIndicator]
+		}
+		clone.constraints[i] = &copied
+	}
+
+	clone.sos1 = make([]*SOSConstraint, len(p.sos1))
+	for i, s := range p.sos1 {
+		copied := SOSConstraint{variables: make([]*Variable, len(s.variables))}
+		for j, v := range s.variables {
+			copied.variables[j] = variables[v]
+		}
+		clone.sos1[i] = &copied
 	}
+	clone.objectives = append([]ObjectiveTerm(nil), p.objectives...)
+
+	return &clone
+}
+
+// AddFreeVariable adds a variable that is unrestricted in sign, i.e. has no lower bound, and returns a reference to that variable.
+func (p *Problem) AddFreeVariable(name string) *Variable {
+	return p.AddVariable(name).LowerBound(math.Inf(-1))
+}
+
+// AddBinaryVariable adds an integer variable bounded by [0, 1] and returns a reference to that variable.
+// Branching on a binary variable directly fixes it to either 0 or 1.
+func (p *Problem) AddBinaryVariable(name string) *Variable {
+	v, err := p.tryAddBinaryVariable(name)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// add a binary variable like AddBinaryVariable, but return an error instead of panicking (see TryAddVariable).
+func (p *Problem) tryAddBinaryVariable(name string) (*Variable, error) {
+	v, err := p.TryAddVariable(name)
+	if err != nil {
+		return nil, err
+	}
+	v.IsInteger().UpperBound(1)
+	v.isBinary = true
+	return v, nil
 }
 
 // add a variable and return a reference to that variable.
 // Defaults to no integrality constraint and an objective function coefficient of 0
+// The name identifies the variable in the Solution and in exports, so AddVariable panics if another variable of the problem has the same name.
+// Use TryAddVariable to handle this case as an error instead.
 func (p *Problem) AddVariable(name string) *Variable {
-	// TODO: check for uniqueness of variable name as it has an important role downstream
+	v, err := p.TryAddVariable(name)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// TryAddVariable adds a variable like AddVariable, but returns an error instead of panicking:
+// one wrapping ErrDuplicateVariableName if another variable of the problem has the same name, or ErrProblemFrozen if the problem is frozen.
+func (p *Problem) TryAddVariable(name string) (*Variable, error) {
+	done, err := p.modify()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	for _, v := range p.variables {
+		if v.name == name {
+			return nil, fmt.Errorf("variable %v: %w", name, ErrDuplicateVariableName)
+		}
+	}
 
 	v := Variable{
 		name:        name,
@@ -82,38 +279,93 @@ func (p *Problem) AddVariable(name string) *Variable {
 		integer:     false,
 		upper:       math.Inf(1),
 		lower:       0,
+		lock:        p.lock,
 	}
 
 	p.variables = append(p.variables, &v)
 
-	return &v
+	return &v, nil
 }
 
 // SetCoeff sets the value of the variable in the objective function
 func (v *Variable) SetCoeff(coef float64) *Variable {
+	defer v.lock.mustModify()()
 	v.coefficient = coef
 	return v
 }
 
 func (v *Variable) IsInteger() *Variable {
+	defer v.lock.mustModify()()
 	v.integer = true
 	return v
 }
 
-// UpperBound sets the inclusive upper bound of this variable. Input must be positive.
+// SetBranchDirection sets the direction to explore first when branching on this variable, overriding the default of the problem.
+func (v *Variable) SetBranchDirection(direction BranchDirection) *Variable {
+	defer v.lock.mustModify()()
+	v.branchDirection = direction
+	v.hasBranchDirection = true
+	return v
+}
+
+// SetBranchingPriority sets the priority of this variable for branching, which defaults to 0.
+// When the branching heuristic deems several variables equally good to branch on, the one with the highest priority is selected.
+func (v *Variable) SetBranchingPriority(priority int) *Variable {
+	defer v.lock.mustModify()()
+	v.branchingPriority = priority
+	return v
+}
+
+// UpperBound sets the inclusive upper bound of this variable.
 func (v *Variable) UpperBound(bound float64) *Variable {
+	defer v.lock.mustModify()()
 	v.upper = bound
 	return v
 }
 
-// LowerBound sets the inclusive lower bound of this variable. Input must be positive.
+// LowerBound sets the inclusive lower bound of this variable. The lower bound defaults to zero, but may be negative.
 func (v *Variable) LowerBound(bound float64) *Variable {
+	defer v.lock.mustModify()()
 	v.lower = bound
 	return v
 }
 
-func (p *Problem) AddConstraint() *Constraint {
+// Name returns the name of the variable.
+func (v *Variable) Name() string {
+	return v.name
+}
+
+// ObjectiveCoeff returns the coefficient of the variable in the objective function.
+func (v *Variable) ObjectiveCoeff() float64 {
+	return v.coefficient
+}
+
+// Lower returns the inclusive lower bound of the variable.
+func (v *Variable) Lower() float64 {
+	return v.lower
+}
+
+// Upper returns the inclusive upper bound of the variable.
+func (v *Variable) Upper() float64 {
+	return v.upper
+}
+
+// IntegerConstrained returns whether the variable is constrained to integer values.
+func (v *Variable) IntegerConstrained() bool {
+	return v.integer
+}
+
+// String formats the variable for debugging purposes.
+func (v *Variable) String() string {
+	return fmt.Sprintf("%v [%v, %v] int=%v coef=%v", v.name, v.lower, v.upper, v.integer, v.coefficient)
+}
+
+// AddConstraint adds a constraint and returns a reference to it. The name may be empty, in which case the constraint is unnamed.
+func (p *Problem) AddConstraint(name string) *Constraint {
+	defer p.mustModify()()
+
 	c := &Constraint{
+		name:    name,
 		problem: p,
 	}
 	p.constraints = append(p.constraints, c)
@@ -121,19 +373,252 @@ func (p *Problem) AddConstraint() *Constraint {
 	return c
 }
 
+// GetConstraint retrieves a named constraint by its name using a linear search.
+func (p *Problem) GetConstraint(name string) (*Constraint, bool) {
+	if name == "" {
+		return nil, false
+	}
+	for _, c := range p.constraints {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Name returns the name of the constraint, which is empty if the constraint is unnamed.
+func (c *Constraint) Name() string {
+	return c.name
+}
+
+// GetRHS returns the right-hand side of the constraint.
+func (c *Constraint) GetRHS() float64 {
+	return c.rhs
+}
+
+// GetInequality returns whether the constraint is an inequality, as opposed to an equality.
+func (c *Constraint) GetInequality() bool {
+	return c.inequality
+}
+
+// GetExpressions returns the terms that make up the left-hand side of the constraint, excluding the big-M term (if any).
+func (c *Constraint) GetExpressions() []Term {
+	terms := make([]Term, len(c.expressions))
+	for i, e := range c.expressions {
+		terms[i] = Term{Coef: e.coef, Variable: e.variable}
+	}
+	return terms
+}
+
+// SetRHS modifies the right-hand side of the constraint. The problem has to be solved again for the change to take effect.
+func (c *Constraint) SetRHS(val float64) *Constraint {
+	defer c.problem.mustModify()()
+	c.rhs = val
+	return c
+}
+
+// Scale multiplies the coefficients of the left-hand side of the constraint, including the big-M term (if any), and its right-hand side by the factor,
+// e.g. to normalize the coefficients for numerical stability. Scaling an inequality by a negative factor flips its direction.
+// The factor may not be zero, as that would remove the constraint. If so, this call will panic.
+func (c *Constraint) Scale(factor float64) *Constraint {
+	defer c.problem.mustModify()()
+	if factor == 0 {
+		panic(fmt.Sprintf("cannot scale constraint %v by zero", c.name))
+	}
+
+	// copy the expressions, such that copies of the constraint are left untouched
+	expressions := make([]expression, len(c.expressions))
+	for i, e := range c.expressions {
+		expressions[i] = expression{coef: e.coef * factor, variable: e.variable}
+	}
+	c.expressions = expressions
+
+	c.rhs *= factor
+	c.bigM *= factor
+	if factor < 0 && c.inequality {
+		c.greaterThanOrEqual = !c.greaterThanOrEqual
+	}
+	return c
+}
+
+// String formats the constraint for debugging purposes, e.g. "c1: 2*x + 3*y <= 12".
+func (c *Constraint) String() string {
+	var terms []string
+	for _, e := range c.lhsExpressions() {
+		terms = append(terms, fmt.Sprintf("%v*%v", e.coef, e.variable.name))
+	}
+
+	op := "="
+	if c.inequality {
+		op = "<="
+		if c.greaterThanOrEqual {
+			op = ">="
+		}
+	}
+
+	str := fmt.Sprintf("%v %v %v", strings.Join(terms, " + "), op, c.rhs)
+	if c.name != "" {
+		str = c.name + ": " + str
+	}
+	return str
+}
+
+// RemoveConstraint removes the constraint with the provided name from the problem.
+// Constraints cannot be removed once the problem has been solved, as the solution refers to them. If so, an error wrapping ErrProblemSolved is returned.
+func (p *Problem) RemoveConstraint(name string) error {
+	done, err := p.modify()
+	if err != nil {
+		return err
+	}
+	defer done()
+	if p.solved != nil && *p.solved {
+		return fmt.Errorf("cannot remove constraint %v: %w", name, ErrProblemSolved)
+	}
+
+	for i, c := range p.constraints {
+		if name != "" && c.name == name {
+			// copy the remaining constraints, such that copies of the problem are left untouched
+			constraints := make([]*Constraint, 0, len(p.constraints)-1)
+			constraints = append(constraints, p.constraints[:i]...)
+			constraints = append(constraints, p.constraints[i+1:]...)
+
+			// both sides of a range constraint are removed together
+			if c.rangeID != 0 {
+				remaining := constraints[:0]
+				for _, other := range constraints {
+					if other.rangeID != c.rangeID {
+						remaining = append(remaining, other)
+					}
+				}
+				constraints = remaining
+			}
+
+			p.constraints = constraints
+			return nil
+		}
+	}
+
+	return fmt.Errorf("constraint %v not found", name)
+}
+
+// RemoveVariable removes the variable from the problem, along with the constraints that only involve that variable, such as bounds added as constraints.
+// An error is returned if the variable is part of a constraint on other variables, e.g. as the indicator of a big-M constraint, or of an SOS1 constraint.
+// Like constraints, variables cannot be removed once the problem has been solved, in which case an error wrapping ErrProblemSolved is returned.
+func (p *Problem) RemoveVariable(v *Variable) error {
+	done, err := p.modify()
+	if err != nil {
+		return err
+	}
+	defer done()
+	if p.solved != nil && *p.solved {
+		return fmt.Errorf("cannot remove variable %v: %w", v.name, ErrProblemSolved)
+	}
+
+	index := -1
+	for i, variable := range p.variables {
+		if variable == v {
+			index = i
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("variable %v not found", v.name)
+	}
+
+	var remaining []*Constraint
+	for _, c := range p.constraints {
+		involved, others := false, c.bigMIndicator != nil && c.bigMIndicator != v
+		if c.bigMIndicator == v {
+			involved = true
+		}
+		for _, e := range c.expressions {
+			if e.variable == v {
+				involved = true
+			} else {
+				others = true
+			}
+		}
+
+		switch {
+		case involved && others:
+			return fmt.Errorf("variable %v cannot be removed, as it is part of constraint %v", v.name, c)
+		case !involved:
+			remaining = append(remaining, c)
+		}
+	}
+	for _, s := range p.sos1 {
+		for _, variable := range s.variables {
+			if variable == v {
+				return fmt.Errorf("variable %v cannot be removed, as it is part of an SOS1 constraint", v.name)
+			}
+		}
+	}
+
+	// copy the remaining variables, such that copies of the problem are left untouched
+	variables := make([]*Variable, 0, len(p.variables)-1)
+	variables = append(variables, p.variables[:index]...)
+	p.variables = append(variables, p.variables[index+1:]...)
+	p.constraints = remaining
+	return nil
+}
+
 func (p *Constraint) EqualTo(val float64) *Constraint {
+	defer p.problem.mustModify()()
 	p.inequality = false
+	p.greaterThanOrEqual = false
 	p.rhs = val
 	return p
 }
 
 func (p *Constraint) SmallerThanOrEqualTo(val float64) *Constraint {
+	defer p.problem.mustModify()()
+	p.inequality = true
+	p.greaterThanOrEqual = false
+	p.rhs = val
+	return p
+}
+
+// GreaterThanOrEqualTo turns the constraint into a 'greater than or equal to' inequality.
+// It is negated to a 'smaller than or equal to' inequality when the problem is converted to its numerical representation.
+func (p *Constraint) GreaterThanOrEqualTo(val float64) *Constraint {
+	defer p.problem.mustModify()()
 	p.inequality = true
+	p.greaterThanOrEqual = true
 	p.rhs = val
 	return p
 }
 
+// BigM turns the constraint into a big-M constraint that is switched on and off by a binary indicator variable.
+// E.g. for a constraint x <= 0, this yields x <= M * indicator, which is expanded to x - M * indicator <= 0.
+// The indicator variable must be integer-constrained and bounded by [0, 1]. If not, this call will panic.
+func (c *Constraint) BigM(indicator *Variable, M float64) *Constraint {
+	defer c.problem.mustModify()()
+
+	// check if the provided variable has been declared in this problem. If not, this call will panic
+	c.problem.getVariableIndex(indicator)
+
+	if !indicator.integer || indicator.lower != 0 || indicator.upper != 1 {
+		panic(fmt.Sprintf("big-M indicator variable %v must be integer-constrained and bounded by [0, 1]", indicator.name))
+	}
+
+	c.inequality = true
+	c.bigMIndicator = indicator
+	c.bigM = M
+	return c
+}
+
+// get all expressions that make up the left-hand side of the constraint, including the expanded big-M term (if any).
+func (c *Constraint) lhsExpressions() []expression {
+	if c.bigMIndicator == nil {
+		return c.expressions
+	}
+
+	exprs := make([]expression, len(c.expressions), len(c.expressions)+1)
+	copy(exprs, c.expressions)
+	return append(exprs, expression{coef: -c.bigM, variable: c.bigMIndicator})
+}
+
 func (c *Constraint) AddExpression(coef float64, v *Variable) *Constraint {
+	defer c.problem.mustModify()()
 	// check if the provided variable has been declared in this problem. If not, this call will panic
 	c.problem.getVariableIndex(v)
 
@@ -143,26 +628,412 @@ func (c *Constraint) AddExpression(coef float64, v *Variable) *Constraint {
 	return c
 }
 
-func (p *Problem) Maximize() {
+// Maximize sets the objective function to be maximized.
+// Optionally, the objective coefficients of the variables can be set in bulk by name, as with Variable.SetCoeff.
+// An error is returned if a name does not belong to any variable of the problem, in which case the problem is left untouched.
+func (p *Problem) Maximize(coeffs ...map[string]float64) error {
+	done, err := p.modify()
+	if err != nil {
+		return err
+	}
+	defer done()
+	if err := p.setCoeffs(coeffs); err != nil {
+		return err
+	}
 	p.maximize = true
+	return nil
 }
 
-func (p *Problem) Minimize() {
+// Minimize sets the objective function to be minimized, which is the default.
+// Optionally, the objective coefficients of the variables can be set in bulk by name, as with Variable.SetCoeff.
+// An error is returned if a name does not belong to any variable of the problem, in which case the problem is left untouched.
+func (p *Problem) Minimize(coeffs ...map[string]float64) error {
+	done, err := p.modify()
+	if err != nil {
+		return err
+	}
+	defer done()
+	if err := p.setCoeffs(coeffs); err != nil {
+		return err
+	}
 	p.maximize = false
+	return nil
+}
+
+// set the objective coefficients of the variables with the names in the maps, after checking that all names belong to a variable.
+func (p *Problem) setCoeffs(coeffs []map[string]float64) error {
+	byName := make(map[string][]*Variable)
+	for _, v := range p.variables {
+		byName[v.name] = append(byName[v.name], v)
+	}
+
+	for _, m := range coeffs {
+		for name := range m {
+			if len(byName[name]) == 0 {
+				return fmt.Errorf("variable %v not found", name)
+			}
+		}
+	}
+
+	for _, m := range coeffs {
+		for name, coef := range m {
+			for _, v := range byName[name] {
+				v.SetCoeff(coef)
+			}
+		}
+	}
+	return nil
+}
+
+// Relax removes the integrality constraints of all variables in-place, turning the problem into its LP relaxation.
+// The integrality constraints can be restored with Problem.Tighten.
+func (p *Problem) Relax() {
+	defer p.mustModify()()
+	for _, v := range p.variables {
+		if v.integer {
+			v.integer = false
+			v.relaxed = true
+		}
+	}
+}
+
+// RelaxIntegrality returns a copy of the problem without integrality constraints, i.e. its LP relaxation. The problem itself is left untouched.
+func (p *Problem) RelaxIntegrality() Problem {
+	relaxation := p.Clone()
+	relaxation.Relax()
+	return *relaxation
+}
+
+// Tighten restores the integrality constraints removed by Problem.Relax, but only for the variables that
+// have a fractional value in the provided solution of the relaxed problem, i.e. for which integrality actually matters.
+func (p *Problem) Tighten(relaxed *Solution) error {
+	done, err := p.modify()
+	if err != nil {
+		return err
+	}
+	defer done()
+	for _, v := range p.variables {
+		if !v.relaxed {
+			continue
+		}
+
+		val, err := relaxed.GetValueFor(v.name)
+		if err != nil {
+			return err
+		}
+
+		if !isAllIntegerWithTol(val, p.integralityTol) {
+			v.integer = true
+			v.relaxed = false
+		}
+	}
+	return nil
+}
+
+// SetIntegralityTolerance sets the maximum distance between the value of an integer-constrained variable and the nearest integer
+// at which the variable is considered to be integral. This absorbs rounding errors of the LP solver. A tolerance of zero requires exact integers.
+func (p *Problem) SetIntegralityTolerance(tol float64) {
+	defer p.mustModify()()
+	p.integralityTol = tol
+}
+
+// SetObjectiveOffset sets the constant term of the objective function, e.g. a fixed cost that does not depend on any variable.
+// The offset is included in the objective value of the solution, but does not affect which solution is optimal.
+func (p *Problem) SetObjectiveOffset(c0 float64) {
+	defer p.mustModify()()
+	p.objectiveOffset = c0
+}
+
+// ObjectiveOffset returns the constant term of the objective function.
+func (p *Problem) ObjectiveOffset() float64 {
+	return p.objectiveOffset
 }
 
 func (p *Problem) BranchingHeuristic(choice BranchHeuristic) {
+	defer p.mustModify()()
 	p.branchingHeuristic = choice
 }
 
+// SetBranchingStrategy sets a custom strategy to select the variable to branch on, which takes precedence over the branching heuristic.
+// Note that the strategy operates on the variable indices of the problem after presolving, which may differ from the order in which variables were added.
+func (p *Problem) SetBranchingStrategy(strategy BranchingStrategy) {
+	defer p.mustModify()()
+	p.branchingStrategy = strategy
+}
+
+// SetBranchDirection sets the direction to explore first when branching on a variable that has no direction of its own.
+func (p *Problem) SetBranchDirection(direction BranchDirection) {
+	defer p.mustModify()()
+	p.branchDirection = direction
+}
+
+// SetNodeSelection sets the queue that determines the order in which the subProblems of the branch-and-bound procedure are explored.
+// For example, NewBestBoundQueue explores the subProblem with the lowest bound first. Defaults to FIFO order (see NewFIFOQueue).
+func (p *Problem) SetNodeSelection(factory NodeQueueFactory) {
+	defer p.mustModify()()
+	p.nodeSelection = factory
+}
+
+// SetNodeSelectionStrategy sets the order in which the subProblems of the branch-and-bound procedure are explored.
+// A queue set using SetNodeSelection takes precedence over the strategy.
+func (p *Problem) SetNodeSelectionStrategy(strategy NodeSelectionStrategy) {
+	defer p.mustModify()()
+	p.nodeSelectionStrategy = strategy
+}
+
 func (p *Problem) SetWorkers(n int) {
+	defer p.mustModify()()
 	p.workers = n
 }
 
 func (p *Problem) SetInstrumentation(b BnbMiddleware) {
+	defer p.mustModify()()
 	p.instrumentation = b
 }
 
+// DisablePresolve makes the solver bypass the presolve procedure, solving the problem exactly as formulated.
+// This can be useful when debugging formulations.
+func (p *Problem) DisablePresolve() *Problem {
+	defer p.mustModify()()
+	p.skipPresolve = true
+	return p
+}
+
+// EnableEquilibration makes the presolve procedure scale the problem using equilibration (see Problem.EquilibrationScale).
+// The solution is scaled back before it is returned. Has no effect if the presolve procedure is disabled.
+func (p *Problem) EnableEquilibration() *Problem {
+	defer p.mustModify()()
+	p.equilibrate = true
+	return p
+}
+
+// EnableCoefficientScaling makes the presolve procedure scale the problem using geometric mean scaling (see Problem.GeometricMeanScale).
+// The solution is scaled back before it is returned. Has no effect if the presolve procedure is disabled.
+func (p *Problem) EnableCoefficientScaling() *Problem {
+	defer p.mustModify()()
+	p.scaleCoefficients = true
+	return p
+}
+
+// SetSolverConfig sets the optional configuration of the branch-and-bound procedure.
+func (p *Problem) SetSolverConfig(config SolverConfig) {
+	defer p.mustModify()()
+	p.config = config
+}
+
+// GetObjectiveValue evaluates the objective function for a given assignment of values to the variables, without solving.
+// The value includes the objective offset.
+// The assignment maps variable names to their values and must contain a value for each variable in the problem.
+func (p *Problem) GetObjectiveValue(assignment map[string]float64) (float64, error) {
+	var z float64
+	for _, v := range p.variables {
+		val, ok := assignment[v.name]
+		if !ok {
+			return 0, fmt.Errorf("Variable name %v not found in assignment", v.name)
+		}
+		z += v.coefficient * val
+	}
+	return z + p.objectiveOffset, nil
+}
+
+// ObjectiveValue evaluates the objective function, including the objective offset, for the values of the variables in the order in which they were added.
+// Returns an error if the number of values does not match the number of variables.
+func (p *Problem) ObjectiveValue(x []float64) (float64, error) {
+	if len(x) != p.NumVariables() {
+		return 0, fmt.Errorf("got %v values for %v variables", len(x), p.NumVariables())
+	}
+
+	z := p.objectiveOffset
+	for i, v := range p.variables {
+		z += v.coefficient * x[i]
+	}
+	return z, nil
+}
+
+// NumVariables returns the number of variables of the problem.
+func (p *Problem) NumVariables() int {
+	return len(p.variables)
+}
+
+// GetConstraintLHS evaluates the left-hand side of the constraint for a given assignment of values to the variables.
+// The assignment must contain a value for each variable referenced in the constraint.
+func (p *Problem) GetConstraintLHS(c *Constraint, assignment map[string]float64) (float64, error) {
+	var lhs float64
+	for _, e := range c.lhsExpressions() {
+		val, ok := assignment[e.variable.name]
+		if !ok {
+			return 0, fmt.Errorf("Variable name %v not found in assignment", e.variable.name)
+		}
+		lhs += e.coef * val
+	}
+	return lhs, nil
+}
+
+// GetLHSValue evaluates the left-hand side of the constraint, including the big-M term (if any), for the values of the variables of its problem
+// in the order in which they were added. Returns an error if the number of values does not match the number of variables.
+func (c *Constraint) GetLHSValue(x []float64) (float64, error) {
+	if n := c.problem.NumVariables(); len(x) != n {
+		return 0, fmt.Errorf("got %v values for %v variables", len(x), n)
+	}
+
+	var lhs float64
+	for _, e := range c.lhsExpressions() {
+		lhs += e.coef * x[c.problem.getVariableIndex(e.variable)]
+	}
+	return lhs, nil
+}
+
+// AllConstraintViolations evaluates each named constraint for the values of the variables in the order in which they were added, and returns the residuals keyed by name.
+// The residual of an equality is lhs - rhs, whereas that of an inequality is the amount by which it is violated, or zero if it is satisfied.
+// The two sides of a range constraint are reported as one under the name of the range, of which the residual is the amount by which either side is violated.
+// Unnamed constraints are omitted. Returns an error if the number of values does not match the number of variables.
+func (p *Problem) AllConstraintViolations(x []float64) (map[string]float64, error) {
+	residuals := make([]float64, len(p.constraints))
+	ranges := make(map[int]float64)
+	for i, c := range p.constraints {
+		lhs, err := c.GetLHSValue(x)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !c.inequality:
+			residuals[i] = lhs - c.rhs
+		case c.greaterThanOrEqual:
+			residuals[i] = math.Max(0, c.rhs-lhs)
+		default:
+			residuals[i] = math.Max(0, lhs-c.rhs)
+		}
+
+		if c.rangeID != 0 {
+			ranges[c.rangeID] = math.Max(ranges[c.rangeID], math.Abs(residuals[i]))
+		}
+	}
+
+	violations := make(map[string]float64)
+	for i, c := range p.constraints {
+		if c.name == "" {
+			continue
+		}
+		if c.rangeID != 0 {
+			violations[c.name] = ranges[c.rangeID]
+			continue
+		}
+		violations[c.name] = residuals[i]
+	}
+	return violations, nil
+}
+
+// the ratio between a big-M value and the largest other constraint coefficient above which we warn about numerical instability.
+const bigMWarningRatio = 1e6
+
+// NumericalWarnings inspects the problem for formulations that are known to cause numerical instability in the solver,
+// such as big-M values that are very large relative to the other constraint coefficients.
+func (p *Problem) NumericalWarnings() []string {
+	var warnings []string
+
+	// find the largest absolute constraint coefficient that is not a big-M value
+	var maxCoef float64
+	for _, c := range p.constraints {
+		for _, e := range c.expressions {
+			maxCoef = math.Max(maxCoef, math.Abs(e.coef))
+		}
+	}
+	if maxCoef == 0 {
+		maxCoef = 1
+	}
+
+	for i, c := range p.constraints {
+		if c.bigMIndicator == nil {
+			continue
+		}
+		if math.Abs(c.bigM)/maxCoef > bigMWarningRatio {
+			warnings = append(warnings, fmt.Sprintf("constraint %v: big-M value %v on indicator %v is very large relative to the largest other coefficient (%v)", i, c.bigM, c.bigMIndicator.name, maxCoef))
+		}
+	}
+
+	return warnings
+}
+
+// Validate checks the problem for malformed input that cannot be solved, returning an error for each problem found.
+// It checks that the problem has at least one variable and one constraint, that the variable names are unique,
+// that all coefficients are finite, that the bounds of each variable are consistent, and that all constraints only refer to variables of this problem.
+func (p *Problem) Validate() []error {
+	var errs []error
+
+	if len(p.variables) == 0 {
+		errs = append(errs, errors.New("problem has no variables"))
+	}
+	if len(p.constraints) == 0 {
+		errs = append(errs, errors.New("problem has no constraints"))
+	}
+
+	names := make(map[string]bool)
+	for _, v := range p.variables {
+		if names[v.name] {
+			errs = append(errs, fmt.Errorf("duplicate variable name %v", v.name))
+		}
+		names[v.name] = true
+
+		if math.IsNaN(v.coefficient) || math.IsInf(v.coefficient, 0) {
+			errs = append(errs, fmt.Errorf("variable %v: objective coefficient %v is not finite", v.name, v.coefficient))
+		}
+		if math.IsNaN(v.lower) || math.IsNaN(v.upper) || v.lower > v.upper {
+			errs = append(errs, fmt.Errorf("variable %v: lower bound %v is not smaller than or equal to upper bound %v", v.name, v.lower, v.upper))
+		} else if math.IsInf(v.lower, 1) || math.IsInf(v.upper, -1) {
+			errs = append(errs, fmt.Errorf("variable %v: bounds [%v, %v] do not admit a finite value", v.name, v.lower, v.upper))
+		}
+	}
+
+	constraintNames := make(map[string]bool)
+	for i, c := range p.constraints {
+		if c.name != "" {
+			if constraintNames[c.name] {
+				errs = append(errs, fmt.Errorf("duplicate constraint name %v", c.name))
+			}
+			constraintNames[c.name] = true
+		}
+
+		for _, e := range c.lhsExpressions() {
+			if !p.checkExpression(e) {
+				errs = append(errs, fmt.Errorf("constraint %v: variable %v is not part of the problem", i, e.variable.name))
+			}
+			if math.IsNaN(e.coef) || math.IsInf(e.coef, 0) {
+				errs = append(errs, fmt.Errorf("constraint %v: coefficient %v of variable %v is not finite", i, e.coef, e.variable.name))
+			}
+		}
+		if math.IsNaN(c.rhs) || math.IsInf(c.rhs, 0) {
+			errs = append(errs, fmt.Errorf("constraint %v: right-hand side %v is not finite", i, c.rhs))
+		}
+	}
+
+	for i, s := range p.sos1 {
+		for _, v := range s.variables {
+			if !p.checkExpression(expression{variable: v}) {
+				errs = append(errs, fmt.Errorf("SOS1 constraint %v: variable %v is not part of the problem", i, v.name))
+			} else if v.lower < 0 {
+				errs = append(errs, fmt.Errorf("SOS1 constraint %v: variable %v may take on negative values", i, v.name))
+			}
+		}
+	}
+
+	return errs
+}
+
+// combine the errors found by Validate into a single error wrapping ErrInvalidProblem, or return nil if the problem is valid.
+func (p *Problem) validationError() error {
+	errs := p.Validate()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidProblem, strings.Join(msgs, "; "))
+}
+
 // Check whether the expression is legal considering the variables currently present in the problem
 func (p *Problem) checkExpression(e expression) bool {
 
@@ -194,7 +1065,32 @@ func (p Problem) toSolveable() *milpProblem {
 	// simultaneously parse the integrality constraints
 	var c []float64
 	var integrality []bool
-	for _, v := range p.variables {
+	var directions []BranchDirection
+	customDirections := false
+	var binary []bool
+	anyBinary := false
+	var priorities []int
+	anyPriority := false
+
+	// Variables with a negative lower bound are shifted to be nonnegative by substituting x = x' + offset.
+	// The offset of integer-constrained variables is rounded up to preserve their integrality.
+	offsets := make([]float64, len(p.variables))
+	shifted := false
+
+	// Free variables are split into the difference of two nonnegative variables: x = x+ - x-.
+	// The negative parts are appended as additional columns, such that the original variables keep their index.
+	var free []int
+
+	for i, v := range p.variables {
+		if math.IsInf(v.lower, -1) {
+			free = append(free, i)
+		} else if v.lower < 0 {
+			offsets[i] = v.lower
+			if v.integer {
+				offsets[i] = math.Ceil(v.lower)
+			}
+			shifted = true
+		}
 
 		// if the Problem is set to be maximized, we assume that all variable coefficients reflect that.
 		// To turn this maximization problem into a minimization one, we multiply all coefficients with -1.
@@ -205,6 +1101,60 @@ func (p Problem) toSolveable() *milpProblem {
 
 		c = append(c, k)
 		integrality = append(integrality, v.integer)
+
+		direction := p.branchDirection
+		if v.hasBranchDirection {
+			direction = v.branchDirection
+		}
+		directions = append(directions, direction)
+		customDirections = customDirections || direction != BRANCH_DOWN_FIRST
+
+		// the bounds of a binary variable may have been changed after it was added, in which case it is treated as a general integer
+		isBinary := v.isBinary && v.integer && v.lower == 0 && v.upper == 1
+		binary = append(binary, isBinary)
+		anyBinary = anyBinary || isBinary
+
+		priorities = append(priorities, v.branchingPriority)
+		anyPriority = anyPriority || v.branchingPriority != 0
+	}
+
+	// only pass on the branching directions if they deviate from the default
+	if !customDirections {
+		directions = nil
+	}
+
+	// only pass on the binary variables if there are any
+	if !anyBinary {
+		binary = nil
+	}
+
+	// only pass on the branching priorities if any are set
+	if !anyPriority {
+		priorities = nil
+	}
+
+	// the negative parts of free variables inherit the objective coefficient (negated), integrality, branching direction and branching priority of the original variable
+	for _, i := range free {
+		c = append(c, -c[i])
+		integrality = append(integrality, integrality[i])
+		if directions != nil {
+			directions = append(directions, directions[i])
+		}
+		// free variables are never binary
+		if binary != nil {
+			binary = append(binary, false)
+		}
+		if priorities != nil {
+			priorities = append(priorities, priorities[i])
+		}
+	}
+
+	// extend a row of coefficients of the original variables with the negated coefficients of the negative parts of the free variables
+	withNegativeParts := func(row []float64) []float64 {
+		for _, i := range free {
+			row = append(row, -row[i])
+		}
+		return row
 	}
 
 	/// parse the constraints
@@ -217,53 +1167,63 @@ func (p Problem) toSolveable() *milpProblem {
 		// build the matrix row
 		indexRow := make([]float64, len(p.variables))
 
-		for _, exp := range constraint.expressions {
+		for _, exp := range constraint.lhsExpressions() {
 			i := p.getVariableIndex(exp.variable)
-			indexRow[i] = exp.coef
+			indexRow[i] += exp.coef
 		}
 
+		// move the offsets of the shifted variables to the right-hand side
+		rhs := constraint.rhs - floats.Dot(indexRow, offsets)
+
 		if constraint.inequality {
-			Gdata = append(Gdata, indexRow...)
+
+			// 'greater than or equal to' inequalities are negated to fit the G * x <= h form
+			if constraint.greaterThanOrEqual {
+				for i := range indexRow {
+					indexRow[i] = -indexRow[i]
+				}
+				rhs = -rhs
+			}
+
+			Gdata = append(Gdata, withNegativeParts(indexRow)...)
 
 			// add the RHS of the inequality to the h vector
-			h = append(h, constraint.rhs)
+			h = append(h, rhs)
 		} else {
-			Adata = append(Adata, indexRow...)
+			Adata = append(Adata, withNegativeParts(indexRow)...)
 			// add the RHS of the equality to the b vector
-			b = append(b, constraint.rhs)
+			b = append(b, rhs)
 		}
 
 	}
 
 	// combine the Adata vector into a matrix
-	var A *mat.Dense
+	var A ConstraintMatrix
 	if len(b) > 0 {
-		A = mat.NewDense(len(b), len(p.variables), Adata)
+		A = newConstraintMatrix(len(b), len(c), Adata)
 	}
 
 	// add the variable bounds as inequality constraints
-	for _, v := range p.variables {
+	for i, v := range p.variables {
 
 		// convert the upper bound to a row in the constraint matrix
 		if !math.IsInf(v.upper, 1) {
 			uRow := make([]float64, len(p.variables))
-			i := p.getVariableIndex(v)
 			uRow[i] = 1
 
-			Gdata = append(Gdata, uRow...)
+			Gdata = append(Gdata, withNegativeParts(uRow)...)
 
-			// add the RHS of the inequality to the h vector
-			h = append(h, v.upper)
+			// add the RHS of the inequality to the h vector, taking the offset of shifted variables into account
+			h = append(h, v.upper-offsets[i])
 		}
 
 		// convert the lower bound to a row in the constraint matrix
-		// but ONLY if it is nonzero and nonnegative, because negative domain is illegal and the lower bound of the variable is zero by default.
+		// but ONLY if it is positive, because the lower bound of the variable is zero by default and negative lower bounds are dealt with by shifting the variable.
 		if !(v.lower <= 0) {
 			uRow := make([]float64, len(p.variables))
-			i := p.getVariableIndex(v)
 			uRow[i] = -1
 
-			Gdata = append(Gdata, uRow...)
+			Gdata = append(Gdata, withNegativeParts(uRow)...)
 
 			// add the RHS of the inequality to the h vector
 			h = append(h, -v.lower)
@@ -272,9 +1232,36 @@ func (p Problem) toSolveable() *milpProblem {
 	}
 
 	// combine the Gdata vector into a matrix
-	var G *mat.Dense
+	var G ConstraintMatrix
 	if len(h) > 0 {
-		G = mat.NewDense(len(h), len(p.variables), Gdata)
+		G = newConstraintMatrix(len(h), len(c), Gdata)
+	}
+
+	// resolve the positions of the variables of the SOS1 constraints, which are nonnegative and thus neither shifted nor split
+	var sos1 []SOSConstraint
+	for _, s := range p.sos1 {
+		resolved := SOSConstraint{variables: s.variables}
+		for _, v := range s.variables {
+			resolved.indices = append(resolved.indices, p.getVariableIndex(v))
+		}
+		sos1 = append(sos1, resolved)
+	}
+
+	// only pass on the offsets if any variables were shifted
+	if !shifted {
+		offsets = nil
+	}
+
+	// like the coefficients, the constant term of the objective of a maximization problem is negated
+	objectiveOffset := p.objectiveOffset
+	if p.maximize {
+		objectiveOffset = -objectiveOffset
+	}
+
+	// a custom node queue takes precedence over the node selection strategy. The default FIFO order is left to the enumeration tree.
+	nodeSelection := p.nodeSelection
+	if nodeSelection == nil && p.nodeSelectionStrategy != NODE_FIFO {
+		nodeSelection = p.nodeSelectionStrategy.factory()
 	}
 
 	return &milpProblem{
@@ -284,41 +1271,358 @@ func (p Problem) toSolveable() *milpProblem {
 		G: G,
 		h: h,
 		integralityConstraints: integrality,
+		integralityTol:         p.integralityTol,
 		branchingHeuristic:     p.branchingHeuristic,
+		branchingStrategy:      p.branchingStrategy,
+		branchDirections:       directions,
+		binaryVariables:        binary,
+		branchingPriorities:    priorities,
+		sos1Constraints:        sos1,
+		nodeSelection:          nodeSelection,
+		offsets:                offsets,
+		freeVariables:          free,
+		objectiveOffset:        objectiveOffset,
+		config:                 p.config,
+	}
+}
+
+// EqualityConstraintMatrix returns the equality constraints A * x = b of the numerical representation of the problem that is solved,
+// in which e.g. variables with a negative lower bound are shifted and free variables are split (see toSolveable).
+// The matrix is a copy, so it is not affected by subsequent modifications of the problem. Both are nil if there are no equality constraints.
+func (p Problem) EqualityConstraintMatrix() (A *mat.Dense, b []float64) {
+	milp := p.toSolveable()
+	if milp.A == nil {
+		return nil, nil
+	}
+	return mat.DenseCopyOf(milp.A.ToDense()), append([]float64(nil), milp.b...)
+}
+
+// InequalityConstraintMatrix returns the inequality constraints G * x <= h of the numerical representation of the problem that is solved,
+// including the upper bounds of the variables. The matrix is a copy, so it is not affected by subsequent modifications of the problem.
+// Both are nil if there are no inequality constraints.
+func (p Problem) InequalityConstraintMatrix() (G *mat.Dense, h []float64) {
+	milp := p.toSolveable()
+	if milp.G == nil {
+		return nil, nil
 	}
+	return mat.DenseCopyOf(milp.G.ToDense()), append([]float64(nil), milp.h...)
+}
+
+// ObjectiveVector returns the objective coefficients c of the numerical representation of the problem that is solved, which is always minimized.
+// Hence, the coefficients of a maximization problem are negated.
+func (p Problem) ObjectiveVector() []float64 {
+	return append([]float64(nil), p.toSolveable().c...)
+}
+
+// IntegralityVector returns whether each column of the numerical representation of the problem that is solved is integer-constrained.
+func (p Problem) IntegralityVector() []bool {
+	return append([]bool(nil), p.toSolveable().integralityConstraints...)
 }
 
 // SolveWithCtx converts the abstract Problem to a MILPproblem, solves it, and parses its output.
 // Context requires a context.Context as an argument to govern cancellation and solve deadlines.
+// It is a convenience for calling Solve without any options.
 func (p Problem) SolveWithCtx(ctx context.Context) (*Solution, error) {
 
-	preprocessor := newPreprocessor()
-	prepped := preprocessor.preSolve(p)
+	return p.Solve(ctx)
 
-	milp := prepped.toSolveable()
+}
 
-	subSolution, err := milp.solve(ctx, prepped.workers, prepped.instrumentation)
-	if err != nil {
+// LPSolution contains the results of solving the LP relaxation of a Problem.
+type LPSolution struct {
+	Objective float64
+
+	// LP_OPTIMAL if the relaxation was solved, or INFEASIBLE or UNBOUNDED if it has no optimal solution
+	Status SolveStatus
+
+	// The dual values (shadow prices) of the constraints, in the order in which the constraints were added.
+	// Each is the rate at which the optimal objective value changes as the right-hand side of the constraint increases.
+	// Nil if the optimal basis could not be determined.
+	Duals []float64
+
+	// keyed by name
+	byName map[string]float64
+}
+
+// GetValueFor retrieves the value for a decision variable by its name.
+func (s *LPSolution) GetValueFor(varName string) (float64, error) {
+	val, ok := s.byName[varName]
+	if !ok {
+		return 0, fmt.Errorf("Variable name %v not found in LPSolution", varName)
+	}
+	return val, nil
+}
+
+// IsInfeasible checks whether the problem is infeasible by solving only its LP relaxation, which is much faster than a full solve.
+// If the relaxation is infeasible, so is the problem. Note that a feasible relaxation does not imply that the problem has an integer-feasible solution.
+// Solve performs the same check before branching, and reports an infeasible relaxation as STATUS_INFEASIBLE.
+// Other failures to solve the relaxation, e.g. an unbounded relaxation, are returned as an error.
+func (p Problem) IsInfeasible() (bool, error) {
+	if err := p.validationError(); err != nil {
+		return false, err
+	}
+
+	relaxation := p.toSolveable().toInitialSubproblem().solve()
+	if relaxation.err == nil {
+		return false, nil
+	}
+
+	err := translateRootFailure(relaxation)
+	if errors.Is(err, INITIAL_RELAXATION_NOT_FEASIBLE) {
+		return true, nil
+	}
+	return false, relaxation.err
+}
+
+// SolveRelaxation solves the LP relaxation of the problem, i.e. ignoring the integrality constraints, and returns the primal and dual values.
+// The problem is not presolved, so that each constraint has a dual value.
+// If the relaxation cannot be solved, the error is returned along with an LPSolution that describes its status. Invalid problems yield a nil LPSolution.
+func (p Problem) SolveRelaxation(ctx context.Context) (*LPSolution, error) {
+	if err := p.validationError(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	// convert the solution vector to a rawSolution by mapping each solution coefficient to the corresponding variable name
-	rawSol := make(map[string]float64)
-	for i, v := range prepped.variables {
-		rawSol[v.name] = subSolution.x[i]
+	milp := p.RelaxIntegrality().toSolveable()
+	relaxation, err := milp.SolveRelaxation()
+
+	soln := LPSolution{Status: STATUS_LP_OPTIMAL}
+	switch err {
+	case nil:
+	case lp.ErrInfeasible:
+		soln.Status = STATUS_INFEASIBLE
+	case lp.ErrUnbounded:
+		soln.Status = STATUS_UNBOUNDED
+	default:
+		soln.Status = STATUS_UNKNOWN
+	}
+	if err != nil {
+		return &soln, err
 	}
 
-	// postprocess the solution
-	soln := preprocessor.postSolve(rawSol)
+	soln.Objective = relaxation.z
+	soln.byName = make(map[string]float64, len(p.variables))
+	for i, v := range p.variables {
+		soln.byName[v.name] = relaxation.x[i]
+	}
 
-	return &soln, nil
+	// equality constraints correspond to the rows of A, inequality constraints to the rows of G, which follow those of A in the standard form.
+	// 'Greater than or equal to' inequalities were negated, and so was the objective of a maximization problem.
+	if relaxation.dual != nil {
+		soln.Duals = make([]float64, len(p.constraints))
+		equalities, inequalities := 0, len(milp.b)
+		for i, c := range p.constraints {
+			var y float64
+			switch {
+			case !c.inequality:
+				y = relaxation.dual[equalities]
+				equalities++
+			case c.greaterThanOrEqual:
+				y = -relaxation.dual[inequalities]
+				inequalities++
+			default:
+				y = relaxation.dual[inequalities]
+				inequalities++
+			}
+			if p.maximize {
+				y = -y
+			}
+			soln.Duals[i] = y
+		}
+	}
+
+	if p.maximize {
+		soln.Objective = -soln.Objective
+	}
 
+	return &soln, nil
 }
 
 // Solve converts the abstract Problem to a MILPproblem, solves it, and parses its output.
-// It does not time out.
-func (p *Problem) Solve() (*Solution, error) {
+// The context governs cancellation and solve deadlines.
+// The options override the settings of the Problem for this solve only (see SolveOptions).
+// If the solve fails, the error is returned along with a Solution that describes its status and statistics,
+// and holds the best integer-feasible solution found before the procedure stopped, if any. Invalid problems yield a nil Solution.
+func (p Problem) Solve(ctx context.Context, opts ...SolveOption) (*Solution, error) {
+	return p.solveWith(ctx, nil, opts)
+}
+
+// SolveWithBestEffort solves the problem like Solve, but reports the outcome of a solve that was stopped early by its status rather than an error,
+// which suits applications that use the best solution found within a time limit. If the deadline of the context passes before the search is finished,
+// the status is STATUS_TIME_LIMIT_REACHED. Similarly, reaching the node limit, cancelling the context, or proving the problem infeasible or unbounded only show in the status.
+// The Solution holds the best integer-feasible solution found, and is nil if none was found.
+// An error is only returned if the problem could not be solved at all, e.g. because it is invalid or the LP solver failed.
+func (p Problem) SolveWithBestEffort(ctx context.Context, opts ...SolveOption) (*Solution, SolveStatus, error) {
+	soln, err := p.Solve(ctx, opts...)
+	if soln == nil {
+		return nil, STATUS_UNKNOWN, err
+	}
+
+	status := soln.Status
+	if errors.Is(err, context.DeadlineExceeded) {
+		status = STATUS_TIME_LIMIT_REACHED
+	} else if err != nil && status == STATUS_UNKNOWN && !errors.Is(err, context.Canceled) {
+		// the status does not explain why the procedure failed
+		return nil, status, err
+	}
+
+	if soln.byName == nil {
+		return nil, status, nil
+	}
+	soln.Status = status
+	return soln, status, nil
+}
+
+// decide the problem of which all variables were fixed by the presolve procedure, by checking the fixed assignment against the constraints of the problem.
+// The result is the fixed assignment, which is optimal as it is the only candidate, or infeasible if it violates a constraint.
+func (p Problem) solveFixed(prepper *preProcessor) (MIPResult, error) {
+	var result MIPResult
+	fixed := prepper.postSolve(rawSolution{})
+	if !p.satisfiedBy(fixed.byName) {
+		result.Status = STATUS_INFEASIBLE
+		return result, &InfeasibleError{NodeID: 0, Reason: INITIAL_RELAXATION_NOT_FEASIBLE.Error(), err: INITIAL_RELAXATION_NOT_FEASIBLE}
+	}
+
+	// the objective value of the milpProblem is minimized
+	z, err := p.GetObjectiveValue(fixed.byName)
+	if err != nil {
+		return result, err
+	}
+	if p.maximize {
+		z = -z
+	}
+
+	result.BestIntegerSolution = &solution{z: z, bestBound: z}
+	result.LPRelaxation = *result.BestIntegerSolution
+	result.Stats.RootLPBound = z
+	result.Stats.BestBound = z
+	result.Status = STATUS_OPTIMAL
+	return result, nil
+}
+
+// solve the problem as described by Solve, allowing the caller to configure the converted problem before it is solved. The configure function is ignored if nil.
+func (p Problem) solveWith(ctx context.Context, configure func(*milpProblem), opts []SolveOption) (*Solution, error) {
+	options := p.solveOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	p = p.withOptions(options)
+
+	// the problem cannot be modified while it is being solved, which includes its validation
+	p.lock.beginSolve()
+	defer p.lock.endSolve()
+
+	if err := p.validationError(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := options.context(ctx)
+	defer cancel()
+
+	start := time.Now()
+
+	var reoptimize bool
+	p.lock.do(func() { reoptimize = p.cuts.reoptimize() })
+
+	preprocessor := newPreprocessor()
+	prepped := p
+	var presolveStats PresolveStats
+	if !p.skipPresolve && !reoptimize {
+		prepped, presolveStats = preprocessor.preSolve(p)
+	}
+
+	milp := prepped.toSolveable()
+
+	// when re-optimizing after adding cuts, the previous incumbent serves as a warm start if it is still feasible
+	var previous rawSolution
+	p.lock.do(func() { previous = p.cuts.inject(milp, p) })
+
+	var warnings []string
+	if options.InitialSolution != nil {
+		var warning string
+		milp.initialSolution, warning = prepped.initialColumns(milp, options.InitialSolution)
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	if configure != nil {
+		configure(milp)
+	}
+
+	// If the procedure failed, the solution only holds the status and statistics, unless an integer-feasible incumbent was found before it stopped.
+	var result MIPResult
+	var err error
+	if len(prepped.variables) == 0 && len(p.variables) > 0 {
+		// the presolve procedure fixed all variables, so there is no LP to solve
+		result, err = p.solveFixed(preprocessor)
+	} else {
+		result, err = milp.solve(ctx, prepped.workers, prepped.instrumentation)
+	}
+
+	var soln Solution
+	if result.BestIntegerSolution != nil {
+		// convert the solution vector to a rawSolution by mapping each solution coefficient to the corresponding variable name
+		rawSol := make(map[string]float64)
+		for i, v := range prepped.variables {
+			rawSol[v.name] = result.BestIntegerSolution.x[i]
+		}
+
+		// postprocess the solution
+		soln = preprocessor.postSolve(rawSol)
+		soln.BestBound = result.BestIntegerSolution.bestBound
+		soln.MIPGap = result.BestIntegerSolution.mipGap()
+	} else if previous != nil {
+		// no solution better than the previous incumbent was found, which is therefore optimal unless the search was stopped early
+		soln = preprocessor.postSolve(previous)
+		bound, boundErr := p.GetObjectiveValue(previous)
+		if boundErr != nil {
+			return nil, fmt.Errorf("evaluating the objective of the previous incumbent: %w", boundErr)
+		}
+		soln.BestBound = bound
+		if p.maximize {
+			soln.BestBound = -soln.BestBound
+		}
+		result.Status = STATUS_FEASIBLE_NOT_OPTIMAL
+		if errors.Is(err, NO_INTEGER_FEASIBLE_SOLUTION) {
+			result.Status = STATUS_OPTIMAL
+			err = nil
+		}
+	}
+
+	if soln.byName != nil {
+		objective, objectiveErr := p.GetObjectiveValue(soln.byName)
+		if objectiveErr != nil {
+			return nil, fmt.Errorf("evaluating the objective of the solution: %w", objectiveErr)
+		}
+		soln.Objective = objective
+	}
+
+	soln.constraints = p.constraints
+	soln.variables = make([]string, len(p.variables))
+	for i, v := range p.variables {
+		soln.variables[i] = v.name
+	}
+	p.lock.do(func() {
+		if p.solved != nil {
+			*p.solved = true
+		}
+		p.cuts.solved(&soln)
+	})
+	p.stripInternalVariables(&soln)
+	soln.Status = result.Status
+	soln.Stats = result.Stats
+	soln.Warnings = append(warnings, result.Warnings...)
+	soln.Stats.PresolveStats = presolveStats
+	soln.Stats.WallTime = time.Since(start)
+	if p.maximize {
+		soln.Stats.RootLPBound = -soln.Stats.RootLPBound
+		soln.Stats.BestBound = -soln.Stats.BestBound
+		soln.BestBound = -soln.BestBound
+	}
 
-	return p.SolveWithCtx(context.Background())
+	return &soln, err
 
 }
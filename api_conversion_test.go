@@ -3,8 +3,6 @@ package ilp
 import (
 	"testing"
 
-	"gonum.org/v1/gonum/mat"
-
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,25 +19,26 @@ func TestProblem_toSolveableA(t *testing.T) {
 	v4 := prob.AddVariable("v4").SetCoeff(3)
 
 	// add the equality constraints
-	prob.AddConstraint().AddExpression(1, v1).EqualTo(5)
-	prob.AddConstraint().AddExpression(3, v2).EqualTo(2)
-	prob.AddConstraint().AddExpression(1, v3).EqualTo(2)
-	prob.AddConstraint().AddExpression(1, v4).SmallerThanOrEqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v1).EqualTo(5)
+	prob.AddConstraint("").AddExpression(3, v2).EqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v3).EqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v4).SmallerThanOrEqualTo(2)
 
 	solveable := prob.toSolveable()
 	expected := milpProblem{
 		c: []float64{-1, -2, 1, 3},
-		A: mat.NewDense(3, 4, []float64{
+		A: NewDenseConstraints(3, 4, []float64{
 			1, 0, 0, 0,
 			0, 3, 0, 0,
 			0, 0, 1, 0,
 		}),
 		b: []float64{5, 2, 2},
-		G: mat.NewDense(1, 4, []float64{
+		G: NewDenseConstraints(1, 4, []float64{
 			0, 0, 0, 1,
 		}),
 		h: []float64{2},
 		integralityConstraints: []bool{false, false, false, false},
+		integralityTol:         DefaultIntegralityTolerance,
 	}
 
 	//Note:  do not compare pointers
@@ -58,14 +57,14 @@ func TestProblem_toSolveableB(t *testing.T) {
 	v3 := prob.AddVariable("v3").IsInteger().SetCoeff(1)
 
 	// add the equality constraints
-	prob.AddConstraint().AddExpression(1, v1).EqualTo(5)
-	prob.AddConstraint().AddExpression(3, v2).EqualTo(2)
-	prob.AddConstraint().AddExpression(1, v3).EqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v1).EqualTo(5)
+	prob.AddConstraint("").AddExpression(3, v2).EqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v3).EqualTo(2)
 
 	solveable := prob.toSolveable()
 	expected := milpProblem{
 		c: []float64{-1, -2, 1},
-		A: mat.NewDense(3, 3, []float64{
+		A: NewDenseConstraints(3, 3, []float64{
 			1, 0, 0,
 			0, 3, 0,
 			0, 0, 1,
@@ -74,6 +73,7 @@ func TestProblem_toSolveableB(t *testing.T) {
 		G: nil,
 		h: nil,
 		integralityConstraints: []bool{false, true, true},
+		integralityTol:         DefaultIntegralityTolerance,
 	}
 
 	//Note:  do not compare pointers
@@ -92,9 +92,9 @@ func TestProblem_toSolveableC(t *testing.T) {
 	v3 := prob.AddVariable("v3").SetCoeff(1).IsInteger()
 
 	// add the equality constraints
-	prob.AddConstraint().AddExpression(1, v1).EqualTo(5)
-	prob.AddConstraint().AddExpression(3, v2).EqualTo(2)
-	prob.AddConstraint().AddExpression(1, v3).EqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v1).EqualTo(5)
+	prob.AddConstraint("").AddExpression(3, v2).EqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v3).EqualTo(2)
 
 	// set the problem to maximize
 	prob.Maximize()
@@ -102,7 +102,7 @@ func TestProblem_toSolveableC(t *testing.T) {
 	solveable := prob.toSolveable()
 	expected := milpProblem{
 		c: []float64{1, 2, -1},
-		A: mat.NewDense(3, 3, []float64{
+		A: NewDenseConstraints(3, 3, []float64{
 			1, 0, 0,
 			0, 3, 0,
 			0, 0, 1,
@@ -111,6 +111,7 @@ func TestProblem_toSolveableC(t *testing.T) {
 		G: nil,
 		h: nil,
 		integralityConstraints: []bool{false, true, true},
+		integralityTol:         DefaultIntegralityTolerance,
 	}
 
 	//Note:  do not compare pointers
@@ -129,9 +130,9 @@ func TestProblem_toSolveableD(t *testing.T) {
 	v3 := prob.AddVariable("v3").SetCoeff(1).IsInteger()
 
 	// add the equality constraints
-	prob.AddConstraint().AddExpression(1, v1).AddExpression(1, v2).EqualTo(5)
-	prob.AddConstraint().AddExpression(3, v2).EqualTo(2)
-	prob.AddConstraint().AddExpression(1, v3).EqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v1).AddExpression(1, v2).EqualTo(5)
+	prob.AddConstraint("").AddExpression(3, v2).EqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v3).EqualTo(2)
 
 	// set the problem to maximize
 	prob.Maximize()
@@ -139,7 +140,7 @@ func TestProblem_toSolveableD(t *testing.T) {
 	solveable := prob.toSolveable()
 	expected := milpProblem{
 		c: []float64{1, 2, -1},
-		A: mat.NewDense(3, 3, []float64{
+		A: NewDenseConstraints(3, 3, []float64{
 			1, 1, 0,
 			0, 3, 0,
 			0, 0, 1,
@@ -148,6 +149,7 @@ func TestProblem_toSolveableD(t *testing.T) {
 		G: nil,
 		h: nil,
 		integralityConstraints: []bool{false, true, true},
+		integralityTol:         DefaultIntegralityTolerance,
 	}
 
 	//Note:  do not compare pointers
@@ -166,10 +168,10 @@ func TestProblem_toSolveableE(t *testing.T) {
 	v3 := prob.AddVariable("v3").SetCoeff(1).IsInteger()
 
 	// add the equality constraints
-	prob.AddConstraint().AddExpression(1, v1).AddExpression(1, v2).EqualTo(5)
-	prob.AddConstraint().AddExpression(3, v2).EqualTo(2)
-	prob.AddConstraint().AddExpression(1, v3).EqualTo(2)
-	prob.AddConstraint().AddExpression(1, v3).AddExpression(1, v1).SmallerThanOrEqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v1).AddExpression(1, v2).EqualTo(5)
+	prob.AddConstraint("").AddExpression(3, v2).EqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v3).EqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v3).AddExpression(1, v1).SmallerThanOrEqualTo(2)
 
 	// set the problem to maximize
 	prob.Maximize()
@@ -177,17 +179,18 @@ func TestProblem_toSolveableE(t *testing.T) {
 	solveable := prob.toSolveable()
 	expected := milpProblem{
 		c: []float64{1, 2, -1},
-		A: mat.NewDense(3, 3, []float64{
+		A: NewDenseConstraints(3, 3, []float64{
 			1, 1, 0,
 			0, 3, 0,
 			0, 0, 1,
 		}),
 		b: []float64{5, 2, 2},
-		G: mat.NewDense(1, 3, []float64{
+		G: NewDenseConstraints(1, 3, []float64{
 			1, 0, 1,
 		}),
 		h: []float64{2},
 		integralityConstraints: []bool{false, true, true},
+		integralityTol:         DefaultIntegralityTolerance,
 	}
 
 	//Note:  do not compare pointers
@@ -206,10 +209,10 @@ func TestProblem_toSolveableF(t *testing.T) {
 	v3 := prob.AddVariable("v3").SetCoeff(1).IsInteger()
 
 	// add the equality constraints
-	prob.AddConstraint().AddExpression(1, v1).AddExpression(1, v2).SmallerThanOrEqualTo(5)
-	prob.AddConstraint().AddExpression(3, v2).SmallerThanOrEqualTo(2)
-	prob.AddConstraint().AddExpression(1, v3).SmallerThanOrEqualTo(2)
-	prob.AddConstraint().AddExpression(1, v3).AddExpression(1, v1).SmallerThanOrEqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v1).AddExpression(1, v2).SmallerThanOrEqualTo(5)
+	prob.AddConstraint("").AddExpression(3, v2).SmallerThanOrEqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v3).SmallerThanOrEqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v3).AddExpression(1, v1).SmallerThanOrEqualTo(2)
 
 	// set the problem to maximize
 	prob.Maximize()
@@ -219,7 +222,7 @@ func TestProblem_toSolveableF(t *testing.T) {
 		c: []float64{1, 2, -1},
 		A: nil,
 		b: nil,
-		G: mat.NewDense(4, 3, []float64{
+		G: NewDenseConstraints(4, 3, []float64{
 			1, 1, 0,
 			0, 3, 0,
 			0, 0, 1,
@@ -227,6 +230,7 @@ func TestProblem_toSolveableF(t *testing.T) {
 		}),
 		h: []float64{5, 2, 2, 2},
 		integralityConstraints: []bool{false, true, true},
+		integralityTol:         DefaultIntegralityTolerance,
 	}
 
 	//Note:  do not compare pointers
@@ -245,10 +249,10 @@ func TestProblem_toSolveableG(t *testing.T) {
 	v3 := prob.AddVariable("v3").SetCoeff(1).IsInteger().LowerBound(1)
 
 	// add the equality constraints
-	prob.AddConstraint().AddExpression(1, v1).AddExpression(1, v2).SmallerThanOrEqualTo(5)
-	prob.AddConstraint().AddExpression(3, v2).SmallerThanOrEqualTo(2)
-	prob.AddConstraint().AddExpression(1, v3).SmallerThanOrEqualTo(2)
-	prob.AddConstraint().AddExpression(1, v3).AddExpression(1, v1).SmallerThanOrEqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v1).AddExpression(1, v2).SmallerThanOrEqualTo(5)
+	prob.AddConstraint("").AddExpression(3, v2).SmallerThanOrEqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v3).SmallerThanOrEqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v3).AddExpression(1, v1).SmallerThanOrEqualTo(2)
 
 	// set the problem to maximize
 	prob.Maximize()
@@ -258,7 +262,7 @@ func TestProblem_toSolveableG(t *testing.T) {
 		c: []float64{1, 2, -1},
 		A: nil,
 		b: nil,
-		G: mat.NewDense(7, 3, []float64{
+		G: NewDenseConstraints(7, 3, []float64{
 			1, 1, 0,
 			0, 3, 0,
 			0, 0, 1,
@@ -271,8 +275,196 @@ func TestProblem_toSolveableG(t *testing.T) {
 		}),
 		h: []float64{5, 2, 2, 2, 4, -2, -1},
 		integralityConstraints: []bool{false, true, true},
+		integralityTol:         DefaultIntegralityTolerance,
+	}
+
+	//Note:  do not compare pointers
+	assert.Equal(t, expected, *solveable)
+}
+
+// Mixing 'smaller than or equal to' and 'greater than or equal to' inequalities
+func TestProblem_toSolveableGreaterThanOrEqualTo(t *testing.T) {
+
+	// build an abstract Problem with 'greater than or equal to' inequalities
+	prob := NewProblem()
+	v1 := prob.AddVariable("v1").SetCoeff(-1).UpperBound(4)
+	v2 := prob.AddVariable("v2").SetCoeff(-2).IsInteger()
+	prob.AddConstraint("").AddExpression(1, v1).AddExpression(1, v2).SmallerThanOrEqualTo(5)
+	prob.AddConstraint("").AddExpression(3, v2).AddExpression(-1, v1).GreaterThanOrEqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v1).GreaterThanOrEqualTo(1)
+
+	// build the same Problem by negating the inequalities manually
+	negated := NewProblem()
+	n1 := negated.AddVariable("v1").SetCoeff(-1).UpperBound(4)
+	n2 := negated.AddVariable("v2").SetCoeff(-2).IsInteger()
+	negated.AddConstraint("").AddExpression(1, n1).AddExpression(1, n2).SmallerThanOrEqualTo(5)
+	negated.AddConstraint("").AddExpression(-3, n2).AddExpression(1, n1).SmallerThanOrEqualTo(-2)
+	negated.AddConstraint("").AddExpression(-1, n1).SmallerThanOrEqualTo(-1)
+
+	expected := milpProblem{
+		c: []float64{-1, -2},
+		A: nil,
+		b: nil,
+		G: NewDenseConstraints(4, 2, []float64{
+			1, 1,
+			1, -3,
+			-1, 0,
+
+			// var bounds
+			1, 0,
+		}),
+		h:                      []float64{5, -2, -1, 4},
+		integralityConstraints: []bool{false, true},
+		integralityTol:         DefaultIntegralityTolerance,
+	}
+
+	//Note:  do not compare pointers
+	assert.Equal(t, expected, *prob.toSolveable())
+	assert.Equal(t, *negated.toSolveable(), *prob.toSolveable())
+
+	// the presolve procedure negates the inequalities as well
+	assert.Equal(t, *negated.toSolveable(), *sanitizeProblem(copyProblem(prob)).toSolveable())
+}
+
+// A big-M constraint linking a continuous variable to a binary indicator
+func TestProblem_toSolveableBigM(t *testing.T) {
+
+	// build an abstract Problem
+	prob := NewProblem()
+
+	// add the variables
+	x := prob.AddVariable("x").SetCoeff(-1)
+	y := prob.AddVariable("y").SetCoeff(5).IsInteger().UpperBound(1)
+
+	// x <= 10 * y
+	prob.AddConstraint("").AddExpression(1, x).BigM(y, 10)
+
+	solveable := prob.toSolveable()
+	expected := milpProblem{
+		c: []float64{-1, 5},
+		A: nil,
+		b: nil,
+		G: NewDenseConstraints(2, 2, []float64{
+			1, -10,
+
+			// var bounds
+			0, 1,
+		}),
+		h:                      []float64{0, 1},
+		integralityConstraints: []bool{false, true},
+		integralityTol:         DefaultIntegralityTolerance,
 	}
 
 	//Note:  do not compare pointers
 	assert.Equal(t, expected, *solveable)
+
+	// the indicator variable must be binary
+	z := prob.AddVariable("z").IsInteger()
+	assert.Panics(t, func() { prob.AddConstraint("").AddExpression(1, x).BigM(z, 10) })
+
+	// a big-M value that is large relative to the other coefficients should raise a warning
+	assert.Empty(t, prob.NumericalWarnings())
+	prob.AddConstraint("").AddExpression(1, x).BigM(y, 1e9)
+	assert.Len(t, prob.NumericalWarnings(), 1)
+}
+
+func TestProblem_toSolveableBranchDirections(t *testing.T) {
+	prob := NewProblem()
+	prob.AddVariable("x").IsInteger()
+	prob.AddVariable("y").IsInteger().SetBranchDirection(BRANCH_UP_FIRST)
+	prob.AddVariable("z").IsInteger()
+
+	// without deviating preferences, no directions are passed on
+	plain := NewProblem()
+	plain.AddVariable("x").IsInteger()
+	assert.Nil(t, plain.toSolveable().branchDirections)
+
+	assert.Equal(t, []BranchDirection{BRANCH_DOWN_FIRST, BRANCH_UP_FIRST, BRANCH_DOWN_FIRST}, prob.toSolveable().branchDirections)
+
+	// the per-variable preference overrides the default of the problem
+	prob.SetBranchDirection(BRANCH_AUTO)
+	assert.Equal(t, []BranchDirection{BRANCH_AUTO, BRANCH_UP_FIRST, BRANCH_AUTO}, prob.toSolveable().branchDirections)
+}
+
+func TestProblem_toSolveableNodeSelection(t *testing.T) {
+	prob := NewProblem()
+	prob.AddVariable("x").IsInteger()
+
+	// the default FIFO order is left to the enumeration tree
+	assert.Nil(t, prob.toSolveable().nodeSelection)
+
+	prob.SetNodeSelectionStrategy(NODE_DEPTH_FIRST)
+	assert.IsType(t, &DepthFirstQueue{}, prob.toSolveable().nodeSelection())
+
+	// a custom queue takes precedence over the strategy
+	prob.SetNodeSelection(NewBestBoundQueue)
+	assert.IsType(t, &BestBoundQueue{}, prob.toSolveable().nodeSelection())
+}
+
+func TestProblem_toSolveableBinary(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddBinaryVariable("x").SetCoeff(-1)
+	prob.AddVariable("y").IsInteger()
+	prob.AddFreeVariable("z")
+
+	assert.True(t, x.integer)
+	assert.Equal(t, float64(0), x.lower)
+	assert.Equal(t, float64(1), x.upper)
+
+	solveable := prob.toSolveable()
+
+	// the negative part of the free variable is not binary
+	assert.Equal(t, []bool{true, false, false, false}, solveable.binaryVariables)
+
+	// the upper bound of the binary variable is added as an inequality
+	assert.Equal(t, NewDenseConstraints(1, 4, []float64{1, 0, 0, 0}), solveable.G)
+	assert.Equal(t, []float64{1}, solveable.h)
+
+	// a binary variable of which the bounds were changed is treated as a general integer
+	x.UpperBound(2)
+	assert.Nil(t, prob.toSolveable().binaryVariables)
+}
+
+func TestProblem_ConstraintMatrices(t *testing.T) {
+	prob := NewProblem()
+	prob.Maximize()
+	v1 := prob.AddVariable("v1").SetCoeff(-1).LowerBound(-2)
+	v2 := prob.AddVariable("v2").SetCoeff(2).IsInteger().UpperBound(4)
+	v3 := prob.AddVariable("v3").SetCoeff(1)
+	prob.AddConstraint("").AddExpression(1, v1).AddExpression(2, v3).EqualTo(5)
+	prob.AddConstraint("").AddExpression(3, v2).AddExpression(1, v3).SmallerThanOrEqualTo(7)
+
+	solveable := prob.toSolveable()
+
+	A, b := prob.EqualityConstraintMatrix()
+	assert.Equal(t, solveable.A.ToDense(), A)
+	assert.Equal(t, solveable.b, b)
+
+	G, h := prob.InequalityConstraintMatrix()
+	assert.Equal(t, solveable.G.ToDense(), G)
+	assert.Equal(t, solveable.h, h)
+
+	c := prob.ObjectiveVector()
+	assert.Equal(t, solveable.c, c)
+	assert.Equal(t, []float64{1, -2, -1}, c)
+	assert.Equal(t, solveable.integralityConstraints, prob.IntegralityVector())
+
+	// the snapshots are not affected by subsequent modifications of the problem
+	prob.AddConstraint("").AddExpression(1, v1).EqualTo(1)
+	v1.SetCoeff(10)
+	rows, _ := A.Dims()
+	assert.Equal(t, 1, rows)
+	assert.Equal(t, []float64{1, -2, -1}, c)
+
+	// nor do modifications of the snapshots affect the problem
+	G.Set(0, 0, 100)
+	h[0] = 100
+	G2, h2 := prob.InequalityConstraintMatrix()
+	assert.NotEqual(t, G, G2)
+	assert.NotEqual(t, h, h2)
+
+	// a problem without equality constraints
+	A, b = NewProblem().EqualityConstraintMatrix()
+	assert.Nil(t, A)
+	assert.Nil(t, b)
 }
@@ -2,12 +2,15 @@ package ilp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize/convex/lp"
 )
 
 func TestProblem_checkExpression(t *testing.T) {
@@ -71,7 +74,7 @@ func getRandomProblem(pZero float64, m, n int, rnd *rand.Rand) Problem {
 		// }
 
 		// roll the dice on whether it will become an equality or inequality
-		con := prob.AddConstraint().AddExpression(randValue(), v)
+		con := prob.AddConstraint("").AddExpression(randValue(), v)
 		if boolgenerator.Bool() {
 			con.EqualTo(randValue())
 		} else {
@@ -95,25 +98,26 @@ func TestProblem_Solve(t *testing.T) {
 	v4 := prob.AddVariable("v4").SetCoeff(3)
 
 	// add the equality constraints
-	prob.AddConstraint().AddExpression(1, v1).EqualTo(5)
-	prob.AddConstraint().AddExpression(3, v2).EqualTo(2)
-	prob.AddConstraint().AddExpression(1, v3).EqualTo(2)
-	prob.AddConstraint().AddExpression(1, v4).SmallerThanOrEqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v1).EqualTo(5)
+	prob.AddConstraint("").AddExpression(3, v2).EqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v3).EqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v4).SmallerThanOrEqualTo(2)
 
 	solveable := prob.toSolveable()
 	expected := milpProblem{
 		c: []float64{-1, -2, 1, 3},
-		A: mat.NewDense(3, 4, []float64{
+		A: NewDenseConstraints(3, 4, []float64{
 			1, 0, 0, 0,
 			0, 3, 0, 0,
 			0, 0, 1, 0,
 		}),
 		b: []float64{5, 2, 2},
-		G: mat.NewDense(1, 4, []float64{
+		G: NewDenseConstraints(1, 4, []float64{
 			0, 0, 0, 1,
 		}),
 		h: []float64{2},
 		integralityConstraints: []bool{false, false, false, false},
+		integralityTol:         DefaultIntegralityTolerance,
 	}
 
 	// check that the conversion was successful
@@ -137,3 +141,1013 @@ func TestProblem_Solve(t *testing.T) {
 	assert.Equal(t, getVal("v4"), float64(0))
 
 }
+
+func TestProblem_GetObjectiveValue(t *testing.T) {
+	prob := NewProblem()
+	v1 := prob.AddVariable("v1").SetCoeff(-1)
+	v2 := prob.AddVariable("v2").SetCoeff(2)
+	c := prob.AddConstraint("").AddExpression(1, v1).AddExpression(3, v2).SmallerThanOrEqualTo(10)
+
+	z, err := prob.GetObjectiveValue(map[string]float64{"v1": 2, "v2": 3})
+	assert.NoError(t, err)
+	assert.Equal(t, float64(4), z)
+
+	lhs, err := prob.GetConstraintLHS(c, map[string]float64{"v1": 2, "v2": 3})
+	assert.NoError(t, err)
+	assert.Equal(t, float64(11), lhs)
+
+	// missing variable names should result in an error
+	_, err = prob.GetObjectiveValue(map[string]float64{"v1": 2})
+	assert.Error(t, err)
+
+	_, err = prob.GetConstraintLHS(c, map[string]float64{"v2": 3})
+	assert.Error(t, err)
+}
+
+func TestProblem_DisablePresolve(t *testing.T) {
+	prob := NewProblem()
+	v1 := prob.AddVariable("v1").SetCoeff(-1)
+	v2 := prob.AddVariable("v2").SetCoeff(-1)
+
+	// these constraints implicitly fix v2 at zero, which the presolver would otherwise pick up
+	prob.AddConstraint("").AddExpression(1, v1).SmallerThanOrEqualTo(3)
+	prob.AddConstraint("").AddExpression(1, v2).EqualTo(0)
+
+	prob.DisablePresolve()
+	assert.True(t, prob.skipPresolve)
+
+	soln, err := prob.Solve(context.Background())
+	assert.NoError(t, err)
+
+	val, err := soln.GetValueFor("v1")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(3), val)
+
+	val, err = soln.GetValueFor("v2")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), val)
+
+	// the variables should not have been touched by the presolver
+	assert.True(t, math.IsInf(v2.upper, 1))
+}
+
+func TestProblem_NegativeLowerBound(t *testing.T) {
+	getProblem := func() Problem {
+		prob := NewProblem()
+		x := prob.AddVariable("x").SetCoeff(1).LowerBound(-3).UpperBound(5)
+		y := prob.AddVariable("y").SetCoeff(0.5).UpperBound(10)
+		prob.AddConstraint("").AddExpression(1, x).AddExpression(1, y).GreaterThanOrEqualTo(1)
+		return prob
+	}
+
+	// the shifted problem should yield the same solution with and without presolving
+	presolved := getProblem()
+	raw := getProblem()
+	raw.DisablePresolve()
+	for _, prob := range []Problem{presolved, raw} {
+		soln, err := prob.Solve(context.Background())
+		assert.NoError(t, err)
+
+		x, err := soln.GetValueFor("x")
+		assert.NoError(t, err)
+		assert.InDelta(t, float64(-3), x, 1e-9)
+
+		y, err := soln.GetValueFor("y")
+		assert.NoError(t, err)
+		assert.InDelta(t, float64(4), y, 1e-9)
+	}
+
+	// the objective value of the milpProblem should be expressed in terms of the original variables
+	milp := raw.toSolveable()
+	assert.Equal(t, []float64{-3, 0}, milp.offsets)
+	result, err := milp.solve(context.Background(), 1, dummyMiddleware{})
+	got := result.best()
+	assert.NoError(t, err)
+	assert.InDelta(t, float64(-1), got.z, 1e-9)
+}
+
+func TestProblem_SetObjectiveOffset(t *testing.T) {
+	getProblem := func(maximize bool) Problem {
+		prob := NewProblem()
+		prob.BranchingHeuristic(BRANCH_MOST_INFEASIBLE)
+		x := prob.AddVariable("x").SetCoeff(-1).LowerBound(-3).UpperBound(5).IsInteger()
+		y := prob.AddVariable("y").SetCoeff(-2).UpperBound(10).IsInteger()
+		prob.AddConstraint("").AddExpression(2, x).AddExpression(3, y).SmallerThanOrEqualTo(12.5)
+		if maximize {
+			prob.Maximize()
+			x.SetCoeff(1)
+			y.SetCoeff(2)
+		}
+		return prob
+	}
+
+	const offset = 7.25
+	for _, maximize := range []bool{false, true} {
+		plain := getProblem(maximize)
+		withOffset := getProblem(maximize)
+		withOffset.SetObjectiveOffset(offset)
+		assert.Equal(t, offset, withOffset.ObjectiveOffset())
+
+		want, err := plain.Solve(context.Background())
+		if !assert.NoError(t, err) {
+			return
+		}
+		got, err := withOffset.Solve(context.Background())
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		// the offset does not change the optimal solution, only its objective value and bound
+		assert.Equal(t, want.byName, got.byName)
+		assert.Equal(t, want.Objective+offset, got.Objective, "maximize: %v", maximize)
+		assert.InDelta(t, want.BestBound+offset, got.BestBound, 1e-9, "maximize: %v", maximize)
+		assert.InDelta(t, want.Stats.RootLPBound+offset, got.Stats.RootLPBound, 1e-9, "maximize: %v", maximize)
+		assert.InDelta(t, got.BestBound, got.Stats.BestBound, 1e-9, "maximize: %v", maximize)
+		assert.Equal(t, got.MIPGap, got.Stats.Gap, "maximize: %v", maximize)
+
+		z, err := withOffset.GetObjectiveValue(got.byName)
+		assert.NoError(t, err)
+		assert.Equal(t, got.Objective, z)
+
+		wantLP, err := plain.SolveRelaxation(context.Background())
+		assert.NoError(t, err)
+		gotLP, err := withOffset.SolveRelaxation(context.Background())
+		assert.NoError(t, err)
+		assert.InDelta(t, wantLP.Objective+offset, gotLP.Objective, 1e-9, "maximize: %v", maximize)
+	}
+
+	// a non-finite offset is rejected
+	prob := getProblem(false)
+	prob.SetObjectiveOffset(math.NaN())
+	_, err := prob.toSolveable().solve(context.Background(), 1, dummyMiddleware{})
+	assert.True(t, errors.Is(err, ErrInvalidProblem))
+}
+
+func TestProblem_ObjectiveValue(t *testing.T) {
+	for _, maximize := range []bool{false, true} {
+		prob := NewProblem()
+		prob.BranchingHeuristic(BRANCH_MOST_INFEASIBLE)
+		if maximize {
+			prob.Maximize()
+		}
+		x := prob.AddVariable("x").SetCoeff(1).LowerBound(-3).UpperBound(5).IsInteger()
+		y := prob.AddVariable("y").SetCoeff(2).UpperBound(10).IsInteger()
+		prob.AddConstraint("").AddExpression(2, x).AddExpression(-3, y).GreaterThanOrEqualTo(-12.5)
+		prob.SetObjectiveOffset(1.5)
+		assert.Equal(t, 2, prob.NumVariables())
+
+		z, err := prob.ObjectiveValue([]float64{2, 3})
+		assert.NoError(t, err)
+		assert.Equal(t, 9.5, z)
+
+		// the objective value of the solution, regardless of the direction of optimization
+		soln, err := prob.Solve(context.Background())
+		if !assert.NoError(t, err) {
+			return
+		}
+		z, err = prob.ObjectiveValue([]float64{soln.byName["x"], soln.byName["y"]})
+		assert.NoError(t, err)
+		assert.InDelta(t, soln.Objective, z, 1e-9, "maximize: %v", maximize)
+
+		// the milpProblem minimizes the objective function without its constant term, in terms of its columns
+		milp := prob.toSolveable()
+		result, err := milp.solve(context.Background(), 1, dummyMiddleware{})
+		if !assert.NoError(t, err) {
+			return
+		}
+		best := result.BestIntegerSolution
+		columns := milp.toColumns(best.x)
+		assert.InDelta(t, best.z-milp.objectiveConstant(), milp.EvalObjective(columns), 1e-9, "maximize: %v", maximize)
+
+		_, err = prob.ObjectiveValue([]float64{1})
+		assert.Error(t, err)
+	}
+}
+
+func TestProblem_AddFreeVariable(t *testing.T) {
+	// minimize x s.t. x + y >= -4, with x free and y <= 3
+	prob := NewProblem()
+	x := prob.AddFreeVariable("x").SetCoeff(1)
+	y := prob.AddVariable("y").UpperBound(3)
+	prob.AddConstraint("").AddExpression(1, x).AddExpression(1, y).GreaterThanOrEqualTo(-4)
+
+	// the same problem, formulated manually using x = xPos - xNeg
+	manual := NewProblem()
+	xPos := manual.AddVariable("xPos").SetCoeff(1)
+	xNeg := manual.AddVariable("xNeg").SetCoeff(-1)
+	yManual := manual.AddVariable("y").UpperBound(3)
+	manual.AddConstraint("").AddExpression(1, xPos).AddExpression(-1, xNeg).AddExpression(1, yManual).GreaterThanOrEqualTo(-4)
+
+	// the free variable is split into two columns
+	milp := prob.toSolveable()
+	assert.Equal(t, []float64{1, 0, -1}, milp.c)
+	assert.Equal(t, []int{0}, milp.freeVariables)
+
+	manualSoln, err := manual.Solve(context.Background())
+	assert.NoError(t, err)
+	want, err := manual.GetObjectiveValue(manualSoln.byName)
+	assert.NoError(t, err)
+
+	soln, err := prob.Solve(context.Background())
+	assert.NoError(t, err)
+	got, err := prob.GetObjectiveValue(soln.byName)
+	assert.NoError(t, err)
+
+	assert.InDelta(t, want, got, 1e-9)
+	assert.InDelta(t, float64(-7), got, 1e-9)
+}
+
+func TestVariable_Getters(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(-2).LowerBound(1).UpperBound(4).IsInteger()
+	y := prob.AddFreeVariable("y")
+
+	assert.Equal(t, "x", x.Name())
+	assert.Equal(t, float64(-2), x.ObjectiveCoeff())
+	assert.Equal(t, float64(1), x.Lower())
+	assert.Equal(t, float64(4), x.Upper())
+	assert.True(t, x.IntegerConstrained())
+	assert.Equal(t, "x [1, 4] int=true coef=-2", x.String())
+
+	assert.Equal(t, float64(0), y.ObjectiveCoeff())
+	assert.True(t, math.IsInf(y.Lower(), -1))
+	assert.True(t, math.IsInf(y.Upper(), 1))
+	assert.False(t, y.IntegerConstrained())
+	assert.Equal(t, "y [-Inf, +Inf] int=false coef=0", y.String())
+}
+
+func TestProblem_MaximizeWithCoeffs(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(1).UpperBound(3)
+	y := prob.AddVariable("y").SetCoeff(1).UpperBound(3)
+	z := prob.AddVariable("z").SetCoeff(5)
+	prob.AddConstraint("").AddExpression(1, x).AddExpression(1, y).AddExpression(1, z).SmallerThanOrEqualTo(4)
+
+	assert.NoError(t, prob.Maximize(map[string]float64{"x": 2, "y": 3}))
+	assert.Equal(t, float64(2), x.ObjectiveCoeff())
+	assert.Equal(t, float64(3), y.ObjectiveCoeff())
+	assert.Equal(t, float64(5), z.ObjectiveCoeff())
+	z.SetCoeff(0)
+
+	soln, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.InDelta(t, 11, soln.Objective, 1e-9)
+
+	// an unknown name leaves the problem untouched
+	assert.Error(t, prob.Minimize(map[string]float64{"x": -1, "w": 1}))
+	assert.Equal(t, float64(2), x.ObjectiveCoeff())
+	assert.True(t, prob.maximize)
+
+	// without coefficients, only the direction changes
+	assert.NoError(t, prob.Minimize())
+	assert.False(t, prob.maximize)
+	assert.Equal(t, float64(2), x.ObjectiveCoeff())
+}
+
+func TestProblem_RelaxTighten(t *testing.T) {
+	prob := NewProblem()
+	v1 := prob.AddVariable("v1").SetCoeff(-1).IsInteger()
+	v2 := prob.AddVariable("v2").SetCoeff(-1).IsInteger()
+	v3 := prob.AddVariable("v3").SetCoeff(-1)
+
+	prob.AddConstraint("").AddExpression(2, v1).SmallerThanOrEqualTo(3)
+	prob.AddConstraint("").AddExpression(1, v2).SmallerThanOrEqualTo(2)
+	prob.AddConstraint("").AddExpression(1, v3).SmallerThanOrEqualTo(1.5)
+
+	prob.Relax()
+	for _, v := range []*Variable{v1, v2, v3} {
+		assert.False(t, v.integer)
+	}
+
+	lpSoln, err := prob.Solve(context.Background())
+	assert.NoError(t, err)
+	val, err := lpSoln.GetValueFor("v1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, val)
+
+	// only v1 has a fractional value in the LP solution and was integer-constrained before relaxation
+	assert.NoError(t, prob.Tighten(lpSoln))
+	assert.True(t, v1.integer)
+	assert.False(t, v2.integer)
+	assert.False(t, v3.integer)
+
+	ipSoln, err := prob.Solve(context.Background())
+	assert.NoError(t, err)
+	val, err = ipSoln.GetValueFor("v1")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), val)
+}
+
+func TestProblem_Clone(t *testing.T) {
+	prob := NewProblem()
+	prob.BranchingHeuristic(BRANCH_MOST_INFEASIBLE)
+	prob.SetWorkers(2)
+
+	x := prob.AddVariable("x").SetCoeff(-2).IsInteger()
+	y := prob.AddVariable("y").SetCoeff(-1).IsInteger()
+	prob.AddConstraint("").AddExpression(2, x).AddExpression(1, y).SmallerThanOrEqualTo(4.5)
+	prob.AddConstraint("").AddExpression(1, y).SmallerThanOrEqualTo(1)
+
+	clone := prob.Clone()
+	assert.Equal(t, prob.toSolveable(), clone.toSolveable())
+	assert.Equal(t, BRANCH_MOST_INFEASIBLE, clone.branchingHeuristic)
+	assert.Equal(t, 2, clone.workers)
+
+	// nothing is shared with the original
+	for i, v := range clone.variables {
+		assert.False(t, v == prob.variables[i])
+	}
+	for _, c := range clone.constraints {
+		assert.True(t, c.problem == clone)
+		for _, e := range c.expressions {
+			assert.True(t, e.variable == clone.variables[clone.getVariableIndex(e.variable)])
+		}
+	}
+
+	// favour y in the clone, which leaves the original untouched
+	clone.variables[1].SetCoeff(-3)
+	assert.Equal(t, float64(-1), y.coefficient)
+
+	getVal := func(soln *Solution, n string) float64 {
+		x, err := soln.GetValueFor(n)
+		assert.NoError(t, err)
+		return x
+	}
+
+	soln, err := prob.Solve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), getVal(soln, "x"))
+	assert.Equal(t, float64(0), getVal(soln, "y"))
+
+	cloneSoln, err := clone.Solve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), getVal(cloneSoln, "x"))
+	assert.Equal(t, float64(1), getVal(cloneSoln, "y"))
+}
+
+func TestProblem_Validate(t *testing.T) {
+	valid := func() (*Problem, *Variable) {
+		prob := NewProblem()
+		x := prob.AddVariable("x").SetCoeff(-1)
+		prob.AddConstraint("").AddExpression(1, x).SmallerThanOrEqualTo(1)
+		return &prob, x
+	}
+
+	cases := []struct {
+		name   string
+		modify func(p *Problem, x *Variable)
+		want   []string
+	}{
+		{
+			name:   "valid",
+			modify: func(p *Problem, x *Variable) {},
+		},
+		{
+			name: "empty",
+			modify: func(p *Problem, x *Variable) {
+				*p = NewProblem()
+			},
+			want: []string{"problem has no variables", "problem has no constraints"},
+		},
+		{
+			name: "non-finite objective coefficient",
+			modify: func(p *Problem, x *Variable) {
+				x.SetCoeff(math.NaN())
+			},
+			want: []string{"variable x: objective coefficient NaN is not finite"},
+		},
+		{
+			name: "non-finite constraint",
+			modify: func(p *Problem, x *Variable) {
+				p.AddConstraint("").AddExpression(math.Inf(1), x).EqualTo(math.NaN())
+			},
+			want: []string{"constraint 1: coefficient +Inf of variable x is not finite", "constraint 1: right-hand side NaN is not finite"},
+		},
+		{
+			name: "foreign variable",
+			modify: func(p *Problem, x *Variable) {
+				other := NewProblem()
+				p.constraints[0].expressions = append(p.constraints[0].expressions, expression{coef: 1, variable: other.AddVariable("y")})
+			},
+			want: []string{"constraint 0: variable y is not part of the problem"},
+		},
+		{
+			name: "duplicate variable name",
+			modify: func(p *Problem, x *Variable) {
+				// AddVariable rejects duplicate names, so the variable is added directly
+				p.variables = append(p.variables, &Variable{name: "x", upper: math.Inf(1)})
+			},
+			want: []string{"duplicate variable name x"},
+		},
+		{
+			name: "inconsistent bounds",
+			modify: func(p *Problem, x *Variable) {
+				x.LowerBound(2).UpperBound(1)
+			},
+			want: []string{"variable x: lower bound 2 is not smaller than or equal to upper bound 1"},
+		},
+		{
+			name: "infinite lower bound",
+			modify: func(p *Problem, x *Variable) {
+				x.LowerBound(math.Inf(1))
+			},
+			want: []string{"variable x: bounds [+Inf, +Inf] do not admit a finite value"},
+		},
+		{
+			name: "negative SOS1 variable",
+			modify: func(p *Problem, x *Variable) {
+				y := p.AddVariable("y")
+				p.AddSOS1([]*Variable{x, y})
+				y.LowerBound(math.Inf(-1))
+			},
+			want: []string{"SOS1 constraint 0: variable y may take on negative values"},
+		},
+		{
+			name: "duplicate constraint name",
+			modify: func(p *Problem, x *Variable) {
+				p.constraints[0].name = "limit"
+				p.AddConstraint("limit").AddExpression(1, x).GreaterThanOrEqualTo(0)
+			},
+			want: []string{"duplicate constraint name limit"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			prob, x := valid()
+			tc.modify(prob, x)
+
+			var got []string
+			for _, err := range prob.Validate() {
+				got = append(got, err.Error())
+			}
+			assert.Equal(t, tc.want, got)
+
+			// invalid problems are rejected by Solve instead of causing a panic
+			if len(tc.want) > 0 {
+				_, err := prob.Solve(context.Background())
+				assert.True(t, errors.Is(err, ErrInvalidProblem))
+				assert.Contains(t, err.Error(), tc.want[0])
+			}
+		})
+	}
+}
+
+// cancels the solve as soon as the first integer-feasible solution is found
+type cancelOnFeasible struct {
+	cancel context.CancelFunc
+}
+
+func (c cancelOnFeasible) NewSubProblem(s subProblem) {}
+
+func (c cancelOnFeasible) ProcessDecision(s solution, d bnbDecision) {
+	if d == BETTER_THAN_INCUMBENT_FEASIBLE {
+		c.cancel()
+	}
+}
+
+func TestProblem_SolveStatus(t *testing.T) {
+	// an integer problem that requires a lot of branching
+	branching := func() Problem {
+		prob := NewProblem()
+		var vars []*Variable
+		for i, coef := range []float64{-3, -8, -1, -6, -4} {
+			vars = append(vars, prob.AddVariable(fmt.Sprintf("x%d", i)).SetCoeff(coef).IsInteger())
+		}
+		for i, row := range [][]float64{{8, 8, 4, 9, 3}, {6, 5, 8, 1, 7}, {8, 5, 2, 8, 9}} {
+			c := prob.AddConstraint("")
+			for j, coef := range row {
+				c.AddExpression(coef, vars[j])
+			}
+			c.SmallerThanOrEqualTo([]float64{39.5, 26.5, 26.5}[i])
+		}
+		prob.BranchingHeuristic(BRANCH_MOST_INFEASIBLE)
+		prob.SetNodeSelectionStrategy(NODE_DEPTH_FIRST)
+		return prob
+	}
+
+	// a single integer variable bounded by [lower, upper]
+	bounded := func(lower, upper float64) Problem {
+		prob := NewProblem()
+		x := prob.AddVariable("x").SetCoeff(-1).IsInteger()
+		prob.AddConstraint("").AddExpression(1, x).GreaterThanOrEqualTo(lower)
+		prob.AddConstraint("").AddExpression(1, x).SmallerThanOrEqualTo(upper)
+		return prob
+	}
+
+	unbounded := NewProblem()
+	unbounded.Maximize()
+	x := unbounded.AddVariable("x").SetCoeff(1)
+	y := unbounded.AddVariable("y")
+	unbounded.AddConstraint("").AddExpression(1, x).AddExpression(-1, y).SmallerThanOrEqualTo(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cases := []struct {
+		name       string
+		prob       Problem
+		ctx        context.Context
+		opts       []SolveOption
+		wantErr    error
+		wantStatus SolveStatus
+		hasValues  bool
+	}{
+		{name: "optimal", prob: branching(), wantStatus: STATUS_OPTIMAL, hasValues: true},
+		{name: "feasible, not optimal", prob: branching(), ctx: ctx, opts: []SolveOption{WithMiddleware(cancelOnFeasible{cancel})}, wantErr: context.Canceled, wantStatus: STATUS_FEASIBLE_NOT_OPTIMAL, hasValues: true},
+		{name: "node limit", prob: branching(), opts: []SolveOption{WithNodeLimit(1)}, wantErr: ErrNodeLimitExceeded, wantStatus: STATUS_NODE_LIMIT_REACHED},
+		{name: "gap tolerance", prob: branching(), opts: []SolveOption{WithMIPGapAbsolute(100)}, wantStatus: STATUS_GAP_TOLERANCE, hasValues: true},
+		{name: "infeasible relaxation", prob: bounded(2, 1), wantErr: INITIAL_RELAXATION_NOT_FEASIBLE, wantStatus: STATUS_INFEASIBLE},
+		{name: "infeasible", prob: bounded(0.5, 0.7), wantErr: NO_INTEGER_FEASIBLE_SOLUTION, wantStatus: STATUS_INFEASIBLE},
+		{name: "unbounded", prob: unbounded, wantErr: lp.ErrUnbounded, wantStatus: STATUS_UNBOUNDED},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// a custom context is cancelled by the solve
+			reusable := tc.ctx == nil
+			if tc.ctx == nil {
+				tc.ctx = context.Background()
+			}
+
+			soln, err := tc.prob.Solve(tc.ctx, append(tc.opts, WithPresolve(false))...)
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tc.wantErr), "got error %v, want %v", err, tc.wantErr)
+			}
+			if !assert.NotNil(t, soln) {
+				return
+			}
+			assert.Equal(t, tc.wantStatus, soln.Status)
+
+			// the values of the variables are available regardless of the status, if any solution was found
+			_, err = soln.GetValueFor("x0")
+			assert.Equal(t, tc.hasValues, err == nil)
+
+			// the stopped solves are not reported as errors
+			if !reusable {
+				return
+			}
+			best, status, err := tc.prob.SolveWithBestEffort(tc.ctx, append(tc.opts, WithPresolve(false))...)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantStatus, status)
+			assert.Equal(t, tc.hasValues, best != nil)
+		})
+	}
+}
+
+func TestProblem_IsInfeasible(t *testing.T) {
+	prob := NewProblem()
+	x1 := prob.AddVariable("x1").SetCoeff(1).IsInteger()
+	x2 := prob.AddVariable("x2").SetCoeff(1)
+	prob.AddConstraint("").AddExpression(1, x1).AddExpression(1, x2).SmallerThanOrEqualTo(10)
+	prob.AddConstraint("").AddExpression(1, x1).EqualTo(5)
+
+	infeasible, err := prob.IsInfeasible()
+	assert.NoError(t, err)
+	assert.False(t, infeasible)
+
+	prob.AddConstraint("").AddExpression(1, x1).EqualTo(3)
+	infeasible, err = prob.IsInfeasible()
+	assert.NoError(t, err)
+	assert.True(t, infeasible)
+
+	soln, err := prob.Solve(context.Background())
+	assert.Error(t, err)
+	if assert.NotNil(t, soln) {
+		assert.Equal(t, STATUS_INFEASIBLE, soln.Status)
+	}
+}
+
+func TestProblem_SolveRelaxation(t *testing.T) {
+	// minimize 2x + 3y subject to x + y >= 4, x - y = 1 and x <= 10, of which the optimum is x = 2.5, y = 1.5.
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(2).IsInteger()
+	y := prob.AddVariable("y").SetCoeff(3)
+	prob.AddConstraint("").AddExpression(1, x).AddExpression(1, y).GreaterThanOrEqualTo(4)
+	prob.AddConstraint("").AddExpression(1, x).AddExpression(-1, y).EqualTo(1)
+	prob.AddConstraint("").AddExpression(1, x).SmallerThanOrEqualTo(10)
+
+	soln, err := prob.SolveRelaxation(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, STATUS_LP_OPTIMAL, soln.Status)
+	assert.InDelta(t, 9.5, soln.Objective, 1e-9)
+
+	// the integrality constraint of x is ignored
+	val, err := soln.GetValueFor("x")
+	assert.NoError(t, err)
+	assert.InDelta(t, 2.5, val, 1e-9)
+
+	// the objective increases by 2.5 per unit increase of the right-hand side of the first constraint, and decreases by 0.5 for the second
+	assert.InDeltaSlice(t, []float64{2.5, -0.5, 0}, soln.Duals, 1e-9)
+
+	// the duals of a maximization problem are expressed in terms of its own objective
+	prob.Maximize()
+	x.SetCoeff(-2)
+	y.SetCoeff(-3)
+	soln, err = prob.SolveRelaxation(context.Background())
+	assert.NoError(t, err)
+	assert.InDelta(t, -9.5, soln.Objective, 1e-9)
+	assert.InDeltaSlice(t, []float64{-2.5, 0.5, 0}, soln.Duals, 1e-9)
+
+	// an infeasible relaxation yields its status along with the error
+	prob.AddConstraint("").AddExpression(1, x).GreaterThanOrEqualTo(11)
+	soln, err = prob.SolveRelaxation(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, STATUS_INFEASIBLE, soln.Status)
+}
+
+func TestProblem_GetConstraint(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(-1)
+	y := prob.AddVariable("y").SetCoeff(-1)
+	prob.AddConstraint("capacity").AddExpression(1, x).AddExpression(2, y).SmallerThanOrEqualTo(8)
+	prob.AddConstraint("minimum").AddExpression(1, y).GreaterThanOrEqualTo(1)
+	prob.AddConstraint("").AddExpression(1, x).SmallerThanOrEqualTo(5)
+	prob.AddConstraint("fixed").AddExpression(1, x).AddExpression(-1, y).EqualTo(2)
+
+	_, ok := prob.GetConstraint("missing")
+	assert.False(t, ok)
+	_, ok = prob.GetConstraint("")
+	assert.False(t, ok)
+
+	// modify the right-hand side of a retrieved constraint
+	capacity, ok := prob.GetConstraint("capacity")
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, "capacity", capacity.Name())
+	capacity.SmallerThanOrEqualTo(10)
+	assert.Equal(t, float64(10), capacity.GetRHS())
+
+	// the optimum is x = 4.666.., y = 2.666..
+	soln, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	slack, err := soln.ConstraintSlack("capacity")
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, slack, 1e-9)
+
+	surplus, err := soln.ConstraintSlack("minimum")
+	assert.NoError(t, err)
+	assert.InDelta(t, 5.0/3, surplus, 1e-9)
+
+	_, err = soln.ConstraintSlack("fixed")
+	assert.EqualError(t, err, "constraint fixed is not an inequality")
+	_, err = soln.ConstraintSlack("missing")
+	assert.Error(t, err)
+
+	// the optimal solution violates none of the constraints
+	violation, err := soln.ConstraintViolation("minimum")
+	assert.NoError(t, err)
+	assert.InDelta(t, -5.0/3, violation, 1e-9)
+
+	violations, err := soln.AllViolations()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, violations, 3)
+	assert.True(t, violations["capacity"] <= 1e-9)
+	assert.True(t, violations["minimum"] <= 1e-9)
+	assert.InDelta(t, 0, violations["fixed"], 1e-9)
+
+	// after tightening a constraint, the solution violates it
+	capacity.SmallerThanOrEqualTo(8)
+	violation, err = soln.ConstraintViolation("capacity")
+	assert.NoError(t, err)
+	assert.InDelta(t, 2, violation, 1e-9)
+
+	_, err = soln.ConstraintViolation("")
+	assert.Error(t, err)
+}
+
+func TestSolution_ByVariableOrder(t *testing.T) {
+	prob := NewProblem()
+	prob.Maximize()
+	prob.BranchingHeuristic(BRANCH_MOST_INFEASIBLE)
+	z := prob.AddVariable("z").SetCoeff(8).IsInteger()
+	x := prob.AddVariable("x").SetCoeff(4).IsInteger()
+	y := prob.AddVariable("y").SetCoeff(2).IsInteger()
+	prob.AddConstraint("").AddExpression(8, x).AddExpression(6, y).AddExpression(1, z).SmallerThanOrEqualTo(33.5)
+	prob.AddConstraint("").AddExpression(3, x).AddExpression(4, y).AddExpression(9, z).SmallerThanOrEqualTo(25.5)
+
+	soln, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the values are in the order in which the variables were declared
+	values := soln.ByVariableOrder()
+	if !assert.Len(t, values, 3) {
+		return
+	}
+	for i, name := range []string{"z", "x", "y"} {
+		val, err := soln.GetValueFor(name)
+		assert.NoError(t, err)
+		assert.Equal(t, val, values[i])
+	}
+
+	// which matches the columns of the numerical representation of the problem
+	G, h := prob.InequalityConstraintMatrix()
+	var lhs mat.VecDense
+	lhs.MulVec(G, mat.NewVecDense(len(values), values))
+	for i := range h {
+		assert.True(t, lhs.AtVec(i) <= h[i]+1e-9)
+	}
+
+	byName := soln.ToMap()
+	assert.Equal(t, map[string]float64{"z": values[0], "x": values[1], "y": values[2]}, byName)
+
+	// the map is a copy
+	byName["x"] = -1
+	val, _ := soln.GetValueFor("x")
+	assert.Equal(t, values[1], val)
+
+	// a solution without values
+	assert.Nil(t, (&Solution{}).ByVariableOrder())
+	assert.Nil(t, (&Solution{}).ToMap())
+}
+
+func TestConstraint_GetLHSValue(t *testing.T) {
+	prob := NewProblem()
+	prob.Maximize()
+	prob.BranchingHeuristic(BRANCH_MOST_INFEASIBLE)
+	x := prob.AddVariable("x").SetCoeff(4).IsInteger()
+	y := prob.AddVariable("y").SetCoeff(2).IsInteger()
+	z := prob.AddVariable("z").SetCoeff(8).IsInteger()
+	first := prob.AddConstraint("first").AddExpression(8, x).AddExpression(6, y).AddExpression(1, z).SmallerThanOrEqualTo(33.5)
+	prob.AddConstraint("second").AddExpression(3, x).AddExpression(4, y).AddExpression(9, z).SmallerThanOrEqualTo(25.5)
+	prob.AddConstraint("minimum").AddExpression(1, x).AddExpression(1, z).GreaterThanOrEqualTo(2)
+	prob.AddConstraint("balance").AddExpression(1, x).AddExpression(-1, y).EqualTo(1)
+	prob.AddConstraint("").AddExpression(1, z).SmallerThanOrEqualTo(1)
+
+	// before solving
+	lhs, err := first.GetLHSValue([]float64{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 23., lhs)
+
+	_, err = first.GetLHSValue([]float64{1, 2})
+	assert.Error(t, err)
+	_, err = prob.AllConstraintViolations([]float64{1, 2})
+	assert.Error(t, err)
+
+	violations, err := prob.AllConstraintViolations([]float64{4, 0, 2})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]float64{"first": 0.5, "second": 4.5, "minimum": 0, "balance": 3}, violations)
+
+	violations, err = prob.AllConstraintViolations([]float64{0, 0, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1., violations["minimum"])
+	assert.Equal(t, -1., violations["balance"])
+
+	// after solving, the optimal solution violates none of the constraints
+	soln, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	violations, err = prob.AllConstraintViolations(soln.ByVariableOrder())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, violations, 4)
+	for name, violation := range violations {
+		assert.InDelta(t, 0, violation, 1e-9, name)
+	}
+}
+
+func TestConstraint_Modify(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(-1)
+	y := prob.AddVariable("y").SetCoeff(-1).UpperBound(3)
+	capacity := prob.AddConstraint("capacity").AddExpression(1, x).AddExpression(2, y).SmallerThanOrEqualTo(8)
+	prob.AddConstraint("limit").AddExpression(1, x).SmallerThanOrEqualTo(4)
+
+	assert.Equal(t, "capacity: 1*x + 2*y <= 8", capacity.String())
+	assert.True(t, capacity.GetInequality())
+	assert.Equal(t, float64(8), capacity.GetRHS())
+
+	// the returned expressions are a copy
+	exprs := capacity.GetExpressions()
+	assert.Equal(t, []Term{{Coef: 1, Variable: x}, {Coef: 2, Variable: y}}, exprs)
+	exprs[0].Coef = 5
+	assert.Equal(t, float64(1), capacity.expressions[0].coef)
+
+	objective := func() float64 {
+		soln, err := prob.Solve(context.Background())
+		assert.NoError(t, err)
+		obj, err := prob.GetObjectiveValue(soln.byName)
+		assert.NoError(t, err)
+		return obj
+	}
+
+	// x = 4, y = 2
+	assert.InDelta(t, -6, objective(), 1e-9)
+
+	// x = 4, y = 3
+	capacity.SetRHS(12)
+	assert.InDelta(t, -7, objective(), 1e-9)
+
+	// constraints cannot be removed once the problem has been solved
+	assert.True(t, errors.Is(prob.RemoveConstraint("limit"), ErrProblemSolved))
+
+	unsolved := prob.Clone()
+	assert.EqualError(t, unsolved.RemoveConstraint("missing"), "constraint missing not found")
+	assert.NoError(t, unsolved.RemoveConstraint("limit"))
+	assert.Len(t, unsolved.constraints, 1)
+	assert.Len(t, prob.constraints, 2)
+
+	// x = 12, y = 0
+	soln, err := unsolved.Solve(context.Background())
+	assert.NoError(t, err)
+	obj, err := unsolved.GetObjectiveValue(soln.byName)
+	assert.NoError(t, err)
+	assert.InDelta(t, -12, obj, 1e-9)
+}
+
+func TestProblem_RemoveVariable(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(-1).IsInteger()
+	y := prob.AddVariable("y").SetCoeff(-1).IsInteger()
+	z := prob.AddVariable("z").SetCoeff(-5)
+	prob.AddConstraint("capacity").AddExpression(1, x).AddExpression(2, y).SmallerThanOrEqualTo(8.5)
+	prob.AddConstraint("").AddExpression(1, x).SmallerThanOrEqualTo(4)
+	prob.AddConstraint("z bound").AddExpression(1, z).SmallerThanOrEqualTo(3)
+	prob.AddConstraint("").AddExpression(2, z).GreaterThanOrEqualTo(1)
+
+	// x is part of a constraint on y as well
+	assert.EqualError(t, prob.RemoveVariable(x), "variable x cannot be removed, as it is part of constraint capacity: 1*x + 2*y <= 8.5")
+	assert.EqualError(t, prob.RemoveVariable(&Variable{name: "w"}), "variable w not found")
+
+	original := prob.Clone()
+	if !assert.NoError(t, prob.RemoveVariable(z)) {
+		return
+	}
+	assert.Equal(t, []*Variable{x, y}, prob.variables)
+	assert.Len(t, prob.constraints, 2)
+	for _, c := range prob.constraints {
+		for _, e := range c.expressions {
+			assert.NotEqual(t, z, e.variable)
+		}
+	}
+	assert.Len(t, original.variables, 3)
+	assert.Len(t, original.constraints, 4)
+
+	// x = 4, y = 2
+	soln, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.InDelta(t, -6, soln.Objective, 1e-9)
+	_, err = soln.GetValueFor("z")
+	assert.Error(t, err)
+
+	// variables cannot be removed once the problem has been solved
+	assert.True(t, errors.Is(prob.RemoveVariable(y), ErrProblemSolved))
+}
+
+func TestConstraint_Scale(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(-1)
+	y := prob.AddVariable("y").SetCoeff(-1).UpperBound(3)
+	capacity := prob.AddConstraint("capacity").AddExpression(1, x).AddExpression(2, y).SmallerThanOrEqualTo(8)
+	fixed := prob.AddConstraint("fixed").AddExpression(2, y).EqualTo(4)
+
+	// the coefficients and right-hand side are scaled, and the same constraint is returned for chaining
+	assert.True(t, capacity == capacity.Scale(0.5))
+	assert.Equal(t, "capacity: 0.5*x + 1*y <= 4", capacity.String())
+
+	// a negative factor flips the direction of an inequality, but an equality remains an equality
+	capacity.Scale(-2)
+	assert.Equal(t, "capacity: -1*x + -2*y >= -8", capacity.String())
+	fixed.Scale(-0.5)
+	assert.Equal(t, "fixed: -1*y = -2", fixed.String())
+
+	// scaling does not change the solution: x = 4, y = 2
+	soln, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.InDelta(t, 4, soln.byName["x"], 1e-9)
+	assert.InDelta(t, 2, soln.byName["y"], 1e-9)
+
+	// the big-M term is scaled along with the other coefficients
+	ind := prob.AddBinaryVariable("ind")
+	switched := prob.AddConstraint("switched").AddExpression(1, x).SmallerThanOrEqualTo(2).BigM(ind, 10).Scale(-1)
+	assert.Equal(t, "switched: -1*x + 10*ind >= -2", switched.String())
+
+	assert.Panics(t, func() { capacity.Scale(0) })
+}
+
+func TestProblem_RelaxIntegrality(t *testing.T) {
+	// the objective value of a solution, computed from the values of the variables
+	objective := func(p Problem, values interface {
+		GetValueFor(string) (float64, error)
+	}) float64 {
+		z := 0.0
+		for _, v := range p.variables {
+			val, err := values.GetValueFor(v.name)
+			assert.NoError(t, err)
+			z += v.coefficient * val
+		}
+		return z
+	}
+
+	badlyScaled, _, _, _ := getBadlyScaledProblem()
+	problems := map[string]Problem{
+		"mps":          getMPSTestProblem(),
+		"badly scaled": badlyScaled,
+	}
+
+	for name, prob := range problems {
+		t.Run(name, func(t *testing.T) {
+			relaxation := prob.RelaxIntegrality()
+			for i, v := range relaxation.variables {
+				assert.False(t, v.integer)
+				assert.True(t, v != prob.variables[i])
+			}
+
+			// the original problem keeps its integrality constraints
+			assert.Contains(t, getIntegrality(prob), true)
+
+			milp, err := prob.Solve(context.Background())
+			if !assert.NoError(t, err) {
+				return
+			}
+			lp, err := prob.SolveRelaxation(context.Background())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, STATUS_LP_OPTIMAL, lp.Status)
+
+			// the relaxation bounds the objective value of the MIP from below for minimization problems, and from above for maximization problems
+			sense := 1.0
+			if prob.maximize {
+				sense = -1
+			}
+			assert.True(t, sense*objective(prob, lp) <= sense*objective(prob, milp)+1e-9, "relaxation %v, MIP %v", objective(prob, lp), objective(prob, milp))
+			assert.InDelta(t, lp.Objective, objective(prob, lp), 1e-9)
+		})
+	}
+
+	milp := getMPSTestProblem().toSolveable()
+	assert.Equal(t, make([]bool, len(milp.c)), milp.RelaxIntegrality().integralityConstraints)
+	assert.Contains(t, milp.integralityConstraints, true)
+}
+
+// the integrality constraints of the variables of the problem
+func getIntegrality(p Problem) []bool {
+	var integrality []bool
+	for _, v := range p.variables {
+		integrality = append(integrality, v.integer)
+	}
+	return integrality
+}
+
+func TestProblem_TryAddVariable(t *testing.T) {
+	prob := NewProblem()
+	prob.Maximize()
+	prob.BranchingHeuristic(BRANCH_MOST_INFEASIBLE)
+	x, err := prob.TryAddVariable("x")
+	if !assert.NoError(t, err) {
+		return
+	}
+	x.SetCoeff(4).IsInteger()
+	y := prob.AddVariable("y").SetCoeff(2).IsInteger()
+	z := prob.AddVariable("z").SetCoeff(8).IsInteger()
+
+	// a name can only be used once
+	_, err = prob.TryAddVariable("x")
+	assert.True(t, errors.Is(err, ErrDuplicateVariableName))
+	assert.EqualError(t, err, "variable x: duplicate variable name")
+	assert.Panics(t, func() { prob.AddVariable("y") })
+	assert.Panics(t, func() { prob.AddBinaryVariable("z") })
+	assert.Equal(t, 3, prob.NumVariables())
+
+	prob.AddConstraint("").AddExpression(8, x).AddExpression(6, y).AddExpression(1, z).SmallerThanOrEqualTo(33.5)
+	prob.AddConstraint("").AddExpression(3, x).AddExpression(4, y).AddExpression(9, z).SmallerThanOrEqualTo(25.5)
+
+	prob.Freeze()
+	_, err = prob.TryAddVariable("w")
+	assert.True(t, errors.Is(err, ErrProblemFrozen))
+
+	soln, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// each name refers to the value of a single variable
+	for name, want := range map[string]float64{"x": 4, "y": 0, "z": 1} {
+		val, err := soln.GetValueFor(name)
+		assert.NoError(t, err)
+		assert.Equal(t, want, val, name)
+	}
+}
@@ -0,0 +1,68 @@
+package ilp
+
+import (
+	"context"
+)
+
+// the number of progress events buffered by SolveAsync. Events are dropped while the buffer is full.
+const asyncProgressBuffer = 64
+
+// SolveAsync solves the problem like Solve in a background goroutine, and returns channels on which the outcome is delivered.
+// The first channel receives the progress of the branch-and-bound procedure after each decision, along with a final event once the procedure has finished.
+// Events are dropped if the caller does not keep up with them, so the solve never waits for the caller.
+// The objective values of the events are those of the problem, i.e. including the objective offset and negated back for a maximization problem.
+// Once the solve has finished, the progress channel is closed, after which the Solution is sent on the second channel and the error (if any) on the third,
+// like the return values of Solve. All channels are closed afterwards, including when the solve is stopped by the context.
+func (p Problem) SolveAsync(ctx context.Context, opts ...SolveOption) (<-chan SolveProgress, <-chan *Solution, <-chan error) {
+	progress := make(chan SolveProgress, asyncProgressBuffer)
+	solutions := make(chan *Solution, 1)
+	errs := make(chan error, 1)
+
+	// the constant term of the objective function of the milpProblem, which is only known once the problem has been converted
+	var constant float64
+	events := newProgressEvents(0, func(e SolveProgress) {
+		if e.HasIncumbent {
+			e.CurrentIncumbent += constant
+		}
+		e.BestBound += constant
+		if p.maximize {
+			e.CurrentIncumbent = -e.CurrentIncumbent
+			e.BestBound = -e.BestBound
+		}
+
+		select {
+		case progress <- e:
+		default:
+		}
+	})
+
+	// the events are generated alongside the middleware of the options, which must not be modified by appending to them
+	opts = append(opts[:len(opts):len(opts)], func(o *SolveOptions) {
+		if o.Middleware == nil {
+			o.Middleware = events
+			return
+		}
+		o.Middleware = Compose(o.Middleware, events)
+	})
+
+	go func() {
+		soln, err := p.solveWith(ctx, func(milp *milpProblem) {
+			constant = milp.objectiveConstant()
+		}, opts)
+
+		events.Flush()
+		close(progress)
+
+		if soln != nil {
+			solutions <- soln
+		}
+		close(solutions)
+
+		if err != nil {
+			errs <- err
+		}
+		close(errs)
+	}()
+
+	return progress, solutions, errs
+}
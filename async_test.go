@@ -0,0 +1,64 @@
+package ilp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblem_SolveAsync(t *testing.T) {
+	prob, _, _, _ := getTestKnapsackProblem()
+
+	progress, solutions, errs := prob.SolveAsync(context.Background())
+
+	var events []SolveProgress
+	for e := range progress {
+		events = append(events, e)
+	}
+	if !assert.NotEmpty(t, events) {
+		return
+	}
+
+	soln, ok := <-solutions
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, STATUS_OPTIMAL, soln.Status)
+	assert.InDelta(t, 24, soln.Objective, 1e-9)
+
+	// the solution and error channels are closed after the solve
+	_, ok = <-solutions
+	assert.False(t, ok)
+	err, ok := <-errs
+	assert.False(t, ok)
+	assert.NoError(t, err)
+
+	// the events are in the objective sense of the problem, and the final event describes the optimum
+	for i := 1; i < len(events); i++ {
+		assert.True(t, events[i-1].NodesExplored <= events[i].NodesExplored)
+		assert.False(t, events[i].Timestamp.Before(events[i-1].Timestamp))
+		if events[i-1].HasIncumbent {
+			assert.True(t, events[i].CurrentIncumbent >= events[i-1].CurrentIncumbent, "the incumbent of the maximization problem decreased")
+		}
+	}
+	last := events[len(events)-1]
+	assert.Equal(t, soln.Stats.NodesExplored, last.NodesExplored)
+	assert.True(t, last.HasIncumbent)
+	assert.InDelta(t, 24, last.CurrentIncumbent, 1e-9)
+	assert.InDelta(t, 24, last.BestBound, 1e-9)
+
+	// cancelling the context terminates all channels
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	progress, solutions, errs = prob.SolveAsync(ctx)
+	for range progress {
+	}
+	for range solutions {
+	}
+	err, ok = <-errs
+	assert.True(t, ok)
+	assert.Equal(t, context.Canceled, err)
+	_, ok = <-errs
+	assert.False(t, ok)
+}
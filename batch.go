@@ -0,0 +1,130 @@
+package ilp
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// VariableSpec describes a variable to add with Problem.AddVariables.
+// The bounds are taken as-is, so an unbounded variable needs an upper bound of math.Inf(1), unlike a variable added with Problem.AddVariable.
+type VariableSpec struct {
+	Name    string
+	Coef    float64
+	Integer bool
+	Lower   float64
+	Upper   float64
+}
+
+// AddVariables adds a variable for each spec, and returns references to these variables in the same order.
+// Returns an error without adding any variable if two specs share a name, or if a spec has the name of a variable of the problem.
+func (p *Problem) AddVariables(specs []VariableSpec) ([]*Variable, error) {
+	done, err := p.modify()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	names := make(map[string]bool, len(p.variables)+len(specs))
+	for _, v := range p.variables {
+		names[v.name] = true
+	}
+	for _, spec := range specs {
+		if names[spec.Name] {
+			return nil, fmt.Errorf("duplicate variable name %v", spec.Name)
+		}
+		names[spec.Name] = true
+	}
+
+	vars := make([]*Variable, len(specs))
+	for i, spec := range specs {
+		v := p.AddVariable(spec.Name).SetCoeff(spec.Coef).LowerBound(spec.Lower).UpperBound(spec.Upper)
+		if spec.Integer {
+			v.IsInteger()
+		}
+		vars[i] = v
+	}
+	return vars, nil
+}
+
+// AddConstraintMatrix adds an equality constraint A_i * vars = b_i for each row i of A, and returns references to these constraints in the same order.
+// The columns of A correspond to the provided variables, which must belong to this problem. Zero coefficients are omitted from the constraints.
+// The constraints are named after the corresponding element of names, or unnamed if names is nil.
+// If the dimensions do not match, this call will panic.
+func (p *Problem) AddConstraintMatrix(A *mat.Dense, b []float64, vars []*Variable, names []string) []*Constraint {
+	rows, cols := A.Dims()
+	if rows != len(b) || cols != len(vars) || (names != nil && len(names) != rows) {
+		panic(fmt.Sprintf("constraint matrix of %vx%v does not match %v right-hand sides, %v variables and %v names", rows, cols, len(b), len(vars), len(names)))
+	}
+
+	constraints := make([]*Constraint, rows)
+	for i := 0; i < rows; i++ {
+		name := ""
+		if names != nil {
+			name = names[i]
+		}
+
+		c := p.AddConstraint(name)
+		for j, v := range vars {
+			if coef := A.At(i, j); coef != 0 {
+				c.AddExpression(coef, v)
+			}
+		}
+		constraints[i] = c.EqualTo(b[i])
+	}
+	return constraints
+}
+
+// AddExpressionBatch adds the expression coefs[i] * vars[i] to the left-hand side of the constraint for each i, like AddExpression.
+// Returns an error without adding any expression if the lengths of coefs and vars differ, or if a variable does not belong to the problem of the constraint.
+func (c *Constraint) AddExpressionBatch(coefs []float64, vars []*Variable) (*Constraint, error) {
+	done, err := c.problem.modify()
+	if err != nil {
+		return c, err
+	}
+	defer done()
+	if len(coefs) != len(vars) {
+		return c, fmt.Errorf("%v coefficients do not match %v variables", len(coefs), len(vars))
+	}
+
+	registered := make(map[*Variable]bool, len(c.problem.variables))
+	for _, v := range c.problem.variables {
+		registered[v] = true
+	}
+	for _, v := range vars {
+		if !registered[v] {
+			return c, fmt.Errorf("variable %v does not belong to the problem", v.name)
+		}
+	}
+
+	for i, v := range vars {
+		c.expressions = append(c.expressions, expression{coef: coefs[i], variable: v})
+	}
+	return c, nil
+}
+
+// SetConstraintFromDenseRow replaces the left-hand side of the constraint by row * x, where x holds the variables of the problem in the order in which they were added.
+// Zero coefficients are omitted from the constraint.
+// Returns an error without changing the constraint if the constraint does not belong to the problem, or if the length of the row differs from the number of variables.
+func (p *Problem) SetConstraintFromDenseRow(c *Constraint, row []float64) error {
+	done, err := p.modify()
+	if err != nil {
+		return err
+	}
+	defer done()
+	if c.problem != p {
+		return fmt.Errorf("constraint %v does not belong to the problem", c.name)
+	}
+	if len(row) != len(p.variables) {
+		return fmt.Errorf("row of %v coefficients does not match %v variables", len(row), len(p.variables))
+	}
+
+	var expressions []expression
+	for i, coef := range row {
+		if coef != 0 {
+			expressions = append(expressions, expression{coef: coef, variable: p.variables[i]})
+		}
+	}
+	c.expressions = expressions
+	return nil
+}
@@ -0,0 +1,89 @@
+package ilp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestProblem_AddVariables(t *testing.T) {
+	// build the same problem variable by variable
+	single := NewProblem()
+	x := single.AddVariable("x").SetCoeff(-1).LowerBound(-2).UpperBound(math.Inf(1))
+	y := single.AddVariable("y").SetCoeff(2).IsInteger().UpperBound(4)
+	z := single.AddVariable("z").SetCoeff(0.5).UpperBound(math.Inf(1))
+	single.AddConstraint("first").AddExpression(1, x).AddExpression(3, z).EqualTo(5)
+	single.AddConstraint("second").AddExpression(2, y).AddExpression(-1, z).EqualTo(1)
+
+	batch := NewProblem()
+	vars, err := batch.AddVariables([]VariableSpec{
+		{Name: "x", Coef: -1, Lower: -2, Upper: math.Inf(1)},
+		{Name: "y", Coef: 2, Integer: true, Upper: 4},
+		{Name: "z", Coef: 0.5, Upper: math.Inf(1)},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, vars, 3)
+	assert.Equal(t, "y", vars[1].Name())
+
+	A := mat.NewDense(2, 3, []float64{
+		1, 0, 3,
+		0, 2, -1,
+	})
+	constraints := batch.AddConstraintMatrix(A, []float64{5, 1}, vars, []string{"first", "second"})
+	assert.Len(t, constraints, 2)
+	assert.Equal(t, "second: 2*y + -1*z = 1", constraints[1].String())
+
+	assert.Equal(t, *single.toSolveable(), *batch.toSolveable())
+
+	// the names of the variables are unique
+	_, err = batch.AddVariables([]VariableSpec{{Name: "a"}, {Name: "a"}})
+	assert.Error(t, err)
+	_, err = batch.AddVariables([]VariableSpec{{Name: "x"}})
+	assert.Error(t, err)
+	assert.Len(t, batch.variables, 3)
+
+	// unnamed constraints
+	unnamed := batch.AddConstraintMatrix(mat.NewDense(1, 1, []float64{1}), []float64{2}, vars[:1], nil)
+	assert.Equal(t, "", unnamed[0].Name())
+
+	assert.Panics(t, func() { batch.AddConstraintMatrix(A, []float64{5}, vars, nil) })
+}
+
+func TestConstraint_AddExpressionBatch(t *testing.T) {
+	// build the same problem expression by expression
+	want := NewProblem()
+	x := want.AddVariable("x").SetCoeff(-4)
+	y := want.AddVariable("y").SetCoeff(-2)
+	z := want.AddVariable("z").SetCoeff(-8)
+	want.AddConstraint("a").AddExpression(8, x).AddExpression(6, y).AddExpression(1, z).SmallerThanOrEqualTo(33.5)
+	want.AddConstraint("b").AddExpression(3, x).AddExpression(9, z).EqualTo(12)
+
+	got := NewProblem()
+	vars := []*Variable{
+		got.AddVariable("x").SetCoeff(-4),
+		got.AddVariable("y").SetCoeff(-2),
+		got.AddVariable("z").SetCoeff(-8),
+	}
+	a, err := got.AddConstraint("a").AddExpressionBatch([]float64{8, 6, 1}, vars)
+	if !assert.NoError(t, err) {
+		return
+	}
+	a.SmallerThanOrEqualTo(33.5)
+	b := got.AddConstraint("b").EqualTo(12)
+	assert.NoError(t, got.SetConstraintFromDenseRow(b, []float64{3, 0, 9}))
+
+	assert.Equal(t, want.toSolveable(), got.toSolveable())
+
+	// invalid batches leave the constraint untouched
+	_, err = a.AddExpressionBatch([]float64{1, 2}, vars)
+	assert.Error(t, err)
+	_, err = a.AddExpressionBatch([]float64{1}, []*Variable{x})
+	assert.Error(t, err)
+	assert.Error(t, got.SetConstraintFromDenseRow(b, []float64{1, 2}))
+	assert.Error(t, want.SetConstraintFromDenseRow(b, []float64{1, 2, 3}))
+	assert.Equal(t, want.toSolveable(), got.toSolveable())
+}
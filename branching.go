@@ -1,6 +1,47 @@
 package ilp
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
+
+// BranchingStrategy selects the integer-constrained variable to branch on, given the LP solution of a subProblem that is not integer feasible.
+// It returns the index of the variable in the solution vector.
+// Custom strategies take precedence over the built-in BranchHeuristic options.
+type BranchingStrategy interface {
+	SelectVariable(state BranchingState) int
+}
+
+// BranchingState describes the LP solution of a subProblem that is not integer feasible, from which a BranchingStrategy selects the variable to branch on.
+// The variables are the columns of the problem as passed to the solver, which differ from the variables of the Problem if it was presolved
+// or if it contains free variables, which are split into a positive and a negative part.
+type BranchingState struct {
+	// the values of the variables in the LP solution
+	X []float64
+
+	// whether each variable is integer-constrained
+	Integer []bool
+
+	// the objective value of the LP solution. Note that the objective is always minimized.
+	Objective float64
+
+	// the number of branchings between the subProblem and the root problem
+	Depth int
+
+	// the solution itself, from which the built-in strategies take the remaining information
+	sol solution
+}
+
+// describe the solution to a BranchingStrategy. The slices are copies, so the strategy cannot modify the solution.
+func newBranchingState(sol solution) BranchingState {
+	return BranchingState{
+		X:         append([]float64(nil), sol.x...),
+		Integer:   append([]bool(nil), sol.problem.integralityConstraints...),
+		Objective: sol.z,
+		Depth:     sol.problem.Depth(),
+		sol:       sol,
+	}
+}
 
 // selectable heuristic options
 type BranchHeuristic int
@@ -9,10 +50,110 @@ const (
 	BRANCH_MAXFUN          BranchHeuristic = 0
 	BRANCH_MOST_INFEASIBLE BranchHeuristic = 1
 	BRANCH_NAIVE           BranchHeuristic = 2
+	BRANCH_STRONG          BranchHeuristic = 3
+	BRANCH_PSEUDOCOST      BranchHeuristic = 4
 )
 
+// String returns the name of the heuristic, e.g. "BRANCH_MAXFUN".
+func (h BranchHeuristic) String() string {
+	switch h {
+	case BRANCH_MAXFUN:
+		return "BRANCH_MAXFUN"
+	case BRANCH_MOST_INFEASIBLE:
+		return "BRANCH_MOST_INFEASIBLE"
+	case BRANCH_NAIVE:
+		return "BRANCH_NAIVE"
+	case BRANCH_STRONG:
+		return "BRANCH_STRONG"
+	case BRANCH_PSEUDOCOST:
+		return "BRANCH_PSEUDOCOST"
+	default:
+		return fmt.Sprintf("BranchHeuristic(%d)", int(h))
+	}
+}
+
+// The order in which the two children of a branched subProblem are explored.
+type BranchDirection int
+
+const (
+	// explore the child with the rounded-down variable first
+	BRANCH_DOWN_FIRST BranchDirection = 0
+	// explore the child with the rounded-up variable first
+	BRANCH_UP_FIRST BranchDirection = 1
+	// explore the child closest to the current value of the variable first, i.e. round up first if the fractional part exceeds 1/2.
+	BRANCH_AUTO BranchDirection = 2
+)
+
+// get the BranchingStrategy implementing the heuristic.
+// The pseudocosts are only used by BRANCH_PSEUDOCOST and may be nil.
+func (h BranchHeuristic) strategy(pseudocosts *PseudocostTable) BranchingStrategy {
+	switch h {
+	case BRANCH_MAXFUN:
+		return maxFunStrategy{}
+	case BRANCH_MOST_INFEASIBLE:
+		return mostInfeasibleStrategy{}
+	case BRANCH_NAIVE:
+		return naiveStrategy{}
+	case BRANCH_STRONG:
+		return strongStrategy{}
+	case BRANCH_PSEUDOCOST:
+		return pseudocostStrategy{pseudocosts: pseudocosts}
+	default:
+		panic("provided branching heuristic config variable unknown")
+	}
+}
+
+type maxFunStrategy struct{}
+
+func (maxFunStrategy) SelectVariable(state BranchingState) int {
+	sol := state.sol
+
+	// branching on a variable with an integral value would yield a child that is identical to its parent.
+	// Values within the default tolerance of an integer are considered integral even if a smaller tolerance is configured, as they are mostly LP solver noise.
+	tol := math.Max(sol.problem.integralityTol, DefaultIntegralityTolerance)
+	fractional := make([]bool, len(sol.problem.integralityConstraints))
+	found := false
+	for i, v := range sol.x {
+		fractional[i] = sol.problem.integralityConstraints[i] && !isAllIntegerWithTol(v, tol)
+		found = found || fractional[i]
+	}
+	if !found {
+		return mostInfeasibleBranchPoint(sol.x, sol.problem.integralityConstraints, sol.problem.branchingPriorities)
+	}
+
+	return maxFunBranchPoint(sol.problem.c, fractional, sol.problem.branchingPriorities)
+}
+
+type mostInfeasibleStrategy struct{}
+
+func (mostInfeasibleStrategy) SelectVariable(state BranchingState) int {
+	sol := state.sol
+	return mostInfeasibleBranchPoint(sol.x, sol.problem.integralityConstraints, sol.problem.branchingPriorities)
+}
+
+type naiveStrategy struct{}
+
+func (naiveStrategy) SelectVariable(state BranchingState) int {
+	return state.sol.naiveBranchPoint()
+}
+
+type strongStrategy struct{}
+
+func (strongStrategy) SelectVariable(state BranchingState) int {
+	return state.sol.strongBranchPoint()
+}
+
+type pseudocostStrategy struct {
+	pseudocosts *PseudocostTable
+}
+
+func (s pseudocostStrategy) SelectVariable(state BranchingState) int {
+	sol := state.sol
+	return pseudocostBranchPoint(sol.x, sol.problem.integralityConstraints, s.pseudocosts)
+}
+
 // Get the variable to branch on by looking at which variables we branched on previously.
-// If there are no branches yet, so we start at the first constrained variable.
+// If there are no branches yet, we start at the last constrained variable with the highest branching priority.
 // Note that this is a really naive way to find a nice variable to branch on.
 func (s solution) naiveBranchPoint() int {
 	branchOn := 0
@@ -20,9 +161,11 @@ func (s solution) naiveBranchPoint() int {
 	// if there are branches, we cycle through the variables starting from the last one we branched on
 	// when we encounter the next variable with an integrality constraint, we pick that one to branch on.
 	if len(s.problem.bnbConstraints) == 0 {
+		found := false
 		for i := range s.problem.integralityConstraints {
-			if s.problem.integralityConstraints[i] {
+			if s.problem.integralityConstraints[i] && (!found || s.problem.priority(i) >= s.problem.priority(branchOn)) {
 				branchOn = i
+				found = true
 			}
 		}
 	} else {
@@ -51,19 +194,25 @@ func (s solution) naiveBranchPoint() int {
 }
 
 // // Choose the integrality-constrained variable with the highest absolute value in the objective function
-func maxFunBranchPoint(c []float64, integralityConstraints []bool) int {
+// Of the variables with the same absolute value, the one with the highest branching priority is chosen.
+func maxFunBranchPoint(c []float64, integralityConstraints []bool, priorities []int) int {
 	if len(c) != len(integralityConstraints) {
 		panic("number of variables not equal to number of integrality constraints")
 	}
 
 	var candidateValue float64
 	currentCandidate := 0
+	selected := false
 
 	for i, v := range c {
 		if integralityConstraints[i] {
-			// we use greater-than-or-equal-to to ensure an integer-constrained variable is selected if one is present, even if its coefficient is 0.
-			if math.Abs(v) >= candidateValue {
+			// an integer-constrained variable is selected if one is present, even if its coefficient is 0.
+			// Of equal coefficients, the last one with the highest priority is selected.
+			value := math.Abs(v)
+			if !selected || value > candidateValue || value == candidateValue && branchingPriority(priorities, i) >= branchingPriority(priorities, currentCandidate) {
+				candidateValue = value
 				currentCandidate = i
+				selected = true
 			}
 		}
 	}
@@ -71,8 +220,9 @@ func maxFunBranchPoint(c []float64, integralityConstraints []bool) int {
 	return currentCandidate
 }
 
-// Choose the variable with the fractional part closest to 1/2.
-func mostInfeasibleBranchPoint(c []float64, integralityConstraints []bool) int {
+// Choose the variable of which the value in the current solution has the fractional part closest to 1/2.
+// Of the variables with equally close fractional parts, the one with the highest branching priority is chosen.
+func mostInfeasibleBranchPoint(c []float64, integralityConstraints []bool, priorities []int) int {
 	if len(c) != len(integralityConstraints) {
 		panic("number of variables not equal to number of integrality constraints")
 	}
@@ -83,8 +233,10 @@ func mostInfeasibleBranchPoint(c []float64, integralityConstraints []bool) int {
 	for i, v := range c {
 		if integralityConstraints[i] {
 			_, f := math.Modf(v)
-			// we use greater-than-or-equal-to to ensure an integer-constrained variable is selected if one is present, even if candidate value is equal to 0.
-			if (0.5 - f) <= candidateRemainder {
+			// we use smaller-than-or-equal-to to ensure an integer-constrained variable is selected if one is present, even if it is not fractional.
+			remainder := math.Abs(0.5 - math.Abs(f))
+			if remainder < candidateRemainder || remainder == candidateRemainder && branchingPriority(priorities, i) >= branchingPriority(priorities, currentCandidate) {
+				candidateRemainder = remainder
 				currentCandidate = i
 			}
 		}
@@ -92,3 +244,62 @@ func mostInfeasibleBranchPoint(c []float64, integralityConstraints []bool) int {
 
 	return currentCandidate
 }
+
+// Choose the variable of which branching yields the largest improvement of the objective bound.
+// For each integer-constrained variable with a fractional value, both child relaxations are solved.
+// The score of a candidate is the smallest of the two objective improvements, as the weakest child determines how much the bound of the subtree improves.
+// An infeasible child counts as an infinite improvement, as its branch can be pruned right away.
+// If maxCandidates is positive, only the first maxCandidates fractional variables are evaluated to limit the number of LP solves.
+// If parallel branching is enabled, the two child relaxations of each candidate are solved concurrently.
+func (s solution) strongBranchPoint() int {
+	bestCandidate := -1
+	bestScore := math.Inf(-1)
+	evaluated := 0
+
+	for i, v := range s.x {
+		if !s.problem.integralityConstraints[i] || isAllIntegerWithTol(v, s.problem.integralityTol) {
+			continue
+		}
+
+		if s.problem.maxCandidates > 0 && evaluated == s.problem.maxCandidates {
+			break
+		}
+		evaluated++
+
+		down, up := s.problem.branchOn(i, v)
+		downSolution, upSolution := solveBoth(down, up, s.problem.parallelBranching)
+		score := math.Min(s.boundImprovement(downSolution), s.boundImprovement(upSolution))
+		if score > bestScore {
+			bestScore = score
+			bestCandidate = i
+		}
+	}
+
+	// fall back to the most infeasible variable if no fractional variables are present
+	if bestCandidate == -1 {
+		return mostInfeasibleBranchPoint(s.x, s.problem.integralityConstraints, s.problem.branchingPriorities)
+	}
+
+	return bestCandidate
+}
+
+// improvement of the objective value of a child relaxation with respect to this solution.
+func (s solution) boundImprovement(child solution) float64 {
+	if child.err != nil {
+		return math.Inf(1)
+	}
+	return child.z - s.z
+}
+
+// the branching priority of the variable with index i, which is zero if no priorities are set.
+func branchingPriority(priorities []int, i int) int {
+	if i < len(priorities) {
+		return priorities[i]
+	}
+	return 0
+}
+
+// the branching priority of the variable with index i.
+func (p subProblem) priority(i int) int {
+	return branchingPriority(p.branchingPriorities, i)
+}
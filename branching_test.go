@@ -1,13 +1,19 @@
 package ilp
 
 import (
+	"context"
+	"math"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_maxFunBranchPoint(t *testing.T) {
 	type args struct {
 		c                      []float64
 		integralityConstraints []bool
+		priorities             []int
 	}
 	tests := []struct {
 		name string
@@ -78,10 +84,54 @@ func Test_maxFunBranchPoint(t *testing.T) {
 			},
 			want: 4,
 		},
+		{
+			name: "largest coefficient first",
+			args: args{
+				c:                      []float64{5, 1, 2},
+				integralityConstraints: []bool{true, true, true},
+			},
+			want: 0,
+		},
+		{
+			name: "largest coefficients tied, highest priority not last",
+			args: args{
+				c:                      []float64{3, 1, -3, 2},
+				integralityConstraints: []bool{true, true, true, true},
+				priorities:             []int{1, 5, 0, 0},
+			},
+			want: 0,
+		},
+		{
+			name: "largest coefficients tied, highest priority last",
+			args: args{
+				c:                      []float64{3, 4, 1, -4},
+				integralityConstraints: []bool{true, true, true, true},
+				priorities:             []int{9, 0, 0, 1},
+			},
+			want: 3,
+		},
+		{
+			name: "identical coefficients, higher priority first",
+			args: args{
+				c:                      []float64{3, 3},
+				integralityConstraints: []bool{true, true},
+				priorities:             []int{1, 0},
+			},
+			want: 0,
+		},
+		{
+			name: "identical coefficients, equal priorities",
+			args: args{
+				c:                      []float64{3, 3},
+				integralityConstraints: []bool{true, true},
+				priorities:             []int{2, 2},
+			},
+			want: 1,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := maxFunBranchPoint(tt.args.c, tt.args.integralityConstraints); got != tt.want {
+			if got := maxFunBranchPoint(tt.args.c, tt.args.integralityConstraints, tt.args.priorities); got != tt.want {
 				t.Errorf("maxFunBranchPoint() = %v, want %v", got, tt.want)
 			}
 		})
@@ -92,6 +142,7 @@ func Test_closestFractionalBranchPoint(t *testing.T) {
 	type args struct {
 		c                      []float64
 		integralityConstraints []bool
+		priorities             []int
 	}
 	tests := []struct {
 		name string
@@ -154,12 +205,165 @@ func Test_closestFractionalBranchPoint(t *testing.T) {
 			},
 			want: 3,
 		},
+		{
+			name: "multiple exact matches on 1/2. Should pick the highest priority.",
+			args: args{
+				c:                      []float64{1, 2, 3.5, 4.5},
+				integralityConstraints: []bool{false, true, true, true},
+				priorities:             []int{0, 0, 5, 1},
+			},
+			want: 2,
+		},
+		{
+			name: "integral variable after a fractional one",
+			args: args{
+				c:                      []float64{2.5, 3},
+				integralityConstraints: []bool{true, true},
+			},
+			want: 0,
+		},
+		{
+			name: "fractional part furthest from 1/2 last",
+			args: args{
+				c:                      []float64{1.4, 2.9},
+				integralityConstraints: []bool{true, true},
+			},
+			want: 0,
+		},
+		{
+			name: "negative values",
+			args: args{
+				c:                      []float64{-1.5, 2.1, -3.9},
+				integralityConstraints: []bool{true, true, true},
+			},
+			want: 0,
+		},
+		{
+			name: "priority does not override a closer match",
+			args: args{
+				c:                      []float64{1.5, 2.1},
+				integralityConstraints: []bool{true, true},
+				priorities:             []int{0, 5},
+			},
+			want: 0,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := mostInfeasibleBranchPoint(tt.args.c, tt.args.integralityConstraints); got != tt.want {
+			if got := mostInfeasibleBranchPoint(tt.args.c, tt.args.integralityConstraints, tt.args.priorities); got != tt.want {
 				t.Errorf("closestFractionalBranchPoint() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+// Regression test: BRANCH_MAXFUN used to select variables with an integral value, of which one of the children is identical to the parent, such that the search did not terminate.
+func TestProblem_Solve_MaxFunSkipsIntegralVariables(t *testing.T) {
+	prob := NewProblem()
+	prob.BranchingHeuristic(BRANCH_MAXFUN)
+	prob.DisablePresolve()
+	x0 := prob.AddVariable("x0").SetCoeff(5).UpperBound(4).IsInteger()
+	x1 := prob.AddVariable("x1").SetCoeff(-5).UpperBound(4).IsInteger()
+	x2 := prob.AddVariable("x2").SetCoeff(1).LowerBound(-1).UpperBound(1).IsInteger()
+	prob.AddConstraint("").AddExpression(-4, x0).AddExpression(-4, x2).SmallerThanOrEqualTo(7)
+	prob.AddConstraint("").AddExpression(4, x1).AddExpression(3, x2).SmallerThanOrEqualTo(-2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	soln, err := prob.Solve(ctx)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, STATUS_OPTIMAL, soln.Status)
+	assert.InDelta(t, -1, soln.Objective, 1e-9)
+
+	// of the fractional variables, the one with the largest coefficient is selected
+	sol := solution{
+		x:       []float64{1, 0.5, 0.5},
+		problem: &subProblem{c: []float64{5, -4, 1}, integralityConstraints: []bool{true, true, true}, integralityTol: DefaultIntegralityTolerance},
+	}
+	assert.Equal(t, 1, maxFunStrategy{}.SelectVariable(BranchingState{sol: sol}))
+}
+
+// a trivial custom strategy that branches on the first fractional integer-constrained variable, using only the exported view of the solution
+type firstVariableStrategy struct {
+	calls *int
+}
+
+func (s firstVariableStrategy) SelectVariable(state BranchingState) int {
+	*s.calls++
+	for i, x := range state.X {
+		if state.Integer[i] && x != math.Trunc(x) {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestBranchingStrategy(t *testing.T) {
+	prob := milpProblem{
+		c: []float64{-5, -4},
+		G: NewDenseConstraints(2, 2, []float64{
+			6, 4,
+			1, 2,
+		}),
+		h:                      []float64{23, 7},
+		integralityConstraints: []bool{true, false},
+	}
+
+	solve := func(p milpProblem) solution {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		result, err := p.solve(ctx, 1, dummyMiddleware{})
+		got := result.best()
+		assert.NoError(t, err)
+		return got
+	}
+
+	want := solve(prob)
+
+	var calls int
+	prob.branchingStrategy = firstVariableStrategy{calls: &calls}
+	got := solve(prob)
+
+	assert.InDelta(t, want.z, got.z, 1e-9)
+	assert.InDeltaSlice(t, want.x, got.x, 1e-9)
+	assert.True(t, calls > 0, "custom branching strategy was not used")
+
+	// the strategy is passed on from the API layer
+	api := NewProblem()
+	api.SetBranchingStrategy(firstVariableStrategy{calls: &calls})
+	assert.Equal(t, firstVariableStrategy{calls: &calls}, api.toSolveable().branchingStrategy)
+}
+
+func TestVariable_SetBranchingPriority(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(-1).IsInteger()
+	y := prob.AddVariable("y").SetCoeff(-1).IsInteger().SetBranchingPriority(2)
+	z := prob.AddFreeVariable("z").IsInteger().SetBranchingPriority(1)
+	prob.AddConstraint("").AddExpression(2, x).AddExpression(2, y).AddExpression(1, z).SmallerThanOrEqualTo(3)
+
+	// the negative part of the free variable inherits its priority
+	milp := prob.toSolveable()
+	assert.Equal(t, []int{0, 2, 1, 1}, milp.branchingPriorities)
+
+	// x and y are tied on every heuristic, so the higher priority of y breaks the tie
+	root := milp.toInitialSubproblem()
+	sol := solution{problem: &root, x: []float64{0.5, 0.5, 1, 0, 0}}
+	root.integralityConstraints = []bool{true, true, false, false, false}
+	for _, h := range []BranchHeuristic{BRANCH_MAXFUN, BRANCH_MOST_INFEASIBLE, BRANCH_NAIVE} {
+		assert.Equal(t, 1, h.strategy(nil).SelectVariable(newBranchingState(sol)), "heuristic %v", h)
+	}
+
+	// without priorities, the last of the tied variables is selected
+	root.branchingPriorities = nil
+	for _, h := range []BranchHeuristic{BRANCH_MAXFUN, BRANCH_MOST_INFEASIBLE, BRANCH_NAIVE} {
+		assert.Equal(t, 1, h.strategy(nil).SelectVariable(newBranchingState(sol)), "heuristic %v", h)
+	}
+	y.SetBranchingPriority(0)
+	x.SetBranchingPriority(1)
+	root.branchingPriorities = prob.toSolveable().branchingPriorities
+	for _, h := range []BranchHeuristic{BRANCH_MAXFUN, BRANCH_MOST_INFEASIBLE, BRANCH_NAIVE} {
+		assert.Equal(t, 0, h.strategy(nil).SelectVariable(newBranchingState(sol)), "heuristic %v", h)
+	}
+}
@@ -0,0 +1,111 @@
+package ilp
+
+import (
+	"fmt"
+	"math"
+)
+
+// AddCardinalityConstraint adds a constraint that allows at most k of the provided variables to be nonzero.
+// It is encoded with a binary indicator variable y_i for each variable x_i, which must be 1 for x_i to be nonzero:
+// x_i <= u_i * y_i is added as a big-M constraint in which M is the upper bound u_i of x_i, along with x_i >= l_i * y_i if its lower bound l_i is negative,
+// and the sum of the indicators is constrained to at most k. The indicators are internal variables, which are omitted from the Solution.
+// Hence, the bounds of the variables must be finite, and should be set before adding the constraint.
+// Returns an error if k is negative, if a variable does not belong to the problem, if its bounds are not finite,
+// or if the name of an indicator is already taken, in which case the problem is left untouched.
+func (p *Problem) AddCardinalityConstraint(vars []*Variable, k int) error {
+	done, err := p.modify()
+	if err != nil {
+		return err
+	}
+	defer done()
+	if k < 0 {
+		return fmt.Errorf("cardinality %v is negative", k)
+	}
+
+	registered := make(map[*Variable]bool, len(p.variables))
+	names := make(map[string]bool, len(p.variables))
+	for _, v := range p.variables {
+		registered[v] = true
+		names[v.name] = true
+	}
+	for _, v := range vars {
+		if !registered[v] {
+			return fmt.Errorf("variable %v does not belong to the problem", v.name)
+		}
+		if math.IsInf(v.upper, 0) || math.IsInf(v.lower, 0) {
+			return fmt.Errorf("variable %v: bounds [%v, %v] are not finite", v.name, v.lower, v.upper)
+		}
+	}
+
+	// the constraint is redundant if it admits all variables to be nonzero
+	if k >= len(vars) {
+		return nil
+	}
+
+	id := p.cardinalityConstraints
+	indicatorNames := make([]string, len(vars))
+	for i, v := range vars {
+		indicatorNames[i] = fmt.Sprintf("_card%v_%v", id, v.name)
+		if names[indicatorNames[i]] {
+			return fmt.Errorf("indicator of variable %v: variable %v: %w", v.name, indicatorNames[i], ErrDuplicateVariableName)
+		}
+		names[indicatorNames[i]] = true
+	}
+
+	indicators := make([]*Variable, len(vars))
+	for i, name := range indicatorNames {
+		indicator, err := p.tryAddBinaryVariable(name)
+		if err != nil {
+			return err
+		}
+		indicator.internal = true
+		indicators[i] = indicator
+	}
+	p.cardinalityConstraints++
+
+	sum := p.AddConstraint("")
+	for i, v := range vars {
+		indicator := indicators[i]
+		sum.AddExpression(1, indicator)
+
+		p.AddConstraint("").AddExpression(1, v).SmallerThanOrEqualTo(0).BigM(indicator, v.upper)
+		if v.lower < 0 {
+			p.AddConstraint("").AddExpression(1, v).AddExpression(-v.lower, indicator).GreaterThanOrEqualTo(0)
+		}
+	}
+	sum.SmallerThanOrEqualTo(float64(k))
+
+	return nil
+}
+
+// remove the values of the internal variables from the solution, such as the indicators of cardinality constraints.
+func (p Problem) stripInternalVariables(soln *Solution) {
+	internal := make(map[string]bool)
+	for _, v := range p.variables {
+		if v.internal {
+			internal[v.name] = true
+		}
+	}
+	if len(internal) == 0 {
+		return
+	}
+
+	// the values are copied, as the original map may be shared, e.g. as the incumbent of the CutManager
+	if soln.byName != nil {
+		byName := make(map[string]float64, len(soln.byName))
+		for name, val := range soln.byName {
+			if !internal[name] {
+				byName[name] = val
+			}
+		}
+		soln.byName = byName
+	}
+
+	variables := make([]string, 0, len(soln.variables))
+	for _, name := range soln.variables {
+		if !internal[name] {
+			variables = append(variables, name)
+		}
+	}
+	soln.variables = variables
+}
@@ -0,0 +1,114 @@
+package ilp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// maximize the returns of a portfolio of 5 assets of which the amounts are bounded, within a budget.
+func cardinalityTestProblem() (Problem, []*Variable) {
+	returns := []float64{8, 4, 7, 8, 3}
+	costs := []float64{4, 1, 3, 1, 2}
+	upper := []float64{1, 1, 1, 2, 3}
+
+	prob := NewProblem()
+	prob.Maximize()
+	prob.BranchingHeuristic(BRANCH_MOST_INFEASIBLE)
+	budget := prob.AddConstraint("budget")
+	vars := make([]*Variable, len(returns))
+	for i := range vars {
+		vars[i] = prob.AddVariable(fmt.Sprintf("x%v", i)).SetCoeff(returns[i]).UpperBound(upper[i])
+		budget.AddExpression(costs[i], vars[i])
+	}
+	budget.SmallerThanOrEqualTo(7.5)
+	return prob, vars
+}
+
+func TestProblem_AddCardinalityConstraint(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	prob, vars := cardinalityTestProblem()
+	if !assert.NoError(t, prob.AddCardinalityConstraint(vars, 2)) {
+		return
+	}
+	soln, err := prob.Solve(ctx)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the equivalent formulation with explicit indicator variables
+	manual, manualVars := cardinalityTestProblem()
+	sum := manual.AddConstraint("")
+	for _, v := range manualVars {
+		y := manual.AddBinaryVariable("y" + v.Name())
+		manual.AddConstraint("").AddExpression(1, v).SmallerThanOrEqualTo(0).BigM(y, v.Upper())
+		sum.AddExpression(1, y)
+	}
+	sum.SmallerThanOrEqualTo(2)
+	expected, err := manual.Solve(ctx)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, STATUS_OPTIMAL, soln.Status)
+	assert.InDelta(t, expected.Objective, soln.Objective, 1e-9)
+	assert.InDelta(t, 24.25, soln.Objective, 1e-9)
+
+	// at most 2 variables are nonzero, and the indicators are omitted from the solution
+	values := soln.ByVariableOrder()
+	assert.Len(t, values, len(vars))
+	assert.Len(t, soln.ToMap(), len(vars))
+	nonzero := 0
+	for _, val := range values {
+		if math.Abs(val) > 1e-9 {
+			nonzero++
+		}
+	}
+	assert.True(t, nonzero <= 2)
+
+	// without the constraint, more variables are nonzero and the objective value is higher
+	unconstrained, _ := cardinalityTestProblem()
+	relaxed, err := unconstrained.Solve(ctx)
+	if assert.NoError(t, err) {
+		assert.True(t, relaxed.Objective > soln.Objective+1e-9)
+	}
+}
+
+func TestProblem_AddCardinalityConstraint_Errors(t *testing.T) {
+	prob, vars := cardinalityTestProblem()
+	assert.Error(t, prob.AddCardinalityConstraint(vars, -1))
+
+	other := NewProblem()
+	assert.Error(t, prob.AddCardinalityConstraint([]*Variable{other.AddVariable("x")}, 1))
+
+	free := prob.AddVariable("free")
+	assert.Error(t, prob.AddCardinalityConstraint([]*Variable{free}, 0))
+
+	// a variable that is listed twice would need two indicators of the same name
+	n := prob.NumVariables()
+	err := prob.AddCardinalityConstraint([]*Variable{vars[0], vars[0]}, 1)
+	assert.True(t, errors.Is(err, ErrDuplicateVariableName))
+	assert.Equal(t, n, prob.NumVariables())
+
+	// as would a variable whose indicator name is taken
+	taken := prob.AddVariable(fmt.Sprintf("_card0_%v", vars[1].Name()))
+	err = prob.AddCardinalityConstraint(vars, 1)
+	assert.True(t, errors.Is(err, ErrDuplicateVariableName))
+	assert.Equal(t, n+1, prob.NumVariables())
+	assert.NoError(t, prob.RemoveVariable(taken))
+
+	// a redundant constraint is not added
+	n = prob.NumVariables()
+	assert.NoError(t, prob.AddCardinalityConstraint(vars, len(vars)))
+	assert.Equal(t, n, prob.NumVariables())
+
+	prob.Freeze()
+	assert.True(t, errors.Is(prob.AddCardinalityConstraint(vars, 1), ErrProblemFrozen))
+}
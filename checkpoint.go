@@ -0,0 +1,248 @@
+package ilp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+// TreeState is the state of an interrupted branch-and-bound procedure, from which the search can be resumed.
+// The subProblems are described by the branch-and-bound constraints added to the root problem, so the state can only be restored
+// into the enumeration tree of the same problem. The pseudocosts and the instrumentation are not part of the state.
+type TreeState struct {
+	// the subProblems that were queued or being solved when the search was stopped
+	Open []subProblemState `json:"open"`
+
+	Incumbent    *solutionState `json:"incumbent,omitempty"`
+	RootSolution solutionState  `json:"rootSolution"`
+
+	// the last identifier handed out to a subProblem
+	LastID int64 `json:"lastId"`
+
+	Stats            SolveStats             `json:"stats"`
+	IncumbentHistory []incumbentRecordState `json:"incumbentHistory,omitempty"`
+
+	// whether any subProblems were pruned at the maximum depth, and the lowest objective value among them
+	DepthLimited      bool      `json:"depthLimited,omitempty"`
+	DepthLimitedBound jsonFloat `json:"depthLimitedBound"`
+}
+
+type subProblemState struct {
+	ID          int64                `json:"id"`
+	Parent      int64                `json:"parent"`
+	Bound       jsonFloat            `json:"bound"`
+	Estimate    jsonFloat            `json:"estimate"`
+	Constraints []bnbConstraintState `json:"constraints"`
+}
+
+// a bnbConstraint of which the coefficients are stored sparsely, as most of them are zero.
+type bnbConstraintState struct {
+	BranchedVariable int       `json:"branchedVariable"`
+	HSharp           float64   `json:"hsharp"`
+	Indices          []int     `json:"indices"`
+	Coefficients     []float64 `json:"coefficients"`
+}
+
+type solutionState struct {
+	X         []float64 `json:"x"`
+	Z         jsonFloat `json:"z"`
+	BestBound jsonFloat `json:"bestBound"`
+	Dual      []float64 `json:"dual,omitempty"`
+}
+
+type incumbentRecordState struct {
+	Solution      solutionState `json:"solution"`
+	NodeID        int64         `json:"nodeId"`
+	NodesExplored int64         `json:"nodesExplored"`
+	Timestamp     time.Time     `json:"timestamp"`
+}
+
+func newSolutionState(s solution) solutionState {
+	return solutionState{X: s.x, Z: jsonFloat(s.z), BestBound: jsonFloat(s.bestBound), Dual: s.dual}
+}
+
+// restore the solution of the provided subProblem.
+func (s solutionState) restore(problem *subProblem) solution {
+	return solution{problem: problem, x: s.X, z: float64(s.Z), bestBound: float64(s.BestBound), dual: s.Dual}
+}
+
+// SaveState encodes the state of the search as JSON (see TreeState), from which it can be resumed with LoadState.
+// It may only be called while the search is not running, e.g. after it was stopped by the context.
+func (p *enumerationTree) SaveState() ([]byte, error) {
+	state := TreeState{
+		RootSolution:      newSolutionState(p.rootSolution),
+		LastID:            p.idGenerator.Last(),
+		Stats:             p.statistics(),
+		DepthLimited:      p.depthLimited,
+		DepthLimitedBound: jsonFloat(p.depthLimitedBound),
+	}
+	if p.incumbent != nil {
+		incumbent := newSolutionState(*p.incumbent)
+		state.Incumbent = &incumbent
+	}
+	for _, record := range p.incumbentHistory {
+		state.IncumbentHistory = append(state.IncumbentHistory, incumbentRecordState{
+			Solution:      newSolutionState(record.solution),
+			NodeID:        record.nodeID,
+			NodesExplored: record.nodesExplored,
+			Timestamp:     record.timestamp,
+		})
+	}
+
+	// empty the queue to inspect its subProblems, and restore it afterwards
+	var queued []subProblem
+	for p.queue.Len() > 0 {
+		queued = append(queued, p.queue.Dequeue().problem)
+	}
+	for _, prob := range queued {
+		p.queue.Enqueue(newOpenNode(prob))
+	}
+
+	open := append([]subProblem(nil), p.restored...)
+	open = append(open, queued...)
+	for _, prob := range p.dispatched {
+		open = append(open, prob)
+	}
+	sort.Slice(open, func(i, j int) bool {
+		return open[i].id < open[j].id
+	})
+
+	for _, prob := range open {
+		s := subProblemState{
+			ID:       prob.id,
+			Parent:   prob.parent,
+			Bound:    jsonFloat(prob.bound),
+			Estimate: jsonFloat(prob.estimate),
+		}
+		for _, c := range prob.bnbConstraints {
+			constraint := bnbConstraintState{BranchedVariable: c.branchedVariable, HSharp: c.hsharp}
+			for j, coef := range c.gsharp {
+				if coef != 0 {
+					constraint.Indices = append(constraint.Indices, j)
+					constraint.Coefficients = append(constraint.Coefficients, coef)
+				}
+			}
+			s.Constraints = append(s.Constraints, constraint)
+		}
+		state.Open = append(state.Open, s)
+	}
+
+	return json.Marshal(state)
+}
+
+// LoadState restores the state of a search encoded by SaveState, which can then be resumed with resumeSearch.
+// The channels, queue and open subProblems of the tree are reinitialised, discarding any previous search.
+func (p *enumerationTree) LoadState(data []byte) error {
+	var state TreeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	n := len(p.rootProblem.c)
+	var open []subProblem
+	for _, s := range state.Open {
+		prob := p.rootProblem
+		prob.id = s.ID
+		prob.parent = s.Parent
+		prob.bound = float64(s.Bound)
+		prob.estimate = float64(s.Estimate)
+		prob.bnbConstraints = make([]bnbConstraint, 0, len(s.Constraints))
+		for _, c := range s.Constraints {
+			if len(c.Indices) != len(c.Coefficients) {
+				return fmt.Errorf("subProblem %v: constraint has %v indices and %v coefficients", s.ID, len(c.Indices), len(c.Coefficients))
+			}
+			gsharp := make([]float64, n)
+			for k, j := range c.Indices {
+				if j < 0 || j >= n {
+					return fmt.Errorf("subProblem %v: constraint refers to column %v of a problem with %v columns", s.ID, j, n)
+				}
+				gsharp[j] = c.Coefficients[k]
+			}
+			prob.bnbConstraints = append(prob.bnbConstraints, bnbConstraint{branchedVariable: c.BranchedVariable, hsharp: c.HSharp, gsharp: gsharp})
+		}
+		open = append(open, prob)
+	}
+
+	p.active = make(chan subProblem)
+	p.candidates = make(chan solution)
+	for p.queue.Len() > 0 {
+		p.queue.Dequeue()
+	}
+	p.dispatched = make(map[int64]subProblem)
+	p.pendingBranchings = make(map[int64]pendingBranching)
+	p.workInProgress = 0
+
+	p.restored = open
+	p.openBounds = make(map[int64]float64, len(open))
+	for _, prob := range open {
+		p.openBounds[prob.id] = prob.bound
+	}
+
+	p.rootSolution = state.RootSolution.restore(&p.rootProblem)
+	p.incumbent = nil
+	if state.Incumbent != nil {
+		incumbent := state.Incumbent.restore(&p.rootProblem)
+		p.incumbent = &incumbent
+	}
+	p.incumbentHistory = nil
+	for _, record := range state.IncumbentHistory {
+		p.incumbentHistory = append(p.incumbentHistory, incumbentRecord{
+			solution:      record.Solution.restore(&p.rootProblem),
+			nodeID:        record.NodeID,
+			nodesExplored: record.NodesExplored,
+			timestamp:     record.Timestamp,
+		})
+	}
+
+	p.idGenerator.Reset(state.LastID)
+	p.nodesCreated = state.Stats.NodesCreated
+	p.nodesChecked = state.Stats.NodesExplored
+	p.lpRelaxationsSolved = state.Stats.LPRelaxationsSolved
+	p.depthLimited = state.DepthLimited
+	p.depthLimitedBound = float64(state.DepthLimitedBound)
+	p.updateDualBound()
+	p.publishStatistics()
+	return nil
+}
+
+// Resume the search from the state restored by LoadState.
+func (p *enumerationTree) resumeSearch(ctx context.Context, nworkers int) (*solution, error) {
+	for _, prob := range p.restored {
+		p.workAdded()
+		p.queue.Enqueue(newOpenNode(prob))
+		p.instrumentation.NewSubProblem(prob)
+	}
+	p.restored = nil
+
+	return p.search(ctx, nworkers)
+}
+
+// SolveWithCheckpoint solves the problem like Solve, but saves the state of the branch-and-bound procedure (see TreeState) to the state file
+// if the search is stopped by the context, e.g. due to a timeout. If the state file exists, the search is resumed from the state it holds instead of started anew.
+// The state file is removed once the search completes. The problem and the presolve setting should not be changed between the solves that share a state file.
+// Note that the statistics of a resumed search include the work done before it was stopped, except for the wall time.
+func (p Problem) SolveWithCheckpoint(ctx context.Context, stateFile string, opts ...SolveOption) (*Solution, error) {
+	state, err := ioutil.ReadFile(stateFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	interrupted := false
+	soln, err := p.solveWith(ctx, func(milp *milpProblem) {
+		milp.resumeState = state
+		milp.checkpoint = func(state []byte) error {
+			interrupted = true
+			return ioutil.WriteFile(stateFile, state, 0644)
+		}
+	}, opts)
+
+	// the search completed, so it should not be resumed by the next solve
+	if state != nil && !interrupted && err == nil {
+		err = os.Remove(stateFile)
+	}
+	return soln, err
+}
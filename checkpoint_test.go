@@ -0,0 +1,111 @@
+package ilp
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// cancels the context after the provided number of decisions, interrupting the search.
+type interruptMiddleware struct {
+	decisions int64
+	after     int64
+	cancel    context.CancelFunc
+}
+
+func (m *interruptMiddleware) ProcessDecision(solution, bnbDecision) {
+	if atomic.AddInt64(&m.decisions, 1) == m.after {
+		m.cancel()
+	}
+}
+
+func (m *interruptMiddleware) NewSubProblem(subProblem) {}
+
+func TestEnumerationTree_SaveState(t *testing.T) {
+	prob := getTestKnapsackMILP()
+
+	uninterrupted, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var state []byte
+	interrupted := prob
+	interrupted.checkpoint = func(s []byte) error {
+		state = s
+		return nil
+	}
+	_, err = interrupted.solve(ctx, 1, &interruptMiddleware{after: 5, cancel: cancel})
+	if !assert.Equal(t, context.Canceled, err) || !assert.NotNil(t, state) {
+		return
+	}
+
+	// the resumed search only decides on the subProblems that were not decided on before the interruption
+	resumed := prob
+	resumed.resumeState = state
+	counter := &interruptMiddleware{}
+	result, err := resumed.solve(context.Background(), 1, counter)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, STATUS_OPTIMAL, result.Status)
+	assert.InDelta(t, uninterrupted.BestIntegerSolution.z, result.BestIntegerSolution.z, 1e-9)
+	assert.Equal(t, uninterrupted.BestIntegerSolution.x, result.BestIntegerSolution.x)
+	assert.Equal(t, uninterrupted.Stats.NodesExplored, result.Stats.NodesExplored)
+	assert.Equal(t, uninterrupted.Stats.NodesExplored-5, counter.decisions)
+
+	// the state should describe the subProblems of the same problem
+	small := milpProblem{
+		c:                      []float64{-1},
+		G:                      NewDenseConstraints(1, 1, []float64{1}),
+		h:                      []float64{2.5},
+		integralityConstraints: []bool{true},
+	}
+	tree := newEnumerationTree(small.toInitialSubproblem(), dummyMiddleware{}, SolverConfig{}, nil)
+	assert.Error(t, tree.LoadState(state))
+	assert.Error(t, tree.LoadState([]byte("not json")))
+}
+
+func TestProblem_SolveWithCheckpoint(t *testing.T) {
+	prob, _, _, _ := getTestKnapsackProblem()
+
+	uninterrupted, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "state.json")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	prob.SetInstrumentation(&interruptMiddleware{after: 2, cancel: cancel})
+	_, err = prob.SolveWithCheckpoint(ctx, stateFile)
+	if !assert.Equal(t, context.Canceled, err) || !assert.FileExists(t, stateFile) {
+		return
+	}
+
+	prob.SetInstrumentation(dummyMiddleware{})
+	soln, err := prob.SolveWithCheckpoint(context.Background(), stateFile)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, STATUS_OPTIMAL, soln.Status)
+	assert.InDelta(t, uninterrupted.Objective, soln.Objective, 1e-9)
+	assert.Equal(t, uninterrupted.byName, soln.byName)
+
+	// the state file is removed once the search completes
+	_, err = os.Stat(stateFile)
+	assert.True(t, os.IsNotExist(err))
+}
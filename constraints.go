@@ -0,0 +1,199 @@
+package ilp
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// ConstraintMatrix is the coefficient matrix of a set of linear constraints.
+// It can be backed by a dense or a sparse representation. The LP solver only operates on dense matrices,
+// so a sparse matrix is converted to a dense one right before it is solved.
+type ConstraintMatrix interface {
+	Dims() (r, c int)
+	At(i, j int) float64
+
+	// a dense representation of the matrix, which may share its backing data with the receiver and should not be modified.
+	ToDense() *mat.Dense
+}
+
+// DenseConstraints is a ConstraintMatrix backed by a dense matrix.
+type DenseConstraints struct {
+	*mat.Dense
+}
+
+// NewDenseConstraints creates a dense constraint matrix of r rows and c columns, like mat.NewDense.
+func NewDenseConstraints(r, c int, data []float64) DenseConstraints {
+	return DenseConstraints{mat.NewDense(r, c, data)}
+}
+
+func (d DenseConstraints) ToDense() *mat.Dense {
+	return d.Dense
+}
+
+// SparseConstraints is a ConstraintMatrix stored in compressed sparse row (CSR) format.
+// Only the nonzero entries are stored, which saves a lot of memory for large problems in which each constraint only contains a few variables.
+// Rows are built up by appending them one by one.
+type SparseConstraints struct {
+	rows, cols int
+
+	// the nonzero entries of row i are stored at indices rowStart[i] up to rowStart[i+1] of columns and values,
+	// ordered by column.
+	rowStart []int
+	columns  []int
+	values   []float64
+}
+
+// NewSparseConstraints creates an empty sparse constraint matrix with c columns, to which rows can be appended.
+func NewSparseConstraints(c int) *SparseConstraints {
+	return &SparseConstraints{
+		cols:     c,
+		rowStart: []int{0},
+	}
+}
+
+// convert a matrix to a sparse constraint matrix.
+func sparseFrom(m ConstraintMatrix) *SparseConstraints {
+	if s, ok := m.(*SparseConstraints); ok {
+		return s
+	}
+
+	r, c := m.Dims()
+	s := NewSparseConstraints(c)
+	row := make([]float64, c)
+	for i := 0; i < r; i++ {
+		rowOf(m, i, row)
+		s.AppendRow(row)
+	}
+	return s
+}
+
+// AppendRow appends a row to the matrix, of which only the nonzero entries are stored.
+// The row should have an entry for each column. If not, this call will panic.
+func (s *SparseConstraints) AppendRow(row []float64) {
+	if len(row) != s.cols {
+		panic(mat.ErrShape)
+	}
+
+	for j, v := range row {
+		if v != 0 {
+			s.columns = append(s.columns, j)
+			s.values = append(s.values, v)
+		}
+	}
+	s.rows++
+	s.rowStart = append(s.rowStart, len(s.values))
+}
+
+func (s *SparseConstraints) Dims() (r, c int) {
+	return s.rows, s.cols
+}
+
+func (s *SparseConstraints) At(i, j int) float64 {
+	if i < 0 || i >= s.rows || j < 0 || j >= s.cols {
+		panic(mat.ErrIndexOutOfRange)
+	}
+
+	// binary search of the column in the nonzero entries of the row
+	lo, hi := s.rowStart[i], s.rowStart[i+1]
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch {
+		case s.columns[mid] == j:
+			return s.values[mid]
+		case s.columns[mid] < j:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return 0
+}
+
+// T returns the transpose of the matrix, which makes SparseConstraints a mat.Matrix.
+func (s *SparseConstraints) T() mat.Matrix {
+	return mat.Transpose{Matrix: s}
+}
+
+// NNZ returns the number of nonzero entries of the matrix.
+func (s *SparseConstraints) NNZ() int {
+	return len(s.values)
+}
+
+func (s *SparseConstraints) ToDense() *mat.Dense {
+	if s.rows == 0 {
+		return nil
+	}
+
+	d := mat.NewDense(s.rows, s.cols, nil)
+	s.writeTo(d)
+	return d
+}
+
+// write the nonzero entries of the matrix into the top left part of a dense matrix.
+func (s *SparseConstraints) writeTo(d *mat.Dense) {
+	for i := 0; i < s.rows; i++ {
+		for k := s.rowStart[i]; k < s.rowStart[i+1]; k++ {
+			d.Set(i, s.columns[k], s.values[k])
+		}
+	}
+}
+
+// copy row i of the matrix into dst, which should have an entry for each column.
+func rowOf(m ConstraintMatrix, i int, dst []float64) {
+	s, ok := m.(*SparseConstraints)
+	if !ok {
+		for j := range dst {
+			dst[j] = m.At(i, j)
+		}
+		return
+	}
+
+	for j := range dst {
+		dst[j] = 0
+	}
+	for k := s.rowStart[i]; k < s.rowStart[i+1]; k++ {
+		dst[s.columns[k]] = s.values[k]
+	}
+}
+
+// whether the matrix is stored in a sparse format.
+func isSparse(m ConstraintMatrix) bool {
+	_, ok := m.(*SparseConstraints)
+	return ok
+}
+
+// copy the constraint matrix into the top left part of a dense matrix, without converting a sparse matrix to a dense one first.
+func embedConstraints(dst *mat.Dense, m ConstraintMatrix) {
+	if s, ok := m.(*SparseConstraints); ok {
+		s.writeTo(dst)
+		return
+	}
+
+	r, c := m.Dims()
+	dst.Slice(0, r, 0, c).(*mat.Dense).Copy(m.ToDense())
+}
+
+// Constraint matrices with at least this many entries are stored in a sparse format if at most this fraction of their entries is nonzero.
+const (
+	sparseMinEntries = 10000
+	sparseMaxDensity = 0.1
+)
+
+// create a constraint matrix of r rows and c columns from row-major data, choosing the representation based on its size and density.
+func newConstraintMatrix(r, c int, data []float64) ConstraintMatrix {
+	var nnz int
+	for _, v := range data {
+		if v != 0 {
+			nnz++
+		}
+	}
+
+	if len(data) < sparseMinEntries || float64(nnz) > sparseMaxDensity*float64(len(data)) {
+		return NewDenseConstraints(r, c, data)
+	}
+
+	s := NewSparseConstraints(c)
+	for i := 0; i < r; i++ {
+		s.AppendRow(data[i*c : (i+1)*c])
+	}
+	return s
+}
@@ -0,0 +1,138 @@
+package ilp
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSparseConstraints(t *testing.T) {
+	dense := mat.NewDense(3, 4, []float64{
+		0, 2, 0, 0,
+		0, 0, 0, 0,
+		-1, 0, 3, 4,
+	})
+
+	sparse := sparseFrom(DenseConstraints{dense})
+
+	r, c := sparse.Dims()
+	assert.Equal(t, 3, r)
+	assert.Equal(t, 4, c)
+	assert.Equal(t, 4, sparse.NNZ())
+
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			assert.Equal(t, dense.At(i, j), sparse.At(i, j))
+		}
+	}
+	assert.Equal(t, dense, sparse.ToDense())
+	assert.True(t, mat.Equal(dense.T(), sparse.T()))
+
+	assert.Panics(t, func() { sparse.AppendRow([]float64{1, 2}) })
+	assert.Panics(t, func() { sparse.At(3, 0) })
+}
+
+func Test_convertToEqualities_sparse(t *testing.T) {
+	c := []float64{-1, -2, 1, -3}
+	A := NewDenseConstraints(2, 4, []float64{
+		1, 1, 0, 0,
+		0, 0, 1, 1,
+	})
+	b := []float64{1, 1}
+	G := NewDenseConstraints(3, 4, []float64{
+		1, 0, 0, 0,
+		0, 0, 0, 1,
+		0, 0, 0, 0,
+	})
+	h := []float64{1, 2, 3}
+
+	wantC, wantA, wantB := convertToEqualities(c, A, b, G, h)
+
+	// a sparse matrix yields the same equalities in a sparse format
+	gotC, gotA, gotB := convertToEqualities(c, A, b, sparseFrom(G), h)
+	assert.Equal(t, wantC, gotC)
+	assert.Equal(t, wantB, gotB)
+	assert.True(t, isSparse(gotA))
+	assert.Equal(t, wantA.ToDense(), gotA.ToDense())
+}
+
+// A problem with sparse constraint matrices should yield the same solution as its dense counterpart.
+func TestMilpProblem_Solve_Sparse(t *testing.T) {
+	dense := getTestKnapsackMILP()
+
+	sparse := dense
+	sparse.G = sparseFrom(dense.G)
+
+	var results []MIPResult
+	for _, prob := range []milpProblem{dense, sparse} {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		result, err := prob.solve(ctx, 1, dummyMiddleware{})
+		got := result.best()
+		cancel()
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(-24), got.z)
+		results = append(results, result)
+	}
+
+	// the storage of the constraints does not affect the search
+	assert.Equal(t, results[0].BestIntegerSolution.x, results[1].BestIntegerSolution.x)
+	assert.Equal(t, results[0].Stats.NodesExplored, results[1].Stats.NodesExplored)
+}
+
+func Test_newConstraintMatrix(t *testing.T) {
+	// small matrices are always stored densely
+	assert.False(t, isSparse(newConstraintMatrix(2, 2, []float64{1, 0, 0, 0})))
+
+	// large matrices are stored sparsely only if few of their entries are nonzero
+	n := 200
+	data := make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		data[i*n+i] = 1
+	}
+	assert.True(t, isSparse(newConstraintMatrix(n, n, data)))
+
+	for i := range data {
+		data[i] = 1
+	}
+	assert.False(t, isSparse(newConstraintMatrix(n, n, data)))
+}
+
+// Compare the memory usage of the dense and sparse representations of the constraints of a problem with 500 variables,
+// of which 5% of the constraint coefficients are nonzero, when converting them to equalities.
+func BenchmarkConvertToEqualities(b *testing.B) {
+	const (
+		nVar    = 500
+		nCons   = 500
+		density = 0.05
+	)
+
+	rnd := rand.New(rand.NewSource(1))
+	data := make([]float64, nCons*nVar)
+	for i := range data {
+		if rnd.Float64() < density {
+			data[i] = rnd.NormFloat64()
+		}
+	}
+	c := make([]float64, nVar)
+	h := make([]float64, nCons)
+
+	for _, bm := range []struct {
+		name string
+		G    ConstraintMatrix
+	}{
+		{"dense", NewDenseConstraints(nCons, nVar, data)},
+		{"sparse", sparseFrom(NewDenseConstraints(nCons, nVar, data))},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				convertToEqualities(c, nil, nil, bm.G, h)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package ilp
+
+import (
+	"sort"
+)
+
+// SeparateCoverCuts derives cover cuts from the knapsack constraints of the subProblem of the solution, and returns those that cut off the solution.
+// A knapsack constraint sum(a_j * x_j) <= b over binary variables x_j with a_j >= 0 is violated by setting all variables of a cover C,
+// i.e. a set of variables with sum_{j in C} a_j > b, to 1. Hence, the cover cut sum_{j in C} x_j <= |C| - 1 is valid for all integer-feasible points.
+//
+// Each row of the subProblem of which all coefficients are nonnegative is treated as a knapsack constraint over its binary variables,
+// as the other variables can be dropped from it without invalidating it. The branch-and-bound constraints are ignored, so the cuts are globally valid.
+// For each knapsack constraint, the cover is chosen greedily to be the most violated by the solution, and reduced to a minimal cover.
+//
+// SeparateCoverCuts is a LazyConstraintCallback, e.g.
+//
+//	prob.lazyConstraints = SeparateCoverCuts
+func SeparateCoverCuts(sol solution) []bnbConstraint {
+	prob := sol.problem
+	if sol.err != nil || prob.A == nil {
+		return nil
+	}
+
+	rows, cols := prob.A.Dims()
+	row := make([]float64, cols)
+	seen := make(map[string]bool)
+
+	var cuts []bnbConstraint
+	for i := 0; i < rows; i++ {
+		rowOf(prob.A, i, row)
+
+		cover := knapsackCover(row, prob.b[i], sol.x, prob.binaryVariables)
+		if cover == nil {
+			continue
+		}
+
+		cut := bnbConstraint{
+			branchedVariable: noBranchedVariable,
+			hsharp:           float64(len(cover) - 1),
+			gsharp:           make([]float64, cols),
+		}
+		for _, j := range cover {
+			cut.gsharp[j] = 1
+		}
+
+		// different knapsack constraints may yield the same cover
+		key := cutKey(cut)
+		if seen[key] || !cut.violatedBy(sol.x) {
+			continue
+		}
+		seen[key] = true
+		cuts = append(cuts, cut)
+	}
+
+	return cuts
+}
+
+// find a minimal cover of the knapsack constraint row * x <= b over the binary variables, chosen greedily to be the most violated by the solution vector x.
+// Returns nil if the row is not a knapsack constraint, i.e. it has a negative coefficient, or if the binary variables do not admit a cover.
+func knapsackCover(row []float64, b float64, x []float64, binary []bool) []int {
+	var items []int
+	total := 0.
+	for j, a := range row {
+		if a < 0 {
+			return nil
+		}
+		if a > 0 && j < len(binary) && binary[j] {
+			items = append(items, j)
+			total += a
+		}
+	}
+	if total <= b+cutTolerance {
+		return nil
+	}
+
+	// The cut of a cover C is violated if sum_{j in C} (1 - x_j) < 1, so the variables that are cheapest to add per unit of weight are added first.
+	cost := func(j int) float64 {
+		return (1 - x[j]) / row[j]
+	}
+	sort.SliceStable(items, func(k, l int) bool {
+		return cost(items[k]) < cost(items[l])
+	})
+
+	var cover []int
+	weight := 0.
+	for _, j := range items {
+		cover = append(cover, j)
+		weight += row[j]
+		if weight > b+cutTolerance {
+			break
+		}
+	}
+
+	// Reduce the cover to a minimal cover by dropping the variables that are not needed to exceed the capacity, the most expensive first.
+	// A variable that is retained remains needed as others are dropped, so the result is minimal.
+	minimal := make([]int, 0, len(cover))
+	for k := len(cover) - 1; k >= 0; k-- {
+		j := cover[k]
+		if weight-row[j] > b+cutTolerance {
+			weight -= row[j]
+			continue
+		}
+		minimal = append(minimal, j)
+	}
+
+	sort.Ints(minimal)
+	return minimal
+}
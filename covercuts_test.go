@@ -0,0 +1,90 @@
+package ilp
+
+import (
+	"context"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeparateCoverCuts(t *testing.T) {
+	// minimize -5x1 - 4x2 - 3x3 s.t. 5x1 + 4x2 + 3x3 <= 7, with x1, x2 and x3 binary.
+	weights := []float64{5, 4, 3}
+	prob := milpProblem{
+		c: []float64{-5, -4, -3},
+		G: NewDenseConstraints(4, 3, []float64{
+			5, 4, 3,
+			1, 0, 0,
+			0, 1, 0,
+			0, 0, 1,
+		}),
+		h:                      []float64{7, 1, 1, 1},
+		integralityConstraints: []bool{true, true, true},
+		binaryVariables:        []bool{true, true, true},
+		branchingHeuristic:     BRANCH_MOST_INFEASIBLE,
+	}
+	root := prob.toInitialSubproblem().solve()
+	if !assert.NoError(t, root.err) {
+		return
+	}
+
+	// the LP relaxation takes x1 = 1 and x2 = 1/2, so the minimal cover {x1, x2} yields the cut x1 + x2 <= 1
+	cuts := SeparateCoverCuts(root)
+	if !assert.Len(t, cuts, 1) {
+		return
+	}
+	cut := cuts[0]
+	assert.Equal(t, noBranchedVariable, cut.branchedVariable)
+	assert.Equal(t, []float64{1, 1, 0}, cut.gsharp[:3])
+	assert.Equal(t, 1., cut.hsharp)
+	assert.True(t, cut.violatedBy(root.x))
+
+	// the cut is valid for all integer-feasible points, and tight for some of them
+	tight := false
+	for k := 0; k < 8; k++ {
+		x := []float64{float64(k & 1), float64(k >> 1 & 1), float64(k >> 2 & 1)}
+		if floats.Dot(weights, x) > 7 {
+			continue
+		}
+		lhs := floats.Dot(cut.gsharp[:3], x)
+		assert.True(t, lhs <= cut.hsharp)
+		tight = tight || lhs == cut.hsharp
+	}
+	assert.True(t, tight)
+
+	// once the cut is added, the solution satisfies it, but violates the cover cut x1 + x3 <= 1
+	tightened := root.withCuts(cuts).problem.solve()
+	if !assert.NoError(t, tightened.err) {
+		return
+	}
+	assert.False(t, cut.violatedBy(tightened.x))
+	next := SeparateCoverCuts(tightened)
+	if assert.Len(t, next, 1) {
+		assert.Equal(t, []float64{1, 0, 1}, next[0].gsharp[:3])
+	}
+
+	// the cover cuts can be plugged in as lazy constraints
+	prob.lazyConstraints = SeparateCoverCuts
+	result, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.InDelta(t, -7, result.BestIntegerSolution.z, 1e-9)
+}
+
+func Test_knapsackCover(t *testing.T) {
+	binary := []bool{true, true, true, true}
+
+	// the greedy cover {x1, x2, x3} is reduced to the minimal cover {x2, x3}
+	cover := knapsackCover([]float64{1, 4, 5, 2}, 8, []float64{1, 1, 0.5, 0}, binary)
+	assert.Equal(t, []int{1, 2}, cover)
+
+	// rows with negative coefficients are not knapsack constraints
+	assert.Nil(t, knapsackCover([]float64{1, -4, 5, 2}, 8, []float64{1, 1, 0.5, 0}, binary))
+
+	// the binary variables do not admit a cover
+	assert.Nil(t, knapsackCover([]float64{1, 4, 5, 2}, 12, []float64{1, 1, 0.5, 0}, binary))
+	assert.Nil(t, knapsackCover([]float64{1, 4, 5, 2}, 8, []float64{1, 1, 0.5, 0}, []bool{false, false, true, true}))
+}
@@ -0,0 +1,191 @@
+package ilp
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// CutPool manages the Gomory fractional cuts generated during the branch-and-bound procedure.
+// It remembers all cuts generated so far, so that the same cut is never added twice, and limits the number of rounds of cuts generated at each subProblem.
+// Cuts derived from a subProblem without branching decisions, such as the root problem, are globally valid and can be applied at every subProblem.
+// Other cuts are only valid in the subtree of the subProblem they were derived from.
+//
+// Its Generate method is a LazyConstraintCallback, e.g.
+//
+//	pool := NewCutPool(3)
+//	prob.lazyConstraints = pool.Generate
+//
+// A CutPool is safe for concurrent use.
+type CutPool struct {
+	mu sync.Mutex
+
+	// the maximum number of rounds of cuts generated at each subProblem. Zero means unlimited.
+	GomoryRoundsPerNode int
+
+	// the number of rounds of cuts generated so far, keyed by subProblem id
+	rounds map[int64]int
+
+	// the keys of all cuts generated so far (see cutKey)
+	seen map[string]bool
+
+	// the globally valid cuts, and the locally valid cuts keyed by the id of the subProblem they were derived from
+	global []bnbConstraint
+	local  map[int64][]bnbConstraint
+
+	// informed of the cuts generated at each subProblem, if set (see CutPoolMiddleware)
+	observer CutObserver
+}
+
+// CutObserver is implemented by instrumentation that should be informed of the cuts generated by a CutPool.
+type CutObserver interface {
+	// receives the cuts that were generated at the subProblem, and whether they are globally valid.
+	NewCuts(nodeID int64, cuts []bnbConstraint, global bool)
+}
+
+// NewCutPool creates an empty CutPool that generates at most the provided number of rounds of cuts at each subProblem. Zero means unlimited.
+func NewCutPool(roundsPerNode int) *CutPool {
+	return &CutPool{
+		GomoryRoundsPerNode: roundsPerNode,
+		rounds:              make(map[int64]int),
+		seen:                make(map[string]bool),
+		local:               make(map[int64][]bnbConstraint),
+	}
+}
+
+// Generate derives Gomory fractional cuts from the LP basis of the solution, and returns those that were not generated before.
+// Returns no cuts once the round limit of the subProblem of the solution has been reached.
+func (c *CutPool) Generate(sol solution) []bnbConstraint {
+	id := sol.problem.id
+
+	c.mu.Lock()
+	if c.GomoryRoundsPerNode > 0 && c.rounds[id] >= c.GomoryRoundsPerNode {
+		c.mu.Unlock()
+		return nil
+	}
+	c.rounds[id]++
+	c.mu.Unlock()
+
+	// generating the cuts does not involve the pool, so it is done without holding the lock
+	generated := generateGomoryCuts(sol)
+	global := globallyValid(*sol.problem)
+
+	c.mu.Lock()
+	var fresh []bnbConstraint
+	for _, cut := range generated {
+		key := cutKey(cut)
+		if c.seen[key] {
+			continue
+		}
+		c.seen[key] = true
+		fresh = append(fresh, cut)
+	}
+	if global {
+		c.global = append(c.global, fresh...)
+	} else if len(fresh) > 0 {
+		c.local[id] = append(c.local[id], fresh...)
+	}
+	observer := c.observer
+	c.mu.Unlock()
+
+	if observer != nil && len(fresh) > 0 {
+		observer.NewCuts(id, fresh, global)
+	}
+	return fresh
+}
+
+// GlobalCuts returns the globally valid cuts generated so far, which can be applied at every subProblem.
+func (c *CutPool) GlobalCuts() []bnbConstraint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]bnbConstraint(nil), c.global...)
+}
+
+// LocalCuts returns the cuts generated at the subProblem with the provided id that are only valid in its subtree.
+func (c *CutPool) LocalCuts(nodeID int64) []bnbConstraint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]bnbConstraint(nil), c.local[nodeID]...)
+}
+
+// Rounds returns the number of rounds of cuts generated at the subProblem with the provided id.
+func (c *CutPool) Rounds(nodeID int64) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rounds[nodeID]
+}
+
+// whether cuts derived from the subProblem are valid for the entire problem, i.e. none of its constraints are branching decisions.
+// Cuts and lazy constraints added to the subProblem are valid for the entire problem themselves.
+func globallyValid(p subProblem) bool {
+	for _, constr := range p.bnbConstraints {
+		if constr.branchedVariable != noBranchedVariable {
+			return false
+		}
+	}
+	return true
+}
+
+// a key identifying the cut, which ignores differences smaller than the precision of the cut generation.
+func cutKey(cut bnbConstraint) string {
+	var b strings.Builder
+	for _, coef := range cut.gsharp {
+		fmt.Fprintf(&b, "%.9g,", coef)
+	}
+	fmt.Fprintf(&b, "<=%.9g", cut.hsharp)
+	return b.String()
+}
+
+// CutPoolMiddleware is a BnbMiddleware that relays the cuts generated by a CutPool to the middleware it wraps, if that implements CutObserver.
+// It also counts the cuts generated.
+type CutPoolMiddleware struct {
+	BnbMiddleware
+
+	mu          sync.Mutex
+	globalCuts  int64
+	localCuts   int64
+	cutsPerNode map[int64]int64
+}
+
+// NewCutPoolMiddleware wraps the middleware, and registers the created middleware as the observer of the cuts of the pool.
+func NewCutPoolMiddleware(pool *CutPool, wrapped BnbMiddleware) *CutPoolMiddleware {
+	m := &CutPoolMiddleware{
+		BnbMiddleware: wrapped,
+		cutsPerNode:   make(map[int64]int64),
+	}
+
+	pool.mu.Lock()
+	pool.observer = m
+	pool.mu.Unlock()
+
+	return m
+}
+
+func (m *CutPoolMiddleware) NewCuts(nodeID int64, cuts []bnbConstraint, global bool) {
+	m.mu.Lock()
+	if global {
+		m.globalCuts += int64(len(cuts))
+	} else {
+		m.localCuts += int64(len(cuts))
+	}
+	m.cutsPerNode[nodeID] += int64(len(cuts))
+	m.mu.Unlock()
+
+	if observer, ok := m.BnbMiddleware.(CutObserver); ok {
+		observer.NewCuts(nodeID, cuts, global)
+	}
+}
+
+// CutCounts returns the number of globally and locally valid cuts generated so far.
+func (m *CutPoolMiddleware) CutCounts() (global, local int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.globalCuts, m.localCuts
+}
+
+// CutsAt returns the number of cuts generated at the subProblem with the provided id.
+func (m *CutPoolMiddleware) CutsAt(nodeID int64) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cutsPerNode[nodeID]
+}
@@ -0,0 +1,83 @@
+package ilp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// records the cuts relayed by a CutPoolMiddleware
+type cutRecorder struct {
+	dummyMiddleware
+	cuts map[bool]int
+}
+
+func (r *cutRecorder) NewCuts(nodeID int64, cuts []bnbConstraint, global bool) {
+	r.cuts[global] += len(cuts)
+}
+
+func TestCutPool(t *testing.T) {
+	// minimize -5x1 - 4x2 s.t. 6x1 + 4x2 <= 24, x1 + 2x2 <= 6, with x1 and x2 integer-constrained.
+	prob := milpProblem{
+		c:                      []float64{-5, -4},
+		G:                      NewDenseConstraints(2, 2, []float64{6, 4, 1, 2}),
+		h:                      []float64{24, 6},
+		integralityConstraints: []bool{true, true},
+		branchingHeuristic:     BRANCH_MOST_INFEASIBLE,
+	}
+	root := prob.toInitialSubproblem().solve()
+	if !assert.NoError(t, root.err) {
+		return
+	}
+
+	// the same cuts are not generated twice
+	pool := NewCutPool(0)
+	cuts := pool.Generate(root)
+	assert.NotEmpty(t, cuts)
+	assert.Empty(t, pool.Generate(root))
+	assert.Equal(t, 2, pool.Rounds(root.problem.id))
+
+	// cuts derived at the root are globally valid
+	assert.Equal(t, cuts, pool.GlobalCuts())
+	assert.Empty(t, pool.LocalCuts(root.problem.id))
+
+	// no cuts are generated once the round limit of a subProblem is reached
+	limited := NewCutPool(1)
+	assert.Equal(t, cuts, limited.Generate(root))
+	assert.Nil(t, limited.Generate(root))
+	assert.Equal(t, 1, limited.Rounds(root.problem.id))
+
+	// cuts derived below a branching decision are only valid in its subtree
+	child, _ := root.branch(nil)
+	child.id = 1
+	childSolution := child.solve()
+	if !assert.NoError(t, childSolution.err) {
+		return
+	}
+	local := limited.Generate(childSolution)
+	assert.Equal(t, local, limited.LocalCuts(1))
+	assert.Equal(t, cuts, limited.GlobalCuts())
+
+	// the pool can be used as a lazy constraint callback, and relays its cuts through the middleware
+	want, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	pool = NewCutPool(3)
+	recorder := &cutRecorder{cuts: make(map[bool]int)}
+	middleware := NewCutPoolMiddleware(pool, recorder)
+	prob.lazyConstraints = pool.Generate
+	got, err := prob.solve(context.Background(), 1, middleware)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.InDelta(t, want.BestIntegerSolution.z, got.BestIntegerSolution.z, 1e-9)
+
+	global, localCount := middleware.CutCounts()
+	assert.True(t, global > 0)
+	assert.Equal(t, int(global), recorder.cuts[true])
+	assert.Equal(t, int(localCount), recorder.cuts[false])
+	assert.Equal(t, int64(len(pool.GlobalCuts())), middleware.CutsAt(0))
+}
@@ -0,0 +1,373 @@
+package ilp
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// values closer than this tolerance to an integer are considered integer during cut generation.
+const cutTolerance = 1e-6
+
+// the branchedVariable value of bnbConstraints that represent cutting planes rather than branching decisions.
+const noBranchedVariable = -1
+
+// A simplex tableau corresponding to a basic solution of a standard-form problem A * x = b, x >= 0.
+type tableau struct {
+	// column indices of the basic variables. Row i of the tableau corresponds to basic variable basis[i].
+	basis []int
+	basic map[int]bool
+
+	// B^-1 * A
+	rows *mat.Dense
+
+	// B^-1 * b, i.e. the values of the basic variables
+	rhs []float64
+}
+
+// Reconstruct the tableau of a basic solution x of the standard-form problem A * x = b, x >= 0.
+// Gonum's simplex implementation does not expose the optimal basis, so we infer it from the solution itself:
+// all variables with a nonzero value are basic, and the basis is completed with linearly independent columns if the solution is degenerate.
+func newTableau(A *mat.Dense, b []float64, x []float64) (*tableau, error) {
+	basis, err := findBasis(A, x)
+	if err != nil {
+		return nil, err
+	}
+
+	m, _ := A.Dims()
+	B := mat.NewDense(m, m, nil)
+	col := make([]float64, m)
+	for i, j := range basis {
+		mat.Col(col, j, A)
+		B.SetCol(i, col)
+	}
+
+	var rows mat.Dense
+	if err := rows.Solve(B, A); err != nil {
+		return nil, err
+	}
+
+	rhs := mat.NewVecDense(m, nil)
+	if err := rhs.SolveVec(B, mat.NewVecDense(m, b)); err != nil {
+		return nil, err
+	}
+
+	basic := make(map[int]bool)
+	for _, j := range basis {
+		basic[j] = true
+	}
+
+	return &tableau{
+		basis: basis,
+		basic: basic,
+		rows:  &rows,
+		rhs:   rhs.RawVector().Data,
+	}, nil
+}
+
+// find the column indices of a basis corresponding to the basic solution x of A * x = b.
+func findBasis(A *mat.Dense, x []float64) ([]int, error) {
+	m, n := A.Dims()
+	if len(x) != n {
+		return nil, errors.New("solution vector is not of same length as number of columns in A")
+	}
+
+	var basis []int
+	inBasis := make(map[int]bool)
+	columns := mat.NewDense(m, m, nil)
+	newCol := make([]float64, m)
+
+	// try to add the column to the basis, which succeeds only if it is linearly independent from the columns already in it.
+	tryAdd := func(j int) bool {
+		mat.Col(newCol, j, A)
+		columns.SetCol(len(basis), newCol)
+		if mat.Cond(columns.Slice(0, m, 0, len(basis)+1), 1) > 1e12 {
+			return false
+		}
+		basis = append(basis, j)
+		inBasis[j] = true
+		return true
+	}
+
+	// all variables with a nonzero value in a basic solution are basic
+	for j, v := range x {
+		if v > cutTolerance {
+			if len(basis) == m || !tryAdd(j) {
+				return nil, errors.New("solution is not a basic solution")
+			}
+		}
+	}
+
+	// Complete the basis of a degenerate solution. Walk in reverse order because slack variables are typically the last columns of A.
+	for j := n - 1; j >= 0 && len(basis) < m; j-- {
+		if !inBasis[j] {
+			tryAdd(j)
+		}
+	}
+
+	if len(basis) < m {
+		return nil, errors.New("could not find a basis: A is rank deficient")
+	}
+
+	return basis, nil
+}
+
+// fractional part of a number
+func fractional(v float64) float64 {
+	return v - math.Floor(v)
+}
+
+// Reconstruct the tableau of the standard form of the solved subProblem.
+// Also returns the standard-form constraint matrix and right-hand side the tableau was derived from.
+func (s solution) tableau() (*tableau, *mat.Dense, []float64, error) {
+	prob := s.problem
+	n := len(prob.c)
+
+	c, A, b := prob.standardForm()
+
+	// reconstruct the values of the slack variables belonging to the branch-and-bound constraints
+	x := make([]float64, len(c))
+	copy(x, s.x)
+	for k, constr := range prob.bnbConstraints {
+		x[n+k] = constr.hsharp - floats.Dot(constr.gsharp, s.x)
+	}
+
+	tab, err := newTableau(A, b, x)
+	return tab, A, b, err
+}
+
+// Express a cut sum(alpha_j * x_j) >= 1 in the standard-form variable space as a 'smaller than or equal to' constraint on the variables of the subProblem.
+// The slack variables s_k of the branch-and-bound constraints are substituted by s_k = h_k - g_k * x.
+func (p *subProblem) projectCut(alpha []float64) bnbConstraint {
+	n := len(p.c)
+
+	cut := bnbConstraint{
+		branchedVariable: noBranchedVariable,
+		hsharp:           -1,
+		gsharp:           make([]float64, n),
+	}
+	for j := 0; j < n; j++ {
+		cut.gsharp[j] = -alpha[j]
+	}
+	for k, constr := range p.bnbConstraints {
+		a := alpha[n+k]
+		if a == 0 {
+			continue
+		}
+		floats.AddScaled(cut.gsharp, a, constr.gsharp)
+		cut.hsharp += a * constr.hsharp
+	}
+
+	return cut
+}
+
+// whether the cut cuts off the provided solution vector.
+func (c bnbConstraint) violatedBy(x []float64) bool {
+	return floats.Dot(c.gsharp, x) > c.hsharp+cutTolerance
+}
+
+// Generate Gomory mixed-integer cuts (GMIC) from the LP basis of a solution.
+// One cut is derived from each tableau row of which the basic variable is integer-constrained but has a fractional value.
+// Each cut is valid for all integer-feasible points of the solved subProblem, but cuts off the current LP solution.
+// Note that cuts derived at the root problem are globally valid, while cuts derived at other nodes are only valid in their subtree.
+// The cuts are returned as bnbConstraints in the variable space of the subProblem.
+func (s solution) gomoryMixedIntegerCuts() []bnbConstraint {
+	prob := s.problem
+	n := len(prob.c)
+
+	tab, _, _, err := s.tableau()
+	if err != nil {
+		// no cuts can be derived without a tableau
+		return nil
+	}
+
+	var cuts []bnbConstraint
+	for r, basicVar := range tab.basis {
+		if basicVar >= n || !prob.integralityConstraints[basicVar] {
+			continue
+		}
+
+		f0 := fractional(tab.rhs[r])
+		if f0 < cutTolerance || f0 > 1-cutTolerance {
+			continue
+		}
+
+		// coefficients alpha of the cut sum(alpha_j * x_j) >= 1 in the standard-form variable space
+		_, cols := tab.rows.Dims()
+		alpha := make([]float64, cols)
+		for j := range alpha {
+			a := tab.rows.At(r, j)
+			if tab.basic[j] || math.Abs(a) < cutTolerance {
+				continue
+			}
+
+			switch {
+			// the slack variables of the branch-and-bound constraints are treated as continuous, which is always valid.
+			case j < n && prob.integralityConstraints[j]:
+				fj := fractional(a)
+				if fj <= f0 {
+					alpha[j] = fj / f0
+				} else {
+					alpha[j] = (1 - fj) / (1 - f0)
+				}
+			case a >= 0:
+				alpha[j] = a / f0
+			default:
+				alpha[j] = -a / (1 - f0)
+			}
+		}
+
+		// only retain cuts that actually cut off the current solution
+		if cut := prob.projectCut(alpha); cut.violatedBy(s.x) {
+			cuts = append(cuts, cut)
+		}
+	}
+
+	return cuts
+}
+
+// Generate Gomory fractional cuts from the LP basis of a solution.
+// For a tableau row x_i + sum(a_j * x_j) = b with fractional b, the cut sum(frac(a_j) * x_j) >= frac(b) over the nonbasic variables x_j is valid if all of them are integer.
+// Hence, cuts are only derived from rows of which all nonbasic variables with a nonzero coefficient are integer.
+// Slack variables are considered integer if the constraint they belong to only contains integer variables with integer coefficients and has an integer right-hand side.
+// Like Gomory mixed-integer cuts, cuts derived at nodes other than the root are only valid in their subtree.
+func generateGomoryCuts(sol solution) []bnbConstraint {
+	prob := sol.problem
+
+	tab, A, b, err := sol.tableau()
+	if err != nil {
+		// no cuts can be derived without a tableau
+		return nil
+	}
+
+	integral := integralColumns(A, b, prob.integralityConstraints)
+
+	var cuts []bnbConstraint
+	for r, basicVar := range tab.basis {
+		if !integral[basicVar] {
+			continue
+		}
+
+		f0 := fractional(tab.rhs[r])
+		if f0 < cutTolerance || f0 > 1-cutTolerance {
+			continue
+		}
+
+		// coefficients alpha of the cut sum(alpha_j * x_j) >= 1 in the standard-form variable space
+		alpha := make([]float64, len(integral))
+		valid := true
+		for j := range alpha {
+			a := tab.rows.At(r, j)
+			if tab.basic[j] || math.Abs(a-math.Round(a)) < cutTolerance {
+				continue
+			}
+			if !integral[j] {
+				valid = false
+				break
+			}
+			alpha[j] = fractional(a) / f0
+		}
+
+		if !valid {
+			continue
+		}
+
+		// only retain cuts that actually cut off the current solution
+		if cut := prob.projectCut(alpha); cut.violatedBy(sol.x) {
+			cuts = append(cuts, cut)
+		}
+	}
+
+	return cuts
+}
+
+// Determine which columns of the standard-form problem A * x = b, x >= 0 take on integer values in any integer-feasible solution.
+// Besides the integer-constrained variables, this holds for slack variables of which the row only contains integer variables with integer coefficients and has an integer right-hand side.
+func integralColumns(A *mat.Dense, b []float64, integralityConstraints []bool) []bool {
+	m, n := A.Dims()
+
+	integral := make([]bool, n)
+	copy(integral, integralityConstraints)
+
+	// a row is integral if all of its variables other than the slack variable are integer with integer coefficients and the right-hand side is integer
+	integralRow := make([]bool, m)
+	for i := 0; i < m; i++ {
+		integralRow[i] = isAllInteger(b[i])
+	}
+
+	// the number of nonzero entries of each column and the row of the last nonzero entry
+	nonzeros := make([]int, n)
+	lastRow := make([]int, n)
+	for i := 0; i < m; i++ {
+		for j := 0; j < n; j++ {
+			a := A.At(i, j)
+			if a == 0 {
+				continue
+			}
+			nonzeros[j]++
+			lastRow[j] = i
+		}
+	}
+
+	// candidate slack variables appear in a single row with a coefficient of 1
+	slack := make([]bool, n)
+	slacksInRow := make([]int, m)
+	for j := 0; j < n; j++ {
+		slack[j] = !integral[j] && nonzeros[j] == 1 && A.At(lastRow[j], j) == 1
+		if slack[j] {
+			slacksInRow[lastRow[j]]++
+		}
+	}
+
+	for i := 0; i < m; i++ {
+		for j := 0; j < n && integralRow[i]; j++ {
+			a := A.At(i, j)
+			if a == 0 || slack[j] {
+				continue
+			}
+			integralRow[i] = integral[j] && isAllInteger(a)
+		}
+	}
+
+	for j := 0; j < n; j++ {
+		// a slack variable is only determined by the other variables in its row if it is the only one
+		if slack[j] && integralRow[lastRow[j]] && slacksInRow[lastRow[j]] == 1 {
+			integral[j] = true
+		}
+	}
+
+	return integral
+}
+
+// return a copy of the solution of which the subProblem carries the provided cuts as additional constraints.
+// Because the children of a subProblem inherit its constraints, the cuts are passed on to the entire subtree.
+func (s solution) withCuts(cuts []bnbConstraint) solution {
+	if len(cuts) == 0 {
+		return s
+	}
+
+	prob := *s.problem
+	prob.bnbConstraints = make([]bnbConstraint, 0, len(s.problem.bnbConstraints)+len(cuts))
+	prob.bnbConstraints = append(prob.bnbConstraints, s.problem.bnbConstraints...)
+	prob.bnbConstraints = append(prob.bnbConstraints, cuts...)
+
+	s.problem = &prob
+	return s
+}
+
+// Add the cuts to the subProblem of the solution and solve it again.
+// If the tightened subProblem cannot be solved (e.g. due to numerical issues), the original solution is returned.
+func (s solution) tightenWithCuts(cuts []bnbConstraint) solution {
+	if len(cuts) == 0 {
+		return s
+	}
+
+	tightened := s.withCuts(cuts).problem.solve()
+	if tightened.err != nil {
+		return s
+	}
+
+	return tightened
+}
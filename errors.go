@@ -0,0 +1,137 @@
+package ilp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize/convex/lp"
+)
+
+// InfeasibleError reports that a subProblem has no feasible solution.
+// It wraps INITIAL_RELAXATION_NOT_FEASIBLE if the LP relaxation of the root problem is infeasible,
+// and NO_INTEGER_FEASIBLE_SOLUTION if the search was exhausted without finding an integer-feasible solution.
+type InfeasibleError struct {
+	// the identifier of the subProblem that was found to be infeasible. The root problem has identifier 0.
+	NodeID int64
+	Reason string
+
+	// the underlying error
+	err error
+}
+
+func (e *InfeasibleError) Error() string {
+	return fmt.Sprintf("subProblem %v: %v", e.NodeID, e.Reason)
+}
+
+func (e *InfeasibleError) Unwrap() error {
+	return e.err
+}
+
+// UnboundedError reports that the LP relaxation of a subProblem is unbounded. It wraps lp.ErrUnbounded.
+type UnboundedError struct {
+	// the identifier of the subProblem of which the LP relaxation is unbounded. The root problem has identifier 0.
+	NodeID int64
+}
+
+func (e *UnboundedError) Error() string {
+	return fmt.Sprintf("subProblem %v: %v", e.NodeID, lp.ErrUnbounded)
+}
+
+func (e *UnboundedError) Unwrap() error {
+	return lp.ErrUnbounded
+}
+
+// SingularError reports that the simplex procedure failed on a singular constraint matrix. It wraps lp.ErrSingular.
+type SingularError struct {
+	// the identifier of the subProblem that could not be solved. The root problem has identifier 0.
+	NodeID int64
+
+	// the condition number of the standard-form constraint matrix of the subProblem
+	MatCondition float64
+}
+
+func (e *SingularError) Error() string {
+	return fmt.Sprintf("subProblem %v: %v (condition number %v)", e.NodeID, lp.ErrSingular, e.MatCondition)
+}
+
+func (e *SingularError) Unwrap() error {
+	return lp.ErrSingular
+}
+
+// takes a solver failure and determines whether it warrants a panic or whether it is expected.
+// Expected failures are translated to an error that identifies the subProblem of the solution.
+func translateSolverFailure(s solution) error {
+	switch s.err {
+	case lp.ErrInfeasible:
+		return &InfeasibleError{NodeID: s.problem.id, Reason: "LP relaxation is infeasible", err: lp.ErrInfeasible}
+	case lp.ErrSingular:
+		return &SingularError{NodeID: s.problem.id, MatCondition: s.problem.condition()}
+	}
+	panic(s.err)
+}
+
+// the bnbDecision corresponding to an expected solver failure.
+func failureDecision(err error) bnbDecision {
+	for failure, decision := range expectedFailures {
+		if errors.Is(err, failure) {
+			return decision
+		}
+	}
+	panic(err)
+}
+
+// translate the failure to solve the root problem into an error that identifies it.
+// Failures other than infeasibility, unboundedness and singularity are returned as-is.
+func translateRootFailure(s solution) error {
+	switch s.err {
+	case lp.ErrInfeasible:
+		return &InfeasibleError{NodeID: s.problem.id, Reason: INITIAL_RELAXATION_NOT_FEASIBLE.Error(), err: INITIAL_RELAXATION_NOT_FEASIBLE}
+	case lp.ErrUnbounded:
+		return &UnboundedError{NodeID: s.problem.id}
+	case lp.ErrSingular:
+		// the simplex method does not distinguish inconsistent equality constraints, e.g. x = 5 and x = 3, from redundant ones
+		if _, A, b := s.problem.standardForm(); A != nil && inconsistent(A, b) {
+			return &InfeasibleError{NodeID: s.problem.id, Reason: INITIAL_RELAXATION_NOT_FEASIBLE.Error(), err: INITIAL_RELAXATION_NOT_FEASIBLE}
+		}
+		return &SingularError{NodeID: s.problem.id, MatCondition: s.problem.condition()}
+	}
+	return s.err
+}
+
+// the condition number of the standard-form constraint matrix of the subProblem.
+func (p subProblem) condition() float64 {
+	_, A, _ := p.standardForm()
+	return mat.Cond(A, 2)
+}
+
+// whether the system of equations A * x = b has no solution, regardless of the signs of x, i.e. whether the rank of A is lower than the rank of [A b].
+func inconsistent(A *mat.Dense, b []float64) bool {
+	r, c := A.Dims()
+	augmented := mat.NewDense(r, c+1, nil)
+	augmented.Slice(0, r, 0, c).(*mat.Dense).Copy(A)
+	augmented.SetCol(c, b)
+	rankA, okA := rank(A)
+	rankAugmented, okAugmented := rank(augmented)
+	return okA && okAugmented && rankA < rankAugmented
+}
+
+// the numerical rank of the matrix, i.e. the number of singular values that are not negligible compared to the largest one.
+// Returns false if the singular value decomposition failed.
+func rank(m *mat.Dense) (int, bool) {
+	var svd mat.SVD
+	if !svd.Factorize(m, mat.SVDNone) {
+		return 0, false
+	}
+	values := svd.Values(nil)
+
+	r, c := m.Dims()
+	n := 0
+	for _, v := range values {
+		if v > values[0]*math.Max(float64(r), float64(c))*1e-12 {
+			n++
+		}
+	}
+	return n, true
+}
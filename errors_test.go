@@ -0,0 +1,82 @@
+package ilp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/optimize/convex/lp"
+)
+
+func Test_translateSolverFailure(t *testing.T) {
+	prob := &subProblem{
+		id: 3,
+		c:  []float64{1, 1},
+		A:  NewDenseConstraints(1, 2, []float64{1, 1}),
+		b:  []float64{1},
+	}
+
+	err := translateSolverFailure(solution{problem: prob, err: lp.ErrInfeasible})
+	var infeasible *InfeasibleError
+	if assert.True(t, errors.As(err, &infeasible)) {
+		assert.Equal(t, int64(3), infeasible.NodeID)
+	}
+	assert.True(t, errors.Is(err, lp.ErrInfeasible))
+	assert.Equal(t, expectedFailures[lp.ErrInfeasible], failureDecision(err))
+
+	err = translateSolverFailure(solution{problem: prob, err: lp.ErrSingular})
+	var singular *SingularError
+	if assert.True(t, errors.As(err, &singular)) {
+		assert.Equal(t, int64(3), singular.NodeID)
+		assert.True(t, singular.MatCondition >= 1)
+	}
+	assert.True(t, errors.Is(err, lp.ErrSingular))
+	assert.Equal(t, expectedFailures[lp.ErrSingular], failureDecision(err))
+
+	// unexpected failures warrant a panic
+	assert.Panics(t, func() { translateSolverFailure(solution{problem: prob, err: lp.ErrUnbounded}) })
+}
+
+func TestUnboundedError(t *testing.T) {
+	// maximize x s.t. x - y <= 1
+	unbounded := milpProblem{
+		c:                      []float64{-1, 0},
+		G:                      NewDenseConstraints(1, 2, []float64{1, -1}),
+		h:                      []float64{1},
+		integralityConstraints: []bool{false, false},
+	}
+
+	result, err := unbounded.solve(context.Background(), 1, dummyMiddleware{})
+	assert.Equal(t, STATUS_UNBOUNDED, result.Status)
+	assert.True(t, errors.Is(err, lp.ErrUnbounded))
+
+	var unboundedErr *UnboundedError
+	if assert.True(t, errors.As(err, &unboundedErr)) {
+		assert.Equal(t, int64(0), unboundedErr.NodeID)
+	}
+}
+
+func Test_translateRootFailure_InconsistentEqualities(t *testing.T) {
+	// x = 5 and x = 3 yield a singular constraint matrix, rather than an infeasible one
+	inconsistent := milpProblem{
+		c:                      []float64{1},
+		A:                      NewDenseConstraints(2, 1, []float64{1, 1}),
+		b:                      []float64{5, 3},
+		integralityConstraints: []bool{false},
+	}
+
+	result, err := inconsistent.solve(context.Background(), 1, dummyMiddleware{})
+	assert.True(t, errors.Is(err, INITIAL_RELAXATION_NOT_FEASIBLE))
+	assert.Equal(t, STATUS_INFEASIBLE, result.Status)
+
+	// redundant equality constraints remain a singularity
+	redundant := &subProblem{
+		c: []float64{1},
+		A: NewDenseConstraints(2, 1, []float64{1, 1}),
+		b: []float64{5, 5},
+	}
+	err = translateRootFailure(solution{problem: redundant, err: lp.ErrSingular})
+	var singular *SingularError
+	assert.True(t, errors.As(err, &singular))
+}
@@ -0,0 +1,153 @@
+package ilp
+
+import (
+	"fmt"
+	"sync"
+)
+
+// problemLock tracks whether a Problem may be modified, and guards the state that is shared between copies of the problem.
+// A problem cannot be modified once it has been frozen by Problem.Freeze, nor while it is being solved.
+type problemLock struct {
+	mu sync.Mutex
+
+	// signalled when the last modification in progress ends
+	modified *sync.Cond
+
+	// set by Problem.Freeze
+	frozen bool
+
+	// the number of solves of the problem in progress
+	solving int
+
+	// the number of modifications of the problem in progress, which may be nested, e.g. AddRangeConstraint adds constraints through AddConstraint
+	modifying int
+}
+
+// Freeze makes the problem immutable, after which it can safely be solved by multiple goroutines at once.
+// Methods that modify the problem, such as AddVariable, AddConstraint and Maximize, return an error wrapping ErrProblemFrozen
+// when called on a frozen problem, or panic if they do not return an error. Use Clone to obtain a copy of the problem that can be modified.
+// The same goes for the setters of the Variables of the problem, such as UpperBound and SetCoeff.
+func (p *Problem) Freeze() {
+	if p.lock == nil {
+		p.lock = &problemLock{}
+	}
+	p.lock.mu.Lock()
+	p.lock.frozen = true
+	p.lock.mu.Unlock()
+}
+
+// Frozen returns whether the problem cannot be modified, i.e. it was frozen by Freeze or is being solved.
+func (p *Problem) Frozen() bool {
+	return p.mutable() != nil
+}
+
+// Copy returns a deep copy of the problem. It is an alias for Clone.
+func (p *Problem) Copy() *Problem {
+	return p.Clone()
+}
+
+// an error wrapping ErrProblemFrozen if the problem cannot be modified, or nil otherwise.
+func (p *Problem) mutable() error {
+	if p == nil || p.lock == nil {
+		return nil
+	}
+
+	p.lock.mu.Lock()
+	defer p.lock.mu.Unlock()
+	return p.lock.mutable()
+}
+
+// the mutability check of Problem.mutable. The caller must hold the lock.
+func (l *problemLock) mutable() error {
+	if l.frozen {
+		return ErrProblemFrozen
+	}
+	if l.solving > 0 {
+		return fmt.Errorf("the problem is being solved: %w", ErrProblemFrozen)
+	}
+	return nil
+}
+
+// begin a modification of the problem, returning the function that ends it, or an error wrapping ErrProblemFrozen if the problem cannot be modified.
+// Checking whether the problem is mutable and registering the modification is a single step under the lock,
+// and a solve does not begin until all modifications in progress have ended (see beginSolve), so that a solve never observes a partial modification.
+func (p *Problem) modify() (done func(), err error) {
+	if p == nil {
+		return func() {}, nil
+	}
+	return p.lock.modify()
+}
+
+// begin a modification of the problem like modify, but panic if the problem cannot be modified, for methods that do not return an error.
+// Use as defer p.mustModify()().
+func (p *Problem) mustModify() (done func()) {
+	if p == nil {
+		return func() {}
+	}
+	return p.lock.mustModify()
+}
+
+func (l *problemLock) modify() (done func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.mutable(); err != nil {
+		return nil, err
+	}
+	l.modifying++
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.modifying--
+		if l.modifying == 0 && l.modified != nil {
+			l.modified.Broadcast()
+		}
+	}, nil
+}
+
+func (l *problemLock) mustModify() (done func()) {
+	done, err := l.modify()
+	if err != nil {
+		panic(err)
+	}
+	return done
+}
+
+// register a solve of the problem, during which it cannot be modified.
+// Waits for the modifications of the problem in progress to end.
+func (l *problemLock) beginSolve() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.modifying > 0 {
+		if l.modified == nil {
+			l.modified = sync.NewCond(&l.mu)
+		}
+		l.modified.Wait()
+	}
+	l.solving++
+}
+
+func (l *problemLock) endSolve() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.solving--
+	l.mu.Unlock()
+}
+
+// run f while holding the lock, such that concurrent solves do not race on the state shared between copies of the problem.
+func (l *problemLock) do(f func()) {
+	if l != nil {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+	}
+	f()
+}
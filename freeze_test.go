@@ -0,0 +1,132 @@
+package ilp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblem_Freeze(t *testing.T) {
+	prob, _, _, _ := getTestKnapsackProblem()
+	assert.False(t, prob.Frozen())
+
+	prob.Freeze()
+	assert.True(t, prob.Frozen())
+	assert.Panics(t, func() { prob.AddVariable("w") })
+	assert.Panics(t, func() { prob.AddConstraint("") })
+	assert.True(t, errors.Is(prob.Minimize(), ErrProblemFrozen))
+	assert.True(t, errors.Is(prob.RemoveConstraint(""), ErrProblemFrozen))
+	assert.Equal(t, 3, prob.NumVariables())
+
+	// a copy of a frozen problem can be modified
+	copied := prob.Copy()
+	assert.False(t, copied.Frozen())
+	assert.NotPanics(t, func() { copied.AddVariable("w") })
+	assert.Equal(t, 3, prob.NumVariables())
+}
+
+func TestProblem_Freeze_VariableSetters(t *testing.T) {
+	prob, _, _, _ := getTestKnapsackProblem()
+	prob.Freeze()
+
+	x := prob.variables[0]
+	assert.Panics(t, func() { x.SetCoeff(1) })
+	assert.Panics(t, func() { x.IsInteger() })
+	assert.Panics(t, func() { x.SetBranchDirection(BRANCH_UP_FIRST) })
+	assert.Panics(t, func() { x.SetBranchingPriority(1) })
+	assert.Panics(t, func() { x.UpperBound(1) })
+	assert.Panics(t, func() { x.LowerBound(1) })
+	assert.Equal(t, 4.0, x.ObjectiveCoeff())
+
+	// the variables of a copy of a frozen problem can be modified, without affecting the original
+	copied := prob.Clone()
+	assert.NotPanics(t, func() { copied.variables[0].SetCoeff(1) })
+	assert.Equal(t, 1.0, copied.variables[0].ObjectiveCoeff())
+	assert.Equal(t, 4.0, x.ObjectiveCoeff())
+}
+
+func Test_problemLock_modify(t *testing.T) {
+	l := &problemLock{}
+	done, err := l.modify()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// a solve does not begin while the problem is being modified
+	began := make(chan struct{})
+	go func() {
+		l.beginSolve()
+		close(began)
+	}()
+	select {
+	case <-began:
+		t.Fatal("the solve began during a modification")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	done()
+	<-began
+
+	// the problem cannot be modified once a solve has begun
+	_, err = l.modify()
+	assert.True(t, errors.Is(err, ErrProblemFrozen))
+
+	l.endSolve()
+	done, err = l.modify()
+	assert.NoError(t, err)
+	done()
+}
+
+// records whether the problem could be modified while it was being solved.
+type frozenObserver struct {
+	dummyMiddleware
+	prob   *Problem
+	frozen bool
+}
+
+func (m *frozenObserver) NewSubProblem(subProblem) {
+	m.frozen = m.prob.Frozen()
+}
+
+func TestProblem_FrozenDuringSolve(t *testing.T) {
+	prob, _, _, _ := getTestKnapsackProblem()
+	m := &frozenObserver{prob: &prob}
+	prob.SetInstrumentation(m)
+
+	_, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, m.frozen)
+	assert.False(t, prob.Frozen())
+}
+
+func TestProblem_ConcurrentSolveFrozen(t *testing.T) {
+	prob, _, _, _ := getTestKnapsackProblem()
+	prob.Freeze()
+
+	var wg sync.WaitGroup
+	solutions := make([]*Solution, 4)
+	errs := make([]error, len(solutions))
+	for i := range solutions {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			solutions[i], errs[i] = prob.Solve(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	for i, soln := range solutions {
+		if !assert.NoError(t, errs[i]) {
+			continue
+		}
+		assert.Equal(t, STATUS_OPTIMAL, soln.Status)
+		assert.InDelta(t, 24, soln.Objective, 1e-9)
+	}
+	assert.True(t, prob.Frozen())
+}
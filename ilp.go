@@ -3,8 +3,11 @@ package ilp
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"time"
 
-	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/optimize/convex/lp"
 )
 
@@ -13,22 +16,219 @@ type milpProblem struct {
 	// s.t      G * x <= h
 	//          A * x = b
 	c []float64
-	A *mat.Dense
+	A ConstraintMatrix
 	b []float64
-	G *mat.Dense
+	G ConstraintMatrix
 	h []float64
 
 	// which variables to apply the integrality constraint to. Should have same order as c.
 	integralityConstraints []bool
 
+	// values within this distance of an integer satisfy the integrality constraints. Zero requires exact integers.
+	integralityTol float64
+
 	// which branching heuristic to use. Determines which integer variable is branched on at each split.
 	// defaults to 0 == maxFun
 	branchingHeuristic BranchHeuristic
+
+	// custom branching strategy, which takes precedence over the branching heuristic if not nil.
+	branchingStrategy BranchingStrategy
+
+	// the direction to explore first when branching on each variable. Should have same order as c, or be nil to always round down first.
+	branchDirections []BranchDirection
+
+	// which variables are binary, i.e. integer-constrained and bounded by [0, 1]. Should have same order as c, or be nil if there are none.
+	binaryVariables []bool
+
+	// the branching priority of each variable (see Variable.SetBranchingPriority). Should have same order as c, or be nil if none are set.
+	branchingPriorities []int
+
+	// the SOS1 constraints, referring to the variables by their position in c. Nil if there are none.
+	sos1Constraints []SOSConstraint
+
+	// creates the queue that determines the order in which the subProblems are explored. Defaults to FIFO order if nil.
+	nodeSelection NodeQueueFactory
+
+	// offsets of the variables that were shifted to be nonnegative, i.e. the original variables are x + offsets.
+	// Has an entry for each variable except the negative parts of the free variables, or is nil if no variables were shifted.
+	offsets []float64
+
+	// indices of the free variables, which are split into a positive and a negative part: x = x+ - x-.
+	// The columns of the negative parts are appended to the original variables in the same order.
+	freeVariables []int
+
+	// constant term of the objective function, which is added to the objective value of the solutions
+	objectiveOffset float64
+
+	// configuration of the branch-and-bound procedure
+	config SolverConfig
+
+	// generates the constraints that are only added once they are violated, if set
+	lazyConstraints LazyConstraintCallback
+
+	// an upper bound on the optimal objective value if set, e.g. the objective value of a known integer-feasible solution.
+	// subProblems of which the objective value is not lower than the cutoff are pruned.
+	cutoff    float64
+	hasCutoff bool
+
+	// an integer-feasible solution expressed in terms of the columns of c, e.g. found by a heuristic, with which the search starts as incumbent.
+	// It is ignored if it is not feasible. Nil if there is none.
+	initialSolution []float64
+
+	// the state of an interrupted search to resume (see TreeState). Nil if the search starts anew.
+	resumeState []byte
+
+	// called with the state of the search if it is stopped by the context, so that it can be resumed later. Ignored if nil.
+	checkpoint func(state []byte) error
+}
+
+// SolverConfig contains optional settings of the branch-and-bound procedure.
+// The zero value disables all optional features, except probing (see ProbingLimit).
+type SolverConfig struct {
+	// Derive Gomory mixed-integer cuts from the LP basis of each subProblem that is branched on.
+	// Cuts derived at the root are added to all subProblems, cuts derived at other nodes are added to their subtree.
+	EnableGomoryMixedIntegerCuts bool
+
+	// The number of rounds of Gomory fractional cuts derived at each subProblem before it is branched on.
+	// Each round adds the cuts derived from the LP basis of the previous round and solves the subProblem again.
+	// Zero disables Gomory fractional cuts.
+	GomoryRounds int
+
+	// The maximum number of fractional variables evaluated at each node when using BRANCH_STRONG.
+	// Zero means all fractional variables are evaluated.
+	MaxStrongBranchingCandidates int
+
+	// Create the two children of each branched subProblem concurrently, and solve the two child relaxations of each candidate variable
+	// of strong branching concurrently. This speeds up branching when evaluating the children is expensive, e.g. with BRANCH_STRONG.
+	ParallelBranching bool
+
+	// Stop the search as soon as the relative gap (incumbent - bound) / |incumbent| between the objective value of the incumbent
+	// and the best bound on the optimal objective value drops to this tolerance, e.g. 0.01 for 1%. Zero disables this criterion.
+	RelativeGapTolerance float64
+
+	// Stop the search as soon as the absolute gap (incumbent - bound) drops to this tolerance. Zero disables this criterion.
+	AbsoluteGapTolerance float64
+
+	// Stop the search after checking this many subProblems, returning the incumbent along with ErrNodeLimitExceeded.
+	// Zero means no limit.
+	NodeLimit int64
+
+	// Do not branch on subProblems at this depth in the enumeration tree (see subProblem.Depth), so that no subProblem is deeper than this.
+	// If any subProblem is pruned for this reason, the incumbent is returned along with ErrMaxDepthExceeded, as it may not be optimal.
+	// Zero means no limit.
+	MaxDepth int64
+
+	// Run the feasibility pump for up to this many iterations before the branch-and-bound procedure, and start the search with the solution it finds as incumbent.
+	// Skipped if an initial solution is provided. Zero disables the feasibility pump.
+	FeasibilityPumpIterations int
+
+	// Round the solution to the initial relaxation before branching (see RoundingHeuristic), and start the search with the result as incumbent if it is integer-feasible.
+	RoundingHeuristic bool
+
+	// Once an incumbent is known, fix the integer-constrained variables of each subProblem that is branched on at zero if their reduced cost exceeds the gap
+	// to the incumbent (see reducedCostFixing). The fixings are added to the subtree of the subProblem, so they count towards the depth of its descendants.
+	ReducedCostFixing bool
+
+	// Probe up to this many binary variables in each pass of the presolve procedure, fixing those for which one of the two values makes the LP relaxation infeasible
+	// (see preProcessor.probeBinaryVariables). Each probe solves two LP relaxations.
+	// Zero means DefaultProbingLimit binary variables are probed. A negative value disables probing.
+	ProbingLimit int
+}
+
+// DefaultProbingLimit is the number of binary variables probed in each pass of the presolve procedure if SolverConfig.ProbingLimit is zero.
+const DefaultProbingLimit = 50
+
+// SolveStatus describes the outcome of solving a problem, i.e. how much the returned solution can be trusted.
+type SolveStatus int
+
+const (
+	// the procedure stopped before an integer-feasible solution was found or proven not to exist, e.g. due to a timeout
+	STATUS_UNKNOWN SolveStatus = iota
+
+	// the solution is proven to be optimal
+	STATUS_OPTIMAL
+
+	// the solution is integer-feasible, but the procedure was stopped (e.g. by a timeout) before it was proven to be optimal
+	STATUS_FEASIBLE_NOT_OPTIMAL
+
+	// the problem is proven to have no integer-feasible solution
+	STATUS_INFEASIBLE
+
+	// the objective value of the LP relaxation of the problem is unbounded
+	STATUS_UNBOUNDED
+
+	// the node limit was reached. The solution, if any, is integer-feasible but not proven to be optimal
+	STATUS_NODE_LIMIT_REACHED
+
+	// the search was stopped because the gap between the solution and the best bound was within the configured tolerances
+	STATUS_GAP_TOLERANCE
+
+	// the solution is an optimal solution of the LP relaxation of the problem, which need not be integer-feasible
+	STATUS_LP_OPTIMAL
+
+	// the deadline of the context passed before the solution, if any, was proven to be optimal (see Problem.SolveWithBestEffort)
+	STATUS_TIME_LIMIT_REACHED
+)
+
+func (s SolveStatus) String() string {
+	switch s {
+	case STATUS_OPTIMAL:
+		return "OPTIMAL"
+	case STATUS_FEASIBLE_NOT_OPTIMAL:
+		return "FEASIBLE_NOT_OPTIMAL"
+	case STATUS_INFEASIBLE:
+		return "INFEASIBLE"
+	case STATUS_UNBOUNDED:
+		return "UNBOUNDED"
+	case STATUS_NODE_LIMIT_REACHED:
+		return "NODE_LIMIT_REACHED"
+	case STATUS_GAP_TOLERANCE:
+		return "GAP_TOLERANCE"
+	case STATUS_LP_OPTIMAL:
+		return "LP_OPTIMAL"
+	case STATUS_TIME_LIMIT_REACHED:
+		return "TIME_LIMIT_REACHED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// SolveStats describes the effort spent on solving a problem.
+type SolveStats struct {
+	// the reductions made by the presolve procedure, if it was applied
+	PresolveStats
+
+	// the number of subProblems created, including the initial relaxation
+	NodesCreated int64
+
+	// the number of subProblems of which the solution was checked
+	NodesExplored int64
+
+	// the number of LP relaxations solved, including those solved to evaluate branching candidates and to tighten subProblems with cutting planes
+	LPRelaxationsSolved int64
+
+	// the total duration of the solve, including the presolve procedure
+	WallTime time.Duration
+
+	// the objective value of the LP relaxation of the (presolved) problem
+	RootLPBound float64
+
+	// the best known lower bound on the optimal objective value, and the relative gap between the incumbent and this bound (+Inf without incumbent).
+	// While the search is running (see enumerationTree.LiveStats), these refer to the minimization problem solved by the branch-and-bound procedure,
+	// excluding the constant term of the objective function. They are not encoded as JSON, as they may be infinite.
+	BestBound float64 `json:"-"`
+	Gap       float64 `json:"-"`
 }
 
 var (
 	INITIAL_RELAXATION_NOT_FEASIBLE = errors.New("initial relaxation is not feasible")
 	NO_INTEGER_FEASIBLE_SOLUTION    = errors.New("no integer feasible solution found")
+	ErrNodeLimitExceeded            = errors.New("node limit exceeded")
+	ErrMaxDepthExceeded             = errors.New("maximum depth exceeded")
+	ErrInvalidProblem               = errors.New("invalid problem")
+	ErrProblemSolved                = errors.New("the problem has been solved")
+	ErrProblemFrozen                = errors.New("the problem is frozen")
+	ErrDuplicateVariableName        = errors.New("duplicate variable name")
 )
 
 var (
@@ -64,15 +264,53 @@ func (p milpProblem) toInitialSubproblem() subProblem {
 		A: Anew,
 		b: bNew,
 		integralityConstraints: intNew,
+		branchHeuristic:        p.branchingHeuristic,
+		maxCandidates:          p.config.MaxStrongBranchingCandidates,
+		parallelBranching:      p.config.ParallelBranching,
+		integralityTol:         p.integralityTol,
+		branchingStrategy:      p.branchingStrategy,
+		branchDirections:       p.branchDirections,
+		binaryVariables:        p.binaryVariables,
+		branchingPriorities:    p.branchingPriorities,
+		sos1Constraints:        p.sos1Constraints,
 
 		// for the initial subproblem, there are no branch-and-bound-specific inequality constraints.
 		bnbConstraints: []bnbConstraint{},
 	}
 }
 
+// MIPResult is the outcome of the branch-and-bound procedure, expressed in terms of the original variables of the milpProblem.
+type MIPResult struct {
+	// the solution to the LP relaxation of the problem, which is available even if no integer-feasible solution was found.
+	// Its error is set if the LP relaxation could not be solved, e.g. because it is infeasible or unbounded.
+	LPRelaxation solution
+
+	// the best integer-feasible solution found, or nil if none was found.
+	BestIntegerSolution *solution
+
+	// statistics of the branch-and-bound procedure
+	Stats SolveStats
+
+	// whether the best integer-feasible solution is optimal, merely feasible, or absent and why
+	Status SolveStatus
+
+	// describes the problems with the input that did not prevent solving, e.g. an infeasible initial solution that was ignored
+	Warnings []string
+}
+
+// the best integer-feasible solution, or an empty solution if none was found.
+func (r MIPResult) best() solution {
+	if r.BestIntegerSolution == nil {
+		return solution{}
+	}
+	return *r.BestIntegerSolution
+}
+
 // Argument workers specifies how many workers should be used for traversing the enumeration tree.
 // This is mainly important from a space complexity point of view, as each worker is a potentially concurrent simplex algorithm.
-func (p milpProblem) solve(ctx context.Context, workers int, instrumentation BnbMiddleware) (solution, error) {
+// An error is returned if the procedure did not prove the best integer-feasible solution to be optimal (or the gap to be within the tolerances),
+// along with a result describing the partial progress made.
+func (p milpProblem) solve(ctx context.Context, workers int, instrumentation BnbMiddleware) (result MIPResult, err error) {
 	if workers <= 0 {
 		panic("number of workers may not be lower than zero")
 	}
@@ -81,67 +319,255 @@ func (p milpProblem) solve(ctx context.Context, workers int, instrumentation Bnb
 		panic("integrality constraints vector is not same length as vector c")
 	}
 
+	if err := validateNumerics(&p); err != nil {
+		return MIPResult{Status: STATUS_UNKNOWN}, fmt.Errorf("%w: %v", ErrInvalidProblem, err)
+	}
+
 	initialRelaxation := p.toInitialSubproblem()
 
 	// Start the branch and bound procedure for this problem
-	enumTree := newEnumerationTree(initialRelaxation, instrumentation)
+	enumTree := newEnumerationTree(initialRelaxation, instrumentation, p.config, p.nodeSelection)
+	if p.hasCutoff {
+		enumTree.cutoff = p.cutoff
+	}
+	enumTree.lazyConstraints = p.lazyConstraints
+
+	// a resumed search restores its incumbent, so the heuristics are skipped
+	if p.resumeState != nil {
+		p.initialSolution = nil
+		if err := enumTree.LoadState(p.resumeState); err != nil {
+			return MIPResult{Status: STATUS_UNKNOWN}, fmt.Errorf("restoring the state of the search: %w", err)
+		}
+	}
+
+	// if no initial solution is provided, try to find one without branching
+	if p.resumeState == nil && p.initialSolution == nil && p.config.FeasibilityPumpIterations > 0 {
+		if pumped, err := FeasibilityPump(p, p.config.FeasibilityPumpIterations); err == nil {
+			p.initialSolution = pumped.x
+		}
+	}
+
+	// start with the initial solution as incumbent, which prunes the subProblems that cannot improve on it
+	var warnings []string
+	if p.resumeState == nil {
+		initial, warning := p.initialIncumbent()
+		if initial != nil {
+			enumTree.setIncumbent(*initial)
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
 
 	// start the branch and bound procedure, presenting the solution to the initial relaxation as a candidate
-	incumbent := enumTree.startSearch(ctx, workers)
+	start := time.Now()
+	var incumbent *solution
+	if p.resumeState != nil {
+		incumbent, err = enumTree.resumeSearch(ctx, workers)
+	} else {
+		incumbent, err = enumTree.startSearch(ctx, workers)
+	}
 
-	// if the solver timed out, we return that as an error, along with the best-effort incumbent solution.
-	if timedOut := ctx.Err(); timedOut != nil {
-		var val solution
+	// save the state of a search stopped by the context, so that it can be resumed
+	if p.checkpoint != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		state, saveErr := enumTree.SaveState()
+		if saveErr == nil {
+			saveErr = p.checkpoint(state)
+		}
+		if saveErr != nil {
+			err = fmt.Errorf("%w (saving the state of the search failed: %v)", err, saveErr)
+		}
+	}
+
+	defer func() {
+		logResult(result, err)
+	}()
+
+	result = MIPResult{
+		LPRelaxation: p.postprocess(enumTree.rootSolution),
+		Stats:        enumTree.statistics(),
+		Warnings:     warnings,
+	}
+	result.Stats.WallTime = time.Since(start)
+	result.Stats.RootLPBound += p.objectiveConstant()
+	result.Stats.BestBound += p.objectiveConstant()
+	if incumbent != nil && incumbent.err == nil {
+		result.Stats.Gap = relativeGap(incumbent.z+p.objectiveConstant(), result.Stats.BestBound)
+	}
+
+	// if the solver timed out or reached the node limit, we return that as an error, along with the best-effort incumbent solution.
+	if err != nil {
+		result.Status = STATUS_UNKNOWN
 		if incumbent != nil {
-			val = *incumbent
+			best := p.postprocess(p.snapToIntegers(*incumbent))
+			result.BestIntegerSolution = &best
+			result.Status = STATUS_FEASIBLE_NOT_OPTIMAL
+		}
+		if errors.Is(err, ErrNodeLimitExceeded) {
+			result.Status = STATUS_NODE_LIMIT_REACHED
 		}
-		return val, timedOut
+		return result, err
 	}
 
-	// Check if a nil solution has been returned
+	// The search space was exhausted without finding an integer-feasible solution, even though the LP relaxation is feasible.
 	if incumbent == nil {
-		return solution{}, NO_INTEGER_FEASIBLE_SOLUTION
+		result.Status = STATUS_INFEASIBLE
+		return result, &InfeasibleError{NodeID: 0, Reason: NO_INTEGER_FEASIBLE_SOLUTION.Error(), err: NO_INTEGER_FEASIBLE_SOLUTION}
 	}
 
+	// the LP relaxation itself could not be solved
 	if incumbent.err != nil {
-		return solution{}, incumbent.err
+		result.Status = STATUS_UNKNOWN
+		switch {
+		case errors.Is(incumbent.err, INITIAL_RELAXATION_NOT_FEASIBLE):
+			result.Status = STATUS_INFEASIBLE
+		case errors.Is(incumbent.err, lp.ErrUnbounded):
+			result.Status = STATUS_UNBOUNDED
+		}
+		return result, incumbent.err
 	}
 
-	// remove the slack variables that were introduced by the conversion to standard form from the solution vector
-	postprocessed := *incumbent
-	postprocessed.x = postprocessed.x[:len(p.c)]
+	best := p.postprocess(p.snapToIntegers(*incumbent))
+	result.BestIntegerSolution = &best
 
-	return postprocessed, nil
+	// the search may have been stopped early because the gap was within the tolerances
+	result.Status = STATUS_OPTIMAL
+	if best.bestBound < best.z {
+		result.Status = STATUS_GAP_TOLERANCE
+	}
+	return result, nil
+
+}
+
+// check every element of c, A, b, G and h for NaN and Inf values, which the LP solver cannot handle.
+// Returns an error describing the position of the first one found, or nil if all elements are finite.
+func validateNumerics(p *milpProblem) error {
+	if err := checkFiniteVector(p.c, "objective coefficient"); err != nil {
+		return err
+	}
+	if kind := nonFinite(p.objectiveOffset); kind != "" {
+		return fmt.Errorf("%v in objective offset", kind)
+	}
+	if err := checkFiniteMatrix(p.A, "equality constraint"); err != nil {
+		return err
+	}
+	if err := checkFiniteVector(p.b, "equality right-hand side"); err != nil {
+		return err
+	}
+	if err := checkFiniteMatrix(p.G, "inequality constraint"); err != nil {
+		return err
+	}
+	return checkFiniteVector(p.h, "inequality right-hand side")
+}
 
+// describe a value as NaN or Inf, or return an empty string if it is finite.
+func nonFinite(val float64) string {
+	switch {
+	case math.IsNaN(val):
+		return "NaN"
+	case math.IsInf(val, 0):
+		return "Inf"
+	}
+	return ""
 }
 
-//TODO: COPIED FROM GONUM FOR DEBUGGING: REMOVEME
-// findLinearlyIndependnt finds a set of linearly independent columns of A, and
-// returns the column indexes of the linearly independent columns.
-func findLinearlyIndependent(A mat.Matrix) []int {
-	m, n := A.Dims()
-	idxs := make([]int, 0, m)
-	columns := mat.NewDense(m, m, nil)
-	newCol := make([]float64, m)
-	// Walk in reverse order because slack variables are typically the last columns
-	// of A.
-	for i := n - 1; i >= 0; i-- {
-		if len(idxs) == m {
-			break
+func checkFiniteVector(v []float64, name string) error {
+	for i, val := range v {
+		if kind := nonFinite(val); kind != "" {
+			return fmt.Errorf("%v in %v at index %v", kind, name, i)
 		}
-		mat.Col(newCol, i, A)
-		columns.SetCol(len(idxs), newCol)
-		if len(idxs) == 0 {
-			// A column is linearly independent from the null set.
-			// If all-zero column of A are allowed, this code needs to be adjusted.
-			idxs = append(idxs, i)
-			continue
+	}
+	return nil
+}
+
+func checkFiniteMatrix(m ConstraintMatrix, name string) error {
+	if m == nil {
+		return nil
+	}
+	rows, cols := m.Dims()
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			if kind := nonFinite(m.At(i, j)); kind != "" {
+				return fmt.Errorf("%v in %v row %v, column %v", kind, name, i, j)
+			}
 		}
-		if mat.Cond(columns.Slice(0, m, 0, len(idxs)+1), 1) > 1e12 {
-			// Not linearly independent.
-			continue
+	}
+	return nil
+}
+
+// round the values of the integer-constrained variables of an integer-feasible solution to the nearest integer,
+// which removes the rounding errors of the LP solver that are within the integrality tolerance.
+func (p milpProblem) snapToIntegers(s solution) solution {
+	if s.x == nil {
+		return s
+	}
+
+	x := make([]float64, len(s.x))
+	copy(x, s.x)
+	for i, integer := range p.integralityConstraints {
+		if integer {
+			x[i] = math.Round(x[i])
 		}
-		idxs = append(idxs, i)
 	}
-	return idxs
+	s.x = x
+	return s
+}
+
+// RelaxIntegrality returns a copy of the problem without integrality constraints, i.e. its LP relaxation.
+func (p milpProblem) RelaxIntegrality() *milpProblem {
+	relaxation := p
+	relaxation.integralityConstraints = make([]bool, len(p.integralityConstraints))
+	relaxation.binaryVariables = nil
+	return &relaxation
+}
+
+// SolveRelaxation solves the LP relaxation of the problem without branching, i.e. ignoring the integrality constraints.
+// The solution is expressed in terms of the original variables and holds the dual values of the standard-form constraints:
+// the equality constraints A, followed by the inequality constraints G (see toInitialSubproblem).
+func (p milpProblem) SolveRelaxation() (solution, error) {
+	relaxation := p.postprocess(p.toInitialSubproblem().solve())
+	return relaxation, relaxation.err
+}
+
+// Express a solution in terms of the original variables of the problem.
+// This removes the slack variables that were introduced by the conversion to standard form from the solution vector,
+// recombines the parts of the free variables and undoes the shift of the variables with a negative lower bound.
+func (p milpProblem) postprocess(s solution) solution {
+	if s.x == nil {
+		return s
+	}
+
+	n := len(p.c) - len(p.freeVariables)
+
+	x := make([]float64, n)
+	copy(x, s.x)
+	for k, i := range p.freeVariables {
+		x[i] -= s.x[n+k]
+	}
+
+	if p.offsets != nil {
+		floats.Add(x, p.offsets)
+	}
+
+	constant := p.objectiveConstant()
+	s.z += constant
+	s.bestBound += constant
+
+	s.x = x
+	return s
+}
+
+// EvalObjective evaluates the objective function that is minimized by the branch-and-bound procedure for a solution vector expressed in terms of the columns of c,
+// excluding the constant term of the objective function (see objectiveConstant). Any slack variables at the end of the solution vector are ignored.
+func (p milpProblem) EvalObjective(x []float64) float64 {
+	return floats.Dot(p.c, x[:len(p.c)])
+}
+
+// The constant term of the objective function: the objective offset plus the constant introduced by shifting the variables with a negative lower bound.
+func (p milpProblem) objectiveConstant() float64 {
+	if p.offsets == nil {
+		return p.objectiveOffset
+	}
+	n := len(p.c) - len(p.freeVariables)
+	return p.objectiveOffset + floats.Dot(p.c[:n], p.offsets)
 }
@@ -3,9 +3,12 @@ package ilp
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"math/rand"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -17,7 +20,7 @@ import (
 func TestMilpProblem_Solve_Smoke_NoInteger(t *testing.T) {
 	prob := milpProblem{
 		c: []float64{-1, -2, 0, 0},
-		A: mat.NewDense(2, 4, []float64{
+		A: NewDenseConstraints(2, 4, []float64{
 			-1, 2, 1, 0,
 			3, 1, 0, 1,
 		}),
@@ -28,7 +31,8 @@ func TestMilpProblem_Solve_Smoke_NoInteger(t *testing.T) {
 	// solve the problem with 1 worker and a one-second timeout
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	got, err := prob.solve(ctx, 1, dummyMiddleware{})
+	result, err := prob.solve(ctx, 1, dummyMiddleware{})
+	got := result.best()
 
 	assert.NoError(t, err)
 	assert.Equal(t, float64(-8), got.z)
@@ -40,11 +44,11 @@ func TestMilpProblem_Solve_InfiniteRecursion_Regression(t *testing.T) {
 
 	prob := milpProblem{
 		c: []float64{1.7356332566545616, -0.2058339272568599, -1.051665297603944},
-		A: mat.NewDense(1, 3, []float64{
+		A: NewDenseConstraints(1, 3, []float64{
 			-0.7762132098737671, 1.42027949678888, -0.3304567624749696,
 		}),
 		b: []float64{-0.24703471683023603},
-		G: mat.NewDense(1, 3, []float64{
+		G: NewDenseConstraints(1, 3, []float64{
 			-0.6775235462631393, -1.9616379110849085, 1.9859192819811322,
 		}),
 		h: []float64{-0.041138108068992485},
@@ -59,7 +63,8 @@ func TestMilpProblem_Solve_InfiniteRecursion_Regression(t *testing.T) {
 	// solve the problem with 2 workers and a one-second timeout
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	got, err := prob.solve(ctx, 2, tl)
+	result, err := prob.solve(ctx, 2, tl)
+	got := result.best()
 
 	// dump the logged tree to a DOT-file
 	dumpToDot(t, tl)
@@ -74,18 +79,56 @@ func TestMilpProblem_Solve_InfiniteRecursion_Regression(t *testing.T) {
 
 }
 
+// The same regression test case, solved through the Problem API with SolveWithBestEffort.
+func TestProblem_SolveWithBestEffort_InfiniteRecursion_Regression(t *testing.T) {
+
+	prob := NewProblem()
+	x1 := prob.AddVariable("x1").SetCoeff(1.7356332566545616).IsInteger()
+	x2 := prob.AddVariable("x2").SetCoeff(-0.2058339272568599).IsInteger()
+	x3 := prob.AddVariable("x3").SetCoeff(-1.051665297603944).IsInteger()
+	prob.AddConstraint("").
+		AddExpression(-0.7762132098737671, x1).
+		AddExpression(1.42027949678888, x2).
+		AddExpression(-0.3304567624749696, x3).
+		EqualTo(-0.24703471683023603)
+	prob.AddConstraint("").
+		AddExpression(-0.6775235462631393, x1).
+		AddExpression(-1.9616379110849085, x2).
+		AddExpression(1.9859192819811322, x3).
+		SmallerThanOrEqualTo(-0.041138108068992485)
+	prob.DisablePresolve()
+
+	// initiate the logger instrumentation
+	tl := NewTreeLogger()
+	prob.SetInstrumentation(tl)
+	prob.SetWorkers(2)
+
+	// solve the problem with 2 workers and a one-second timeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	soln, status, err := prob.SolveWithBestEffort(ctx)
+
+	// dump the logged tree to a DOT-file
+	dumpToDot(t, tl)
+
+	// the timeout is not an error, and no integer-feasible solution was found before it
+	assert.NoError(t, err)
+	assert.Equal(t, STATUS_TIME_LIMIT_REACHED, status)
+	assert.Nil(t, soln)
+}
+
 // a regression test for nil returns of the search procedure that caused a panic
 func TestMilpProblem_Solve_NilReturn_Regression(t *testing.T) {
 
 	prob := milpProblem{
 		c: []float64{0.6572445982216386, -1.2787102180406373, -0.714364219639056, 0.4294876505980715, -1.2694040908754067},
-		A: mat.NewDense(3, 5, []float64{
+		A: NewDenseConstraints(3, 5, []float64{
 			-1.150658083043829, 0.6742357592398329, 0.05482227950158375, -0.4402215293563758, -0.42514963905670267,
 			1.8805693836928625, 1.2321077204169477, -1.4072763551877006, 0.32105052839669324, 0.8175654516598202,
 			-1.2427589013990952, 0.8480328391203368, 1.8893229216030778, 1.6284926471665957, -0.6924382873998646,
 		}),
 		b: []float64{-1.6441336258376302, 1.7731638122722604, 0.41457840377809935},
-		G: mat.NewDense(3, 5, []float64{
+		G: NewDenseConstraints(3, 5, []float64{
 			0.5833490684770126, -0.7706968790319841, 0.6630978893449531, -0.560670828793711, -0.9502215220573013,
 			-0.25962903857408626, -0.613464243927484, 0.8559661237279594, -2.5511417937898293, 0.8262232497486882,
 			-1.136768995071479, -0.5756455306742008, -1.372457014240165, 0.21778519481503805, 2.7692491194887667,
@@ -102,13 +145,14 @@ func TestMilpProblem_Solve_NilReturn_Regression(t *testing.T) {
 	// solve the problem with 2 workers and a one-second timeout
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	got, err := prob.solve(ctx, 2, tl)
+	result, err := prob.solve(ctx, 2, tl)
+	got := result.best()
 
 	// dump the logged tree to a DOT-file
 	dumpToDot(t, tl)
 
 	assert.Error(t, err)
-	assert.Equal(t, err, NO_INTEGER_FEASIBLE_SOLUTION)
+	assert.True(t, errors.Is(err, NO_INTEGER_FEASIBLE_SOLUTION))
 
 	if !(reflect.DeepEqual(want.x, got.x) && want.z == got.z) {
 		t.Log(got)
@@ -120,16 +164,16 @@ func TestMilpProblem_Solve_NilReturn_Regression(t *testing.T) {
 func dumpToDot(t *testing.T, tree *TreeLogger) {
 	var buffer bytes.Buffer
 	tree.ToDOT(&buffer)
-	err := ioutil.WriteFile("__debugviz__.dot", buffer.Bytes(), 0644)
+	err := ioutil.WriteFile(filepath.Join(t.TempDir(), "__debugviz__.dot"), buffer.Bytes(), 0644)
 	assert.NoError(t, err)
 }
 
 func TestMilpProblem_SolveMultiple(t *testing.T) {
 	type fields struct {
 		c                      []float64
-		A                      *mat.Dense
+		A                      ConstraintMatrix
 		b                      []float64
-		G                      *mat.Dense
+		G                      ConstraintMatrix
 		h                      []float64
 		integralityConstraints []bool
 	}
@@ -143,7 +187,7 @@ func TestMilpProblem_SolveMultiple(t *testing.T) {
 			name: "No integrality constraints, no inequalities",
 			fields: fields{
 				c: []float64{-1, -2, 0, 0},
-				A: mat.NewDense(2, 4, []float64{
+				A: NewDenseConstraints(2, 4, []float64{
 					-1, 2, 1, 0,
 					3, 1, 0, 1,
 				}),
@@ -161,7 +205,7 @@ func TestMilpProblem_SolveMultiple(t *testing.T) {
 			name: "Intial relaxation satisfies integrality",
 			fields: fields{
 				c: []float64{-1, -2, 0, 0},
-				A: mat.NewDense(2, 4, []float64{
+				A: NewDenseConstraints(2, 4, []float64{
 					-1, 2, 1, 0,
 					3, 1, 0, 1,
 				}),
@@ -179,7 +223,7 @@ func TestMilpProblem_SolveMultiple(t *testing.T) {
 			name: "1: One integrality constraint and no initial inequality constraints.",
 			fields: fields{
 				c: []float64{-1, -2, 0, 0},
-				A: mat.NewDense(2, 4, []float64{
+				A: NewDenseConstraints(2, 4, []float64{
 					-1, 2.6, 1, 0,
 					3, 1.1, 0, 1,
 				}),
@@ -197,7 +241,7 @@ func TestMilpProblem_SolveMultiple(t *testing.T) {
 			name: "2: One integrality constraint and no initial inequality constraints.",
 			fields: fields{
 				c: []float64{-1, -2, 0},
-				A: mat.NewDense(2, 3, []float64{
+				A: NewDenseConstraints(2, 3, []float64{
 					-1, 2.6, 1.2,
 					3, 1.1, 1.6,
 				}),
@@ -215,7 +259,7 @@ func TestMilpProblem_SolveMultiple(t *testing.T) {
 			name: "3: One integrality constraint and no initial inequality constraints.",
 			fields: fields{
 				c: []float64{-1, -2, 1},
-				A: mat.NewDense(2, 3, []float64{
+				A: NewDenseConstraints(2, 3, []float64{
 					-2, 2.6, 2,
 					6, 1.1, 1,
 				}),
@@ -233,12 +277,12 @@ func TestMilpProblem_SolveMultiple(t *testing.T) {
 			name: "One integrality constraint and one initial inequality constraint.",
 			fields: fields{
 				c: []float64{-1, -2, 1},
-				A: mat.NewDense(2, 3, []float64{
+				A: NewDenseConstraints(2, 3, []float64{
 					-2, 2.6, 2,
 					6, 1.1, 1,
 				}),
 				b: []float64{4, 9},
-				G: mat.NewDense(1, 3, []float64{
+				G: NewDenseConstraints(1, 3, []float64{
 					-1, 0, 0,
 				}),
 				h: []float64{-1},
@@ -255,11 +299,11 @@ func TestMilpProblem_SolveMultiple(t *testing.T) {
 			name: "infinite recursion regression: two integrality constraints and two initial inequality constraints.",
 			fields: fields{
 				c: []float64{1.7356332566545616, -0.2058339272568599, -1.051665297603944},
-				A: mat.NewDense(1, 3, []float64{
+				A: NewDenseConstraints(1, 3, []float64{
 					-0.7762132098737671, 1.42027949678888, -0.3304567624749696,
 				}),
 				b: []float64{-0.24703471683023603},
-				G: mat.NewDense(1, 3, []float64{
+				G: NewDenseConstraints(1, 3, []float64{
 					-0.6775235462631393, -1.9616379110849085, 1.9859192819811322,
 				}),
 				h: []float64{-0.041138108068992485},
@@ -289,8 +333,9 @@ func TestMilpProblem_SolveMultiple(t *testing.T) {
 				// solve the problem with 'i' workers and a one-second timeout
 				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 				defer cancel()
-				got, err := p.solve(ctx, i, dummyMiddleware{})
-				if err != tt.wantErr {
+				result, err := p.solve(ctx, i, dummyMiddleware{})
+				got := result.best()
+				if !errors.Is(err, tt.wantErr) {
 					t.Log(got)
 					t.Errorf("milpProblem.SolveWithCtx() error = %v, wantErr %v", err, tt.wantErr)
 					return
@@ -328,7 +373,7 @@ func TestRandomized(t *testing.T) {
 }
 
 func testRandomMILP(t *testing.T, nTest int, pZero float64, maxN int, rnd *rand.Rand, workers int) {
-	var sol solution
+	var sol MIPResult
 	var err error
 
 	// Try a bunch of random LPs
@@ -343,11 +388,11 @@ func testRandomMILP(t *testing.T, nTest int, pZero float64, maxN int, rnd *rand.
 		fmt.Println("integrality:")
 		fmt.Println(prob.integralityConstraints)
 		fmt.Println("A:")
-		fmt.Println(mat.Formatted(prob.A))
+		fmt.Println(mat.Formatted(prob.A.ToDense()))
 		fmt.Println("b:")
 		fmt.Println(prob.b)
 		fmt.Println("G:")
-		fmt.Println(mat.Formatted(prob.G))
+		fmt.Println(mat.Formatted(prob.G.ToDense()))
 		fmt.Println("h:")
 		fmt.Println(prob.h)
 
@@ -366,6 +411,114 @@ func testRandomMILP(t *testing.T, nTest int, pZero float64, maxN int, rnd *rand.
 
 }
 
+// Randomized problems with a node limit should always terminate within the limit.
+func TestRandomized_NodeLimit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping randomized testing in short mode!")
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+
+	const nodeLimit = 10
+	for i := 0; i < 100; i++ {
+		n := rnd.Intn(10) + 2
+		m := rnd.Intn(n-1) + 1
+		prob := getRandomMILP(0, m, n, rnd)
+		prob.config.NodeLimit = nodeLimit
+
+		counter := &decisionCounter{}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		result, err := prob.solve(ctx, 2, counter)
+		sol := result.best()
+		cancel()
+
+		if counter.decisions > nodeLimit {
+			t.Errorf("problem %v: checked %v subproblems, exceeding the node limit of %v", i, counter.decisions, nodeLimit)
+		}
+
+		// either a valid solution is returned, or the search was stopped by the node limit or failed for other reasons (e.g. unboundedness).
+		if err == nil {
+			assert.True(t, feasibleForIP(prob.integralityConstraints, sol.x, prob.integralityTol), "problem %v: solution not integer feasible", i)
+		} else {
+			t.Log(err)
+		}
+	}
+}
+
+func Test_validateNumerics(t *testing.T) {
+	getProblem := func() *milpProblem {
+		return &milpProblem{
+			c: []float64{1, 2, 3},
+			A: NewDenseConstraints(2, 3, []float64{1, 0, 1, 0, 1, 1}),
+			b: []float64{1, 2},
+			G: NewSparseConstraints(3),
+			h: []float64{},
+		}
+	}
+	assert.NoError(t, validateNumerics(getProblem()))
+
+	prob := getProblem()
+	prob.c[2] = math.NaN()
+	assert.EqualError(t, validateNumerics(prob), "NaN in objective coefficient at index 2")
+
+	prob = getProblem()
+	prob.A.(DenseConstraints).Set(1, 2, math.Inf(-1))
+	assert.EqualError(t, validateNumerics(prob), "Inf in equality constraint row 1, column 2")
+
+	prob = getProblem()
+	prob.h = []float64{math.Inf(1)}
+	prob.G.(*SparseConstraints).AppendRow([]float64{1, 0, 0})
+	assert.EqualError(t, validateNumerics(prob), "Inf in inequality right-hand side at index 0")
+}
+
+// Randomized problems containing NaN or Inf values should be rejected before they reach the LP solver, rather than causing a panic.
+func TestRandomized_NonFinite(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	nonFinite := []float64{math.NaN(), math.Inf(1), math.Inf(-1)}
+
+	for i := 0; i < 100; i++ {
+		n := rnd.Intn(10) + 2
+		m := rnd.Intn(n-1) + 1
+		prob := getRandomMILP(0, m, n, rnd)
+
+		// corrupt a random element of c, A, b, G or h
+		val := nonFinite[rnd.Intn(len(nonFinite))]
+		switch rnd.Intn(5) {
+		case 0:
+			prob.c[rnd.Intn(n)] = val
+		case 1:
+			prob.A.(DenseConstraints).Set(rnd.Intn(m), rnd.Intn(n), val)
+		case 2:
+			prob.b[rnd.Intn(m)] = val
+		case 3:
+			prob.G.(DenseConstraints).Set(rnd.Intn(m), rnd.Intn(n), val)
+		case 4:
+			prob.h[rnd.Intn(m)] = val
+		}
+
+		result, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+		assert.True(t, errors.Is(err, ErrInvalidProblem), "problem %v: unexpected error %v", i, err)
+		assert.Equal(t, STATUS_UNKNOWN, result.Status)
+
+		// the same holds for the abstract problem, which is validated before it is converted
+		abstract := getRandomProblem(0, m, n, rnd)
+		abstract.constraints[rnd.Intn(len(abstract.constraints))].expressions[0].coef = val
+		_, err = abstract.Solve(context.Background())
+		assert.True(t, errors.Is(err, ErrInvalidProblem), "problem %v: unexpected error %v", i, err)
+	}
+}
+
+// counts the number of decisions made by the branch-and-bound procedure
+type decisionCounter struct {
+	decisions int
+}
+
+func (d *decisionCounter) ProcessDecision(s solution, decision bnbDecision) {
+	d.decisions++
+}
+
+func (d *decisionCounter) NewSubProblem(s subProblem) {}
+
 // adapted from Gonum's lp.Simplex.
 func getRandomMILP(pZero float64, m, n int, rnd *rand.Rand) *milpProblem {
 
@@ -420,9 +573,9 @@ func getRandomMILP(pZero float64, m, n int, rnd *rand.Rand) *milpProblem {
 	}
 	return &milpProblem{
 		c: c,
-		A: a,
+		A: DenseConstraints{a},
 		b: b,
-		G: g,
+		G: DenseConstraints{g},
 		h: h,
 		integralityConstraints: integralityConstraints,
 	}
@@ -450,3 +603,285 @@ func (b *Boolgen) Bool() bool {
 
 	return result
 }
+
+// Enabling Gomory mixed-integer cuts should yield the same optimum using a smaller enumeration tree.
+func TestMilpProblem_Solve_GomoryMixedIntegerCuts(t *testing.T) {
+	prob := milpProblem{
+		c: []float64{-5, -4},
+		G: NewDenseConstraints(2, 2, []float64{
+			6, 4,
+			1, 2,
+		}),
+		h:                      []float64{24.5, 6.2},
+		integralityConstraints: []bool{false, true},
+	}
+
+	solveWithTree := func(p milpProblem) (solution, int) {
+		tl := NewTreeLogger()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		result, err := p.solve(ctx, 1, tl)
+		got := result.best()
+		assert.NoError(t, err)
+		return got, len(tl.nodes)
+	}
+
+	plain, plainNodes := solveWithTree(prob)
+
+	prob.config.EnableGomoryMixedIntegerCuts = true
+	cut, cutNodes := solveWithTree(prob)
+
+	assert.InDelta(t, plain.z, cut.z, 1e-9)
+	assert.InDeltaSlice(t, plain.x, cut.x, 1e-9)
+	assert.True(t, cutNodes < plainNodes, "expected fewer nodes with cuts: %v with cuts, %v without", cutNodes, plainNodes)
+}
+
+// Strong branching should yield the same optimum as most-infeasible branching using a smaller enumeration tree.
+func TestMilpProblem_Solve_StrongBranching(t *testing.T) {
+	prob := getTestKnapsackMILP()
+
+	solveWithTree := func(p milpProblem) (solution, int) {
+		tl := NewTreeLogger()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		result, err := p.solve(ctx, 1, tl)
+		got := result.best()
+		assert.NoError(t, err)
+		return got, len(tl.nodes)
+	}
+
+	prob.branchingHeuristic = BRANCH_MOST_INFEASIBLE
+	infeasible, infeasibleNodes := solveWithTree(prob)
+
+	prob.branchingHeuristic = BRANCH_STRONG
+	strong, strongNodes := solveWithTree(prob)
+
+	assert.Equal(t, float64(-24), infeasible.z)
+	assert.InDelta(t, infeasible.z, strong.z, 1e-9)
+	assert.True(t, strongNodes < infeasibleNodes, "expected fewer nodes with strong branching: %v with strong branching, %v without", strongNodes, infeasibleNodes)
+
+}
+
+// Parallel branching should explore the same enumeration tree as sequential branching.
+// Run with the race detector to check the concurrent creation of the children.
+func TestMilpProblem_Solve_ParallelBranching(t *testing.T) {
+	for _, heuristic := range []BranchHeuristic{BRANCH_MOST_INFEASIBLE, BRANCH_STRONG} {
+		prob := getKnapsackMILP(rand.New(rand.NewSource(1)), 2, 5)
+		prob.branchingHeuristic = heuristic
+		prob.config.NodeLimit = 200
+
+		sequential, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+		if err != nil && !errors.Is(err, ErrNodeLimitExceeded) {
+			t.Fatal(err)
+		}
+
+		prob.config.ParallelBranching = true
+		parallel, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+		if err != nil && !errors.Is(err, ErrNodeLimitExceeded) {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, sequential.best().x, parallel.best().x, "heuristic %v", heuristic)
+		assert.Equal(t, sequential.Stats.NodesExplored, parallel.Stats.NodesExplored, "heuristic %v", heuristic)
+		assert.Equal(t, sequential.Stats.LPRelaxationsSolved, parallel.Stats.LPRelaxationsSolved, "heuristic %v", heuristic)
+	}
+}
+
+// a multidimensional knapsack problem with n general integer variables and m constraints with positive coefficients.
+func getKnapsackMILP(rnd *rand.Rand, m, n int) milpProblem {
+	c := make([]float64, n)
+	integrality := make([]bool, n)
+	for j := range c {
+		c[j] = -float64(rnd.Intn(20) + 1)
+		integrality[j] = true
+	}
+
+	G := make([]float64, m*n)
+	for i := range G {
+		G[i] = float64(rnd.Intn(10) + 1)
+	}
+
+	// fractional right-hand sides ensure a fractional LP relaxation
+	h := make([]float64, m)
+	for i := range h {
+		h[i] = float64(rnd.Intn(10*n)+5*n) + 0.5
+	}
+
+	return milpProblem{
+		c:                      c,
+		G:                      NewDenseConstraints(m, n, G),
+		h:                      h,
+		integralityConstraints: integrality,
+	}
+}
+
+// the knapsack problem of three general integer variables that is shared by the tests of the branch-and-bound procedure:
+// min -4x - 2y - 8z subject to 8x + 6y + z <= 33.5 and 3x + 4y + 9z <= 25.5. Its LP relaxation is fractional, and its optimum is -24.
+func getTestKnapsackMILP() milpProblem {
+	return milpProblem{
+		c: []float64{-4, -2, -8},
+		G: NewDenseConstraints(2, 3, []float64{
+			8, 6, 1,
+			3, 4, 9,
+		}),
+		h:                      []float64{33.5, 25.5},
+		integralityConstraints: []bool{true, true, true},
+		branchingHeuristic:     BRANCH_MOST_INFEASIBLE,
+	}
+}
+
+// the knapsack problem of getTestKnapsackMILP, formulated as the maximization of 4x + 2y + 8z using the Problem API.
+func getTestKnapsackProblem() (Problem, *Variable, *Variable, *Variable) {
+	prob := NewProblem()
+	prob.Maximize()
+	prob.BranchingHeuristic(BRANCH_MOST_INFEASIBLE)
+	x := prob.AddVariable("x").SetCoeff(4).IsInteger()
+	y := prob.AddVariable("y").SetCoeff(2).IsInteger()
+	z := prob.AddVariable("z").SetCoeff(8).IsInteger()
+	prob.AddConstraint("").AddExpression(8, x).AddExpression(6, y).AddExpression(1, z).SmallerThanOrEqualTo(33.5)
+	prob.AddConstraint("").AddExpression(3, x).AddExpression(4, y).AddExpression(9, z).SmallerThanOrEqualTo(25.5)
+	return prob, x, y, z
+}
+
+// Compare the wall time of exploring the first nodes of the enumeration tree of a knapsack problem using strong branching,
+// with the child relaxations of each candidate variable solved sequentially or in parallel.
+func BenchmarkParallelBranching(b *testing.B) {
+	for _, bm := range []struct {
+		name     string
+		parallel bool
+	}{
+		{"sequential", false},
+		{"parallel", true},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			prob := getKnapsackMILP(rand.New(rand.NewSource(1)), 6, 14)
+			prob.branchingHeuristic = BRANCH_STRONG
+			prob.config.NodeLimit = 50
+			prob.config.ParallelBranching = bm.parallel
+
+			for i := 0; i < b.N; i++ {
+				if _, err := prob.solve(context.Background(), 1, dummyMiddleware{}); err != nil && !errors.Is(err, ErrNodeLimitExceeded) {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// Enabling Gomory fractional cuts should yield the same optimum using a smaller enumeration tree.
+func TestMilpProblem_Solve_GomoryFractionalCuts(t *testing.T) {
+	prob := milpProblem{
+		c: []float64{-7, -8},
+		G: NewDenseConstraints(2, 2, []float64{
+			6, 1,
+			9, 8,
+		}),
+		h:                      []float64{17, 23},
+		integralityConstraints: []bool{true, true},
+		branchingHeuristic:     BRANCH_MOST_INFEASIBLE,
+	}
+
+	solveWithTree := func(p milpProblem) (solution, int) {
+		tl := NewTreeLogger()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		result, err := p.solve(ctx, 1, tl)
+		got := result.best()
+		assert.NoError(t, err)
+		return got, len(tl.nodes)
+	}
+
+	plain, plainNodes := solveWithTree(prob)
+
+	prob.config.GomoryRounds = 3
+	cut, cutNodes := solveWithTree(prob)
+
+	assert.Equal(t, float64(-16), plain.z)
+	assert.InDelta(t, plain.z, cut.z, 1e-9)
+	assert.True(t, cutNodes < plainNodes, "expected fewer nodes with cuts: %v with cuts, %v without", cutNodes, plainNodes)
+}
+
+// With a gap tolerance, the search should stop early with a solution that is within the tolerance of the optimum.
+func TestMilpProblem_Solve_GapTolerance(t *testing.T) {
+	prob := milpProblem{
+		c: []float64{-3, -3, -5},
+		G: NewDenseConstraints(2, 3, []float64{
+			2, 1, 8,
+			7, 6, 8,
+		}),
+		h:                      []float64{19.5, 29.5},
+		integralityConstraints: []bool{true, true, true},
+		branchingHeuristic:     BRANCH_MOST_INFEASIBLE,
+	}
+
+	solveWithTree := func(p milpProblem) (solution, int) {
+		tl := NewTreeLogger()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		result, err := p.solve(ctx, 1, tl)
+		got := result.best()
+		assert.NoError(t, err)
+		return got, len(tl.nodes)
+	}
+
+	optimal, optimalNodes := solveWithTree(prob)
+	assert.Equal(t, float64(-16), optimal.z)
+	assert.Equal(t, optimal.z, optimal.bestBound)
+	assert.Equal(t, float64(0), optimal.mipGap())
+
+	prob.config.RelativeGapTolerance = 0.1
+	got, gotNodes := solveWithTree(prob)
+
+	assert.True(t, gotNodes < optimalNodes, "expected fewer nodes with gap tolerance: %v with tolerance, %v without", gotNodes, optimalNodes)
+	assert.True(t, got.mipGap() <= 0.1, "gap %v exceeds tolerance", got.mipGap())
+	assert.True(t, got.bestBound <= optimal.z, "bound %v exceeds optimum %v", got.bestBound, optimal.z)
+	assert.True(t, relativeGap(got.z, optimal.z) <= 0.1)
+}
+
+func TestMilpProblem_Solve_Result(t *testing.T) {
+	// minimize -x s.t. 0.5 <= x <= 0.7, which has a feasible LP relaxation but no integer-feasible solution
+	infeasible := milpProblem{
+		c: []float64{-1},
+		G: NewDenseConstraints(2, 1, []float64{
+			-1,
+			1,
+		}),
+		h:                      []float64{-0.5, 0.7},
+		integralityConstraints: []bool{true},
+	}
+
+	result, err := infeasible.solve(context.Background(), 1, dummyMiddleware{})
+	assert.True(t, errors.Is(err, NO_INTEGER_FEASIBLE_SOLUTION))
+	assert.Equal(t, STATUS_INFEASIBLE, result.Status)
+	assert.Nil(t, result.BestIntegerSolution)
+
+	// the LP relaxation is still reported, in terms of the original variables
+	assert.NoError(t, result.LPRelaxation.err)
+	assert.InDelta(t, -0.7, result.LPRelaxation.z, 1e-9)
+	assert.InDeltaSlice(t, []float64{0.7}, result.LPRelaxation.x, 1e-9)
+
+	// an infeasible LP relaxation is reported as such
+	infeasible.h = []float64{-2, 1}
+	result, err = infeasible.solve(context.Background(), 1, dummyMiddleware{})
+	assert.True(t, errors.Is(err, INITIAL_RELAXATION_NOT_FEASIBLE))
+	assert.Equal(t, STATUS_INFEASIBLE, result.Status)
+	assert.Nil(t, result.BestIntegerSolution)
+	assert.Equal(t, err, result.LPRelaxation.err)
+
+	// the error identifies the root problem as infeasible
+	var infeasibleErr *InfeasibleError
+	if assert.True(t, errors.As(err, &infeasibleErr)) {
+		assert.Equal(t, int64(0), infeasibleErr.NodeID)
+		assert.Equal(t, INITIAL_RELAXATION_NOT_FEASIBLE.Error(), infeasibleErr.Reason)
+	}
+
+	// a solvable problem yields both the LP relaxation and the integer-feasible solution
+	infeasible.h = []float64{-0.5, 1.7}
+	result, err = infeasible.solve(context.Background(), 1, dummyMiddleware{})
+	assert.NoError(t, err)
+	assert.Equal(t, STATUS_OPTIMAL, result.Status)
+	assert.InDelta(t, -1.7, result.LPRelaxation.z, 1e-9)
+	if assert.NotNil(t, result.BestIntegerSolution) {
+		assert.InDeltaSlice(t, []float64{1}, result.BestIntegerSolution.x, 1e-9)
+	}
+}
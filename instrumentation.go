@@ -1,8 +1,14 @@
 package ilp
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 type BnbMiddleware interface {
@@ -26,6 +32,17 @@ func (d dummyMiddleware) NewSubProblem(s subProblem) {
 
 type TreeLogger struct {
 	nodes map[int64]node
+
+	// the id of the node of the incumbent, i.e. the last node that was found to be integer feasible, if any
+	incumbent    int64
+	hasIncumbent bool
+
+	// the number of decisions processed, and the integer-feasible solutions that replaced the incumbent in the order in which they were found
+	decisions int64
+	history   []incumbentRecord
+
+	// whether the DOT output annotates the nodes at which a new incumbent was found
+	annotateIncumbents bool
 }
 
 func NewTreeLogger() *TreeLogger {
@@ -45,6 +62,15 @@ type node struct {
 	// intermediate solution
 	x []float64
 
+	// the objective function value of the parent, which bounds the objective function value of this node
+	bound float64
+
+	// the constraint that distinguishes this node from its parent, e.g. "x2 <= 3". Empty for the root node.
+	branch string
+
+	// the number of branch-and-bound constraints of the subproblem, i.e. the depth of the node in the tree
+	depth int
+
 	// whether the subproblem corresponding to this node has been solved
 	solved bool
 
@@ -58,11 +84,33 @@ func newNode(p subProblem) node {
 	return node{
 		id:     p.id,
 		parent: p.parent,
+		bound:  p.bound,
+		branch: branchLabel(p),
+		depth:  p.Depth(),
 
 		// z, x, and decision are nil-valued at this point
 	}
 }
 
+// describe the last bnbConstraint of the subProblem, which was added to create it from its parent.
+// A branching decision on variable i is described as "xi <= h" or "xi >= h", whereas cutting planes are described as "cuts".
+func branchLabel(p subProblem) string {
+	if len(p.bnbConstraints) == 0 {
+		return ""
+	}
+
+	last := p.bnbConstraints[len(p.bnbConstraints)-1]
+	if last.branchedVariable == noBranchedVariable {
+		return "cuts"
+	}
+
+	// the constraint is factor * x_i <= hsharp, in which the factor is -1 for 'greater than or equal to' constraints
+	if last.gsharp[last.branchedVariable] < 0 {
+		return fmt.Sprintf("x%v >= %v", last.branchedVariable, -last.hsharp)
+	}
+	return fmt.Sprintf("x%v <= %v", last.branchedVariable, last.hsharp)
+}
+
 func (t *TreeLogger) ProcessDecision(s solution, d bnbDecision) {
 	node, found := t.nodes[s.problem.id]
 	if !found {
@@ -77,6 +125,60 @@ func (t *TreeLogger) ProcessDecision(s solution, d bnbDecision) {
 
 	// reassign the node
 	t.nodes[s.problem.id] = node
+
+	t.decisions++
+
+	// every integer-feasible node that is accepted improves upon the previous incumbent
+	if d == BETTER_THAN_INCUMBENT_FEASIBLE || d == INITIAL_RX_FEASIBLE_FOR_IP {
+		t.incumbent = s.problem.id
+		t.hasIncumbent = true
+		t.history = append(t.history, incumbentRecord{
+			solution:      s,
+			nodeID:        s.problem.id,
+			nodesExplored: t.decisions,
+			timestamp:     time.Now(),
+		})
+	}
+}
+
+// IncumbentRecord describes an integer-feasible solution that replaced the incumbent when it was found.
+type IncumbentRecord struct {
+	// the ID of the node of the solution
+	NodeID int64
+
+	// the objective value of the solution. Note that the objective is always minimized.
+	Objective float64
+
+	// the values of the variables in the solution
+	X []float64
+
+	// the number of nodes checked when the solution was found, including its own
+	NodesExplored int64
+
+	Timestamp time.Time
+}
+
+// IncumbentHistory returns the integer-feasible solutions that replaced the incumbent, in the order in which they were found.
+// As the objective is minimized, the objective value of each solution is lower than that of the previous one.
+func (t *TreeLogger) IncumbentHistory() []IncumbentRecord {
+	history := make([]IncumbentRecord, len(t.history))
+	for i, record := range t.history {
+		history[i] = IncumbentRecord{
+			NodeID:        record.nodeID,
+			Objective:     record.solution.z,
+			X:             append([]float64(nil), record.solution.x...),
+			NodesExplored: record.nodesExplored,
+			Timestamp:     record.timestamp,
+		}
+	}
+	return history
+}
+
+// AnnotateIncumbents sets whether the DOT output annotates each node at which a new incumbent was found with the position of that incumbent in the history,
+// and the number of nodes explored until it was found.
+func (t *TreeLogger) AnnotateIncumbents(enabled bool) *TreeLogger {
+	t.annotateIncumbents = enabled
+	return t
 }
 
 func (t *TreeLogger) NewSubProblem(s subProblem) {
@@ -87,6 +189,8 @@ func (t *TreeLogger) NewSubProblem(s subProblem) {
 }
 
 // takes an io.Writer to write the DOT-file visualisation of the processed enumeration tree to.
+// The edges are labelled with the branching decision that created the child. Nodes that were found to be integer feasible are drawn as a double circle,
+// of which the incumbent has a bold border. The label of each node shows its objective value as a fraction of that of its parent, which shows how much the bound tightened.
 func (t *TreeLogger) ToDOT(out io.Writer) {
 
 	writeRow := func(r string, args ...interface{}) {
@@ -111,6 +215,7 @@ func (t *TreeLogger) ToDOT(out io.Writer) {
 	for id, n := range t.nodes {
 		color := "Pink"
 		label := "unsolved"
+		attributes := ""
 		if n.solved {
 			tag := ""
 			switch n.decision {
@@ -122,6 +227,7 @@ func (t *TreeLogger) ToDOT(out io.Writer) {
 			case BETTER_THAN_INCUMBENT_FEASIBLE:
 				color = "Green"
 				tag = "IP feasible. New incumbent!"
+				attributes += ",shape=doublecircle"
 
 			case SUBPROBLEM_NOT_FEASIBLE:
 				color = "Red"
@@ -138,15 +244,38 @@ func (t *TreeLogger) ToDOT(out io.Writer) {
 				color = "Red"
 				tag = "singular"
 
+			case MAX_DEPTH_REACHED:
+				color = "Orange"
+				tag = "max depth"
+
 			default:
 				color = "Red"
 				tag = string(n.decision)
 			}
 
-			label = fmt.Sprintf("<Z=%.2f <BR /> id:%v <BR /> %v >", n.z, n.id, tag)
+			// the root node does not have a parent to compare the bound with, and failed nodes do not have a bound
+			tightening := ""
+			if id != n.parent && n.bound != 0 && !math.IsNaN(n.z) {
+				tightening = fmt.Sprintf(" <BR /> Z/parent Z=%.3f", n.z/n.bound)
+			}
+
+			annotation := ""
+			if t.annotateIncumbents {
+				for k, record := range t.history {
+					if record.nodeID == id {
+						annotation = fmt.Sprintf(" <BR /> incumbent #%v after %v nodes", k+1, record.nodesExplored)
+					}
+				}
+			}
+
+			label = fmt.Sprintf("<Z=%.2f <BR /> id:%v <BR /> %v%v%v >", n.z, n.id, tag, tightening, annotation)
 		}
 
-		writeRow("%v [label=%v,color=%v];", id, label, color)
+		if t.hasIncumbent && id == t.incumbent {
+			attributes += ",penwidth=3"
+		}
+
+		writeRow("%v [label=%v,color=%v%v];", id, label, color, attributes)
 		relations[id] = n.parent
 	}
 
@@ -158,8 +287,220 @@ func (t *TreeLogger) ToDOT(out io.Writer) {
 			continue
 		}
 
-		writeRow("%v -> %v ;", parentID, nodeID)
+		writeRow("%v -> %v [label=\"%v\"];", parentID, nodeID, t.nodes[nodeID].branch)
 	}
 
 	writeRow("}")
 }
+
+// StatisticsMiddleware is a BnbMiddleware that gathers aggregate statistics of the enumeration tree:
+// the number of subProblems created, the number of each decision, the time between the creation of each subProblem and the decision on it,
+// and the distribution of the depths of the subProblems. It is safe for concurrent use, so the statistics can be reported while solving.
+type StatisticsMiddleware struct {
+	mu sync.Mutex
+
+	created   int64
+	decisions map[bnbDecision]int64
+
+	// the time at which each subProblem without a decision was created, keyed by id
+	pending map[int64]time.Time
+
+	// the total time between the creation of a subProblem and the decision on it, and the number of subProblems it covers
+	nodeTime time.Duration
+	timed    int64
+
+	// the number of subProblems created at each depth, i.e. the number of bnbConstraints of the subProblem
+	depths map[int]int64
+}
+
+func NewStatisticsMiddleware() *StatisticsMiddleware {
+	return &StatisticsMiddleware{
+		decisions: make(map[bnbDecision]int64),
+		pending:   make(map[int64]time.Time),
+		depths:    make(map[int]int64),
+	}
+}
+
+func (m *StatisticsMiddleware) NewSubProblem(s subProblem) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.created++
+	m.depths[s.Depth()]++
+	m.pending[s.id] = time.Now()
+}
+
+func (m *StatisticsMiddleware) ProcessDecision(s solution, d bnbDecision) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.decisions[d]++
+	if created, ok := m.pending[s.problem.id]; ok {
+		m.nodeTime += time.Since(created)
+		m.timed++
+		delete(m.pending, s.problem.id)
+	}
+}
+
+// SubProblemsCreated returns the number of subProblems created, including the root problem.
+func (m *StatisticsMiddleware) SubProblemsCreated() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.created
+}
+
+// Decisions returns the number of times each decision was made.
+func (m *StatisticsMiddleware) Decisions() map[bnbDecision]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	decisions := make(map[bnbDecision]int64, len(m.decisions))
+	for d, n := range m.decisions {
+		decisions[d] = n
+	}
+	return decisions
+}
+
+// NodeTime returns the total time between the creation of a subProblem and the decision on it, which includes the time it spent in the queue,
+// along with the number of subProblems of which a decision was made.
+func (m *StatisticsMiddleware) NodeTime() (time.Duration, int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.nodeTime, m.timed
+}
+
+// DepthHistogram returns the number of subProblems created at each depth of the enumeration tree. The root problem has depth 0.
+func (m *StatisticsMiddleware) DepthHistogram() map[int]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	depths := make(map[int]int64, len(m.depths))
+	for d, n := range m.depths {
+		depths[d] = n
+	}
+	return depths
+}
+
+// Report returns a human-readable summary of the statistics.
+func (m *StatisticsMiddleware) Report() string {
+	decisions := m.Decisions()
+	depths := m.DepthHistogram()
+	nodeTime, timed := m.NodeTime()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "subProblems created: %v\n", m.SubProblemsCreated())
+
+	b.WriteString("decisions:\n")
+	var names []string
+	for d := range decisions {
+		names = append(names, string(d))
+	}
+	sort.Strings(names)
+	for _, d := range names {
+		fmt.Fprintf(&b, "  %v: %v\n", d, decisions[bnbDecision(d)])
+	}
+
+	var perNode time.Duration
+	if timed > 0 {
+		perNode = nodeTime / time.Duration(timed)
+	}
+	fmt.Fprintf(&b, "time from creation to decision: %v in total, %v per subProblem\n", nodeTime, perNode)
+
+	b.WriteString("depth distribution:\n")
+	var levels []int
+	for d := range depths {
+		levels = append(levels, d)
+	}
+	sort.Ints(levels)
+	for _, d := range levels {
+		fmt.Fprintf(&b, "  %v: %v\n", d, depths[d])
+	}
+
+	return b.String()
+}
+
+// Compose returns a BnbMiddleware that passes each event to all of the provided middlewares, in order.
+func Compose(middlewares ...BnbMiddleware) BnbMiddleware {
+	return composedMiddleware(middlewares)
+}
+
+type composedMiddleware []BnbMiddleware
+
+func (c composedMiddleware) ProcessDecision(s solution, d bnbDecision) {
+	for _, m := range c {
+		m.ProcessDecision(s, d)
+	}
+}
+
+func (c composedMiddleware) NewSubProblem(s subProblem) {
+	for _, m := range c {
+		m.NewSubProblem(s)
+	}
+}
+
+// the JSON representation of a node of the enumeration tree logged by a TreeLogger.
+type jsonNode struct {
+	ID        int64       `json:"id"`
+	ParentID  int64       `json:"parentId"`
+	Z         jsonFloat   `json:"z"`
+	X         []jsonFloat `json:"x"`
+	Bound     jsonFloat   `json:"bound"`
+	Branch    string      `json:"branch,omitempty"`
+	Decision  string      `json:"decision,omitempty"`
+	Solved    bool        `json:"solved"`
+	Depth     int         `json:"depth"`
+	Incumbent bool        `json:"incumbent"`
+}
+
+// MarshalJSON encodes the logged enumeration tree as an array of nodes ordered by id, for offline analysis of the search.
+// The objective values are those of the minimization problem solved by the branch-and-bound procedure. The depth of a node is the number of
+// branch-and-bound constraints of its subProblem, and incumbent tells whether the node improved on the incumbent when it was decided on.
+// The objective value and solution of unsolved nodes are zero and null. The array adheres to the following JSON schema:
+//
+//	{
+//	  "type": "array",
+//	  "items": {
+//	    "type": "object",
+//	    "required": ["id", "parentId", "z", "x", "bound", "solved", "depth", "incumbent"],
+//	    "properties": {
+//	      "id":        {"type": "integer"},
+//	      "parentId":  {"type": "integer", "description": "equal to id for the root node"},
+//	      "z":         {"type": ["number", "string"], "description": "\"Infinity\", \"-Infinity\" or \"NaN\" if not finite"},
+//	      "x":         {"type": ["array", "null"], "items": {"type": ["number", "string"]}},
+//	      "bound":     {"type": ["number", "string"], "description": "the objective value of the parent"},
+//	      "branch":    {"type": "string", "description": "the constraint that created the node from its parent, e.g. \"x2 <= 3\""},
+//	      "decision":  {"type": "string", "description": "the branch-and-bound decision, absent if unsolved"},
+//	      "solved":    {"type": "boolean"},
+//	      "depth":     {"type": "integer", "minimum": 0},
+//	      "incumbent": {"type": "boolean"}
+//	    }
+//	  }
+//	}
+func (t *TreeLogger) MarshalJSON() ([]byte, error) {
+	nodes := make([]jsonNode, 0, len(t.nodes))
+	for _, n := range t.nodes {
+		jn := jsonNode{
+			ID:        n.id,
+			ParentID:  n.parent,
+			Z:         jsonFloat(n.z),
+			Bound:     jsonFloat(n.bound),
+			Branch:    n.branch,
+			Decision:  string(n.decision),
+			Solved:    n.solved,
+			Depth:     n.depth,
+			Incumbent: n.decision == BETTER_THAN_INCUMBENT_FEASIBLE || n.decision == INITIAL_RX_FEASIBLE_FOR_IP,
+		}
+		if n.x != nil {
+			jn.X = make([]jsonFloat, len(n.x))
+			for i, v := range n.x {
+				jn.X[i] = jsonFloat(v)
+			}
+		}
+		nodes = append(nodes, jn)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+	return json.Marshal(nodes)
+}
@@ -1,6 +1,13 @@
 package ilp
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -101,3 +108,225 @@ func Test_TreeLogger(t *testing.T) {
 	// err := ioutil.WriteFile("enumtree.test.dot", buffer.Bytes(), 0644)
 	// assert.NoError(t, err)
 }
+
+func TestTreeLogger_ToDOT(t *testing.T) {
+	prob := getTestKnapsackMILP()
+
+	tl := NewTreeLogger()
+	_, err := prob.solve(context.Background(), 1, tl)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var buf bytes.Buffer
+	tl.ToDOT(&buf)
+
+	nodeLine := regexp.MustCompile(`^(\d+) \[label=(.*),color=\w+(.*)\];$`)
+	edgeLine := regexp.MustCompile(`^(\d+) -> (\d+) \[label="(.*)"\];$`)
+
+	var edges, incumbents, feasible int
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if m := edgeLine.FindStringSubmatch(line); m != nil {
+			edges++
+
+			// every edge to a non-root node is labelled with a branching decision
+			assert.Regexp(t, `^x\d+ (<=|>=) -?\d+$`, m[3])
+			continue
+		}
+
+		if m := nodeLine.FindStringSubmatch(line); m != nil {
+			if strings.Contains(m[3], "penwidth=3") {
+				incumbents++
+			}
+			if strings.Contains(m[3], "shape=doublecircle") {
+				feasible++
+				assert.Equal(t, BETTER_THAN_INCUMBENT_FEASIBLE, tl.nodes[mustParseInt(t, m[1])].decision)
+			}
+
+			// solved non-root nodes show how much the bound tightened with respect to the parent
+			id := mustParseInt(t, m[1])
+			if n := tl.nodes[id]; n.solved && id != n.parent && n.decision != SUBPROBLEM_IS_DEGENERATE && n.decision != SUBPROBLEM_NOT_FEASIBLE {
+				assert.Contains(t, m[2], "Z/parent Z=")
+			}
+		}
+	}
+
+	assert.Equal(t, len(tl.nodes)-1, edges)
+	assert.Equal(t, 1, incumbents)
+	assert.True(t, feasible > 0)
+	assert.True(t, tl.hasIncumbent)
+	assert.Equal(t, BETTER_THAN_INCUMBENT_FEASIBLE, tl.nodes[tl.incumbent].decision)
+}
+
+func TestTreeLogger_MarshalJSON(t *testing.T) {
+	prob := getTestKnapsackMILP()
+
+	tl := NewTreeLogger()
+	result, err := prob.solve(context.Background(), 1, tl)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	data, err := json.Marshal(tl)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var nodes []struct {
+		ID        int64
+		ParentID  int64
+		Z         jsonFloat
+		X         []jsonFloat
+		Decision  string
+		Solved    bool
+		Depth     int
+		Incumbent bool
+	}
+	if !assert.NoError(t, json.Unmarshal(data, &nodes)) {
+		return
+	}
+
+	// all nodes are present, ordered by id
+	if !assert.Len(t, nodes, len(tl.nodes)) {
+		return
+	}
+	incumbents := 0
+	for i, n := range nodes {
+		if i > 0 {
+			assert.True(t, nodes[i-1].ID < n.ID)
+		}
+
+		logged := tl.nodes[n.ID]
+		assert.Equal(t, logged.parent, n.ParentID)
+		assert.Equal(t, string(logged.decision), n.Decision)
+		assert.Equal(t, logged.solved, n.Solved)
+		assert.Equal(t, len(logged.x), len(n.X))
+		if n.ID == n.ParentID {
+			assert.Equal(t, 0, n.Depth)
+		} else {
+			assert.True(t, n.Depth > 0)
+		}
+		if n.Incumbent {
+			incumbents++
+			assert.Equal(t, string(BETTER_THAN_INCUMBENT_FEASIBLE), n.Decision)
+		}
+	}
+
+	// the last incumbent is the optimum
+	assert.Equal(t, len(tl.history), incumbents)
+	optimum := tl.nodes[tl.incumbent]
+	assert.Equal(t, result.BestIntegerSolution.z, optimum.z)
+	assert.Equal(t, float64(nodes[0].Z), tl.nodes[nodes[0].ID].z)
+}
+
+func mustParseInt(t *testing.T, s string) int64 {
+	v, err := strconv.ParseInt(s, 10, 64)
+	assert.NoError(t, err)
+	return v
+}
+
+func TestStatisticsMiddleware(t *testing.T) {
+	decisions := []bnbDecision{
+		SUBPROBLEM_IS_DEGENERATE,
+		SUBPROBLEM_NOT_FEASIBLE,
+		WORSE_THAN_INCUMBENT,
+		BETTER_THAN_INCUMBENT_BRANCHING,
+		BETTER_THAN_INCUMBENT_FEASIBLE,
+		INITIAL_RX_FEASIBLE_FOR_IP,
+		VIOLATES_LAZY_CONSTRAINTS,
+	}
+
+	stats := NewStatisticsMiddleware()
+
+	// the subProblem with id i has depth i and receives the first i+1 decisions, one each
+	for i := range decisions {
+		p := subProblem{id: int64(i), bnbConstraints: make([]bnbConstraint, i)}
+		stats.NewSubProblem(p)
+		for _, d := range decisions[:i+1] {
+			stats.ProcessDecision(solution{problem: &p}, d)
+		}
+	}
+
+	assert.Equal(t, int64(len(decisions)), stats.SubProblemsCreated())
+	for i, d := range decisions {
+		assert.Equal(t, int64(len(decisions)-i), stats.Decisions()[d], string(d))
+	}
+	for i := range decisions {
+		assert.Equal(t, int64(1), stats.DepthHistogram()[i])
+	}
+
+	// only the first decision on each subProblem is timed
+	_, timed := stats.NodeTime()
+	assert.Equal(t, int64(len(decisions)), timed)
+
+	report := stats.Report()
+	assert.Contains(t, report, "subProblems created: 7\n")
+	assert.Contains(t, report, "  "+string(SUBPROBLEM_IS_DEGENERATE)+": 7\n")
+	assert.Contains(t, report, "  "+string(VIOLATES_LAZY_CONSTRAINTS)+": 1\n")
+	assert.Contains(t, report, "  6: 1\n")
+}
+
+func TestCompose(t *testing.T) {
+	prob := getTestKnapsackMILP()
+
+	tl := NewTreeLogger()
+	stats := NewStatisticsMiddleware()
+	_, err := prob.solve(context.Background(), 1, Compose(tl, stats))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// both middlewares receive every event
+	assert.Equal(t, int64(len(tl.nodes)), stats.SubProblemsCreated())
+
+	perDecision := make(map[bnbDecision]int64)
+	var depth0 int64
+	for _, n := range tl.nodes {
+		perDecision[n.decision]++
+		if n.id == n.parent {
+			depth0++
+		}
+	}
+	assert.Equal(t, perDecision, stats.Decisions())
+	assert.Equal(t, depth0, stats.DepthHistogram()[0])
+}
+
+func TestTreeLogger_IncumbentHistory(t *testing.T) {
+	prob := getTestKnapsackMILP()
+
+	tl := NewTreeLogger().AnnotateIncumbents(true)
+	tree := newEnumerationTree(prob.toInitialSubproblem(), tl, prob.config, nil)
+	incumbent, err := tree.startSearch(context.Background(), 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	history := tl.IncumbentHistory()
+	if !assert.NotEmpty(t, history) {
+		return
+	}
+	assert.Equal(t, len(tree.incumbentHistory), len(history))
+
+	// each new incumbent improves on the previous one
+	for i := 1; i < len(history); i++ {
+		assert.True(t, history[i].Objective < history[i-1].Objective)
+		assert.True(t, history[i].NodesExplored > history[i-1].NodesExplored)
+		assert.False(t, history[i].Timestamp.Before(history[i-1].Timestamp))
+	}
+	for i, record := range tree.incumbentHistory {
+		assert.Equal(t, history[i].NodeID, record.nodeID)
+		assert.Equal(t, history[i].NodesExplored, record.nodesExplored)
+	}
+
+	last := history[len(history)-1]
+	assert.Equal(t, incumbent.z, last.Objective)
+	assert.Equal(t, incumbent.x, last.X)
+	assert.Equal(t, tl.incumbent, last.NodeID)
+
+	// the node of each incumbent is annotated in the DOT output
+	var buf bytes.Buffer
+	tl.ToDOT(&buf)
+	for i := range history {
+		assert.Contains(t, buf.String(), fmt.Sprintf("incumbent #%v after", i+1))
+	}
+}
@@ -0,0 +1,284 @@
+package ilp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ProblemDTO is the plain data representation of a Problem, which is encoded as JSON by Problem.MarshalJSON and as YAML by Problem.ExportYAML.
+// The expressions, big-M indicators and SOS1 constraints refer to the variables by name.
+type ProblemDTO struct {
+	Maximize           bool            `json:"maximize" yaml:"maximize"`
+	BranchingHeuristic BranchHeuristic `json:"branchingHeuristic" yaml:"branchingHeuristic"`
+	Variables          []VariableDTO   `json:"variables" yaml:"variables"`
+	Constraints        []ConstraintDTO `json:"constraints" yaml:"constraints"`
+
+	// the constant term of the objective function
+	ObjectiveOffset float64 `json:"objectiveOffset,omitempty" yaml:"objectiveOffset,omitempty"`
+
+	// the SOS1 constraints, each listing the names of its variables in order
+	SOS1 [][]string `json:"sos1,omitempty" yaml:"sos1,omitempty"`
+}
+
+// VariableDTO is the plain data representation of a Variable. The omitted bounds of a decoded variable default to [0, +Inf) like those of Problem.AddVariable.
+type VariableDTO struct {
+	Name        string    `json:"name" yaml:"name"`
+	Coefficient float64   `json:"coefficient" yaml:"coefficient"`
+	Integer     bool      `json:"integer" yaml:"integer"`
+	Binary      bool      `json:"binary,omitempty" yaml:"binary,omitempty"`
+	Lower       jsonFloat `json:"lower" yaml:"lower"`
+	Upper       jsonFloat `json:"upper" yaml:"upper"`
+}
+
+func (v *VariableDTO) UnmarshalJSON(data []byte) error {
+	// the alias type prevents infinite recursion, as it does not have this method
+	type alias VariableDTO
+	decoded := alias{Upper: jsonFloat(math.Inf(1))}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*v = VariableDTO(decoded)
+	return nil
+}
+
+// ConstraintDTO is the plain data representation of a Constraint.
+type ConstraintDTO struct {
+	Name               string          `json:"name,omitempty" yaml:"name,omitempty"`
+	Expressions        []ExpressionDTO `json:"expressions" yaml:"expressions"`
+	RHS                float64         `json:"rhs" yaml:"rhs"`
+	Inequality         bool            `json:"inequality" yaml:"inequality"`
+	GreaterThanOrEqual bool            `json:"greaterThanOrEqual,omitempty" yaml:"greaterThanOrEqual,omitempty"`
+
+	// the name of the indicator variable of a big-M constraint, along with the value of M
+	BigMIndicator string  `json:"bigMIndicator,omitempty" yaml:"bigMIndicator,omitempty"`
+	BigM          float64 `json:"bigM,omitempty" yaml:"bigM,omitempty"`
+
+	// the ID shared by the two sides of a range constraint
+	RangeID int `json:"rangeID,omitempty" yaml:"rangeID,omitempty"`
+}
+
+// ExpressionDTO is the plain data representation of a term coef * variable of the left-hand side of a constraint.
+type ExpressionDTO struct {
+	Variable string  `json:"variable" yaml:"variable"`
+	Coef     float64 `json:"coef" yaml:"coef"`
+}
+
+// a number that may be infinite or NaN, which is represented by the strings "Infinity", "-Infinity" and "NaN" as JSON has no notion of these.
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	switch {
+	case math.IsInf(float64(f), 1):
+		return []byte(`"Infinity"`), nil
+	case math.IsInf(float64(f), -1):
+		return []byte(`"-Infinity"`), nil
+	case math.IsNaN(float64(f)):
+		return []byte(`"NaN"`), nil
+	}
+	return json.Marshal(float64(f))
+}
+
+func (f *jsonFloat) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case `"Infinity"`:
+		*f = jsonFloat(math.Inf(1))
+		return nil
+	case `"-Infinity"`:
+		*f = jsonFloat(math.Inf(-1))
+		return nil
+	case `"NaN"`:
+		*f = jsonFloat(math.NaN())
+		return nil
+	}
+
+	var v float64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*f = jsonFloat(v)
+	return nil
+}
+
+// MarshalJSON encodes the variables, constraints, objective sense, objective offset and branching heuristic of the problem.
+// The name of unnamed constraints is omitted.
+// Infinite bounds are encoded as the strings "Infinity" and "-Infinity".
+// Note that the other solver settings, such as the instrumentation and custom strategies, are not encoded.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.toDTO())
+}
+
+// the plain data representation of the problem (see ProblemDTO).
+func (p Problem) toDTO() ProblemDTO {
+	jp := ProblemDTO{
+		Maximize:           p.maximize,
+		BranchingHeuristic: p.branchingHeuristic,
+		Variables:          make([]VariableDTO, len(p.variables)),
+		Constraints:        make([]ConstraintDTO, len(p.constraints)),
+		ObjectiveOffset:    p.objectiveOffset,
+	}
+
+	for i, v := range p.variables {
+		jp.Variables[i] = VariableDTO{
+			Name:        v.name,
+			Coefficient: v.coefficient,
+			Integer:     v.integer,
+			Binary:      v.isBinary,
+			Lower:       jsonFloat(v.lower),
+			Upper:       jsonFloat(v.upper),
+		}
+	}
+
+	for i, c := range p.constraints {
+		jc := ConstraintDTO{
+			Name:               c.name,
+			Expressions:        make([]ExpressionDTO, len(c.expressions)),
+			RHS:                c.rhs,
+			Inequality:         c.inequality,
+			GreaterThanOrEqual: c.greaterThanOrEqual,
+			RangeID:            c.rangeID,
+		}
+		for j, e := range c.expressions {
+			jc.Expressions[j] = ExpressionDTO{Variable: e.variable.name, Coef: e.coef}
+		}
+		if c.bigMIndicator != nil {
+			jc.BigMIndicator = c.bigMIndicator.name
+			jc.BigM = c.bigM
+		}
+		jp.Constraints[i] = jc
+	}
+
+	for _, s := range p.sos1 {
+		names := make([]string, len(s.variables))
+		for i, v := range s.variables {
+			names[i] = v.name
+		}
+		jp.SOS1 = append(jp.SOS1, names)
+	}
+
+	return jp
+}
+
+// UnmarshalJSON replaces the problem with the one encoded by MarshalJSON.
+// The expressions refer to the variables by name, so the variable names should be unique.
+// The settings of the problem that are not encoded are reset to those of NewProblem.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	done, err := p.modify()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	var jp ProblemDTO
+	if err := json.Unmarshal(data, &jp); err != nil {
+		return err
+	}
+	return p.fromDTO(jp)
+}
+
+// replace the problem with the one represented by the plain data, of which the settings that are not represented are reset to those of NewProblem.
+func (p *Problem) fromDTO(jp ProblemDTO) error {
+	prob := NewProblem()
+	prob.maximize = jp.Maximize
+	prob.branchingHeuristic = jp.BranchingHeuristic
+	prob.objectiveOffset = jp.ObjectiveOffset
+
+	variables := make(map[string]*Variable, len(jp.Variables))
+	for _, jv := range jp.Variables {
+		if _, ok := variables[jv.Name]; ok {
+			return fmt.Errorf("duplicate variable name %v", jv.Name)
+		}
+
+		v := prob.AddVariable(jv.Name).SetCoeff(jv.Coefficient).LowerBound(float64(jv.Lower)).UpperBound(float64(jv.Upper))
+		v.integer = jv.Integer
+		v.isBinary = jv.Binary
+		variables[jv.Name] = v
+	}
+
+	// rewire the references to the variables by name
+	lookup := func(name string) (*Variable, error) {
+		v, ok := variables[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown variable %v", name)
+		}
+		return v, nil
+	}
+
+	for i, jc := range jp.Constraints {
+		c := prob.AddConstraint(jc.Name)
+
+		// refer to unnamed constraints by their position in error messages
+		label := jc.Name
+		if label == "" {
+			label = strconv.Itoa(i)
+		}
+
+		for _, je := range jc.Expressions {
+			v, err := lookup(je.Variable)
+			if err != nil {
+				return fmt.Errorf("constraint %v: %v", label, err)
+			}
+			c.expressions = append(c.expressions, expression{coef: je.Coef, variable: v})
+		}
+
+		c.rhs = jc.RHS
+		c.inequality = jc.Inequality
+		c.greaterThanOrEqual = jc.GreaterThanOrEqual
+
+		// later range constraints draw IDs that do not clash with the decoded ones
+		c.rangeID = jc.RangeID
+		if jc.RangeID > prob.rangeConstraints {
+			prob.rangeConstraints = jc.RangeID
+		}
+
+		if jc.BigMIndicator != "" {
+			v, err := lookup(jc.BigMIndicator)
+			if err != nil {
+				return fmt.Errorf("constraint %v: %v", label, err)
+			}
+			c.bigMIndicator = v
+			c.bigM = jc.BigM
+		}
+	}
+
+	for i, names := range jp.SOS1 {
+		sos := &SOSConstraint{}
+		for _, name := range names {
+			v, err := lookup(name)
+			if err != nil {
+				return fmt.Errorf("SOS1 constraint %v: %v", i, err)
+			}
+			sos.variables = append(sos.variables, v)
+		}
+		prob.sos1 = append(prob.sos1, sos)
+	}
+
+	// the constraints refer to the problem they were added to
+	for _, c := range prob.constraints {
+		c.problem = p
+	}
+	*p = prob
+
+	return nil
+}
+
+// MarshalJSON encodes the objective value, status, variable values, bound and gap of the solution, along with the solve statistics.
+// Infinite values are encoded as the strings "Infinity" and "-Infinity".
+func (s Solution) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Objective    jsonFloat
+		Status       string
+		Coefficients map[string]float64
+		BestBound    jsonFloat
+		MIPGap       jsonFloat
+		SolveStats   SolveStats
+	}{
+		Objective:    jsonFloat(s.Objective),
+		Status:       s.Status.String(),
+		Coefficients: s.byName,
+		BestBound:    jsonFloat(s.BestBound),
+		MIPGap:       jsonFloat(s.MIPGap),
+		SolveStats:   s.Stats,
+	})
+}
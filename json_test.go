@@ -0,0 +1,103 @@
+package ilp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblem_JSON(t *testing.T) {
+	prob := getLPTestProblem()
+	if _, err := prob.AddSOS1(prob.variables[:2]); !assert.NoError(t, err) {
+		return
+	}
+	prob.BranchingHeuristic(BRANCH_MOST_INFEASIBLE)
+	prob.SetObjectiveOffset(-4)
+
+	data, err := json.Marshal(prob)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var decoded Problem
+	if !assert.NoError(t, json.Unmarshal(data, &decoded)) {
+		return
+	}
+
+	// the constraints refer to the variables and the problem they were decoded into
+	assert.Equal(t, prob.toSolveable(), decoded.toSolveable())
+	assert.Equal(t, BRANCH_MOST_INFEASIBLE, decoded.branchingHeuristic)
+	assert.Empty(t, decoded.Validate())
+	for _, c := range decoded.constraints {
+		assert.True(t, c.problem == &decoded)
+	}
+
+	// encoding the decoded problem yields the same JSON
+	again, err := json.Marshal(decoded)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(data), string(again))
+}
+
+func TestProblem_UnmarshalJSON(t *testing.T) {
+	data := `{
+		"maximize": true,
+		"variables": [
+			{"name": "x", "coefficient": 1, "upper": 2.5},
+			{"name": "y", "coefficient": 2, "integer": true, "lower": "-Infinity"}
+		],
+		"constraints": [
+			{"name": "total", "expressions": [{"variable": "x", "coef": 1}, {"variable": "y", "coef": 1}], "rhs": 4, "inequality": true}
+		]
+	}`
+
+	var prob Problem
+	if !assert.NoError(t, json.Unmarshal([]byte(data), &prob)) {
+		return
+	}
+
+	// the omitted bounds default to those of Problem.AddVariable
+	want := NewProblem()
+	want.Maximize()
+	x := want.AddVariable("x").SetCoeff(1).UpperBound(2.5)
+	y := want.AddFreeVariable("y").SetCoeff(2).IsInteger()
+	want.AddConstraint("").AddExpression(1, x).AddExpression(1, y).SmallerThanOrEqualTo(4)
+	assert.Equal(t, want.toSolveable(), prob.toSolveable())
+
+	// the expressions should refer to declared variables
+	err := json.Unmarshal([]byte(`{"variables": [{"name": "x"}], "constraints": [{"name": "c1", "expressions": [{"variable": "z", "coef": 1}]}]}`), &prob)
+	assert.EqualError(t, err, "constraint c1: unknown variable z")
+}
+
+func TestSolution_MarshalJSON(t *testing.T) {
+	prob := getMPSTestProblem()
+	want, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the decoded problem yields the same objective value
+	data, err := json.Marshal(prob)
+	assert.NoError(t, err)
+	var decoded Problem
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	got, err := decoded.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, want.Objective, got.Objective)
+
+	encoded, err := json.Marshal(got)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(encoded, &fields))
+	assert.Equal(t, got.Objective, fields["Objective"])
+	assert.Equal(t, "OPTIMAL", fields["Status"])
+	assert.Equal(t, float64(0), fields["MIPGap"])
+	assert.Len(t, fields["Coefficients"], len(prob.variables))
+	assert.Contains(t, fields["SolveStats"], "NodesExplored")
+}
@@ -0,0 +1,157 @@
+package ilp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// a subset of the search space of SolveKBest, described by the bounds of the variables, along with its optimal solution.
+type kBestCandidate struct {
+	lower []float64
+	upper []float64
+
+	solution *Solution
+}
+
+// SolveKBest returns up to k integer-feasible solutions of the problem, sorted from best to worst objective value.
+// The solutions differ in the value of at least one integer-constrained variable, so a problem without integer variables yields a single solution.
+// Fewer than k solutions are returned if the problem does not have that many.
+//
+// After the optimal solution of a subset of the search space is found, the remainder of that subset is partitioned
+// by bound cuts on the integer variables (see Lawler 1972, A procedure for computing the K best solutions to discrete optimization problems).
+// For each integer variable x_i with value v_i in the solution, two subsets fix the variables x_1 ... x_{i-1} to their values
+// and require x_i <= v_i - 1 and x_i >= v_i + 1 respectively. Each subset is solved separately, so this takes up to 2 * k * (number of integer variables) solves.
+// The options apply to each of these solves (see Problem.Solve), except that the presolve procedure is always disabled.
+// If a solve fails for another reason than infeasibility, the solutions found until then are returned along with the error.
+func (p Problem) SolveKBest(ctx context.Context, k int, opts ...SolveOption) ([]*Solution, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("k should be positive, got %v", k)
+	}
+
+	lower := make([]float64, len(p.variables))
+	upper := make([]float64, len(p.variables))
+	for i, v := range p.variables {
+		lower[i] = v.lower
+		upper[i] = v.upper
+	}
+
+	root, err := p.solveWithin(ctx, lower, upper, opts)
+	if err != nil || root == nil {
+		return nil, err
+	}
+
+	var solutions []*Solution
+	candidates := []*kBestCandidate{root}
+	for len(solutions) < k && len(candidates) > 0 {
+		// take the candidate with the best objective value
+		best := 0
+		for i, c := range candidates {
+			if p.better(c.solution, candidates[best].solution) {
+				best = i
+			}
+		}
+		next := candidates[best]
+		candidates = append(candidates[:best], candidates[best+1:]...)
+		solutions = append(solutions, next.solution)
+
+		if len(solutions) == k {
+			break
+		}
+
+		partitions, err := p.partition(ctx, next, opts)
+		candidates = append(candidates, partitions...)
+		if err != nil {
+			return solutions, err
+		}
+	}
+
+	sort.SliceStable(solutions, func(i, j int) bool {
+		return p.better(solutions[i], solutions[j])
+	})
+	return solutions, nil
+}
+
+// whether solution a has a better objective value than solution b.
+func (p Problem) better(a, b *Solution) bool {
+	if p.maximize {
+		return a.Objective > b.Objective
+	}
+	return a.Objective < b.Objective
+}
+
+// partition the remainder of the subset of the search space of the candidate, i.e. excluding the integer values of its solution,
+// and solve each of the resulting subsets. Subsets without integer-feasible solutions are omitted.
+func (p Problem) partition(ctx context.Context, c *kBestCandidate, opts []SolveOption) ([]*kBestCandidate, error) {
+	lower := append([]float64(nil), c.lower...)
+	upper := append([]float64(nil), c.upper...)
+
+	var partitions []*kBestCandidate
+	for i, v := range p.variables {
+		if !v.integer {
+			continue
+		}
+
+		val := math.Round(c.solution.byName[v.name])
+
+		// x_i <= v_i - 1
+		below := append([]float64(nil), upper...)
+		below[i] = val - 1
+		candidate, err := p.solveWithin(ctx, lower, below, opts)
+		if err != nil {
+			return partitions, err
+		}
+		if candidate != nil {
+			partitions = append(partitions, candidate)
+		}
+
+		// x_i >= v_i + 1
+		above := append([]float64(nil), lower...)
+		above[i] = val + 1
+		candidate, err = p.solveWithin(ctx, above, upper, opts)
+		if err != nil {
+			return partitions, err
+		}
+		if candidate != nil {
+			partitions = append(partitions, candidate)
+		}
+
+		// the following subsets fix this variable to its value
+		lower[i] = val
+		upper[i] = val
+	}
+	return partitions, nil
+}
+
+// solve a copy of the problem with the provided bounds of the variables.
+// Returns a nil candidate without an error if the subset of the search space described by the bounds has no integer-feasible solution.
+func (p Problem) solveWithin(ctx context.Context, lower, upper []float64, opts []SolveOption) (*kBestCandidate, error) {
+	for i := range lower {
+		if lower[i] > upper[i] {
+			return nil, nil
+		}
+	}
+
+	clone := p.Clone()
+	for i, v := range clone.variables {
+		v.lower = lower[i]
+		v.upper = upper[i]
+	}
+
+	// the presolve procedure is disabled, as it does not detect the infeasibility of subsets in which all variables of a constraint are fixed
+	soln, err := clone.Solve(ctx, append(append([]SolveOption(nil), opts...), WithPresolve(false))...)
+	switch {
+	case errors.Is(err, INITIAL_RELAXATION_NOT_FEASIBLE), errors.Is(err, NO_INTEGER_FEASIBLE_SOLUTION):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	return &kBestCandidate{
+		lower:    append([]float64(nil), lower...),
+		upper:    append([]float64(nil), upper...),
+		solution: soln,
+	}, nil
+}
@@ -0,0 +1,111 @@
+package ilp
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// enumerate the objective values of all integer-feasible assignments of a problem of which all variables are bounded integers.
+func bruteForceObjectives(t *testing.T, p Problem) []float64 {
+	var objectives []float64
+	assignment := make(map[string]float64)
+
+	var enumerate func(i int)
+	enumerate = func(i int) {
+		if i == len(p.variables) {
+			if p.satisfiedBy(assignment) {
+				z, err := p.GetObjectiveValue(assignment)
+				assert.NoError(t, err)
+				objectives = append(objectives, z)
+			}
+			return
+		}
+		v := p.variables[i]
+		for val := v.lower; val <= v.upper; val++ {
+			assignment[v.name] = val
+			enumerate(i + 1)
+		}
+	}
+	enumerate(0)
+	return objectives
+}
+
+func TestProblem_SolveKBest(t *testing.T) {
+	getKnapsack := func() Problem {
+		prob := NewProblem()
+		prob.Maximize()
+		prob.BranchingHeuristic(BRANCH_MOST_INFEASIBLE)
+
+		weights := []float64{3, 4, 5, 2}
+		values := []float64{4, 5, 7, 3}
+		capacity := prob.AddConstraint("capacity")
+		for i := range weights {
+			v := prob.AddBinaryVariable(string(rune('a' + i))).SetCoeff(values[i])
+			capacity.AddExpression(weights[i], v)
+		}
+		capacity.SmallerThanOrEqualTo(9.5)
+		return prob
+	}
+
+	getGeneralInteger := func() Problem {
+		prob := NewProblem()
+		prob.BranchingHeuristic(BRANCH_MOST_INFEASIBLE)
+		x := prob.AddVariable("x").SetCoeff(-3).IsInteger().LowerBound(-1).UpperBound(3)
+		y := prob.AddVariable("y").SetCoeff(-2).IsInteger().UpperBound(4)
+		prob.AddConstraint("").AddExpression(2, x).AddExpression(3, y).SmallerThanOrEqualTo(10.5)
+		return prob
+	}
+
+	tests := []struct {
+		name       string
+		getProblem func() Problem
+		k          int
+	}{
+		{name: "binary knapsack", getProblem: getKnapsack, k: 5},
+		{name: "general integers", getProblem: getGeneralInteger, k: 6},
+		{name: "more than the number of feasible solutions", getProblem: getKnapsack, k: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prob := tt.getProblem()
+
+			want := bruteForceObjectives(t, prob)
+			sort.Float64s(want)
+			if prob.maximize {
+				sort.Sort(sort.Reverse(sort.Float64Slice(want)))
+			}
+			if len(want) > tt.k {
+				want = want[:tt.k]
+			}
+
+			solutions, err := prob.SolveKBest(context.Background(), tt.k)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			var got []float64
+			seen := make(map[string]bool)
+			for _, soln := range solutions {
+				got = append(got, soln.Objective)
+
+				// every solution is feasible and distinct
+				assert.True(t, prob.satisfiedBy(soln.byName), "infeasible solution %v", soln.byName)
+				key := ""
+				for _, v := range prob.variables {
+					key += formatNumber(soln.byName[v.name]) + " "
+				}
+				assert.False(t, seen[key], "duplicate solution %v", key)
+				seen[key] = true
+			}
+
+			// the solutions are ordered by objective value
+			assert.InDeltaSlice(t, want, got, 1e-9)
+		})
+	}
+
+	_, err := getKnapsack().SolveKBest(context.Background(), 0)
+	assert.Error(t, err)
+}
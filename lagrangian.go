@@ -0,0 +1,139 @@
+package ilp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/optimize/convex/lp"
+)
+
+// LagrangianRelax computes a lower bound on the optimal objective value of the problem by Lagrangian relaxation, using the subgradient method.
+// The inequalities of G that involve more than one variable are relaxed into the objective function, weighted by nonnegative multipliers,
+// whereas the inequalities on a single variable (i.e. bounds) and the equalities are kept. For multipliers u, the Lagrangian subproblem
+//
+//	minimize	c^T x + u^T (G x - h)
+//	s.t. 		A x = b, bounds on x, x >= 0
+//
+// is solved as an LP, of which the objective value is a lower bound. The multipliers are then moved along the subgradient G x - h by a diminishing step.
+// The search starts from dualMults, which holds a multiplier for each row of G (nil starts from zero), of which the entries for the kept rows are ignored.
+// The best lower bound found in maxIter iterations is returned along with its multipliers. It excludes the constant term of the objective function.
+// Note that, as the subproblem is solved as an LP, the bound does not exceed the bound of the LP relaxation of the problem.
+// An error is returned if the subproblem is infeasible, or unbounded for every multipliers tried.
+func LagrangianRelax(p milpProblem, dualMults []float64, maxIter int) (lowerBound float64, bestDuals []float64, err error) {
+	if p.G == nil {
+		return 0, nil, errors.New("the problem has no inequalities to relax")
+	}
+	rows, cols := p.G.Dims()
+	if dualMults == nil {
+		dualMults = make([]float64, rows)
+	}
+	if len(dualMults) != rows {
+		return 0, nil, fmt.Errorf("%v multipliers provided for %v inequalities", len(dualMults), rows)
+	}
+
+	// split the inequalities into the ones that are relaxed, and the bounds that are kept
+	var relaxed, kept []int
+	for i := 0; i < rows; i++ {
+		nonzero := 0
+		for j := 0; j < cols; j++ {
+			if p.G.At(i, j) != 0 {
+				nonzero++
+			}
+		}
+		if nonzero > 1 {
+			relaxed = append(relaxed, i)
+		} else {
+			kept = append(kept, i)
+		}
+	}
+
+	subproblem := milpProblem{
+		A:                      p.A,
+		b:                      p.b,
+		integralityConstraints: make([]bool, len(p.c)),
+	}
+	if len(kept) > 0 {
+		G := make([]float64, 0, len(kept)*cols)
+		for _, i := range kept {
+			G = append(G, extendRow(p.G, i, cols)...)
+			subproblem.h = append(subproblem.h, p.h[i])
+		}
+		subproblem.G = NewDenseConstraints(len(kept), cols, G)
+	}
+
+	u := make([]float64, rows)
+	for _, i := range relaxed {
+		u[i] = math.Max(0, dualMults[i])
+	}
+
+	lowerBound = math.Inf(-1)
+	subgradient := make([]float64, rows)
+	for iter := 0; iter < maxIter; iter++ {
+		// c + G^T u, and the constant term -u^T h
+		subproblem.c = append(subproblem.c[:0], p.c...)
+		constant := 0.0
+		for _, i := range relaxed {
+			for j := 0; j < cols; j++ {
+				subproblem.c[j] += u[i] * p.G.At(i, j)
+			}
+			constant -= u[i] * p.h[i]
+		}
+
+		z, x, err := subproblem.solveLP()
+		if err != nil {
+			// the multipliers found so far yield a bound, even though the subproblem is unbounded for the current ones
+			if !math.IsInf(lowerBound, -1) && errors.Is(err, lp.ErrUnbounded) {
+				break
+			}
+			return 0, nil, err
+		}
+
+		if bound := z + constant; bound > lowerBound {
+			lowerBound = bound
+			bestDuals = append(bestDuals[:0], u...)
+		}
+
+		// the subgradient of the relaxed inequalities is their violation by the solution to the subproblem.
+		// Multipliers that are zero are not decreased any further.
+		for _, i := range relaxed {
+			subgradient[i] = floats.Dot(extendRow(p.G, i, cols), x) - p.h[i]
+			if u[i] == 0 && subgradient[i] < 0 {
+				subgradient[i] = 0
+			}
+		}
+		norm := floats.Norm(subgradient, 2)
+		if norm == 0 {
+			// the solution to the subproblem satisfies the relaxed inequalities and complementary slackness, so the bound cannot be improved
+			break
+		}
+
+		step := math.Max(1, math.Abs(lowerBound)) / (float64(iter+1) * norm)
+		for _, i := range relaxed {
+			u[i] = math.Max(0, u[i]+step*subgradient[i])
+		}
+	}
+
+	if bestDuals == nil {
+		return lowerBound, append([]float64(nil), u...), nil
+	}
+	return lowerBound, bestDuals, nil
+}
+
+// solve the Lagrangian subproblem as an LP, returning its objective value and the values of the columns of c.
+// Without any constraints, it is unbounded unless none of the objective coefficients is negative, in which case zero is optimal.
+func (p milpProblem) solveLP() (float64, []float64, error) {
+	if p.A == nil && p.G == nil {
+		if floats.Min(p.c) < 0 {
+			return 0, nil, lp.ErrUnbounded
+		}
+		return 0, make([]float64, len(p.c)), nil
+	}
+
+	s := p.toInitialSubproblem().solve()
+	if s.err != nil {
+		return 0, nil, s.err
+	}
+	return s.z, s.x[:len(p.c)], nil
+}
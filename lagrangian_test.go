@@ -0,0 +1,85 @@
+package ilp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/optimize/convex/lp"
+)
+
+func TestLagrangianRelax(t *testing.T) {
+	tests := []struct {
+		name string
+		prob milpProblem
+	}{
+		{
+			name: "knapsack with bounds",
+			prob: milpProblem{
+				c: []float64{-4, -2, -8},
+				G: NewDenseConstraints(5, 3, []float64{
+					8, 6, 1,
+					3, 4, 9,
+					1, 0, 0,
+					0, 1, 0,
+					0, 0, 1,
+				}),
+				h:                      []float64{33.5, 25.5, 3, 3, 2},
+				integralityConstraints: []bool{true, true, true},
+				branchingHeuristic:     BRANCH_MOST_INFEASIBLE,
+			},
+		},
+		{
+			name: "with equality",
+			prob: milpProblem{
+				c: []float64{-1, -2, -1},
+				A: NewDenseConstraints(1, 3, []float64{1, 0, 1}),
+				b: []float64{2},
+				G: NewDenseConstraints(3, 3, []float64{
+					2, 3, 0,
+					0, 1, 0,
+					1, 1, 1,
+				}),
+				h:                      []float64{7.5, 2, 3.5},
+				integralityConstraints: []bool{true, true, true},
+				branchingHeuristic:     BRANCH_MOST_INFEASIBLE,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			optimal, err := tt.prob.solve(context.Background(), 1, dummyMiddleware{})
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			bound, duals, err := LagrangianRelax(tt.prob, nil, 50)
+			if !assert.NoError(t, err) {
+				return
+			}
+			rows, _ := tt.prob.G.Dims()
+			assert.Len(t, duals, rows)
+			for _, u := range duals {
+				assert.True(t, u >= 0)
+			}
+			assert.True(t, bound <= optimal.BestIntegerSolution.z+1e-9, "bound %v exceeds the optimal objective value %v", bound, optimal.BestIntegerSolution.z)
+			assert.True(t, bound <= optimal.LPRelaxation.z+1e-9, "bound %v exceeds the LP bound %v", bound, optimal.LPRelaxation.z)
+
+			// starting from the best multipliers does not yield a worse bound
+			restarted, _, err := LagrangianRelax(tt.prob, duals, 1)
+			assert.NoError(t, err)
+			assert.InDelta(t, bound, restarted, 1e-9)
+		})
+	}
+
+	prob := tests[0].prob
+	_, _, err := LagrangianRelax(prob, []float64{1}, 10)
+	assert.EqualError(t, err, "1 multipliers provided for 5 inequalities")
+
+	// without bounds, the subproblem is unbounded if no inequalities are kept
+	prob.G = NewDenseConstraints(2, 3, []float64{8, 6, 1, 3, 4, 9})
+	prob.h = []float64{33.5, 25.5}
+	_, _, err = LagrangianRelax(prob, nil, 10)
+	assert.True(t, errors.Is(err, lp.ErrUnbounded), "unexpected error %v", err)
+}
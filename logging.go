@@ -0,0 +1,76 @@
+package ilp
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+)
+
+var (
+	loggerMu sync.RWMutex
+
+	// the logger of the package, which discards all records unless replaced by SetLogger
+	logger = discardLogger()
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// SetLogger sets the structured logger to which the solver reports the key events of each solve, i.e. the summary of the presolve procedure,
+// the solution of the initial relaxation, each new incumbent and the final result at level Info, and each pruned node at level Debug.
+// The objective values are those of the minimization problem solved by the branch-and-bound procedure, so the objective values of a maximization problem are negated.
+// By default, nothing is logged. Passing nil restores the default.
+func SetLogger(l *slog.Logger) {
+	if l == nil {
+		l = discardLogger()
+	}
+
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	logger = l
+}
+
+// the logger set by SetLogger.
+func getLogger() *slog.Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	return logger
+}
+
+// whether the decision prunes the subProblem, i.e. does not lead to either a new incumbent or new subProblems.
+func (d bnbDecision) prunes() bool {
+	switch d {
+	case BETTER_THAN_INCUMBENT_BRANCHING, BETTER_THAN_INCUMBENT_FEASIBLE, INITIAL_RX_FEASIBLE_FOR_IP, VIOLATES_LAZY_CONSTRAINTS:
+		return false
+	}
+	return true
+}
+
+// log the summary of the presolve procedure.
+func logPresolve(stats PresolveStats) {
+	getLogger().Info("presolve finished",
+		"originalVariables", stats.OriginalVariables,
+		"reducedVariables", stats.ReducedVariables,
+		"originalConstraints", stats.OriginalConstraints,
+		"reducedConstraints", stats.ReducedConstraints,
+		"passes", stats.Passes,
+		"duration", stats.Duration,
+	)
+}
+
+// log the final result of the branch-and-bound procedure.
+func logResult(result MIPResult, err error) {
+	attrs := []interface{}{
+		"status", result.Status.String(),
+		"nodes", result.Stats.NodesExplored,
+		"wallTime", result.Stats.WallTime,
+	}
+	if result.BestIntegerSolution != nil {
+		attrs = append(attrs, "objective", result.BestIntegerSolution.z, "bestBound", result.BestIntegerSolution.bestBound)
+	}
+	if err != nil {
+		attrs = append(attrs, "error", err.Error())
+	}
+	getLogger().Info("solve finished", attrs...)
+}
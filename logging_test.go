@@ -0,0 +1,68 @@
+package ilp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetLogger(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer SetLogger(nil)
+
+	prob, _, _, _ := getTestKnapsackProblem()
+	soln, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the keys of the first record of each message
+	records := make(map[string]map[string]interface{})
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if !assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record)) {
+			return
+		}
+		msg := record["msg"].(string)
+		if _, ok := records[msg]; !ok {
+			records[msg] = record
+		}
+	}
+
+	expected := map[string][]string{
+		"presolve finished":         {"originalVariables", "reducedVariables", "originalConstraints", "reducedConstraints", "passes", "duration"},
+		"initial relaxation solved": {"objective"},
+		"new incumbent":             {"objective", "node"},
+		"node pruned":               {"node", "reason"},
+		"solve finished":            {"status", "nodes", "wallTime", "objective", "bestBound"},
+	}
+	for msg, keys := range expected {
+		record, ok := records[msg]
+		if !assert.True(t, ok, "no record with message %q", msg) {
+			continue
+		}
+		for _, key := range keys {
+			assert.Contains(t, record, key, "record with message %q", msg)
+		}
+	}
+	assert.Equal(t, "OPTIMAL", records["solve finished"]["status"])
+	assert.Equal(t, float64(soln.Stats.NodesExplored), records["solve finished"]["nodes"])
+
+	// the objective value of the maximization problem is negated
+	assert.Equal(t, -soln.Objective, records["solve finished"]["objective"])
+	assert.Equal(t, "DEBUG", records["node pruned"]["level"])
+
+	// nothing is logged by default
+	SetLogger(nil)
+	buf.Reset()
+	_, err = prob.Solve(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, buf.Len())
+}
@@ -0,0 +1,743 @@
+package ilp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseLP reads a problem in CPLEX LP format.
+// The objective (Minimize/Maximize), Subject To, Bounds, General and Binary sections are supported,
+// as well as the End keyword and backslash comments. Variables are added to the problem in the order in which they first appear.
+// The names of the constraints are retained, whereas the name of the objective is discarded.
+// Constant terms in the objective are summed into the objective offset.
+// Errors indicate the line number and token at which parsing failed.
+func ParseLP(r io.Reader) (*Problem, error) {
+	tokens, err := tokenizeLP(r)
+	if err != nil {
+		return nil, err
+	}
+
+	prob := NewProblem()
+	parser := &lpParser{
+		tokens:    tokens,
+		problem:   &prob,
+		variables: make(map[string]*Variable),
+	}
+
+	if err := parser.parse(); err != nil {
+		return nil, err
+	}
+
+	return &prob, nil
+}
+
+type lpTokenKind int
+
+const (
+	lpIdentifier lpTokenKind = iota
+	lpNumber
+	lpOperator
+	lpSign
+	lpColon
+	lpSection
+)
+
+type lpToken struct {
+	kind lpTokenKind
+	text string
+	line int
+
+	// the value of a number
+	value float64
+
+	// the normalized name of a section keyword
+	section string
+}
+
+// the sections of an LP file, keyed by their (lowercase) keywords. Keywords consisting of two words are joined by a space.
+var lpSections = map[string]string{
+	"minimize":   "minimize",
+	"minimise":   "minimize",
+	"minimum":    "minimize",
+	"min":        "minimize",
+	"maximize":   "maximize",
+	"maximise":   "maximize",
+	"maximum":    "maximize",
+	"max":        "maximize",
+	"subject to": "constraints",
+	"such that":  "constraints",
+	"st":         "constraints",
+	"s.t.":       "constraints",
+	"st.":        "constraints",
+	"bounds":     "bounds",
+	"bound":      "bounds",
+	"general":    "general",
+	"generals":   "general",
+	"gen":        "general",
+	"integer":    "general",
+	"integers":   "general",
+	"binary":     "binary",
+	"binaries":   "binary",
+	"bin":        "binary",
+	"end":        "end",
+}
+
+// split an LP file into tokens, dropping comments.
+func tokenizeLP(r io.Reader) ([]lpToken, error) {
+	var tokens []lpToken
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '\\'); i >= 0 {
+			text = text[:i]
+		}
+
+		lineTokens, err := tokenizeLPLine(text, line)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, lineTokens...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// characters that may appear in variable and constraint names, besides letters and digits.
+const lpNameChars = "!\"#$%&()/,.;?@_`'{}|~[]"
+
+func isLPNameChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(lpNameChars, r)
+}
+
+func tokenizeLPLine(text string, line int) ([]lpToken, error) {
+	var tokens []lpToken
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '+' || r == '-':
+			tokens = append(tokens, lpToken{kind: lpSign, text: string(r), line: line})
+			i++
+
+		case r == ':':
+			tokens = append(tokens, lpToken{kind: lpColon, text: ":", line: line})
+			i++
+
+		case r == '<' || r == '>' || r == '=':
+			op := string(r)
+			if i+1 < len(runes) && (runes[i+1] == '=' || runes[i+1] == '<' || runes[i+1] == '>') {
+				op += string(runes[i+1])
+			}
+			i += len([]rune(op))
+
+			switch op {
+			case "<", "<=", "=<":
+				op = "<="
+			case ">", ">=", "=>":
+				op = ">="
+			case "=":
+			default:
+				return nil, fmt.Errorf("line %d: invalid operator %q", line, op)
+			}
+			tokens = append(tokens, lpToken{kind: lpOperator, text: op, line: line})
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// only consume an exponent if it is followed by digits, so that e.g. 2e is read as the number 2 followed by the variable e
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				j := i + 1
+				if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+					j++
+				}
+				if j < len(runes) && unicode.IsDigit(runes[j]) {
+					for j < len(runes) && unicode.IsDigit(runes[j]) {
+						j++
+					}
+					i = j
+				}
+			}
+
+			text := string(runes[start:i])
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid number %q", line, text)
+			}
+			tokens = append(tokens, lpToken{kind: lpNumber, text: text, value: value, line: line})
+
+		case isLPNameChar(r):
+			start := i
+			for i < len(runes) && isLPNameChar(runes[i]) {
+				i++
+			}
+			text := string(runes[start:i])
+
+			lower := strings.ToLower(text)
+			switch {
+			case lower == "inf" || lower == "infinity":
+				tokens = append(tokens, lpToken{kind: lpNumber, text: text, value: math.Inf(1), line: line})
+			default:
+				tokens = append(tokens, lpToken{kind: lpIdentifier, text: text, line: line})
+			}
+
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", line, r)
+		}
+	}
+
+	// section keywords are only recognized at the start of a line, such that they can still be used as variable names elsewhere
+	if len(tokens) > 1 && tokens[0].kind == lpIdentifier && tokens[1].kind == lpIdentifier {
+		if section, ok := lpSections[strings.ToLower(tokens[0].text+" "+tokens[1].text)]; ok {
+			text := tokens[0].text + " " + tokens[1].text
+			tokens = append([]lpToken{{kind: lpSection, text: text, section: section, line: line}}, tokens[2:]...)
+			return tokens, nil
+		}
+	}
+	if len(tokens) > 0 && tokens[0].kind == lpIdentifier {
+		if section, ok := lpSections[strings.ToLower(tokens[0].text)]; ok {
+			tokens[0].kind = lpSection
+			tokens[0].section = section
+		}
+	}
+
+	return tokens, nil
+}
+
+type lpParser struct {
+	tokens []lpToken
+	pos    int
+
+	problem   *Problem
+	variables map[string]*Variable
+}
+
+// a term of a linear expression
+type lpTerm struct {
+	coef     float64
+	variable *Variable
+}
+
+func (p *lpParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *lpParser) peek() lpToken {
+	return p.tokens[p.pos]
+}
+
+// the error for the current token, or for the end of the file if all tokens have been consumed.
+func (p *lpParser) errorf(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if p.done() {
+		line := 0
+		if len(p.tokens) > 0 {
+			line = p.tokens[len(p.tokens)-1].line
+		}
+		return fmt.Errorf("line %d: unexpected end of file: %s", line, msg)
+	}
+	t := p.peek()
+	return fmt.Errorf("line %d: unexpected token %q: %s", t.line, t.text, msg)
+}
+
+// get the variable with the provided name, adding it to the problem if it does not exist yet.
+func (p *lpParser) variable(name string) *Variable {
+	v, ok := p.variables[name]
+	if !ok {
+		v = p.problem.AddVariable(name)
+		p.variables[name] = v
+	}
+	return v
+}
+
+func (p *lpParser) parse() error {
+	if p.done() || p.peek().kind != lpSection || (p.peek().section != "minimize" && p.peek().section != "maximize") {
+		return p.errorf("expected the objective section (Minimize or Maximize)")
+	}
+
+	for !p.done() {
+		section := p.peek()
+		if section.kind != lpSection {
+			return p.errorf("expected a section keyword")
+		}
+		p.pos++
+
+		var err error
+		switch section.section {
+		case "minimize", "maximize":
+			if section.section == "maximize" {
+				p.problem.Maximize()
+			}
+			err = p.parseObjective()
+		case "constraints":
+			err = p.parseConstraints()
+		case "bounds":
+			err = p.parseBounds()
+		case "general":
+			err = p.parseIntegers(false)
+		case "binary":
+			err = p.parseIntegers(true)
+		case "end":
+			if !p.done() {
+				return p.errorf("expected the end of the file after End")
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parse the name of a constraint or objective, if any. Returns an empty string if there is no name.
+func (p *lpParser) parseLabel() string {
+	if p.pos+1 < len(p.tokens) && p.peek().kind == lpIdentifier && p.tokens[p.pos+1].kind == lpColon {
+		name := p.peek().text
+		p.pos += 2
+		return name
+	}
+	return ""
+}
+
+func (p *lpParser) parseObjective() error {
+	p.parseLabel()
+
+	terms, constant, err := p.parseExpression()
+	if err != nil {
+		return err
+	}
+
+	for _, t := range terms {
+		t.variable.SetCoeff(t.variable.coefficient + t.coef)
+	}
+
+	// constant terms make up the objective offset
+	p.problem.SetObjectiveOffset(p.problem.objectiveOffset + constant)
+	return nil
+}
+
+// parse a linear expression up to the next operator or section keyword.
+// Returns the terms and the sum of the constant terms.
+func (p *lpParser) parseExpression() ([]lpTerm, float64, error) {
+	var terms []lpTerm
+	var constant float64
+
+	for first := true; !p.done(); first = false {
+		t := p.peek()
+		if t.kind == lpOperator || t.kind == lpSection {
+			break
+		}
+
+		// a new constraint starts with its name
+		if t.kind == lpIdentifier && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].kind == lpColon {
+			break
+		}
+
+		// the sign of the term, which may consist of multiple sign tokens
+		sign := 1.0
+		signed := false
+		for !p.done() && p.peek().kind == lpSign {
+			if p.peek().text == "-" {
+				sign = -sign
+			}
+			signed = true
+			p.pos++
+		}
+
+		// every term except the first must be preceded by a sign
+		if !first && !signed {
+			return nil, 0, p.errorf("expected + or - between terms")
+		}
+
+		if p.done() {
+			return nil, 0, p.errorf("expected a term")
+		}
+
+		coef := sign
+		if p.peek().kind == lpNumber {
+			coef *= p.peek().value
+			p.pos++
+
+			// a number that is not followed by a variable is a constant
+			if p.done() || p.peek().kind != lpIdentifier || (p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].kind == lpColon) {
+				constant += coef
+				continue
+			}
+		}
+
+		if p.peek().kind != lpIdentifier {
+			return nil, 0, p.errorf("expected a variable")
+		}
+		terms = append(terms, lpTerm{coef: coef, variable: p.variable(p.peek().text)})
+		p.pos++
+	}
+
+	return terms, constant, nil
+}
+
+// parse a signed number, which may be infinite.
+func (p *lpParser) parseNumber() (float64, error) {
+	sign := 1.0
+	for !p.done() && p.peek().kind == lpSign {
+		if p.peek().text == "-" {
+			sign = -sign
+		}
+		p.pos++
+	}
+
+	if p.done() || p.peek().kind != lpNumber {
+		return 0, p.errorf("expected a number")
+	}
+	value := sign * p.peek().value
+	p.pos++
+	return value, nil
+}
+
+func (p *lpParser) parseConstraints() error {
+	for !p.done() && p.peek().kind != lpSection {
+		name := p.parseLabel()
+
+		terms, constant, err := p.parseExpression()
+		if err != nil {
+			return err
+		}
+		if len(terms) == 0 {
+			return p.errorf("expected a constraint with at least one variable")
+		}
+
+		if p.done() || p.peek().kind != lpOperator {
+			return p.errorf("expected <=, >= or =")
+		}
+		op := p.peek().text
+		p.pos++
+
+		rhs, err := p.parseNumber()
+		if err != nil {
+			return err
+		}
+
+		// constants on the left-hand side are moved to the right-hand side
+		rhs -= constant
+
+		c := p.problem.AddConstraint(name)
+		for _, t := range terms {
+			c.AddExpression(t.coef, t.variable)
+		}
+
+		switch op {
+		case "<=":
+			c.SmallerThanOrEqualTo(rhs)
+		case ">=":
+			c.GreaterThanOrEqualTo(rhs)
+		default:
+			c.EqualTo(rhs)
+		}
+	}
+
+	return nil
+}
+
+func (p *lpParser) parseBounds() error {
+	for !p.done() && p.peek().kind != lpSection {
+		// x free
+		if p.peek().kind == lpIdentifier && p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].kind == lpIdentifier && strings.ToLower(p.tokens[p.pos+1].text) == "free" {
+			p.variable(p.peek().text).LowerBound(math.Inf(-1)).UpperBound(math.Inf(1))
+			p.pos += 2
+			continue
+		}
+
+		// l <= x [<= u]
+		if p.peek().kind == lpNumber || p.peek().kind == lpSign {
+			lower, err := p.parseNumber()
+			if err != nil {
+				return err
+			}
+			if p.done() || p.peek().kind != lpOperator || p.peek().text != "<=" {
+				return p.errorf("expected <= after the lower bound")
+			}
+			p.pos++
+
+			v, err := p.parseBoundVariable()
+			if err != nil {
+				return err
+			}
+			v.LowerBound(lower)
+
+			if !p.done() && p.peek().kind == lpOperator {
+				if p.peek().text != "<=" {
+					return p.errorf("expected <= before the upper bound")
+				}
+				p.pos++
+
+				upper, err := p.parseNumber()
+				if err != nil {
+					return err
+				}
+				v.UpperBound(upper)
+			}
+			continue
+		}
+
+		// x <= u, x >= l or x = v
+		v, err := p.parseBoundVariable()
+		if err != nil {
+			return err
+		}
+		if p.done() || p.peek().kind != lpOperator {
+			return p.errorf("expected <=, >= or = after the variable")
+		}
+		op := p.peek().text
+		p.pos++
+
+		value, err := p.parseNumber()
+		if err != nil {
+			return err
+		}
+
+		switch op {
+		case "<=":
+			v.UpperBound(value)
+		case ">=":
+			v.LowerBound(value)
+		default:
+			v.LowerBound(value).UpperBound(value)
+		}
+	}
+
+	return nil
+}
+
+func (p *lpParser) parseBoundVariable() (*Variable, error) {
+	if p.done() || p.peek().kind != lpIdentifier {
+		return nil, p.errorf("expected a variable")
+	}
+	v := p.variable(p.peek().text)
+	p.pos++
+	return v, nil
+}
+
+// parse a list of integer variables. Binary variables are additionally bounded by [0, 1].
+func (p *lpParser) parseIntegers(binary bool) error {
+	for !p.done() && p.peek().kind != lpSection {
+		v, err := p.parseBoundVariable()
+		if err != nil {
+			return err
+		}
+
+		v.IsInteger()
+		if binary {
+			v.LowerBound(0).UpperBound(1)
+			v.isBinary = true
+		}
+	}
+
+	return nil
+}
+
+// ExportLP writes the problem to w in CPLEX LP format.
+// Unnamed constraints are named c1, c2, etc. after their position. Note that the names of named constraints should be valid LP names.
+// Every variable is listed in the objective, even if its coefficient is zero, such that ParseLP declares the variables in the same order.
+// A nonzero objective offset is written as a constant term at the end of the objective.
+// Variables that do not appear in any constraint are always listed in the Bounds section, even if their bounds are the default [0, +Inf).
+func (p *Problem) ExportLP(w io.Writer) error {
+	lw := &lpWriter{w: bufio.NewWriter(w)}
+
+	if p.maximize {
+		lw.printf("Maximize\n")
+	} else {
+		lw.printf("Minimize\n")
+	}
+
+	objective := make([]expression, len(p.variables))
+	for i, v := range p.variables {
+		objective[i] = expression{coef: v.coefficient, variable: v}
+	}
+	lw.expression("obj", objective)
+
+	// the objective offset is written as a constant term
+	if offset := p.objectiveOffset; offset != 0 {
+		sign := "+"
+		if offset < 0 {
+			sign = "-"
+			offset = -offset
+		}
+		lw.printf(" %s %s", sign, formatNumber(offset))
+	}
+	lw.printf("\n")
+
+	inConstraint := make(map[*Variable]bool)
+	lw.printf("Subject To\n")
+	for i, c := range p.constraints {
+		exprs := c.lhsExpressions()
+		for _, e := range exprs {
+			inConstraint[e.variable] = true
+		}
+
+		op := "="
+		switch {
+		case c.inequality && c.greaterThanOrEqual:
+			op = ">="
+		case c.inequality:
+			op = "<="
+		}
+
+		name := c.name
+		if name == "" {
+			name = fmt.Sprintf("c%d", i+1)
+		}
+		lw.expression(name, exprs)
+		lw.printf(" %s %s\n", op, formatNumber(c.rhs))
+	}
+
+	var bounds, generals, binaries []string
+	for _, v := range p.variables {
+		binary := v.integer && v.lower == 0 && v.upper == 1
+		switch {
+		case binary:
+			binaries = append(binaries, v.name)
+		case v.integer:
+			generals = append(generals, v.name)
+		}
+
+		// the bounds of binary variables are implied by the Binary section
+		if binary {
+			continue
+		}
+		if b := lpBound(v); b != "" {
+			bounds = append(bounds, b)
+		} else if !inConstraint[v] {
+			bounds = append(bounds, fmt.Sprintf("%s >= 0", v.name))
+		}
+	}
+
+	if len(bounds) > 0 {
+		lw.printf("Bounds\n")
+		for _, b := range bounds {
+			lw.printf(" %s\n", b)
+		}
+	}
+	lw.list("Generals", generals)
+	lw.list("Binary", binaries)
+
+	lw.printf("End\n")
+
+	if lw.err != nil {
+		return lw.err
+	}
+	return lw.w.Flush()
+}
+
+// the Bounds entry that describes the bounds of the variable, or an empty string if the bounds are the default [0, +Inf).
+func lpBound(v *Variable) string {
+	lowerInf := math.IsInf(v.lower, -1)
+	upperInf := math.IsInf(v.upper, 1)
+
+	switch {
+	case v.lower == v.upper:
+		return fmt.Sprintf("%s = %s", v.name, formatNumber(v.lower))
+	case lowerInf && upperInf:
+		return fmt.Sprintf("%s free", v.name)
+	case lowerInf:
+		return fmt.Sprintf("-inf <= %s <= %s", v.name, formatNumber(v.upper))
+	case upperInf && v.lower == 0:
+		return ""
+	case upperInf:
+		return fmt.Sprintf("%s >= %s", v.name, formatNumber(v.lower))
+	// some readers interpret a negative upper bound as a lower bound of -Inf if the lower bound is not explicitly set
+	case v.lower == 0 && v.upper >= 0:
+		return fmt.Sprintf("%s <= %s", v.name, formatNumber(v.upper))
+	default:
+		return fmt.Sprintf("%s <= %s <= %s", formatNumber(v.lower), v.name, formatNumber(v.upper))
+	}
+}
+
+// the maximum length of a line in the LP output, after which expressions are continued on the next line.
+const lpMaxLineLength = 255
+
+// writes the sections of an LP file, retaining the first error encountered.
+type lpWriter struct {
+	w   *bufio.Writer
+	err error
+
+	// the length of the current line
+	length int
+}
+
+// write a named linear expression, wrapping long expressions over multiple lines.
+func (l *lpWriter) expression(name string, exprs []expression) {
+	l.printf(" %s:", name)
+	for i, e := range exprs {
+		sign := "+"
+		coef := e.coef
+		if coef < 0 {
+			sign = "-"
+			coef = -coef
+		}
+
+		term := fmt.Sprintf("%s %s", formatNumber(coef), e.variable.name)
+		switch {
+		case i == 0 && sign == "+":
+		case i == 0:
+			term = sign + term
+		default:
+			term = sign + " " + term
+		}
+
+		if l.length+len(term)+1 > lpMaxLineLength {
+			l.printf("\n ")
+		}
+		l.printf(" %s", term)
+	}
+}
+
+// write a section listing the provided variable names, if any.
+func (l *lpWriter) list(section string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	l.printf("%s\n", section)
+	for i, name := range names {
+		if i == 0 {
+			l.printf(" %s", name)
+			continue
+		}
+		if l.length+len(name)+1 > lpMaxLineLength {
+			l.printf("\n")
+		}
+		l.printf(" %s", name)
+	}
+	l.printf("\n")
+}
+
+func (l *lpWriter) printf(format string, args ...interface{}) {
+	if l.err != nil {
+		return
+	}
+
+	s := fmt.Sprintf(format, args...)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		l.length = len(s) - i - 1
+	} else {
+		l.length += len(s)
+	}
+
+	_, l.err = l.w.WriteString(s)
+}
@@ -0,0 +1,171 @@
+package ilp
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLP(t *testing.T) {
+	lp := `\ a problem with all supported sections
+Maximize
+ obj: 3 x + 2 y - z + w - 4 + 1.5
+Subject To
+ c1: x + y <= 10
+ c2: 2 y - z
+     = 0
+ x + w + v >= -1
+ c4: - x + 2.5e-1 y =< 3
+Bounds
+ x <= 4
+ z free
+ -2 <= w <= 5
+ v = 1.5
+Generals
+ y w
+Binary
+ b
+End
+`
+	got, err := ParseLP(strings.NewReader(lp))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the same problem, built using the API
+	want := NewProblem()
+	want.Maximize()
+	want.SetObjectiveOffset(-2.5)
+
+	x := want.AddVariable("x").SetCoeff(3).UpperBound(4)
+	y := want.AddVariable("y").SetCoeff(2).IsInteger()
+	z := want.AddFreeVariable("z").SetCoeff(-1)
+	w := want.AddVariable("w").SetCoeff(1).IsInteger().LowerBound(-2).UpperBound(5)
+	v := want.AddVariable("v").LowerBound(1.5).UpperBound(1.5)
+	want.AddBinaryVariable("b")
+
+	want.AddConstraint("").AddExpression(1, x).AddExpression(1, y).SmallerThanOrEqualTo(10)
+	want.AddConstraint("").AddExpression(2, y).AddExpression(-1, z).EqualTo(0)
+	want.AddConstraint("").AddExpression(1, x).AddExpression(1, w).AddExpression(1, v).GreaterThanOrEqualTo(-1)
+	want.AddConstraint("").AddExpression(-1, x).AddExpression(0.25, y).SmallerThanOrEqualTo(3)
+
+	assert.Equal(t, want.toSolveable(), got.toSolveable())
+
+	// the names of the constraints are retained
+	var names []string
+	for _, c := range got.constraints {
+		names = append(names, c.Name())
+	}
+	assert.Equal(t, []string{"c1", "c2", "", "c4"}, names)
+}
+
+func TestParseLP_errors(t *testing.T) {
+	cases := []struct {
+		name string
+		lp   string
+		err  string
+	}{
+		{
+			name: "missing objective",
+			lp:   "Subject To\n x <= 1\nEnd",
+			err:  `line 1: unexpected token "Subject To": expected the objective section (Minimize or Maximize)`,
+		},
+		{
+			name: "missing operator",
+			lp:   "Minimize\n x\nSubject To\n c1: x + y\n 3\nEnd",
+			err:  `line 5: unexpected token "3": expected + or - between terms`,
+		},
+		{
+			name: "missing right-hand side",
+			lp:   "Minimize\n x\nSubject To\n c1: x + y <= z\nEnd",
+			err:  `line 4: unexpected token "z": expected a number`,
+		},
+		{
+			name: "invalid character",
+			lp:   "Minimize\n x * y\nEnd",
+			err:  `line 2: unexpected character '*'`,
+		},
+		{
+			name: "unterminated bound",
+			lp:   "Minimize\n x\nBounds\n x <=",
+			err:  `line 4: unexpected end of file: expected a number`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseLP(strings.NewReader(tc.lp))
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func getLPTestProblem() Problem {
+	prob := getMPSTestProblem()
+
+	// a binary indicator variable and a variable that does not appear in any constraint
+	b := prob.AddBinaryVariable("b")
+	prob.AddVariable("orphan").SetCoeff(0.1)
+
+	prob.AddConstraint("").AddExpression(1, prob.variables[0]).SmallerThanOrEqualTo(0).BigM(b, 1e6)
+
+	return prob
+}
+
+func TestExportLP(t *testing.T) {
+	prob := getLPTestProblem()
+
+	var buf strings.Builder
+	assert.NoError(t, prob.ExportLP(&buf))
+
+	want := `Maximize
+ obj: 3 x + 2 y - 1 z + 1 w + 0 v + 0 b + 0.1 orphan
+Subject To
+ c1: 1 x + 1 y <= 10
+ c2: 2 y - 1 z = 0
+ c3: 1 w + 1 v + 1 x >= -1
+ c4: 1 x - 1e+06 b <= 0
+Bounds
+ x <= 4
+ z free
+ -2 <= w <= 5
+ v = 1.5
+ orphan >= 0
+Generals
+ y w
+Binary
+ b
+End
+`
+	assert.Equal(t, want, buf.String())
+}
+
+func TestExportLP_roundTrip(t *testing.T) {
+	prob := getLPTestProblem()
+	prob.SetObjectiveOffset(-12.5)
+
+	// add a long constraint, which is wrapped over multiple lines
+	long := prob.AddConstraint("")
+	for i := 0; i < 100; i++ {
+		long.AddExpression(1.0/3, prob.AddVariable(fmt.Sprintf("long%d", i)).LowerBound(math.Inf(-1)).UpperBound(float64(-i)))
+	}
+	long.GreaterThanOrEqualTo(-math.Pi)
+
+	var buf bytes.Buffer
+	assert.NoError(t, prob.ExportLP(&buf))
+
+	for _, line := range strings.Split(buf.String(), "\n") {
+		assert.True(t, len(line) <= lpMaxLineLength, "line exceeds the maximum length: %v", line)
+	}
+
+	parsed, err := ParseLP(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, prob.toSolveable(), parsed.toSolveable())
+}
@@ -0,0 +1,32 @@
+package ilp
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize/convex/lp"
+)
+
+// LPSolver solves the LP relaxations of the subProblems of the branch-and-bound procedure, which are passed in standard form:
+//
+//	minimize	c^T x
+//	s.t. 		A * x = b
+//				x >= 0 .
+//
+// It returns the optimal objective value z and the optimal solution x, which holds a value for each column of A.
+// An infeasible or unbounded LP should be reported by returning lp.ErrInfeasible or lp.ErrUnbounded (or an error wrapping them),
+// so that the solver can tell the subProblems that are pruned from the ones that failed.
+// The arguments are reused once Solve returns, so the implementation should not retain them, and should return a newly allocated x.
+// Solve is called concurrently by the workers of the branch-and-bound procedure.
+type LPSolver interface {
+	Solve(c []float64, A *mat.Dense, b []float64) (z float64, x []float64, err error)
+}
+
+// SimplexSolver is an LPSolver that uses the simplex method of gonum (lp.Simplex).
+type SimplexSolver struct{}
+
+func (SimplexSolver) Solve(c []float64, A *mat.Dense, b []float64) (float64, []float64, error) {
+	return lp.Simplex(c, A, b, 0, nil)
+}
+
+// DefaultLPSolver solves every LP relaxation, e.g. of the subProblems of the branch-and-bound procedure and of the heuristics.
+// It can be replaced by an alternative backend, but it should not be modified while a problem is being solved.
+var DefaultLPSolver LPSolver = SimplexSolver{}
@@ -0,0 +1,106 @@
+package ilp
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize/convex/lp"
+)
+
+// an LPSolver that records the LPs it is asked to solve, and returns the result of the solve function for each of them.
+type mockLPSolver struct {
+	mu    sync.Mutex
+	calls int
+	sizes [][2]int
+
+	solve func(c []float64, A *mat.Dense, b []float64) (float64, []float64, error)
+}
+
+func (m *mockLPSolver) Solve(c []float64, A *mat.Dense, b []float64) (float64, []float64, error) {
+	m.mu.Lock()
+	m.calls++
+	m.sizes = append(m.sizes, [2]int{len(b), len(c)})
+	m.mu.Unlock()
+	return m.solve(c, A, b)
+}
+
+// replace the DefaultLPSolver for the duration of the test.
+func useLPSolver(t *testing.T, solver LPSolver) {
+	previous := DefaultLPSolver
+	DefaultLPSolver = solver
+	t.Cleanup(func() { DefaultLPSolver = previous })
+}
+
+func Test_subProblem_solve_DefaultLPSolver(t *testing.T) {
+	mock := &mockLPSolver{solve: func(c []float64, A *mat.Dense, b []float64) (float64, []float64, error) {
+		return -3, []float64{1, 2, 0, 0.5}, nil
+	}}
+	useLPSolver(t, mock)
+
+	p := subProblem{
+		c: []float64{-1, -1, 0},
+		A: NewDenseConstraints(1, 3, []float64{1, 1, 1}),
+		b: []float64{3},
+		bnbConstraints: []bnbConstraint{
+			{branchedVariable: 0, hsharp: 1, gsharp: []float64{1, 0, 0}},
+		},
+	}
+
+	// the slack variable of the bnb constraint is dropped from the solution
+	s := p.solve()
+	assert.NoError(t, s.err)
+	assert.Equal(t, float64(-3), s.z)
+	assert.Equal(t, []float64{1, 2, 0}, s.x)
+	assert.Equal(t, 1, mock.calls)
+	assert.Equal(t, [][2]int{{2, 4}}, mock.sizes)
+
+	mock.solve = func(c []float64, A *mat.Dense, b []float64) (float64, []float64, error) {
+		return 0, nil, lp.ErrInfeasible
+	}
+	assert.Equal(t, lp.ErrInfeasible, p.solve().err)
+}
+
+func TestMilpProblem_Solve_DefaultLPSolver(t *testing.T) {
+	prob := milpProblem{
+		c:                      []float64{-1, -1},
+		G:                      NewDenseConstraints(1, 2, []float64{1, 1}),
+		h:                      []float64{3},
+		integralityConstraints: []bool{true, true},
+	}
+
+	// the predetermined solution to the initial relaxation is integral, so the search ends without branching
+	mock := &mockLPSolver{solve: func(c []float64, A *mat.Dense, b []float64) (float64, []float64, error) {
+		return -3, []float64{1, 2, 0}, nil
+	}}
+	useLPSolver(t, mock)
+
+	result, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, STATUS_OPTIMAL, result.Status)
+	assert.Equal(t, []float64{1, 2}, result.BestIntegerSolution.x)
+	assert.Equal(t, float64(-3), result.BestIntegerSolution.z)
+	assert.Equal(t, 1, mock.calls)
+
+	mock.solve = func(c []float64, A *mat.Dense, b []float64) (float64, []float64, error) {
+		return 0, nil, lp.ErrInfeasible
+	}
+	result, err = prob.solve(context.Background(), 1, dummyMiddleware{})
+	assert.Error(t, err)
+	assert.Equal(t, STATUS_INFEASIBLE, result.Status)
+
+	// every LP relaxation solved by the branch-and-bound procedure is passed to the solver
+	mock = &mockLPSolver{solve: SimplexSolver{}.Solve}
+	useLPSolver(t, mock)
+	prob = getTestKnapsackMILP()
+	result, err = prob.solve(context.Background(), 2, dummyMiddleware{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.InDelta(t, -24, result.BestIntegerSolution.z, 1e-9)
+	assert.Equal(t, result.Stats.LPRelaxationsSolved, int64(mock.calls))
+}
@@ -0,0 +1,786 @@
+package ilp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// the name of the objective row in MPS exports
+const mpsObjectiveRow = "COST"
+
+// ExportMPS writes the problem to w in fixed-format MPS.
+// Unnamed constraints are named R1, R2, etc. after their position.
+// Range constraints (see Constraint.Range) are written as a single row with an entry in the RANGES section.
+// MPS assumes minimization, so the objective of a maximization problem is negated.
+// Note that fixed-format MPS limits names to 8 characters. Longer variable names are written as-is,
+// which most readers accept as long as the names do not contain spaces.
+func (p *Problem) ExportMPS(w io.Writer) error {
+	mw := &mpsWriter{w: bufio.NewWriter(w)}
+
+	// the 'smaller than or equal to' side of a range constraint is written as part of the row of its other side
+	partners := p.rangePartners()
+	skipped := make(map[*Constraint]bool, len(partners))
+	for _, upper := range partners {
+		skipped[upper] = true
+	}
+
+	rowNames := make([]string, len(p.constraints))
+	for i, c := range p.constraints {
+		rowNames[i] = c.name
+		if c.name == "" {
+			rowNames[i] = fmt.Sprintf("R%d", i+1)
+		}
+	}
+
+	mw.header("NAME", "PROBLEM")
+
+	// ROWS section: the objective followed by the constraints
+	mw.header("ROWS", "")
+	mw.fields("N", mpsObjectiveRow)
+	for i, c := range p.constraints {
+		if !skipped[c] {
+			mw.fields(mpsRowType(c), rowNames[i])
+		}
+	}
+
+	// COLUMNS section: the nonzero coefficients of each variable, with the integer variables wrapped in markers
+	mw.header("COLUMNS", "")
+
+	// collect the coefficients of each variable in the constraints, combining duplicate expressions
+	coefs := make(map[*Variable][]float64)
+	for _, v := range p.variables {
+		coefs[v] = make([]float64, len(p.constraints))
+	}
+	for i, c := range p.constraints {
+		if skipped[c] {
+			continue
+		}
+		for _, e := range c.lhsExpressions() {
+			coefs[e.variable][i] += e.coef
+		}
+	}
+
+	markers := 0
+	inIntegerBlock := false
+	for _, v := range p.variables {
+		if v.integer != inIntegerBlock {
+			marker := "'INTORG'"
+			if inIntegerBlock {
+				marker = "'INTEND'"
+			}
+			mw.fields("", fmt.Sprintf("MARKER%d", markers), "'MARKER'", "", marker)
+			markers++
+			inIntegerBlock = v.integer
+		}
+
+		objective := v.coefficient
+		if p.maximize {
+			objective = -objective
+		}
+
+		// always write the objective coefficient, so that variables without any nonzero coefficients are still declared
+		mw.fields("", v.name, mpsObjectiveRow, formatNumber(objective))
+		for i, coef := range coefs[v] {
+			if coef != 0 {
+				mw.fields("", v.name, rowNames[i], formatNumber(coef))
+			}
+		}
+	}
+	if inIntegerBlock {
+		mw.fields("", fmt.Sprintf("MARKER%d", markers), "'MARKER'", "", "'INTEND'")
+	}
+
+	// RHS section: only the nonzero right-hand sides.
+	// By convention, the right-hand side of the objective row is the negated objective offset.
+	mw.header("RHS", "")
+	if p.objectiveOffset != 0 {
+		offset := p.objectiveOffset
+		if p.maximize {
+			offset = -offset
+		}
+		mw.fields("", "RHS", mpsObjectiveRow, formatNumber(-offset))
+	}
+	for i, c := range p.constraints {
+		if c.rhs != 0 && !skipped[c] {
+			mw.fields("", "RHS", rowNames[i], formatNumber(c.rhs))
+		}
+	}
+
+	// RANGES section: the width of each range constraint, which extends its 'greater than or equal to' row upwards
+	mw.header("RANGES", "")
+	for i, c := range p.constraints {
+		if upper, ok := partners[c]; ok {
+			mw.fields("", "RNG", rowNames[i], formatNumber(upper.rhs-c.rhs))
+		}
+	}
+
+	// BOUNDS section: only the bounds that deviate from the default [0, +Inf)
+	mw.header("BOUNDS", "")
+	for _, v := range p.variables {
+		for _, b := range mpsBounds(v) {
+			mw.fields(b.kind, "BND", v.name, b.value)
+		}
+	}
+
+	mw.header("ENDATA", "")
+
+	if mw.err != nil {
+		return mw.err
+	}
+	return mw.w.Flush()
+}
+
+// the MPS row type of a constraint
+func mpsRowType(c *Constraint) string {
+	switch {
+	case !c.inequality:
+		return "E"
+	case c.greaterThanOrEqual:
+		return "G"
+	default:
+		return "L"
+	}
+}
+
+// a BOUNDS entry of a variable
+type mpsBound struct {
+	kind  string
+	value string
+}
+
+// the BOUNDS entries that describe the bounds of the variable.
+func mpsBounds(v *Variable) []mpsBound {
+	lowerInf := math.IsInf(v.lower, -1)
+	upperInf := math.IsInf(v.upper, 1)
+
+	switch {
+	case v.isBinary && v.integer && v.lower == 0 && v.upper == 1:
+		return []mpsBound{{"BV", ""}}
+	case v.lower == v.upper:
+		return []mpsBound{{"FX", formatNumber(v.lower)}}
+	case lowerInf && upperInf:
+		return []mpsBound{{"FR", ""}}
+	}
+
+	var bounds []mpsBound
+	switch {
+	case lowerInf:
+		bounds = append(bounds, mpsBound{"MI", ""})
+	// some readers interpret a negative upper bound as a lower bound of -Inf if the lower bound is not explicitly set
+	case v.lower != 0 || (!upperInf && v.upper < 0):
+		bounds = append(bounds, mpsBound{"LO", formatNumber(v.lower)})
+	}
+
+	switch {
+	case !upperInf:
+		bounds = append(bounds, mpsBound{"UP", formatNumber(v.upper)})
+	// some readers default the upper bound of integer variables to 1
+	case v.integer:
+		bounds = append(bounds, mpsBound{"PL", ""})
+	}
+
+	return bounds
+}
+
+// format a number as compactly as possible, without losing precision.
+func formatNumber(v float64) string {
+	// avoid writing negated zeroes as -0
+	if v == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// writes the fixed-format fields of MPS records, retaining the first error encountered.
+type mpsWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// write a section header, optionally followed by a value in the position of the second field.
+func (m *mpsWriter) header(section string, value string) {
+	if value != "" {
+		m.printf("%-14s%s\n", section, value)
+		return
+	}
+	m.printf("%s\n", section)
+}
+
+// the (1-based) columns at which the fields following the name field of a record start.
+var mpsFieldStarts = []int{15, 25, 40, 50}
+
+// write a record in the fixed-format field positions: columns 2-3, 5-12, 15-22, 25-36, 40-47 and 50-61.
+func (m *mpsWriter) fields(kind string, name string, values ...string) {
+	line := fmt.Sprintf(" %-2s %-8s", kind, name)
+	for k, v := range values {
+		// pad the line up to the start of the field, or at least separate it from the previous one
+		start := mpsFieldStarts[k%len(mpsFieldStarts)] - 1
+		if len(line) < start {
+			line += strings.Repeat(" ", start-len(line))
+		} else {
+			line += " "
+		}
+
+		// the names are left-aligned, the numbers right-aligned
+		if k%2 == 0 {
+			line += fmt.Sprintf("%-8s", v)
+		} else {
+			line += fmt.Sprintf("%12s", v)
+		}
+	}
+	m.printf("%s\n", strings.TrimRight(line, " "))
+}
+
+func (m *mpsWriter) printf(format string, args ...interface{}) {
+	if m.err != nil {
+		return
+	}
+	_, m.err = fmt.Fprintf(m.w, format, args...)
+}
+
+// ParseMPS reads a problem in fixed or free MPS format.
+// The ROWS, COLUMNS, RHS, RANGES, BOUNDS and OBJSENSE sections are supported, as well as integer markers in the COLUMNS section.
+// Records are split on whitespace first. If that fails, the records are split at the fixed column positions instead,
+// which allows for the names with spaces that fixed-format MPS permits.
+// The first N row is the objective, further N rows are ignored. The negated right-hand side of the objective row is the objective offset.
+// Ranged constraints are split into a 'greater than or equal to' constraint and a 'smaller than or equal to' constraint, of which the latter is appended to the constraints.
+// As is customary, a negative upper bound without an explicit lower bound sets the lower bound to -Inf, and bound values of 1e30 and beyond are treated as infinite.
+// Errors indicate the line number at which parsing failed.
+func ParseMPS(r io.Reader) (*Problem, error) {
+	lines, err := readMPSLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	prob, err := parseMPS(lines, strings.Fields)
+	if err != nil {
+		if fixed, fixedErr := parseMPS(lines, splitFixedMPS); fixedErr == nil {
+			return fixed, nil
+		}
+		return nil, err
+	}
+
+	return prob, nil
+}
+
+// PreprocessMPS normalises free MPS to fixed-column MPS, such that it can be read by tools that only support the fixed format.
+// Comments and blank lines are dropped and the set names that free MPS allows to be omitted are filled in.
+// Note that names longer than 8 characters are written as-is, like ExportMPS does.
+func PreprocessMPS(r io.Reader) (io.Reader, error) {
+	lines, err := readMPSLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	mw := &mpsWriter{w: bufio.NewWriter(&buf)}
+
+	section := ""
+	for _, l := range lines {
+		if l.header() {
+			fields := strings.Fields(l.text)
+			section = strings.ToUpper(fields[0])
+			switch {
+			case section == "NAME" && len(fields) > 1:
+				mw.header(section, strings.Join(fields[1:], " "))
+			case section == "OBJSENSE" && len(fields) > 1:
+				mw.header(section, "")
+				mw.printf("    %s\n", fields[1])
+			default:
+				mw.header(section, "")
+			}
+			continue
+		}
+
+		rec, err := newMPSRecord(section, strings.Fields(l.text), l.number)
+		if err != nil {
+			return nil, err
+		}
+
+		switch section {
+		case "OBJSENSE":
+			mw.printf("    %s\n", rec.kind)
+			continue
+		case "RHS":
+			rec.defaultName("RHS")
+		case "RANGES":
+			rec.defaultName("RNG")
+		case "BOUNDS":
+			rec.defaultName("BND")
+		}
+		mw.fields(rec.kind, rec.name, rec.values...)
+	}
+
+	if mw.err != nil {
+		return nil, mw.err
+	}
+	if err := mw.w.Flush(); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+// a line of an MPS file, along with its (1-based) line number.
+type mpsLine struct {
+	text   string
+	number int
+}
+
+// whether the line is a section header, which starts in the first column.
+func (l mpsLine) header() bool {
+	return !strings.HasPrefix(l.text, " ") && !strings.HasPrefix(l.text, "\t")
+}
+
+// read the lines of an MPS file, dropping comments and blank lines.
+func readMPSLines(r io.Reader) ([]mpsLine, error) {
+	var lines []mpsLine
+
+	scanner := bufio.NewScanner(r)
+	number := 0
+	for scanner.Scan() {
+		number++
+		text := scanner.Text()
+		if strings.HasPrefix(text, "*") || strings.TrimSpace(text) == "" {
+			continue
+		}
+		lines = append(lines, mpsLine{text: strings.TrimRight(text, " \t\r"), number: number})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// the (0-based) column ranges of the fields of a fixed-format MPS record.
+var mpsFixedFields = [][2]int{{1, 3}, {4, 12}, {14, 22}, {24, 36}, {39, 47}, {49, 61}}
+
+// split a record at the fixed column positions, dropping the empty fields.
+func splitFixedMPS(text string) []string {
+	var fields []string
+	for _, f := range mpsFixedFields {
+		if f[0] >= len(text) {
+			break
+		}
+		end := f[1]
+		if end > len(text) {
+			end = len(text)
+		}
+		if field := strings.TrimSpace(text[f[0]:end]); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// a record of an MPS section, of which the fields are identified by their position in fixed-format MPS.
+type mpsRecord struct {
+	line int
+
+	// the row type in the ROWS section, the bound type in the BOUNDS section or the sense in the OBJSENSE section
+	kind string
+
+	// the row name in the ROWS section, the column name in the COLUMNS section or the (optional) set name in the other sections
+	name string
+
+	// the remaining fields, i.e. pairs of row names and values or a column name followed by an optional value in the BOUNDS section
+	values []string
+}
+
+// bound types that are not followed by a value
+var mpsValuelessBounds = map[string]bool{"FR": true, "MI": true, "PL": true, "BV": true}
+
+// identify the fields of a record of the section, taking the optional set names into account.
+func newMPSRecord(section string, fields []string, line int) (mpsRecord, error) {
+	rec := mpsRecord{line: line}
+	n := len(fields)
+
+	valid := false
+	switch section {
+	case "OBJSENSE":
+		valid = n == 1
+		if valid {
+			rec.kind = fields[0]
+		}
+	case "ROWS":
+		valid = n == 2
+		if valid {
+			rec.kind, rec.name = fields[0], fields[1]
+		}
+	case "COLUMNS":
+		// an integer marker has a single value following the 'MARKER' keyword
+		valid = n == 3 || n == 5
+		if valid {
+			rec.name, rec.values = fields[0], fields[1:]
+		}
+	case "RHS", "RANGES":
+		// the set name is present if the pairs of row names and values are preceded by an odd field
+		switch n {
+		case 2, 4:
+			rec.values = fields
+			valid = true
+		case 3, 5:
+			rec.name, rec.values = fields[0], fields[1:]
+			valid = true
+		}
+	case "BOUNDS":
+		if n == 0 {
+			break
+		}
+		rec.kind = strings.ToUpper(fields[0])
+
+		// the number of fields following the set name, if present
+		withValue := 2
+		if mpsValuelessBounds[rec.kind] {
+			withValue = 1
+		}
+
+		switch {
+		case n-1 == withValue:
+			rec.values = fields[1:]
+			valid = true
+		// a binary bound may be followed by a redundant value
+		case n-1 == withValue+1, rec.kind == "BV" && n == 4:
+			rec.name, rec.values = fields[1], fields[2:]
+			valid = true
+		}
+	default:
+		return rec, fmt.Errorf("line %d: record outside of a data section", line)
+	}
+
+	if !valid {
+		return rec, fmt.Errorf("line %d: unexpected number of fields (%d) in a record of the %v section", line, n, section)
+	}
+	return rec, nil
+}
+
+// set the name of the record if it was omitted.
+func (r *mpsRecord) defaultName(name string) {
+	if r.name == "" {
+		r.name = name
+	}
+}
+
+// the MPS value that is considered to be infinite in the BOUNDS section
+const mpsInfinity = 1e30
+
+// parses the records of an MPS file into a problem.
+type mpsParser struct {
+	problem *Problem
+
+	// the name of the objective row, if declared yet
+	objective string
+
+	// the constraints in the order of the ROWS section
+	constraints []*Constraint
+	rows        map[string]*Constraint
+
+	// rows of type N other than the objective, which are ignored
+	freeRows map[string]bool
+
+	variables map[string]*Variable
+
+	// whether the COLUMNS records are within an integer marker block
+	integer bool
+
+	// the RANGES of the constraints
+	ranges map[*Constraint]float64
+
+	// variables of which the lower bound is set explicitly in the BOUNDS section
+	lowerBounded map[*Variable]bool
+}
+
+// parse the lines of an MPS file, splitting the records into fields with the provided function.
+func parseMPS(lines []mpsLine, split func(string) []string) (*Problem, error) {
+	prob := NewProblem()
+	p := &mpsParser{
+		problem:      &prob,
+		rows:         make(map[string]*Constraint),
+		freeRows:     make(map[string]bool),
+		variables:    make(map[string]*Variable),
+		ranges:       make(map[*Constraint]float64),
+		lowerBounded: make(map[*Variable]bool),
+	}
+
+	section := ""
+	for _, l := range lines {
+		if l.header() {
+			var err error
+			if section, err = p.parseHeader(l); err != nil {
+				return nil, err
+			}
+			if section == "ENDATA" {
+				break
+			}
+			continue
+		}
+
+		rec, err := newMPSRecord(section, split(l.text), l.number)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.parseRecord(section, rec); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.objective == "" {
+		return nil, errors.New("no objective row (type N) declared")
+	}
+
+	p.applyRanges()
+
+	return &prob, nil
+}
+
+// parse a section header, returning the name of the section.
+func (p *mpsParser) parseHeader(l mpsLine) (string, error) {
+	fields := strings.Fields(l.text)
+	section := strings.ToUpper(fields[0])
+
+	switch section {
+	case "NAME", "ROWS", "COLUMNS", "RHS", "RANGES", "BOUNDS", "ENDATA":
+	case "OBJSENSE":
+		// free MPS allows the sense to follow the header on the same line
+		if len(fields) > 1 {
+			return section, p.parseSense(mpsRecord{line: l.number, kind: fields[1]})
+		}
+	default:
+		return "", fmt.Errorf("line %d: unsupported section %v", l.number, fields[0])
+	}
+
+	return section, nil
+}
+
+func (p *mpsParser) parseRecord(section string, rec mpsRecord) error {
+	switch section {
+	case "OBJSENSE":
+		return p.parseSense(rec)
+	case "ROWS":
+		return p.parseRow(rec)
+	case "COLUMNS":
+		return p.parseColumn(rec)
+	case "RHS":
+		return p.parseRHS(rec)
+	case "RANGES":
+		return p.parseRange(rec)
+	default:
+		return p.parseBound(rec)
+	}
+}
+
+func (p *mpsParser) parseSense(rec mpsRecord) error {
+	switch strings.ToUpper(rec.kind) {
+	case "MAX", "MAXIMIZE":
+		p.problem.Maximize()
+	case "MIN", "MINIMIZE":
+		p.problem.Minimize()
+	default:
+		return fmt.Errorf("line %d: unknown objective sense %q", rec.line, rec.kind)
+	}
+	return nil
+}
+
+func (p *mpsParser) parseRow(rec mpsRecord) error {
+	if p.rows[rec.name] != nil || p.freeRows[rec.name] || rec.name == p.objective {
+		return fmt.Errorf("line %d: duplicate row %q", rec.line, rec.name)
+	}
+
+	var c *Constraint
+	switch strings.ToUpper(rec.kind) {
+	case "N":
+		if p.objective == "" {
+			p.objective = rec.name
+		} else {
+			p.freeRows[rec.name] = true
+		}
+		return nil
+	case "L":
+		c = p.problem.AddConstraint(rec.name).SmallerThanOrEqualTo(0)
+	case "G":
+		c = p.problem.AddConstraint(rec.name).GreaterThanOrEqualTo(0)
+	case "E":
+		c = p.problem.AddConstraint(rec.name).EqualTo(0)
+	default:
+		return fmt.Errorf("line %d: unknown row type %q", rec.line, rec.kind)
+	}
+
+	p.rows[rec.name] = c
+	p.constraints = append(p.constraints, c)
+	return nil
+}
+
+func (p *mpsParser) parseColumn(rec mpsRecord) error {
+	if rec.values[0] == "'MARKER'" {
+		switch rec.values[1] {
+		case "'INTORG'":
+			p.integer = true
+		case "'INTEND'":
+			p.integer = false
+		default:
+			return fmt.Errorf("line %d: unknown marker %v", rec.line, rec.values[1])
+		}
+		return nil
+	}
+	if len(rec.values)%2 != 0 {
+		return fmt.Errorf("line %d: expected pairs of row names and values", rec.line)
+	}
+
+	v, ok := p.variables[rec.name]
+	if !ok {
+		v = p.problem.AddVariable(rec.name)
+		if p.integer {
+			v.IsInteger()
+		}
+		p.variables[rec.name] = v
+	}
+
+	return p.parsePairs(rec, func(row string, c *Constraint, value float64) {
+		switch {
+		case c != nil:
+			c.AddExpression(value, v)
+		case row == p.objective:
+			v.SetCoeff(value)
+		}
+	})
+}
+
+func (p *mpsParser) parseRHS(rec mpsRecord) error {
+	return p.parsePairs(rec, func(row string, c *Constraint, value float64) {
+		switch {
+		case c != nil:
+			c.rhs = value
+		case row == p.objective:
+			p.problem.SetObjectiveOffset(-value)
+		}
+	})
+}
+
+func (p *mpsParser) parseRange(rec mpsRecord) error {
+	return p.parsePairs(rec, func(row string, c *Constraint, value float64) {
+		if c != nil {
+			p.ranges[c] = value
+		}
+	})
+}
+
+// parse the pairs of row names and values of the record, passing the constraint of each row to the provided function.
+// The constraint is nil for the objective row and the free rows.
+func (p *mpsParser) parsePairs(rec mpsRecord, apply func(row string, c *Constraint, value float64)) error {
+	for i := 0; i < len(rec.values); i += 2 {
+		row := rec.values[i]
+		c := p.rows[row]
+		if c == nil && row != p.objective && !p.freeRows[row] {
+			return fmt.Errorf("line %d: unknown row %q", rec.line, row)
+		}
+
+		value, err := parseMPSNumber(rec.values[i+1], rec.line)
+		if err != nil {
+			return err
+		}
+
+		apply(row, c, value)
+	}
+	return nil
+}
+
+func (p *mpsParser) parseBound(rec mpsRecord) error {
+	v, ok := p.variables[rec.values[0]]
+	if !ok {
+		return fmt.Errorf("line %d: unknown column %q", rec.line, rec.values[0])
+	}
+
+	var value float64
+	if !mpsValuelessBounds[rec.kind] {
+		var err error
+		if value, err = parseMPSNumber(rec.values[1], rec.line); err != nil {
+			return err
+		}
+		if value >= mpsInfinity {
+			value = math.Inf(1)
+		} else if value <= -mpsInfinity {
+			value = math.Inf(-1)
+		}
+	}
+
+	switch rec.kind {
+	case "UP", "UI":
+		v.UpperBound(value)
+		if value < 0 && !p.lowerBounded[v] {
+			v.LowerBound(math.Inf(-1))
+		}
+	case "LO", "LI":
+		v.LowerBound(value)
+		p.lowerBounded[v] = true
+	case "FX":
+		v.LowerBound(value).UpperBound(value)
+		p.lowerBounded[v] = true
+	case "FR":
+		v.LowerBound(math.Inf(-1)).UpperBound(math.Inf(1))
+		p.lowerBounded[v] = true
+	case "MI":
+		v.LowerBound(math.Inf(-1))
+		p.lowerBounded[v] = true
+	case "PL":
+		v.UpperBound(math.Inf(1))
+	case "BV":
+		v.IsInteger().LowerBound(0).UpperBound(1)
+		v.isBinary = true
+		p.lowerBounded[v] = true
+	default:
+		return fmt.Errorf("line %d: unknown bound type %q", rec.line, rec.kind)
+	}
+
+	if rec.kind == "UI" || rec.kind == "LI" {
+		v.IsInteger()
+	}
+
+	return nil
+}
+
+// turn each ranged constraint into a 'greater than or equal to' constraint and an additional 'smaller than or equal to' constraint,
+// which are linked as a range constraint (see Constraint.Range).
+func (p *mpsParser) applyRanges() {
+	for _, c := range p.constraints {
+		r, ok := p.ranges[c]
+		if !ok {
+			continue
+		}
+
+		var lower, upper float64
+		switch {
+		case !c.inequality && r < 0:
+			lower, upper = c.rhs+r, c.rhs
+		case !c.inequality, c.greaterThanOrEqual:
+			lower, upper = c.rhs, c.rhs+math.Abs(r)
+		default:
+			lower, upper = c.rhs-math.Abs(r), c.rhs
+		}
+
+		if lower == upper {
+			c.EqualTo(lower)
+			continue
+		}
+
+		c.GreaterThanOrEqualTo(lower)
+		upperConstraint := p.problem.AddConstraint("").SmallerThanOrEqualTo(upper)
+		upperConstraint.expressions = append([]expression(nil), c.expressions...)
+
+		c.rangeID = p.problem.newRangeID()
+		upperConstraint.rangeID = c.rangeID
+	}
+}
+
+func parseMPSNumber(text string, line int) (float64, error) {
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("line %d: invalid number %q", line, text)
+	}
+	return value, nil
+}
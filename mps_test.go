@@ -0,0 +1,344 @@
+package ilp
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getMPSTestProblem() Problem {
+	prob := NewProblem()
+	prob.Maximize()
+
+	x := prob.AddVariable("x").SetCoeff(3).UpperBound(4)
+	y := prob.AddVariable("y").SetCoeff(2).IsInteger()
+	z := prob.AddFreeVariable("z").SetCoeff(-1)
+	w := prob.AddVariable("w").SetCoeff(1).IsInteger().LowerBound(-2).UpperBound(5)
+	v := prob.AddVariable("v").LowerBound(1.5).UpperBound(1.5)
+
+	prob.AddConstraint("").AddExpression(1, x).AddExpression(1, y).SmallerThanOrEqualTo(10)
+	prob.AddConstraint("").AddExpression(2, y).AddExpression(-1, z).EqualTo(0)
+	prob.AddConstraint("").AddExpression(1, w).AddExpression(1, v).AddExpression(1, x).GreaterThanOrEqualTo(-1)
+
+	return prob
+}
+
+func TestExportMPS(t *testing.T) {
+	prob := getMPSTestProblem()
+
+	var buf bytes.Buffer
+	assert.NoError(t, prob.ExportMPS(&buf))
+
+	want := `NAME          PROBLEM
+ROWS
+ N  COST
+ L  R1
+ E  R2
+ G  R3
+COLUMNS
+    x         COST                -3
+    x         R1                   1
+    x         R3                   1
+    MARKER0   'MARKER'                 'INTORG'
+    y         COST                -2
+    y         R1                   1
+    y         R2                   2
+    MARKER1   'MARKER'                 'INTEND'
+    z         COST                 1
+    z         R2                  -1
+    MARKER2   'MARKER'                 'INTORG'
+    w         COST                -1
+    w         R3                   1
+    MARKER3   'MARKER'                 'INTEND'
+    v         COST                 0
+    v         R3                   1
+RHS
+    RHS       R1                  10
+    RHS       R3                  -1
+RANGES
+BOUNDS
+ UP BND       x                    4
+ PL BND       y
+ FR BND       z
+ LO BND       w                   -2
+ UP BND       w                    5
+ FX BND       v                  1.5
+ENDATA
+`
+	assert.Equal(t, want, buf.String())
+
+	// reading the export back should yield the same problem data
+	got := readMPS(t, buf.String())
+	assert.Equal(t, map[string]string{"COST": "N", "R1": "L", "R2": "E", "R3": "G"}, got.rowTypes)
+	assert.Equal(t, map[string]bool{"y": true, "w": true}, got.integers)
+	assert.Equal(t, map[string]float64{"R1": 10, "R3": -1}, got.rhs)
+	for i, c := range prob.constraints {
+		row := "R" + strconv.Itoa(i+1)
+		for _, e := range c.expressions {
+			assert.Equal(t, e.coef, got.coefs[e.variable.name][row])
+		}
+	}
+	for _, v := range prob.variables {
+		// maximization is exported as minimization of the negated objective
+		assert.Equal(t, -v.coefficient, got.coefs[v.name]["COST"])
+	}
+	assert.Equal(t, []string{"UP x 4", "PL y", "FR z", "LO w -2", "UP w 5", "FX v 1.5"}, got.bounds)
+}
+
+type mpsContents struct {
+	rowTypes map[string]string
+	coefs    map[string]map[string]float64
+	integers map[string]bool
+	rhs      map[string]float64
+	bounds   []string
+}
+
+// a minimal reader of the MPS sections written by ExportMPS, which splits records on whitespace.
+func readMPS(t *testing.T, mps string) mpsContents {
+	contents := mpsContents{
+		rowTypes: make(map[string]string),
+		coefs:    make(map[string]map[string]float64),
+		integers: make(map[string]bool),
+		rhs:      make(map[string]float64),
+	}
+
+	parseNumber := func(s string) float64 {
+		v, err := strconv.ParseFloat(s, 64)
+		assert.NoError(t, err)
+		return v
+	}
+
+	var section string
+	integer := false
+	scanner := bufio.NewScanner(strings.NewReader(mps))
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if !strings.HasPrefix(line, " ") {
+			section = fields[0]
+			continue
+		}
+
+		switch section {
+		case "ROWS":
+			contents.rowTypes[fields[1]] = fields[0]
+		case "COLUMNS":
+			if fields[1] == "'MARKER'" {
+				integer = fields[2] == "'INTORG'"
+				continue
+			}
+			if contents.coefs[fields[0]] == nil {
+				contents.coefs[fields[0]] = make(map[string]float64)
+			}
+			contents.coefs[fields[0]][fields[1]] = parseNumber(fields[2])
+			if integer {
+				contents.integers[fields[0]] = true
+			}
+		case "RHS":
+			contents.rhs[fields[1]] = parseNumber(fields[2])
+		case "BOUNDS":
+			contents.bounds = append(contents.bounds, strings.Join(append([]string{fields[0]}, fields[2:]...), " "))
+		default:
+			t.Errorf("unexpected record in section %v: %v", section, line)
+		}
+	}
+
+	return contents
+}
+
+// a free MPS file with all supported sections
+const freeMPS = `NAME TESTPROB
+* a comment
+OBJSENSE MAX
+ROWS
+ N obj
+ L lim1
+ G lim2
+ E myeqn
+ N unused
+COLUMNS
+ MARKER 'MARKER' 'INTORG'
+ x obj 1 lim1 1
+ x lim2 1
+ MARKER 'MARKER' 'INTEND'
+ y obj 2 lim1 1
+ y myeqn -1 unused 3
+ z obj -1 myeqn 1
+ b obj 0.5 lim2 1
+RHS
+ RHS lim1 4 lim2 1
+ myeqn 7
+RANGES
+ RNG lim1 2.5 myeqn -3
+BOUNDS
+ UP BND x 4
+ MI BND y
+ UP BND y 1e30
+ UP BND z -2
+ BV BND b
+ENDATA
+`
+
+func TestParseMPS(t *testing.T) {
+	got, err := ParseMPS(strings.NewReader(freeMPS))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the same problem, built using the API
+	want := NewProblem()
+	want.Maximize()
+
+	x := want.AddVariable("x").SetCoeff(1).IsInteger().UpperBound(4)
+	y := want.AddFreeVariable("y").SetCoeff(2)
+	z := want.AddVariable("z").SetCoeff(-1).LowerBound(math.Inf(-1)).UpperBound(-2)
+	b := want.AddBinaryVariable("b").SetCoeff(0.5)
+
+	// the ranged constraints are split in two
+	want.AddConstraint("").AddExpression(1, x).AddExpression(1, y).GreaterThanOrEqualTo(1.5)
+	want.AddConstraint("").AddExpression(1, x).AddExpression(1, b).GreaterThanOrEqualTo(1)
+	want.AddConstraint("").AddExpression(-1, y).AddExpression(1, z).GreaterThanOrEqualTo(4)
+	want.AddConstraint("").AddExpression(1, x).AddExpression(1, y).SmallerThanOrEqualTo(4)
+	want.AddConstraint("").AddExpression(-1, y).AddExpression(1, z).SmallerThanOrEqualTo(7)
+
+	assert.Equal(t, want.toSolveable(), got.toSolveable())
+}
+
+func TestParseMPS_fixed(t *testing.T) {
+	// fixed-format names may contain spaces and the set names may be left blank
+	mps := `NAME          FIXED
+ROWS
+ N  COST
+ L  ROW 1
+COLUMNS
+    VAR 1     COST                 1   ROW 1                2
+    VAR 2     ROW 1               -1
+RHS
+              ROW 1                5
+BOUNDS
+ UP           VAR 1                3
+ MI           VAR 2
+ENDATA
+`
+	got, err := ParseMPS(strings.NewReader(mps))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	want := NewProblem()
+	v1 := want.AddVariable("VAR 1").SetCoeff(1).UpperBound(3)
+	v2 := want.AddVariable("VAR 2").LowerBound(math.Inf(-1))
+	want.AddConstraint("").AddExpression(2, v1).AddExpression(-1, v2).SmallerThanOrEqualTo(5)
+
+	assert.Equal(t, want.toSolveable(), got.toSolveable())
+	assert.Equal(t, "VAR 1", got.variables[0].name)
+}
+
+func TestParseMPS_errors(t *testing.T) {
+	cases := []struct {
+		name string
+		mps  string
+		err  string
+	}{
+		{
+			name: "unknown row type",
+			mps:  "ROWS\n N obj\n X r1\nENDATA",
+			err:  `line 3: unknown row type "X"`,
+		},
+		{
+			name: "unknown row",
+			mps:  "ROWS\n N obj\nCOLUMNS\n x obj 1 r1 1\nENDATA",
+			err:  `line 4: unknown row "r1"`,
+		},
+		{
+			name: "invalid number",
+			mps:  "ROWS\n N obj\n L r1\nCOLUMNS\n x r1 one\nENDATA",
+			err:  `line 5: invalid number "one"`,
+		},
+		{
+			name: "unknown column",
+			mps:  "ROWS\n N obj\nCOLUMNS\n x obj 1\nBOUNDS\n UP BND y 1\nENDATA",
+			err:  `line 6: unknown column "y"`,
+		},
+		{
+			name: "unknown bound type",
+			mps:  "ROWS\n N obj\nCOLUMNS\n x obj 1\nBOUNDS\n XX BND x 1\nENDATA",
+			err:  `line 6: unknown bound type "XX"`,
+		},
+		{
+			name: "unexpected number of fields",
+			mps:  "ROWS\n N obj\nCOLUMNS\n x obj 1 2\nENDATA",
+			err:  `line 4: unexpected number of fields (4) in a record of the COLUMNS section`,
+		},
+		{
+			name: "unsupported section",
+			mps:  "ROWS\n N obj\nSOS\nENDATA",
+			err:  `line 3: unsupported section SOS`,
+		},
+		{
+			name: "missing objective",
+			mps:  "ROWS\n L r1\nENDATA",
+			err:  `no objective row (type N) declared`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseMPS(strings.NewReader(tc.mps))
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestExportMPS_roundTrip(t *testing.T) {
+	prob := getLPTestProblem()
+	prob.constraints[1].name = "balance"
+	prob.SetObjectiveOffset(3.5)
+
+	var buf bytes.Buffer
+	assert.NoError(t, prob.ExportMPS(&buf))
+
+	parsed, err := ParseMPS(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the maximization problem is read back as the minimization of its negated objective and offset, which has the same numerical representation
+	assert.Equal(t, prob.toSolveable(), parsed.toSolveable())
+	for i, v := range prob.variables {
+		assert.Equal(t, v.name, parsed.variables[i].name)
+	}
+
+	// named constraints keep their name, unnamed ones are named after their position
+	_, ok := parsed.GetConstraint("balance")
+	assert.True(t, ok)
+	_, ok = parsed.GetConstraint("R1")
+	assert.True(t, ok)
+}
+
+func TestPreprocessMPS(t *testing.T) {
+	fixed, err := PreprocessMPS(strings.NewReader(freeMPS))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(fixed)
+	assert.NoError(t, err)
+
+	// the records are aligned to the fixed field positions and the omitted set name is filled in
+	assert.Contains(t, buf.String(), "\n    x         obj                  1   lim1                 1\n")
+	assert.Contains(t, buf.String(), "\n    RHS       myeqn                7\n")
+	assert.Contains(t, buf.String(), "\nOBJSENSE\n    MAX\n")
+
+	want, err := ParseMPS(strings.NewReader(freeMPS))
+	assert.NoError(t, err)
+	got, err := ParseMPS(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, want.toSolveable(), got.toSolveable())
+}
@@ -0,0 +1,127 @@
+package ilp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// ObjectiveTerm is one of the objectives of a multi-objective problem, e.g. minimizing cost or minimizing lateness.
+type ObjectiveTerm struct {
+	// the coefficient of each variable in the objective, in the order in which the variables were added to the problem
+	Weights []float64
+
+	// whether the objective is maximized rather than minimized
+	Maximize bool
+}
+
+// AddObjective adds an objective to the multi-objective problem and returns its index, by which it is referred to by SetObjectiveWeights and SolveLexicographic.
+// The weights of the term are copied.
+func (p *Problem) AddObjective(term ObjectiveTerm) int {
+	defer p.mustModify()()
+	term.Weights = append([]float64(nil), term.Weights...)
+	p.objectives = append(p.objectives, term)
+	return len(p.objectives) - 1
+}
+
+// check that the objective has a weight for each variable of the problem.
+func (p *Problem) checkObjective(k int) error {
+	if k < 0 || k >= len(p.objectives) {
+		return fmt.Errorf("objective %v not found", k)
+	}
+	if n := len(p.objectives[k].Weights); n != len(p.variables) {
+		return fmt.Errorf("objective %v has %v weights for %v variables", k, n, len(p.variables))
+	}
+	return nil
+}
+
+// SetObjectiveWeights scalarizes the multi-objective problem by setting the objective function to the weighted sum of its objectives,
+// i.e. the coefficient of each variable becomes sum_k w_k * c_k, where c_k is negated for the objectives that are maximized, and the problem is minimized.
+// An error is returned if the number of weights differs from the number of objectives, or if any weight is negative.
+func (p *Problem) SetObjectiveWeights(weights []float64) error {
+	done, err := p.modify()
+	if err != nil {
+		return err
+	}
+	defer done()
+	if len(weights) != len(p.objectives) {
+		return fmt.Errorf("%v weights provided for %v objectives", len(weights), len(p.objectives))
+	}
+
+	c := make([]float64, len(p.variables))
+	for k, w := range weights {
+		if w < 0 {
+			return fmt.Errorf("weight %v of objective %v is negative", w, k)
+		}
+		if err := p.checkObjective(k); err != nil {
+			return err
+		}
+
+		// the combined objective is minimized
+		if p.objectives[k].Maximize {
+			w = -w
+		}
+		floats.AddScaled(c, w, p.objectives[k].Weights)
+	}
+
+	p.Minimize()
+	for i, v := range p.variables {
+		v.SetCoeff(c[i])
+	}
+	return nil
+}
+
+// SolveLexicographic optimizes the objectives of the multi-objective problem one after another, in the provided order of priority.
+// Once an objective is optimized, it is fixed at its optimal value by an equality constraint, so that each next objective is optimized among the solutions
+// that are optimal for all objectives before it. Returns the solution of each stage, of which the objective value is that of the objective of the stage.
+// The problem itself is not modified. If a stage fails, the solutions of the previous stages are returned along with the error.
+func (p Problem) SolveLexicographic(ctx context.Context, priorityOrder []int, opts ...SolveOption) ([]*Solution, error) {
+	if len(priorityOrder) == 0 {
+		return nil, errors.New("no objectives to optimize")
+	}
+	seen := make(map[int]bool, len(priorityOrder))
+	for _, k := range priorityOrder {
+		if err := p.checkObjective(k); err != nil {
+			return nil, err
+		}
+		if seen[k] {
+			return nil, fmt.Errorf("objective %v occurs more than once in the priority order", k)
+		}
+		seen[k] = true
+	}
+
+	stage := p.Clone()
+	stage.objectiveOffset = 0
+
+	var solutions []*Solution
+	for _, k := range priorityOrder {
+		term := p.objectives[k]
+		stage.maximize = term.Maximize
+		for i, v := range stage.variables {
+			v.SetCoeff(term.Weights[i])
+		}
+
+		soln, err := stage.Solve(ctx, opts...)
+		if err != nil {
+			return solutions, fmt.Errorf("optimizing objective %v: %w", k, err)
+		}
+		solutions = append(solutions, soln)
+
+		// the next objectives are optimized among the solutions that are optimal for this one.
+		// An objective without any weights is constant, so it does not restrict them.
+		if floats.Norm(term.Weights, 1) == 0 {
+			continue
+		}
+		fixed := stage.AddConstraint("")
+		for i, v := range stage.variables {
+			if term.Weights[i] != 0 {
+				fixed.AddExpression(term.Weights[i], v)
+			}
+		}
+		fixed.EqualTo(soln.Objective)
+	}
+
+	return solutions, nil
+}
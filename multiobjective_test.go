@@ -0,0 +1,81 @@
+package ilp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// a problem with two objectives: minimizing the cost x + y of doing at least two units of work, and maximizing the work x done by the first machine.
+func multiObjectiveProblem() (prob Problem, cost, work int) {
+	prob = NewProblem()
+	x := prob.AddVariable("x").IsInteger().UpperBound(3)
+	y := prob.AddVariable("y").IsInteger().UpperBound(3)
+	prob.AddConstraint("demand").AddExpression(1, x).AddExpression(1, y).GreaterThanOrEqualTo(2)
+
+	cost = prob.AddObjective(ObjectiveTerm{Weights: []float64{1, 1}})
+	work = prob.AddObjective(ObjectiveTerm{Weights: []float64{1, 0}, Maximize: true})
+	return prob, cost, work
+}
+
+func TestProblem_SolveLexicographic(t *testing.T) {
+	prob, cost, work := multiObjectiveProblem()
+
+	values := func(soln *Solution) []float64 {
+		x, err := soln.GetValueFor("x")
+		assert.NoError(t, err)
+		y, err := soln.GetValueFor("y")
+		assert.NoError(t, err)
+		return []float64{x, y}
+	}
+
+	// among the cheapest solutions, the first machine does all the work
+	solutions, err := prob.SolveLexicographic(context.Background(), []int{cost, work})
+	if !assert.NoError(t, err) || !assert.Len(t, solutions, 2) {
+		return
+	}
+	assert.InDelta(t, 2, solutions[0].Objective, 1e-9)
+	assert.InDelta(t, 2, solutions[1].Objective, 1e-9)
+	assert.InDeltaSlice(t, []float64{2, 0}, values(solutions[1]), 1e-9)
+
+	// maximizing the work first makes the cheapest solution more expensive
+	solutions, err = prob.SolveLexicographic(context.Background(), []int{work, cost})
+	if !assert.NoError(t, err) || !assert.Len(t, solutions, 2) {
+		return
+	}
+	assert.InDelta(t, 3, solutions[0].Objective, 1e-9)
+	assert.InDelta(t, 3, solutions[1].Objective, 1e-9)
+	assert.InDeltaSlice(t, []float64{3, 0}, values(solutions[1]), 1e-9)
+
+	// the problem itself is not modified
+	assert.Len(t, prob.constraints, 1)
+
+	_, err = prob.SolveLexicographic(context.Background(), []int{cost, cost})
+	assert.EqualError(t, err, "objective 0 occurs more than once in the priority order")
+	_, err = prob.SolveLexicographic(context.Background(), []int{2})
+	assert.EqualError(t, err, "objective 2 not found")
+}
+
+func TestProblem_SetObjectiveWeights(t *testing.T) {
+	prob, _, _ := multiObjectiveProblem()
+
+	// minimizes 0.5x + y
+	if !assert.NoError(t, prob.SetObjectiveWeights([]float64{1, 0.5})) {
+		return
+	}
+	assert.Equal(t, 0.5, prob.variables[0].coefficient)
+	assert.Equal(t, float64(1), prob.variables[1].coefficient)
+
+	soln, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.InDelta(t, 1, soln.Objective, 1e-9)
+
+	assert.EqualError(t, prob.SetObjectiveWeights([]float64{1}), "1 weights provided for 2 objectives")
+	assert.EqualError(t, prob.SetObjectiveWeights([]float64{1, -1}), "weight -1 of objective 1 is negative")
+
+	prob.AddVariable("z")
+	assert.EqualError(t, prob.SetObjectiveWeights([]float64{1, 1}), "objective 0 has 2 weights for 3 variables")
+}
@@ -0,0 +1,208 @@
+package ilp
+
+import "container/heap"
+
+// NodeQueue holds the subProblems that are waiting to be solved, and determines the order in which the enumeration tree is explored.
+// A NodeQueue is only accessed by a single goroutine, so implementations do not need to be safe for concurrent use.
+type NodeQueue interface {
+	// add a subProblem to the queue
+	Enqueue(OpenNode)
+
+	// remove and return the subProblem that should be solved next. Is only called if the queue is not empty.
+	Dequeue() OpenNode
+
+	// the number of subProblems in the queue
+	Len() int
+}
+
+// OpenNode is a subProblem of the enumeration tree that is waiting to be solved, as held by a NodeQueue.
+// Its fields describe the subProblem, so that a queue can order the subProblems by them. Dequeue should return the OpenNode as it was enqueued.
+type OpenNode struct {
+	// the ID of the subProblem and that of its parent
+	ID     int64
+	Parent int64
+
+	// the number of branchings between the subProblem and the root problem
+	Depth int
+
+	// the objective value of the parent, which bounds the objective value of the subProblem
+	Bound float64
+
+	// the estimated objective value of the best integer-feasible solution in the subtree of the subProblem
+	Estimate float64
+
+	problem subProblem
+}
+
+func newOpenNode(p subProblem) OpenNode {
+	return OpenNode{
+		ID:       p.id,
+		Parent:   p.parent,
+		Depth:    p.Depth(),
+		Bound:    p.bound,
+		Estimate: p.estimate,
+		problem:  p,
+	}
+}
+
+// NodeSelectionStrategy determines the order in which the subProblems of the branch-and-bound procedure are explored.
+type NodeSelectionStrategy int
+
+const (
+	NODE_FIFO NodeSelectionStrategy = iota
+	NODE_BEST_BOUND
+	NODE_DEPTH_FIRST
+	NODE_BEST_ESTIMATE
+)
+
+// get the factory of the queue implementing the node selection strategy.
+func (s NodeSelectionStrategy) factory() NodeQueueFactory {
+	switch s {
+	case NODE_FIFO:
+		return NewFIFOQueue
+	case NODE_BEST_BOUND:
+		return NewBestBoundQueue
+	case NODE_DEPTH_FIRST:
+		return NewDepthFirstQueue
+	case NODE_BEST_ESTIMATE:
+		return NewBestEstimateQueue
+	default:
+		panic("unexpected node selection strategy")
+	}
+}
+
+// NodeQueueFactory creates an empty NodeQueue. A new queue is created for each branch-and-bound procedure.
+type NodeQueueFactory func() NodeQueue
+
+// FIFOQueue explores the subProblems in the order in which they were created, i.e. breadth-first.
+// This is the default node selection.
+type FIFOQueue struct {
+	buffer []OpenNode
+}
+
+func NewFIFOQueue() NodeQueue {
+	return &FIFOQueue{}
+}
+
+func (q *FIFOQueue) Enqueue(s OpenNode) {
+	q.buffer = append(q.buffer, s)
+}
+
+func (q *FIFOQueue) Dequeue() OpenNode {
+	next := q.buffer[0]
+	if len(q.buffer) > 1 {
+		q.buffer = q.buffer[1:]
+	} else {
+		q.buffer = nil
+	}
+	return next
+}
+
+func (q *FIFOQueue) Len() int {
+	return len(q.buffer)
+}
+
+// BestBoundQueue explores the subProblem with the lowest bound first, i.e. the subProblem of which the parent has the lowest LP relaxation value.
+// This tends to raise the dual bound quickly, at the cost of finding integer-feasible solutions later than depth-first exploration.
+// Ties are broken in favour of the subProblem that was created first.
+type BestBoundQueue struct {
+	nodeHeap
+}
+
+func NewBestBoundQueue() NodeQueue {
+	return &BestBoundQueue{newNodeHeap(func(a, b queuedNode) bool {
+		if a.node.Bound == b.node.Bound {
+			return a.node.ID < b.node.ID
+		}
+		return a.node.Bound < b.node.Bound
+	})}
+}
+
+// DepthFirstQueue explores the most recently added subProblem first, i.e. it is a LIFO stack.
+// The deepest subProblem always takes precedence, and siblings are explored in the order in which they were added,
+// so that the child that should be explored first after branching is not overtaken by its sibling.
+// This tends to find an integer-feasible solution quickly, providing an incumbent to prune the rest of the tree with.
+type DepthFirstQueue struct {
+	nodeHeap
+}
+
+func NewDepthFirstQueue() NodeQueue {
+	return &DepthFirstQueue{newNodeHeap(func(a, b queuedNode) bool {
+		switch {
+		case a.node.Depth != b.node.Depth:
+			return a.node.Depth > b.node.Depth
+		case a.node.Parent == b.node.Parent:
+			return a.seq < b.seq
+		default:
+			return a.seq > b.seq
+		}
+	})}
+}
+
+// BestEstimateQueue explores the subProblem with the lowest estimated objective value of the best integer-feasible solution in its subtree first.
+// The estimate is derived from the pseudocosts of the fractional variables of the parent solution.
+// Ties are broken in favour of the subProblem that was created first.
+type BestEstimateQueue struct {
+	nodeHeap
+}
+
+func NewBestEstimateQueue() NodeQueue {
+	return &BestEstimateQueue{newNodeHeap(func(a, b queuedNode) bool {
+		if a.node.Estimate == b.node.Estimate {
+			return a.node.ID < b.node.ID
+		}
+		return a.node.Estimate < b.node.Estimate
+	})}
+}
+
+// a subProblem along with the order in which it was added to a queue.
+type queuedNode struct {
+	node OpenNode
+	seq  int64
+}
+
+// priority queue of subProblems, ordered by the provided less function.
+type nodeHeap struct {
+	nodes nodeSlice
+	added int64
+}
+
+func newNodeHeap(less func(a, b queuedNode) bool) nodeHeap {
+	return nodeHeap{nodes: nodeSlice{less: less}}
+}
+
+func (h *nodeHeap) Enqueue(s OpenNode) {
+	h.added++
+	heap.Push(&h.nodes, queuedNode{node: s, seq: h.added})
+}
+
+func (h *nodeHeap) Dequeue() OpenNode {
+	return heap.Pop(&h.nodes).(queuedNode).node
+}
+
+func (h *nodeHeap) Len() int {
+	return h.nodes.Len()
+}
+
+// slice of queued subProblems implementing heap.Interface.
+type nodeSlice struct {
+	entries []queuedNode
+	less    func(a, b queuedNode) bool
+}
+
+func (h nodeSlice) Len() int { return len(h.entries) }
+
+func (h nodeSlice) Less(i, j int) bool { return h.less(h.entries[i], h.entries[j]) }
+
+func (h nodeSlice) Swap(i, j int) { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+
+func (h *nodeSlice) Push(x interface{}) {
+	h.entries = append(h.entries, x.(queuedNode))
+}
+
+func (h *nodeSlice) Pop() interface{} {
+	n := len(h.entries)
+	s := h.entries[n-1]
+	h.entries = h.entries[:n-1]
+	return s
+}
@@ -0,0 +1,198 @@
+package ilp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeQueue(t *testing.T) {
+	// a subProblem with the provided number of branch-and-bound constraints
+	atDepth := func(depth int) []bnbConstraint {
+		return make([]bnbConstraint, depth)
+	}
+
+	nodes := []subProblem{
+		{id: 1, parent: -1, bound: -3, estimate: -2, bnbConstraints: atDepth(1)},
+		{id: 2, parent: 1, bound: -5, estimate: -1, bnbConstraints: atDepth(2)},
+		{id: 3, parent: 1, bound: -1, estimate: -4, bnbConstraints: atDepth(2)},
+		{id: 4, bound: -5, estimate: -2, bnbConstraints: atDepth(1)},
+	}
+
+	tests := []struct {
+		name  string
+		queue NodeQueue
+		want  []int64
+	}{
+		{
+			name:  "FIFO",
+			queue: NewFIFOQueue(),
+			want:  []int64{1, 2, 3, 4},
+		},
+		{
+			name:  "best bound",
+			queue: NewBestBoundQueue(),
+			want:  []int64{2, 4, 1, 3},
+		},
+		{
+			name:  "depth first",
+			queue: NewDepthFirstQueue(),
+			want:  []int64{2, 3, 4, 1},
+		},
+		{
+			name:  "best estimate",
+			queue: NewBestEstimateQueue(),
+			want:  []int64{3, 1, 4, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, n := range nodes {
+				tt.queue.Enqueue(newOpenNode(n))
+			}
+			assert.Equal(t, len(nodes), tt.queue.Len())
+
+			var got []int64
+			for tt.queue.Len() > 0 {
+				got = append(got, tt.queue.Dequeue().ID)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// Best-bound node selection should find the same optimum as the default FIFO node selection.
+func TestMilpProblem_Solve_BestBound(t *testing.T) {
+	prob := getTestKnapsackMILP()
+
+	var nodes int64
+	solve := func(p milpProblem) solution {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		result, err := p.solve(ctx, 1, dummyMiddleware{})
+		got := result.best()
+		assert.NoError(t, err)
+		nodes = result.Stats.NodesExplored
+		return got
+	}
+
+	fifo := solve(prob)
+	fifoNodes := nodes
+
+	prob.nodeSelection = NewBestBoundQueue
+	bestBound := solve(prob)
+
+	assert.Equal(t, float64(-24), fifo.z)
+	assert.InDelta(t, fifo.z, bestBound.z, 1e-9)
+	assert.InDelta(t, bestBound.z, bestBound.bestBound, 1e-9)
+	assert.True(t, nodes < fifoNodes, "expected fewer nodes with best-bound node selection: %v with best-bound, %v with FIFO", nodes, fifoNodes)
+
+	// a queue implemented with the exported fields of the open nodes only
+	queue := &shallowestFirstQueue{}
+	prob.nodeSelection = func() NodeQueue { return queue }
+	custom := solve(prob)
+	assert.InDelta(t, fifo.z, custom.z, 1e-9)
+	assert.True(t, queue.enqueued > 0)
+}
+
+// a custom queue that explores the shallowest subProblem first, breaking ties by ID.
+type shallowestFirstQueue struct {
+	nodes    []OpenNode
+	enqueued int
+}
+
+func (q *shallowestFirstQueue) Enqueue(n OpenNode) {
+	q.enqueued++
+	q.nodes = append(q.nodes, n)
+}
+
+func (q *shallowestFirstQueue) Dequeue() OpenNode {
+	best := 0
+	for i, n := range q.nodes {
+		if n.Depth < q.nodes[best].Depth || n.Depth == q.nodes[best].Depth && n.ID < q.nodes[best].ID {
+			best = i
+		}
+	}
+	next := q.nodes[best]
+	q.nodes = append(q.nodes[:best], q.nodes[best+1:]...)
+	return next
+}
+
+func (q *shallowestFirstQueue) Len() int {
+	return len(q.nodes)
+}
+
+// records the number of subProblems checked before the first integer-feasible solution was found.
+type firstFeasibleCounter struct {
+	checked int
+	first   int
+}
+
+func (f *firstFeasibleCounter) NewSubProblem(s subProblem) {}
+
+func (f *firstFeasibleCounter) ProcessDecision(s solution, d bnbDecision) {
+	f.checked++
+	if d == BETTER_THAN_INCUMBENT_FEASIBLE && f.first == 0 {
+		f.first = f.checked
+	}
+}
+
+// Depth-first node selection should find an integer-feasible solution before any of the other strategies.
+func TestMilpProblem_Solve_DepthFirst(t *testing.T) {
+	prob := milpProblem{
+		c: []float64{-3, -8, -1, -6, -4},
+		G: NewDenseConstraints(3, 5, []float64{
+			8, 8, 4, 9, 3,
+			6, 5, 8, 1, 7,
+			8, 5, 2, 8, 9,
+		}),
+		h:                      []float64{39.5, 26.5, 26.5},
+		integralityConstraints: []bool{true, true, true, true, true},
+		branchingHeuristic:     BRANCH_MOST_INFEASIBLE,
+	}
+
+	firstFeasible := func(strategy NodeSelectionStrategy) int {
+		prob.nodeSelection = strategy.factory()
+		counter := &firstFeasibleCounter{}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		result, err := prob.solve(ctx, 1, counter)
+		got := result.best()
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(-32), got.z)
+		return counter.first
+	}
+
+	depthFirst := firstFeasible(NODE_DEPTH_FIRST)
+	for _, strategy := range []NodeSelectionStrategy{NODE_FIFO, NODE_BEST_BOUND, NODE_BEST_ESTIMATE} {
+		other := firstFeasible(strategy)
+		assert.True(t, depthFirst < other, "expected depth-first to find a feasible solution first: after %v nodes with depth-first, %v with strategy %v", depthFirst, other, strategy)
+	}
+}
+
+// subProblems that are solved again with lazy constraints keep their place in the best-estimate order, rather than jumping the queue.
+func TestEnumerationTree_requeue_BestEstimate(t *testing.T) {
+	prob := milpProblem{
+		c:                      []float64{-1, -1},
+		G:                      NewDenseConstraints(1, 2, []float64{1, 1}),
+		h:                      []float64{2},
+		integralityConstraints: []bool{true, true},
+	}
+	tree := newEnumerationTree(prob.toInitialSubproblem(), dummyMiddleware{}, SolverConfig{}, NewBestEstimateQueue)
+	candidate := tree.rootProblem.solve()
+	if !assert.NoError(t, candidate.err) {
+		return
+	}
+
+	open := tree.rootProblem
+	open.estimate = -1
+	tree.addNewProblems(&open)
+
+	tree.requeue(candidate, []bnbConstraint{{branchedVariable: noBranchedVariable, hsharp: 1, gsharp: []float64{1, 1, 0}}})
+	assert.Equal(t, candidate.z, tree.queue.Dequeue().Estimate)
+	assert.Equal(t, open.id, tree.queue.Dequeue().ID)
+}
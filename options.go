@@ -0,0 +1,127 @@
+package ilp
+
+import (
+	"context"
+	"time"
+)
+
+// SolveOptions contains the parameters of a single call to Problem.Solve.
+// The defaults are taken from the settings of the Problem, so options only need to be provided for the parameters that should deviate from those.
+type SolveOptions struct {
+	// number of workers traversing the enumeration tree concurrently
+	Workers int
+
+	// the maximum duration of the solve. Zero means no time limit other than the deadline of the context, if any.
+	TimeLimit time.Duration
+
+	// stop the search once the relative or absolute gap between the incumbent and the best bound is within the tolerance. Zero disables the criterion.
+	RelativeGapTolerance float64
+	AbsoluteGapTolerance float64
+
+	// the maximum number of subProblems checked before the search is stopped. Zero means unlimited.
+	NodeLimit int64
+
+	// the heuristic to determine the variable to branch on
+	BranchHeuristic BranchHeuristic
+
+	// instrumentation of the branch-and-bound procedure
+	Middleware BnbMiddleware
+
+	// whether to apply the presolve procedure before solving
+	Presolve bool
+
+	// values of the variables keyed by name, e.g. found by a heuristic, with which the search starts as incumbent if they are feasible.
+	// An infeasible initial solution is ignored, which is reported in the warnings of the Solution.
+	InitialSolution map[string]float64
+}
+
+// SolveOption sets a parameter of the SolveOptions.
+type SolveOption func(*SolveOptions)
+
+func WithWorkers(n int) SolveOption {
+	return func(o *SolveOptions) {
+		o.Workers = n
+	}
+}
+
+func WithTimeLimit(d time.Duration) SolveOption {
+	return func(o *SolveOptions) {
+		o.TimeLimit = d
+	}
+}
+
+func WithMIPGapRelative(eps float64) SolveOption {
+	return func(o *SolveOptions) {
+		o.RelativeGapTolerance = eps
+	}
+}
+
+func WithMIPGapAbsolute(eps float64) SolveOption {
+	return func(o *SolveOptions) {
+		o.AbsoluteGapTolerance = eps
+	}
+}
+
+func WithNodeLimit(n int64) SolveOption {
+	return func(o *SolveOptions) {
+		o.NodeLimit = n
+	}
+}
+
+func WithBranchHeuristic(h BranchHeuristic) SolveOption {
+	return func(o *SolveOptions) {
+		o.BranchHeuristic = h
+	}
+}
+
+func WithMiddleware(m BnbMiddleware) SolveOption {
+	return func(o *SolveOptions) {
+		o.Middleware = m
+	}
+}
+
+func WithPresolve(enabled bool) SolveOption {
+	return func(o *SolveOptions) {
+		o.Presolve = enabled
+	}
+}
+
+func WithInitialSolution(values map[string]float64) SolveOption {
+	return func(o *SolveOptions) {
+		o.InitialSolution = values
+	}
+}
+
+// the options corresponding to the current settings of the problem.
+func (p Problem) solveOptions() SolveOptions {
+	return SolveOptions{
+		Workers:              p.workers,
+		RelativeGapTolerance: p.config.RelativeGapTolerance,
+		AbsoluteGapTolerance: p.config.AbsoluteGapTolerance,
+		NodeLimit:            p.config.NodeLimit,
+		BranchHeuristic:      p.branchingHeuristic,
+		Middleware:           p.instrumentation,
+		Presolve:             !p.skipPresolve,
+	}
+}
+
+// return a copy of the problem of which the settings are replaced by the options.
+// Note that the variables and constraints are shared with the original problem.
+func (p Problem) withOptions(o SolveOptions) Problem {
+	p.workers = o.Workers
+	p.config.RelativeGapTolerance = o.RelativeGapTolerance
+	p.config.AbsoluteGapTolerance = o.AbsoluteGapTolerance
+	p.config.NodeLimit = o.NodeLimit
+	p.branchingHeuristic = o.BranchHeuristic
+	p.instrumentation = o.Middleware
+	p.skipPresolve = !o.Presolve
+	return p
+}
+
+// apply the time limit of the options to the context, if any.
+func (o SolveOptions) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.TimeLimit > 0 {
+		return context.WithTimeout(ctx, o.TimeLimit)
+	}
+	return context.WithCancel(ctx)
+}
@@ -0,0 +1,66 @@
+package ilp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblem_SolveOptions(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(-1).IsInteger()
+	y := prob.AddVariable("y").SetCoeff(-1.5).IsInteger()
+	prob.AddConstraint("").AddExpression(2, x).AddExpression(1, y).SmallerThanOrEqualTo(4.5)
+	prob.AddConstraint("").AddExpression(1, y).SmallerThanOrEqualTo(1)
+
+	// the options override the settings of the problem
+	counter := &decisionCounter{}
+	soln, err := prob.Solve(context.Background(),
+		WithWorkers(2),
+		WithPresolve(false),
+		WithMiddleware(counter),
+		WithBranchHeuristic(BRANCH_MOST_INFEASIBLE),
+		WithTimeLimit(time.Second),
+	)
+	assert.NoError(t, err)
+	assert.True(t, counter.decisions > 1, "expected the middleware to be called for each checked subProblem")
+
+	val, err := soln.GetValueFor("x")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), val)
+
+	val, err = soln.GetValueFor("y")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), val)
+
+	// the problem itself is not modified by the options
+	assert.Equal(t, 1, prob.workers)
+	assert.False(t, prob.skipPresolve)
+	assert.Equal(t, dummyMiddleware{}, prob.instrumentation)
+
+	// the root relaxation is fractional, so the search is stopped after checking it
+	_, err = prob.Solve(context.Background(), WithPresolve(false), WithNodeLimit(1))
+	assert.Equal(t, ErrNodeLimitExceeded, err)
+}
+
+func TestProblem_SolveStats(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(-1).IsInteger()
+	y := prob.AddVariable("y").SetCoeff(-1.5).IsInteger()
+	prob.AddConstraint("").AddExpression(2, x).AddExpression(1, y).SmallerThanOrEqualTo(4.5)
+	prob.AddConstraint("").AddExpression(1, y).SmallerThanOrEqualTo(1)
+
+	soln, err := prob.Solve(context.Background(), WithPresolve(false), WithBranchHeuristic(BRANCH_MOST_INFEASIBLE))
+	assert.NoError(t, err)
+
+	// the initial relaxation x = 1.75, y = 1 is fractional, so the problem requires branching
+	stats := soln.Stats
+	assert.Equal(t, -3.25, stats.RootLPBound)
+	assert.True(t, stats.NodesExplored > 1)
+	assert.True(t, stats.NodesCreated >= stats.NodesExplored)
+	assert.True(t, stats.LPRelaxationsSolved >= stats.NodesExplored)
+	assert.True(t, stats.WallTime > 0)
+	assert.True(t, stats.WallTime >= stats.PresolveStats.Duration)
+}
@@ -0,0 +1,132 @@
+package ilp
+
+import (
+	"math"
+	"sync"
+)
+
+// EnableParallelPresolve makes each pass of the presolve procedure apply the removal of fixed variables, the detection of implicitly fixed variables,
+// and the removal of empty and duplicate constraints concurrently rather than one after the other (see parallelPreSolve).
+// The reduced problem may differ in the number of passes needed to reach it, but not in its solution. Has no effect if the presolve procedure is disabled.
+func (p *Problem) EnableParallelPresolve() *Problem {
+	defer p.mustModify()()
+	p.parallelPresolve = true
+	return p
+}
+
+// a presolve operation that reduces a problem. It may modify the problem in-place and register undoers with the preProcessor.
+type presolvePass func(prepper *preProcessor, p Problem) Problem
+
+// the result of applying a presolve operation to its own copy of the problem.
+type presolvePassResult struct {
+	prepper *preProcessor
+	reduced Problem
+
+	// the position of each constraint of the copy in the problem it was copied from
+	origin map[*Constraint]int
+}
+
+// Apply filterFixedVars, findImplicitlyFixedVars, removeEmptyConstraints and removeDuplicateConstraints concurrently to the problem.
+// Each operation modifies the variables and constraints of the problem in-place, so each one is applied to its own copy of the problem (see copyProblem).
+// The results are merged by retaining the variables and constraints that are retained by all operations, i.e. the intersection of the retained sets,
+// of which the variables take the most restrictive bounds found by any operation. The constraints are those reduced by filterFixedVars.
+//
+// Unlike the serial presolve procedure, the operations do not see each other's reductions within a pass, e.g. the constraints that are emptied by removing the fixed variables,
+// or the variables found to be fixed at zero. These are picked up by the next pass of the presolve procedure.
+// Like the other presolve operations, the undoers are registered with the preProcessor.
+func (prepper *preProcessor) parallelPreSolve(p Problem) Problem {
+	passes := []presolvePass{
+		(*preProcessor).filterFixedVars,
+		(*preProcessor).findImplicitlyFixedVars,
+		func(_ *preProcessor, p Problem) Problem { return removeEmptyConstraints(p) },
+		func(_ *preProcessor, p Problem) Problem { return removeDuplicateConstraints(p) },
+	}
+
+	results := make([]presolvePassResult, len(passes))
+	var wg sync.WaitGroup
+	for i, pass := range passes {
+		wg.Add(1)
+		go func(i int, pass presolvePass) {
+			defer wg.Done()
+
+			// copying only reads the problem, which is not modified until all operations are done
+			cp := copyProblem(p)
+			origin := make(map[*Constraint]int, len(cp.constraints))
+			for j, c := range cp.constraints {
+				origin[c] = j
+			}
+
+			sub := newPreprocessor()
+			results[i] = presolvePassResult{prepper: sub, reduced: pass(sub, cp), origin: origin}
+		}(i, pass)
+	}
+	wg.Wait()
+
+	merged := results[0].reduced
+
+	// retain the variables retained by all operations, with the most restrictive bounds
+	retainedVars := make(map[string]int)
+	lower := make(map[string]float64)
+	upper := make(map[string]float64)
+	for _, r := range results {
+		for _, v := range r.reduced.variables {
+			if retainedVars[v.name] == 0 {
+				lower[v.name], upper[v.name] = v.lower, v.upper
+			}
+			retainedVars[v.name]++
+			lower[v.name] = math.Max(lower[v.name], v.lower)
+			upper[v.name] = math.Min(upper[v.name], v.upper)
+		}
+	}
+
+	var variables []*Variable
+	for _, v := range merged.variables {
+		if retainedVars[v.name] == len(results) {
+			v.lower, v.upper = lower[v.name], upper[v.name]
+			variables = append(variables, v)
+		}
+	}
+	merged.variables = variables
+
+	// retain the constraints retained by all operations
+	retainedConstraints := make([]int, len(p.constraints))
+	for _, r := range results {
+		for _, c := range r.reduced.constraints {
+			retainedConstraints[r.origin[c]]++
+		}
+	}
+
+	var constraints []*Constraint
+	for _, c := range merged.constraints {
+		if retainedConstraints[results[0].origin[c]] == len(results) {
+			constraints = append(constraints, c)
+		}
+	}
+	merged.constraints = constraints
+
+	// a constraint that is both empty and a duplicate of another empty constraint is counted as empty
+	keptBy := func(r presolvePassResult) []bool {
+		kept := make([]bool, len(p.constraints))
+		for _, c := range r.reduced.constraints {
+			kept[r.origin[c]] = true
+		}
+		return kept
+	}
+	nonempty, unique := keptBy(results[2]), keptBy(results[3]) // the results of removeEmptyConstraints and removeDuplicateConstraints
+	for j := range p.constraints {
+		switch {
+		case !nonempty[j]:
+			prepper.stats.EmptyConstraintsRemoved++
+		case !unique[j]:
+			prepper.stats.DuplicateConstraintsRemoved++
+		}
+	}
+
+	for _, r := range results {
+		prepper.stats.FixedVariablesRemoved += r.prepper.stats.FixedVariablesRemoved
+		prepper.stats.ImpliedZeroVariables += r.prepper.stats.ImpliedZeroVariables
+		prepper.undoers = append(prepper.undoers, r.prepper.undoers...)
+	}
+
+	return merged
+}
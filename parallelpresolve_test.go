@@ -0,0 +1,136 @@
+package ilp
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// a problem of which the presolve procedure removes fixed, implicitly fixed, empty and duplicate variables and constraints.
+func getParallelPresolveTestProblem() Problem {
+	prob := NewProblem()
+	v1 := prob.AddVariable("v1").SetCoeff(-1)
+	v2 := prob.AddVariable("v2").SetCoeff(-1)
+	v3 := prob.AddVariable("v3").SetCoeff(-1).LowerBound(2).UpperBound(2)
+	v4 := prob.AddVariable("v4").SetCoeff(-1)
+	v5 := prob.AddVariable("v5").SetCoeff(-1)
+
+	// v3 is fixed, which bounds v1 by 3
+	prob.AddConstraint("").AddExpression(1, v1).AddExpression(1, v3).SmallerThanOrEqualTo(5)
+
+	// v2 is implicitly fixed at zero, after which the constraint is empty
+	prob.AddConstraint("").AddExpression(1, v2).AddExpression(1, v2).SmallerThanOrEqualTo(0)
+
+	// a duplicate constraint with a larger right-hand side
+	prob.AddConstraint("").AddExpression(1, v4).AddExpression(1, v5).SmallerThanOrEqualTo(4)
+	prob.AddConstraint("").AddExpression(1, v5).AddExpression(1, v4).SmallerThanOrEqualTo(6)
+
+	prob.AddConstraint("").AddExpression(1, v2).AddExpression(1, v1).AddExpression(1, v3).SmallerThanOrEqualTo(7)
+
+	return prob
+}
+
+func TestPreSolve_Parallel(t *testing.T) {
+	serial, serialStats := newPreprocessor().preSolve(getParallelPresolveTestProblem())
+
+	prob := getParallelPresolveTestProblem()
+	prob.EnableParallelPresolve()
+	parallel, parallelStats := newPreprocessor().preSolve(prob)
+
+	// the parallel procedure arrives at the same reduced problem, although it may take more passes
+	assert.Equal(t, serial.toSolveable(), parallel.toSolveable())
+	assert.True(t, parallelStats.Passes >= serialStats.Passes)
+	assert.Equal(t, serialStats.FixedVariablesRemoved, parallelStats.FixedVariablesRemoved)
+	assert.Equal(t, serialStats.ImpliedZeroVariables, parallelStats.ImpliedZeroVariables)
+	assert.Equal(t, serialStats.EmptyConstraintsRemoved+serialStats.DuplicateConstraintsRemoved+serialStats.RedundantConstraintsRemoved,
+		parallelStats.EmptyConstraintsRemoved+parallelStats.DuplicateConstraintsRemoved+parallelStats.RedundantConstraintsRemoved)
+
+	// the problem of the user is left untouched
+	assert.Len(t, prob.variables, 5)
+	assert.Len(t, prob.constraints, 5)
+	assert.Equal(t, 2., prob.variables[2].lower)
+
+	// and the solutions are the same
+	want, err := getParallelPresolveTestProblem().Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	got, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, want.Objective, got.Objective)
+	assert.Equal(t, want.ToMap(), got.ToMap())
+}
+
+func Test_parallelPreSolve(t *testing.T) {
+	prob := sanitizeProblem(copyProblem(getParallelPresolveTestProblem()))
+	prepper := newPreprocessor()
+	reduced := prepper.parallelPreSolve(prob)
+
+	// v3 is removed, v2 is fixed at zero, and the duplicate constraint is removed.
+	// The second constraint is only empty once v2 is removed, which is left to the next pass.
+	var names []string
+	for _, v := range reduced.variables {
+		names = append(names, v.name)
+	}
+	assert.Equal(t, []string{"v1", "v2", "v4", "v5"}, names)
+	assert.Equal(t, 0., reduced.variables[1].upper)
+	assert.Len(t, reduced.constraints, 4)
+	assert.Equal(t, 3., reduced.constraints[0].rhs)
+	assert.Equal(t, 4., reduced.constraints[2].rhs)
+
+	assert.Equal(t, 1, prepper.stats.FixedVariablesRemoved)
+	assert.Equal(t, 1, prepper.stats.ImpliedZeroVariables)
+	assert.Equal(t, 0, prepper.stats.EmptyConstraintsRemoved)
+	assert.Equal(t, 1, prepper.stats.DuplicateConstraintsRemoved)
+	assert.Len(t, prepper.undoers, 1)
+}
+
+// a problem of 200 variables, of which some are fixed, with 200 constraints of which some are empty or duplicates.
+func getPresolveBenchmarkProblem() Problem {
+	rnd := rand.New(rand.NewSource(42))
+	prob := NewProblem()
+	var vars []*Variable
+	for i := 0; i < 200; i++ {
+		v := prob.AddVariable(fmt.Sprintf("x%d", i)).SetCoeff(-rnd.Float64()).UpperBound(10)
+		if i%10 == 0 {
+			v.LowerBound(1).UpperBound(1)
+		}
+		vars = append(vars, v)
+	}
+	for i := 0; i < 200; i++ {
+		row := i - i%4/3
+		c := prob.AddConstraint("")
+		for j, v := range vars {
+			if (row+j)%7 == 0 {
+				c.AddExpression(float64(row%5+1), v)
+			}
+		}
+		c.SmallerThanOrEqualTo(float64(100 + i))
+	}
+	return prob
+}
+
+func BenchmarkPreSolve_Parallel(b *testing.B) {
+	prob := sanitizeProblem(copyProblem(getPresolveBenchmarkProblem()))
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			prepper := newPreprocessor()
+			p := prepper.filterFixedVars(copyProblem(prob))
+			p = prepper.findImplicitlyFixedVars(p)
+			p = removeEmptyConstraints(p)
+			removeDuplicateConstraints(p)
+		}
+	})
+
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			newPreprocessor().parallelPreSolve(prob)
+		}
+	})
+}
@@ -2,8 +2,12 @@ package ilp
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
 
-	"github.com/deckarep/golang-set"
+	"gonum.org/v1/gonum/optimize/convex/lp"
 )
 
 // TODO: see Andersen 1995 for a nice enumeration of simple presolving operations.
@@ -13,6 +17,9 @@ import (
 // store all post-solving operations that bring the solution back to its input shape.
 type preProcessor struct {
 	undoers []undoer
+
+	// statistics of the presolve operations applied so far
+	stats PresolveStats
 }
 
 // map variable names to their computed optimal values
@@ -21,10 +28,31 @@ type rawSolution map[string]float64
 
 // Solution contains the results of a solved Problem.
 type Solution struct {
+	// the objective value of the solution, including the objective offset of the problem
 	Objective float64
 
+	// the outcome of the solve, which tells whether the values of the variables are optimal, merely feasible, or absent
+	Status SolveStatus
+
+	// the best known bound on the objective value of the (presolved) problem and the relative gap between the objective value of the solution and this bound.
+	// Only set if an integer-feasible solution was found.
+	BestBound float64
+	MIPGap    float64
+
+	// statistics describing the effort spent on solving the problem
+	Stats SolveStats
+
+	// describes the problems with the input that did not prevent solving, e.g. an infeasible initial solution that was ignored
+	Warnings []string
+
 	// keyed by name
 	byName map[string]float64
+
+	// the constraints of the solved problem, used to compute their slack
+	constraints []*Constraint
+
+	// the names of the variables of the solved problem, in the order in which they were added to it, excluding internal variables
+	variables []string
 }
 
 // GetValueFor retrieves the value for a decision variable by its name.
@@ -36,31 +64,216 @@ func (s *Solution) GetValueFor(varName string) (float64, error) {
 	return val, nil
 }
 
+// ByVariableOrder returns the values of the variables in the order in which they were added to the problem,
+// e.g. to multiply them with the constraint matrix of the problem. Returns nil if the solution holds no values.
+// Note that the internal variables, such as the indicators of cardinality constraints, are omitted.
+func (s *Solution) ByVariableOrder() []float64 {
+	if s.byName == nil {
+		return nil
+	}
+
+	x := make([]float64, len(s.variables))
+	for i, name := range s.variables {
+		x[i] = s.byName[name]
+	}
+	return x
+}
+
+// ToMap returns the values of the variables keyed by name. Returns nil if the solution holds no values.
+// The map is a copy, so modifying it does not affect the solution.
+func (s *Solution) ToMap() map[string]float64 {
+	if s.byName == nil {
+		return nil
+	}
+
+	values := make(map[string]float64, len(s.byName))
+	for name, val := range s.byName {
+		values[name] = val
+	}
+	return values
+}
+
+// ConstraintSlack computes the slack of the named 'smaller than or equal to' constraint, or the surplus of the named 'greater than or equal to' constraint,
+// from the values of the variables in the solution. Note that the current definition of the constraint is used, which may have been modified after solving.
+func (s *Solution) ConstraintSlack(name string) (float64, error) {
+	constraint, err := s.namedConstraint(name)
+	if err != nil {
+		return 0, err
+	}
+	if !constraint.inequality {
+		return 0, fmt.Errorf("constraint %v is not an inequality", name)
+	}
+	return s.slack(constraint)
+}
+
+// ConstraintViolation computes the amount by which the named constraint is violated by the values of the variables in the solution.
+// For an inequality, this is the negated slack (see ConstraintSlack), so it is positive if the inequality is violated and negative if it is not tight.
+// For an equality, this is the absolute difference between its left-hand side and right-hand side.
+// Note that the current definition of the constraint is used, which may have been modified after solving.
+func (s *Solution) ConstraintViolation(name string) (float64, error) {
+	constraint, err := s.namedConstraint(name)
+	if err != nil {
+		return 0, err
+	}
+	return s.violation(constraint)
+}
+
+// AllViolations computes the violation of each named constraint (see ConstraintViolation), keyed by name. Unnamed constraints are omitted.
+func (s *Solution) AllViolations() (map[string]float64, error) {
+	violations := make(map[string]float64)
+	for _, c := range s.constraints {
+		if c.name == "" {
+			continue
+		}
+		violation, err := s.violation(c)
+		if err != nil {
+			return nil, err
+		}
+		violations[c.name] = violation
+	}
+	return violations, nil
+}
+
+// find the constraint of the solved problem by its name.
+func (s *Solution) namedConstraint(name string) (*Constraint, error) {
+	for _, c := range s.constraints {
+		if c.name == name && name != "" {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("Constraint name %v not found in Solution", name)
+}
+
+// the slack of a 'smaller than or equal to' constraint or the surplus of a 'greater than or equal to' constraint.
+func (s *Solution) slack(constraint *Constraint) (float64, error) {
+	lhs, err := s.lhs(constraint)
+	if err != nil {
+		return 0, err
+	}
+	if constraint.greaterThanOrEqual {
+		return lhs - constraint.rhs, nil
+	}
+	return constraint.rhs - lhs, nil
+}
+
+// the amount by which the constraint is violated.
+func (s *Solution) violation(constraint *Constraint) (float64, error) {
+	if constraint.inequality {
+		slack, err := s.slack(constraint)
+		return -slack, err
+	}
+
+	lhs, err := s.lhs(constraint)
+	if err != nil {
+		return 0, err
+	}
+	return math.Abs(lhs - constraint.rhs), nil
+}
+
+// evaluate the left-hand side of the constraint, including the big-M term (if any), for the values of the variables in the solution.
+func (s *Solution) lhs(constraint *Constraint) (float64, error) {
+	lhs := 0.0
+	for _, e := range constraint.lhsExpressions() {
+		val, err := s.GetValueFor(e.variable.name)
+		if err != nil {
+			return 0, err
+		}
+		lhs += e.coef * val
+	}
+	return lhs, nil
+}
+
 type undoer func(rawSolution) rawSolution
 
 func newPreprocessor() *preProcessor {
 	return &preProcessor{}
 }
 
+// PresolveStats describes the reductions made by the presolve procedure.
+type PresolveStats struct {
+	// the size of the problem before and after presolving
+	OriginalVariables   int
+	ReducedVariables    int
+	OriginalConstraints int
+	ReducedConstraints  int
+
+	// the number of variables with equal bounds that were removed from the problem
+	FixedVariablesRemoved int
+
+	// the number of variables found to be implicitly fixed at zero by the constraints
+	ImpliedZeroVariables int
+
+	// the number of constraints removed because they no longer contained any variables
+	EmptyConstraintsRemoved int
+
+	// the number of constraints removed because another constraint had the same left-hand side
+	DuplicateConstraintsRemoved int
+
+	// the number of constraints removed because the bounds of their variables imply them
+	RedundantConstraintsRemoved int
+
+	// the number of variable bounds tightened using the constraints
+	BoundsTightened int
+
+	// the number of binary variables fixed by probing
+	ProbingFixings int
+
+	// the number of free variables substituted out of the problem along with the equality constraint they appeared in
+	SubstitutedVariables int
+
+	// the number of passes over the presolve operations
+	Passes int
+
+	// the duration of the presolve procedure
+	Duration time.Duration
+}
+
 func (prepper *preProcessor) addUndoer(u undoer) {
 	prepper.undoers = append(prepper.undoers, u)
 }
 
-func (prepper *preProcessor) preSolve(p Problem) Problem {
-
-	fmt.Printf("Presolving problem with %v variables and %v constraints\n", len(p.variables), len(p.constraints))
+// Presolve the problem, returning the reduced problem and statistics describing the reductions.
+func (prepper *preProcessor) preSolve(p Problem) (Problem, PresolveStats) {
+	start := time.Now()
+	prepper.stats.OriginalVariables = len(p.variables)
+	prepper.stats.OriginalConstraints = len(p.constraints)
 
+	// The presolve operations modify the variables and constraints of the problem in-place,
+	// so we work on a copy to leave the problem definition of the user untouched.
 	// remove redundancies caused by the user.
-	preprocessed := sanitizeProblem(p)
+	preprocessed := sanitizeProblem(copyProblem(p))
 
 	// loop over the prepping operations until no more modifications are performed
 	previousNUndoers := 0
 presolve:
 	for {
-		preprocessed = prepper.filterFixedVars(preprocessed)
-		preprocessed = prepper.findImplicitlyFixedVars(preprocessed)
-		preprocessed = removeEmptyConstraints(preprocessed)
-		preprocessed = removeDuplicateConstraints(preprocessed)
+		prepper.stats.Passes++
+
+		// substitute the free variables before bound tightening gives them bounds
+		preprocessed = prepper.substituteFixedSingletons(preprocessed)
+		preprocessed = prepper.tightenBounds(preprocessed)
+		preprocessed = prepper.probeBinaryVariables(preprocessed)
+
+		if preprocessed.parallelPresolve {
+			preprocessed = prepper.parallelPreSolve(preprocessed)
+		} else {
+			preprocessed = prepper.filterFixedVars(preprocessed)
+			preprocessed = prepper.findImplicitlyFixedVars(preprocessed)
+
+			nConstraints := len(preprocessed.constraints)
+			preprocessed = removeEmptyConstraints(preprocessed)
+			prepper.stats.EmptyConstraintsRemoved += nConstraints - len(preprocessed.constraints)
+
+			nConstraints = len(preprocessed.constraints)
+			preprocessed = removeDuplicateConstraints(preprocessed)
+			prepper.stats.DuplicateConstraintsRemoved += nConstraints - len(preprocessed.constraints)
+		}
+
+		nConstraints := len(preprocessed.constraints)
+		preprocessed = prepper.detectRedundantConstraints(preprocessed)
+		prepper.stats.RedundantConstraintsRemoved += nConstraints - len(preprocessed.constraints)
+
+		preprocessed = strengthenIntegerCoefficients(preprocessed)
 
 		if len(prepper.undoers) == previousNUndoers {
 			break presolve
@@ -68,11 +281,19 @@ presolve:
 		previousNUndoers = len(prepper.undoers)
 	}
 
-	fmt.Println("presolve done")
+	if preprocessed.scaleCoefficients {
+		preprocessed = prepper.scaleCoefficients(preprocessed)
+	}
+	if preprocessed.equilibrate {
+		preprocessed = prepper.equilibrate(preprocessed)
+	}
 
-	fmt.Printf("Presolving reduced problem to %v variables and %v constraints\n", len(preprocessed.variables), len(preprocessed.constraints))
+	prepper.stats.ReducedVariables = len(preprocessed.variables)
+	prepper.stats.ReducedConstraints = len(preprocessed.constraints)
+	prepper.stats.Duration = time.Since(start)
+	logPresolve(prepper.stats)
 
-	return preprocessed
+	return preprocessed, prepper.stats
 }
 
 func (prepper *preProcessor) postSolve(s rawSolution) Solution {
@@ -85,24 +306,99 @@ func (prepper *preProcessor) postSolve(s rawSolution) Solution {
 	}
 
 	solution := Solution{
-		Objective: 0,
-		byName:    make(map[string]float64),
+		byName: make(map[string]float64),
 	}
 
 	for varName, value := range postsolved {
 		solution.byName[varName] = value
-		solution.Objective = solution.Objective + value
 	}
 
 	return solution
 }
 
+// Make a deep copy of the problem, in which all variables and constraints are copies of the originals.
+// The expressions of the copied constraints point to the copied variables.
+func copyProblem(p Problem) Problem {
+	cp := p
+
+	copies := make(map[*Variable]*Variable, len(p.variables))
+	cp.variables = make([]*Variable, len(p.variables))
+	for i, v := range p.variables {
+		vCopy := *v
+		// the copy is modified while the problem is being solved
+		vCopy.lock = nil
+		cp.variables[i] = &vCopy
+		copies[v] = &vCopy
+	}
+
+	cp.constraints = make([]*Constraint, len(p.constraints))
+	for i, c := range p.constraints {
+		cCopy := *c
+		cCopy.problem = &cp
+		cCopy.expressions = make([]expression, len(c.expressions))
+		for j, e := range c.expressions {
+			cCopy.expressions[j] = expression{coef: e.coef, variable: copies[e.variable]}
+		}
+		if c.bigMIndicator != nil {
+			cCopy.bigMIndicator = copies[c.bigMIndicator]
+		}
+		cp.constraints[i] = &cCopy
+	}
+
+	cp.sos1 = make([]*SOSConstraint, len(p.sos1))
+	for i, s := range p.sos1 {
+		sCopy := SOSConstraint{variables: make([]*Variable, len(s.variables))}
+		for j, v := range s.variables {
+			sCopy.variables[j] = copies[v]
+		}
+		cp.sos1[i] = &sCopy
+	}
+
+	return cp
+}
+
+// scale the problem using equilibration and register an undoer that scales the solution values back to the original problem.
+// If the problem cannot be scaled, it is returned as-is.
+func (prepper *preProcessor) equilibrate(p Problem) Problem {
+	rowScales, colScales, err := p.EquilibrationScale()
+	if err != nil {
+		return p
+	}
+
+	prepper.addUndoer(p.scalingInfo(rowScales, colScales).unscale)
+
+	return p
+}
+
+// scale the problem using geometric mean scaling and register an undoer that scales the solution values back to the original problem.
+// If the problem cannot be scaled, it is returned as-is.
+func (prepper *preProcessor) scaleCoefficients(p Problem) Problem {
+	info, err := p.GeometricMeanScale()
+	if err != nil {
+		return p
+	}
+
+	prepper.addUndoer(info.unscale)
+
+	return p
+}
+
 // remove redundant statements from the problem definition that were introduced by the user.
 // TODO: explicit duplicate constraints
 // TODO: constraints that are superseded by the variable bounds?
 func sanitizeProblem(p Problem) Problem {
 	for _, c := range p.constraints {
 		c.expressions = filterZeroExpressions(c.expressions)
+
+		// negate 'greater than or equal to' inequalities, so that the other presolve operations only have to deal with 'smaller than or equal to' inequalities.
+		if c.greaterThanOrEqual {
+			for i := range c.expressions {
+				c.expressions[i].coef = -c.expressions[i].coef
+			}
+			c.bigM = -c.bigM
+			c.rhs = -c.rhs
+			c.greaterThanOrEqual = false
+		}
 	}
 
 	return p
@@ -131,18 +427,32 @@ func isFixed(variable *Variable) bool {
 func (prepper *preProcessor) filterFixedVars(p Problem) Problem {
 	filteredProb := p
 
+	// a variable of an SOS1 constraint that is fixed at a nonzero value forces the other variables of the set to zero
+	for _, s := range filteredProb.sos1 {
+		for _, v := range s.variables {
+			if isFixed(v) && v.lower != 0 {
+				for _, other := range s.variables {
+					if other != v {
+						other.upper = 0
+					}
+				}
+				break
+			}
+		}
+	}
+
 	var newVars []*Variable
 	fixedVars := make(map[string]float64)
 	for _, v := range filteredProb.variables {
 		if !isFixed(v) {
 			newVars = append(newVars, v)
 		} else {
-			// store the coefficients of the fixed variables in the objective function for injection as a constant during postsolve procedure.
-			fixedVars[v.name] = v.coefficient * v.lower
+			// store the values of the fixed variables for injection into the solution during the postsolve procedure.
+			fixedVars[v.name] = v.lower
 		}
 	}
 
-	fmt.Printf("removed %v fixed variables \n", len(filteredProb.variables)-len(newVars))
+	prepper.stats.FixedVariablesRemoved += len(filteredProb.variables) - len(newVars)
 	filteredProb.variables = newVars
 
 	// update the RHS of the constraint and remove the expression pointing to this variable:
@@ -151,16 +461,33 @@ func (prepper *preProcessor) filterFixedVars(p Problem) Problem {
 		var replacementExpressions []expression
 		for _, e := range c.expressions {
 			if isFixed(e.variable) {
-				c.rhs = c.rhs - (e.variable.coefficient * e.variable.lower)
+				c.rhs = c.rhs - (e.coef * e.variable.lower)
 			} else {
 				replacementExpressions = append(replacementExpressions, e)
 			}
 		}
 		c.expressions = replacementExpressions
+
+		// a fixed big-M indicator turns the big-M term into a constant as well
+		if c.bigMIndicator != nil && isFixed(c.bigMIndicator) {
+			c.rhs = c.rhs + c.bigM*c.bigMIndicator.lower
+			c.bigMIndicator = nil
+		}
 	}
 
-	// the additive constant c0 for each variable in the objective function needs to be updated as
-	// c0 := c0 + cjxj,
+	// the fixed variables no longer take part in the SOS1 constraints
+	for _, s := range filteredProb.sos1 {
+		var remaining []*Variable
+		for _, v := range s.variables {
+			if !isFixed(v) {
+				remaining = append(remaining, v)
+			}
+		}
+		s.variables = remaining
+	}
+
+	// the fixed variables are restored in the solution with their values. Their contribution c_j * x_j to the objective
+	// is accounted for when the objective value of the solution is computed from the original problem.
 	if len(fixedVars) > 0 {
 		undoer := func(s rawSolution) rawSolution {
 			// add the fixed values to the raw solution
@@ -180,9 +507,91 @@ func (prepper *preProcessor) filterFixedVars(p Problem) Problem {
 
 }
 
+// Substitute the continuous free variables that appear in a single equality constraint, and nowhere else, out of the problem.
+// If x_j only appears in sum(a_k * x_k) = b, then x_j = (b - sum_{k!=j} a_k * x_k) / a_j is determined by the other variables of the constraint,
+// and since x_j is unbounded, the constraint does not restrict these variables. Hence, both x_j and the constraint can be removed,
+// after substituting x_j in the objective function. The undoer computes the value of x_j from the values of the other variables.
+// As the other variables may be removed by later presolve operations, the undoers must be applied in reverse order (see postSolve).
+// This procedure modifies the objective coefficients of the variables of the problem, so it should only be applied to a copy of the Problem of the user (see copyProblem).
+func (prepper *preProcessor) substituteFixedSingletons(p Problem) Problem {
+	// count the occurrences of each variable in the constraints and SOS1 constraints of the problem
+	occurrences := make(map[*Variable]int)
+	for _, c := range p.constraints {
+		for _, e := range c.lhsExpressions() {
+			occurrences[e.variable]++
+		}
+	}
+	for _, s := range p.sos1 {
+		for _, v := range s.variables {
+			occurrences[v]++
+		}
+	}
+
+	substituted := make(map[*Variable]bool)
+	removed := make(map[*Constraint]bool)
+	for _, c := range p.constraints {
+		if c.inequality || c.bigMIndicator != nil {
+			continue
+		}
+
+		for j, e := range c.expressions {
+			v := e.variable
+			if occurrences[v] != 1 || v.integer || !math.IsInf(v.lower, -1) || !math.IsInf(v.upper, 1) {
+				continue
+			}
+
+			// substitute x_j in the objective function
+			others := make([]expression, 0, len(c.expressions)-1)
+			others = append(others, c.expressions[:j]...)
+			others = append(others, c.expressions[j+1:]...)
+			ratio := v.coefficient / e.coef
+			p.objectiveOffset += ratio * c.rhs
+			for _, other := range others {
+				other.variable.coefficient -= ratio * other.coef
+			}
+
+			name, coef, rhs := v.name, e.coef, c.rhs
+			prepper.addUndoer(func(s rawSolution) rawSolution {
+				value := rhs
+				for _, other := range others {
+					value -= other.coef * s[other.variable.name]
+				}
+				s[name] = value / coef
+				return s
+			})
+
+			substituted[v] = true
+			removed[c] = true
+			break
+		}
+	}
+
+	if len(substituted) == 0 {
+		return p
+	}
+	prepper.stats.SubstitutedVariables += len(substituted)
+
+	var variables []*Variable
+	for _, v := range p.variables {
+		if !substituted[v] {
+			variables = append(variables, v)
+		}
+	}
+	var constraints []*Constraint
+	for _, c := range p.constraints {
+		if !removed[c] {
+			constraints = append(constraints, c)
+		}
+	}
+	p.variables = variables
+	p.constraints = constraints
+
+	return p
+}
+
 // all variables that are implicitly fixed due to the shape of a constraint should be set to be explicitly fixed.
 // Note that this could be part of a second pass; setting the implicitly fixed vars to explicitly fixed and then removing them with filterFixedVars.
-// TODO: However, we dont want to modify the original variables (i.e. set their bounds)
+// This procedure sets the bounds of the variables of the problem, so it should only be applied to a copy of the Problem of the user (see copyProblem).
 // TODO: a more elegant procedure can be considered. This procedure only considers constraint i with bi = 0 and Sij > 0, making it very limited in its application.
 func (prepper *preProcessor) findImplicitlyFixedVars(p Problem) Problem {
 
@@ -191,11 +600,11 @@ func (prepper *preProcessor) findImplicitlyFixedVars(p Problem) Problem {
 		removable := false
 		if c.rhs == 0 {
 
-			// check for any negative coefficients
+			// check for any negative coefficients or variables that can take on negative values
 			nonnegative := true
 		checker:
-			for _, e := range c.expressions {
-				if e.coef < 0 {
+			for _, e := range c.lhsExpressions() {
+				if e.coef < 0 || e.variable.lower < 0 {
 					nonnegative = false
 					break checker
 				}
@@ -217,9 +626,11 @@ func (prepper *preProcessor) findImplicitlyFixedVars(p Problem) Problem {
 		}
 	}
 
-	fmt.Printf("found %v variables implicitly fixed at zero \n", len(implicitZero))
-	//TODO: MODIFIES ORIGINAL PROBLEM: REMOVE ME (just a PoC)
+	// the variables that are already fixed at zero are not counted again, e.g. when parallelPreSolve has not yet removed them
 	for v := range implicitZero {
+		if v.lower != 0 || v.upper != 0 {
+			prepper.stats.ImpliedZeroVariables++
+		}
 		v.LowerBound(0).UpperBound(0)
 	}
 
@@ -235,61 +646,328 @@ func removeEmptyConstraints(p Problem) Problem {
 		}
 	}
 
-	fmt.Printf("removed %v empty constraints\n", len(p.constraints)-len(filtered))
 	p.constraints = filtered
 	return p
 }
 
-// This function may need a rethink if this turns out not to be performant for larger problems.
+// Remove the constraints of which the variable-coefficient expressions are a duplicate of those of another constraint of the same type,
+// retaining the most restrictive one: the one with the smallest right-hand side for 'smaller than or equal to' constraints,
+// and the largest for 'greater than or equal to' constraints. Equality constraints are only duplicates if their right-hand sides are equal as well.
+// The retained constraint takes the place of the first of its duplicates.
 func removeDuplicateConstraints(p Problem) Problem {
 
-	// map each set that uniquely identifies each constraint to the Constraint
-	var sets []mapset.Set
+	// the position in the retained constraints of the constraint retained for each key
+	retainedFor := make(map[string]int)
+
+	var retained []*Constraint
 	for _, constraint := range p.constraints {
+		key := constraintKey(constraint)
 
-		// add the variable names of the constraint to a set
-		cSet := mapset.NewSet()
+		i, duplicate := retainedFor[key]
+		if !duplicate {
+			retainedFor[key] = len(retained)
+			retained = append(retained, constraint)
+			continue
+		}
 
-		for _, e := range constraint.expressions {
-			cSet.Add(fmt.Sprintf("%v-%v", e.variable.name, e.coef))
+		// replace the retained constraint if the duplicate is more restrictive
+		other := retained[i]
+		if constraint.greaterThanOrEqual && constraint.rhs > other.rhs || !constraint.greaterThanOrEqual && constraint.rhs < other.rhs {
+			retained[i] = constraint
 		}
+	}
+
+	// substitute the constraints slice
+	p.constraints = retained
+
+	return p
+
+}
+
+// a key identifying the type and the variable-coefficient expressions of the left-hand side of the constraint, regardless of their order.
+// Repeated expressions count as often as they appear. The key of an equality constraint includes its right-hand side.
+func constraintKey(c *Constraint) string {
+	exprs := c.lhsExpressions()
+	terms := make([]string, len(exprs))
+	for i, e := range exprs {
+		terms[i] = fmt.Sprintf("%v-%v", e.variable.name, e.coef)
+	}
+	sort.Strings(terms)
 
-		sets = append(sets, cSet)
+	kind := "="
+	if c.inequality {
+		kind = "<="
+		if c.greaterThanOrEqual {
+			kind = ">="
+		}
 	}
+	key := kind + " " + strings.Join(terms, " ")
+	if !c.inequality {
+		key += fmt.Sprintf(" %v", c.rhs)
+	}
+	return key
+}
 
-	// cross-compare each set of variable-coefficient expressions.
-	// if we encounter a duplicate, we throw the one with the highest rhs out.
-	var equalExpressions [][]*Constraint
+// Remove the inequalities a * x <= b of a single variable that are implied by the bounds of the variable, i.e. max(a * l, a * u) <= b.
+// Bound tightening turns each such inequality into a bound, after which it is redundant.
+// Detecting redundant constraints with more variables would require solving an LP for each of them, so these are left in place.
+func (prepper *preProcessor) detectRedundantConstraints(p Problem) Problem {
 	var retained []*Constraint
-	for i, s := range sets {
-		unique := true
-		for j := range sets {
-			if i == j {
+	for _, c := range p.constraints {
+		terms := c.lhsExpressions()
+		if c.inequality && len(terms) == 1 {
+			v := terms[0].variable
+			if math.Max(terms[0].coef*v.lower, terms[0].coef*v.upper) <= c.rhs {
+				continue
+			}
+		}
+		retained = append(retained, c)
+	}
+
+	p.constraints = retained
+	return p
+}
+
+// check if the variable is integer-constrained and bounded by [0, 1]
+func isBinary(v *Variable) bool {
+	return v.integer && v.lower == 0 && v.upper == 1
+}
+
+// Strengthen the coefficients of integer variables in inequality constraints, which tightens the LP relaxation without changing the set of integer-feasible points.
+// For an inequality sum(a_k * x_k) <= b with integer x_j bounded by [l_j, u_j], consider the maximum activity M of the other terms given the variable bounds:
+//  - if a_j > 0 and M + a_j * (u_j - 1) < b, the constraint is redundant for x_j <= u_j - 1. With d = b - M - a_j * (u_j - 1), we can set a_j := a_j - d and b := b - d * u_j.
+//  - if a_j < 0 and M + a_j * (l_j + 1) < b, the constraint is redundant for x_j >= l_j + 1. With d = b - M - a_j * (l_j + 1), we can set a_j := a_j + d and b := b + d * l_j.
+// For binary x_j, this is the classic way to tighten overly large big-M coefficients. If x_j can take on more than two values, the constraint is only
+// left intact for the values in between if d <= |a_j|, so larger reductions are skipped.
+// See Savelsbergh 1994, Preprocessing and probing techniques for mixed integer programming problems.
+func strengthenIntegerCoefficients(p Problem) Problem {
+	for _, c := range p.constraints {
+		if !c.inequality {
+			continue
+		}
+
+		terms := c.lhsExpressions()
+		for j, term := range terms {
+			v := term.variable
+			if !v.integer || term.coef == 0 {
+				continue
+			}
+
+			// compute the maximum activity of the other terms of the constraint
+			var maxActivity float64
+			for k, other := range terms {
+				if k == j {
+					continue
+				}
+				maxActivity += math.Max(other.coef*other.variable.lower, other.coef*other.variable.upper)
+			}
+
+			// the maximum activity is unbounded if any of the other variables is unbounded
+			if math.IsInf(maxActivity, 0) || math.IsNaN(maxActivity) {
+				break
+			}
+
+			// only variables with more than two values are restricted in the size of the reduction
+			binary := v.upper-v.lower <= 1
+
+			switch {
+			case term.coef > 0 && !math.IsInf(v.upper, 0) && maxActivity+term.coef*(v.upper-1) < c.rhs:
+				d := c.rhs - maxActivity - term.coef*(v.upper-1)
+				if binary || d <= term.coef {
+					terms[j].coef = term.coef - d
+					c.rhs = c.rhs - d*v.upper
+				}
+
+			case term.coef < 0 && !math.IsInf(v.lower, 0) && maxActivity+term.coef*(v.lower+1) < c.rhs:
+				d := c.rhs - maxActivity - term.coef*(v.lower+1)
+				if binary || d <= -term.coef {
+					terms[j].coef = term.coef + d
+					c.rhs = c.rhs + d*v.lower
+				}
+			}
+		}
+
+		// write the strengthened coefficients back to the constraint
+		for k := range c.expressions {
+			c.expressions[k].coef = terms[k].coef
+		}
+		if c.bigMIndicator != nil {
+			c.bigM = -terms[len(terms)-1].coef
+		}
+	}
+
+	return p
+}
+
+// the maximum number of passes over the constraints when tightening the variable bounds
+const maxBoundTighteningPasses = 20
+
+// only bound improvements larger than this (relative to the magnitude of the bound) are applied, to avoid an endless series of tiny improvements.
+const boundTighteningTolerance = 1e-6
+
+// Tighten the bounds of the variables using the constraints in which they appear, until no more bounds can be improved.
+// For a constraint sum(a_k * x_k) <= b, the minimum activity of the other terms given their bounds yields a bound on x_j:
+//  - if a_j > 0, x_j <= (b - sum_{k!=j} min(a_k * l_k, a_k * u_k)) / a_j
+//  - if a_j < 0, x_j >= (b - sum_{k!=j} min(a_k * l_k, a_k * u_k)) / a_j
+// Equality constraints are treated as a pair of opposing inequalities. The bounds of integer variables are rounded to the nearest integer inside them.
+// This procedure sets the bounds of the variables of the problem, so it should only be applied to a copy of the Problem of the user (see copyProblem).
+func (prepper *preProcessor) tightenBounds(p Problem) Problem {
+	var tightened int
+	for pass := 0; pass < maxBoundTighteningPasses; pass++ {
+		changed := false
+		for _, c := range p.constraints {
+			terms := c.lhsExpressions()
+			if hasDuplicateVariables(terms) {
 				continue
 			}
-			if sets[j].Equal(s) {
-				equalExpressions = append(equalExpressions, []*Constraint{p.constraints[i], p.constraints[j]})
-				unique = false
+
+			changed = tightenBoundsOfRow(terms, c.rhs, &tightened) || changed
+			if !c.inequality {
+				negated := make([]expression, len(terms))
+				for k, e := range terms {
+					negated[k] = expression{coef: -e.coef, variable: e.variable}
+				}
+				changed = tightenBoundsOfRow(negated, -c.rhs, &tightened) || changed
 			}
 		}
-		if unique {
-			retained = append(retained, p.constraints[i])
+
+		if !changed {
+			break
+		}
+	}
+
+	prepper.stats.BoundsTightened += tightened
+
+	return p
+}
+
+// whether any variable appears in more than one of the expressions
+func hasDuplicateVariables(exprs []expression) bool {
+	seen := make(map[*Variable]bool, len(exprs))
+	for _, e := range exprs {
+		if seen[e.variable] {
+			return true
+		}
+		seen[e.variable] = true
+	}
+	return false
+}
+
+// tighten the bounds of the variables of a single constraint sum(a_k * x_k) <= b, counting the number of tightened bounds.
+// Returns whether any bound was tightened.
+func tightenBoundsOfRow(terms []expression, rhs float64, tightened *int) bool {
+	// the minimum activity of each term, of which the infinite ones are counted separately
+	minActivities := make([]float64, len(terms))
+	var finiteMinActivity float64
+	var infinite int
+	for k, e := range terms {
+		minActivities[k] = math.Min(e.coef*e.variable.lower, e.coef*e.variable.upper)
+		if math.IsInf(minActivities[k], -1) {
+			infinite++
+		} else {
+			finiteMinActivity += minActivities[k]
 		}
 	}
 
-	// decide which one of the non-unique pairs to retain
-	// -> pick the one with the smallest RHS, regardless of it being an equality or inequality.
-	for _, pairs := range equalExpressions {
-		if pairs[0].rhs > pairs[1].rhs {
-			retained = append(retained, pairs[1])
+	changed := false
+	for j, e := range terms {
+		if e.coef == 0 {
+			continue
+		}
+
+		// the minimum activity of the other terms must be finite
+		others := finiteMinActivity
+		switch {
+		case infinite == 0:
+			others -= minActivities[j]
+		case infinite == 1 && math.IsInf(minActivities[j], -1):
+		default:
+			continue
+		}
+
+		bound := (rhs - others) / e.coef
+		v := e.variable
+
+		if e.coef > 0 {
+			if v.integer {
+				bound = math.Floor(bound + boundTighteningTolerance)
+			}
+			if bound < v.upper-boundTighteningTolerance*math.Max(1, math.Abs(bound)) {
+				// guard against crossing the lower bound due to rounding errors. Otherwise, the problem is infeasible, which is left to the solver to find out.
+				if bound < v.lower && bound > v.lower-boundTighteningTolerance*math.Max(1, math.Abs(bound)) {
+					bound = v.lower
+				}
+				v.upper = bound
+				changed = true
+				*tightened++
+			}
+		} else {
+			if v.integer {
+				bound = math.Ceil(bound - boundTighteningTolerance)
+			}
+			if bound > v.lower+boundTighteningTolerance*math.Max(1, math.Abs(bound)) {
+				if bound > v.upper && bound < v.upper+boundTighteningTolerance*math.Max(1, math.Abs(bound)) {
+					bound = v.upper
+				}
+				v.lower = bound
+				changed = true
+				*tightened++
+			}
 		}
 	}
 
-	fmt.Printf("removed %v (%v) duplicated constraints \n", len(equalExpressions), len(p.constraints)-len(retained))
+	return changed
+}
 
-	// substitute the constraints slice
-	p.constraints = retained
+// Probe the binary variables by tentatively fixing each of them to 0 and 1 and checking the feasibility of the LP relaxation of the resulting problem.
+// If one of the two values makes the LP relaxation infeasible, the variable must take on the other value and is fixed to it.
+// The fixed variables are removed by filterFixedVars, which registers the undoer that restores their values.
+// Only the first SolverConfig.ProbingLimit (by default DefaultProbingLimit) binary variables are probed, as each probe requires solving two LP relaxations.
+// If both values are infeasible, the problem is infeasible, which is left to the solver to find out.
+// This procedure sets the bounds of the variables of the problem, so it should only be applied to a copy of the Problem of the user (see copyProblem).
+func (prepper *preProcessor) probeBinaryVariables(p Problem) Problem {
+	limit := p.config.ProbingLimit
+	if limit == 0 {
+		limit = DefaultProbingLimit
+	}
+
+	var probed, fixed int
+	for j, v := range p.variables {
+		if probed >= limit {
+			break
+		}
+		if !isBinary(v) {
+			continue
+		}
+		probed++
+
+		zeroFeasible := relaxationFeasibleWith(p, j, 0)
+		oneFeasible := relaxationFeasibleWith(p, j, 1)
+
+		switch {
+		case zeroFeasible && !oneFeasible:
+			v.UpperBound(0)
+			fixed++
+		case oneFeasible && !zeroFeasible:
+			v.LowerBound(1)
+			fixed++
+		}
+	}
+
+	prepper.stats.ProbingFixings += fixed
 
 	return p
+}
+
+// whether the LP relaxation of the problem is feasible when the variable with index j is fixed to the provided value.
+// Only a proof of infeasibility by the LP solver counts as infeasible.
+func relaxationFeasibleWith(p Problem, j int, value float64) bool {
+	tentative := copyProblem(p)
+	tentative.variables[j].LowerBound(value).UpperBound(value)
+	for _, v := range tentative.variables {
+		v.integer = false
+	}
 
+	soln := tentative.toSolveable().toInitialSubproblem().solve()
+	return soln.err != lp.ErrInfeasible
 }
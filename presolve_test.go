@@ -1,8 +1,13 @@
 package ilp
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"reflect"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_preProcessor_filterFixedVars(t *testing.T) {
@@ -63,3 +68,544 @@ func Test_preProcessor_filterFixedVars(t *testing.T) {
 		})
 	}
 }
+
+// Regression test: the undoer of filterFixedVars used to restore the objective contribution c_j * x_j of a fixed variable instead of its value x_j.
+func Test_preProcessor_filterFixedVars_Postsolve(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(1).UpperBound(10)
+	fixed := prob.AddVariable("fixed").SetCoeff(3).LowerBound(2).UpperBound(2)
+	prob.AddConstraint("").AddExpression(1, x).AddExpression(1, fixed).GreaterThanOrEqualTo(5)
+
+	prepper := newPreprocessor()
+	prepped := prepper.filterFixedVars(copyProblem(prob))
+	assert.Equal(t, 1, len(prepped.variables))
+	assert.Equal(t, map[string]float64{"x": 3, "fixed": 2}, prepper.postSolve(rawSolution{"x": 3}).byName)
+
+	soln, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	got, err := soln.GetValueFor(fixed.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, got)
+	assert.InDelta(t, 3+3*2, soln.Objective, 1e-9)
+}
+
+// Regression test: solving a problem of which all variables are fixed by the presolve procedure used to pass an empty LP to the simplex solver, which panicked.
+func TestProblem_Solve_AllVariablesFixedByPresolve(t *testing.T) {
+	prob := NewProblem()
+	x0 := prob.AddVariable("x0").SetCoeff(-2).LowerBound(1).UpperBound(5).IsInteger()
+	x1 := prob.AddVariable("x1").SetCoeff(1).UpperBound(2).IsInteger()
+	x2 := prob.AddVariable("x2").SetCoeff(5).LowerBound(1).UpperBound(2).IsInteger()
+	prob.AddConstraint("").AddExpression(4, x0).AddExpression(-1, x1).AddExpression(1, x2).SmallerThanOrEqualTo(4)
+	prob.AddConstraint("").AddExpression(-1, x1).SmallerThanOrEqualTo(1)
+	prob.AddConstraint("").AddExpression(-4, x0).AddExpression(-1, x1).AddExpression(4, x2).GreaterThanOrEqualTo(0)
+	prob.AddConstraint("").AddExpression(-4, x1).SmallerThanOrEqualTo(1)
+	prob.Maximize()
+
+	prepped, _ := newPreprocessor().preSolve(prob)
+	assert.Empty(t, prepped.variables)
+
+	soln, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, STATUS_OPTIMAL, soln.Status)
+	assert.InDelta(t, 10, soln.Objective, 1e-9)
+	assert.Equal(t, map[string]float64{"x0": 1, "x1": 2, "x2": 2}, soln.byName)
+
+	// the same optimum is found without presolving
+	prob.DisablePresolve()
+	want, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.InDelta(t, want.Objective, soln.Objective, 1e-9)
+}
+
+// Regression test: presolving used to modify the bounds of the original variables, which broke re-use of a Problem.
+func TestPreSolve_DoesNotModifyProblem(t *testing.T) {
+	prob := NewProblem()
+	v1 := prob.AddVariable("v1").SetCoeff(-1)
+	v2 := prob.AddVariable("v2").SetCoeff(-1)
+	v3 := prob.AddVariable("v3").SetCoeff(-1).LowerBound(2).UpperBound(2)
+
+	// v2 is implicitly fixed at zero, v3 is explicitly fixed
+	c1 := prob.AddConstraint("").AddExpression(1, v1).AddExpression(1, v3).SmallerThanOrEqualTo(5)
+	prob.AddConstraint("").AddExpression(1, v2).EqualTo(0)
+
+	for i := 0; i < 2; i++ {
+		soln, err := prob.Solve(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if val, _ := soln.GetValueFor("v1"); val != 3 {
+			t.Errorf("solve %v: unexpected value for v1: %v", i, val)
+		}
+
+		if v2.lower != 0 || !math.IsInf(v2.upper, 1) {
+			t.Errorf("solve %v: bounds of v2 were modified: [%v, %v]", i, v2.lower, v2.upper)
+		}
+
+		if c1.rhs != 5 || len(c1.expressions) != 2 {
+			t.Errorf("solve %v: constraint was modified: %v", i, c1)
+		}
+	}
+}
+
+func Test_strengthenIntegerCoefficients(t *testing.T) {
+	getProblem := func() Problem {
+		prob := NewProblem()
+		x := prob.AddVariable("x").SetCoeff(-1).UpperBound(10)
+		y := prob.AddVariable("y").SetCoeff(5).IsInteger().UpperBound(1)
+
+		// x <= 100 * y, with an overly large big-M value
+		prob.AddConstraint("").AddExpression(1, x).BigM(y, 100)
+
+		// 3y + x <= 20 is redundant when y = 0, so the coefficient of y and the rhs can be reduced by 10
+		prob.AddConstraint("").AddExpression(3, y).AddExpression(1, x).SmallerThanOrEqualTo(20)
+		return prob
+	}
+
+	original := getProblem()
+	strengthened := strengthenIntegerCoefficients(copyProblem(original))
+
+	assert.Equal(t, float64(10), strengthened.constraints[0].bigM)
+	assert.Equal(t, float64(-7), strengthened.constraints[1].expressions[0].coef)
+	assert.Equal(t, float64(10), strengthened.constraints[1].rhs)
+
+	// the original problem should be left untouched
+	assert.Equal(t, float64(100), original.constraints[0].bigM)
+
+	// the LP relaxation of the strengthened problem should be tighter
+	relaxedObjective := func(p Problem) float64 {
+		milp := p.toSolveable()
+		milp.integralityConstraints = make([]bool, len(milp.c))
+		result, err := milp.solve(context.Background(), 1, dummyMiddleware{})
+		soln := result.best()
+		assert.NoError(t, err)
+		return soln.z
+	}
+	assert.True(t, relaxedObjective(strengthened) > relaxedObjective(original))
+
+	// but the integer optimum should remain the same
+	integerObjective := func(p Problem) float64 {
+		result, err := p.toSolveable().solve(context.Background(), 1, dummyMiddleware{})
+		soln := result.best()
+		assert.NoError(t, err)
+		return soln.z
+	}
+	assert.Equal(t, integerObjective(original), integerObjective(strengthened))
+}
+
+func Test_strengthenIntegerCoefficients_GeneralIntegers(t *testing.T) {
+	getProblem := func() Problem {
+		prob := NewProblem()
+		x := prob.AddVariable("x").SetCoeff(-1).IsInteger().UpperBound(3)
+		y := prob.AddVariable("y").SetCoeff(-1).UpperBound(2)
+
+		// 4x + y <= 13 is redundant for x <= 2, so the coefficient of x can be reduced by 3 and the rhs by 3 * 3, yielding x + y <= 4
+		prob.AddConstraint("").AddExpression(4, x).AddExpression(1, y).SmallerThanOrEqualTo(13)
+		return prob
+	}
+
+	original := getProblem()
+	strengthened := strengthenIntegerCoefficients(copyProblem(original))
+	assert.Equal(t, float64(1), strengthened.constraints[0].expressions[0].coef)
+	assert.Equal(t, float64(4), strengthened.constraints[0].rhs)
+
+	// the LP relaxation of the strengthened problem should be tighter
+	relaxedObjective := func(p Problem) float64 {
+		milp := p.toSolveable()
+		milp.integralityConstraints = make([]bool, len(milp.c))
+		result, err := milp.solve(context.Background(), 1, dummyMiddleware{})
+		assert.NoError(t, err)
+		return result.best().z
+	}
+	assert.True(t, relaxedObjective(strengthened) > relaxedObjective(original)+1e-9)
+
+	// but the integer optimum should remain the same
+	integerObjective := func(p Problem) float64 {
+		result, err := p.toSolveable().solve(context.Background(), 1, dummyMiddleware{})
+		assert.NoError(t, err)
+		return result.best().z
+	}
+	assert.InDelta(t, integerObjective(original), integerObjective(strengthened), 1e-9)
+
+	prob := NewProblem()
+	z := prob.AddVariable("z").IsInteger().LowerBound(1).UpperBound(4)
+	w := prob.AddVariable("w").UpperBound(5)
+
+	// -3z + w <= 0 is redundant for z >= 2, so the coefficient of z can be increased by 1 and the rhs by 1 * 1, yielding -2z + w <= 1
+	prob.AddConstraint("").AddExpression(-3, z).AddExpression(1, w).SmallerThanOrEqualTo(0)
+
+	// 4z + w <= 30 is redundant altogether, and reducing the coefficient of z would cut off some of its values
+	prob.AddConstraint("").AddExpression(4, z).AddExpression(1, w).SmallerThanOrEqualTo(30)
+
+	strengthened = strengthenIntegerCoefficients(copyProblem(prob))
+	assert.Equal(t, float64(-2), strengthened.constraints[0].expressions[0].coef)
+	assert.Equal(t, float64(1), strengthened.constraints[0].rhs)
+	assert.Equal(t, float64(4), strengthened.constraints[1].expressions[0].coef)
+	assert.Equal(t, float64(30), strengthened.constraints[1].rhs)
+}
+
+func Test_preProcessor_tightenBounds(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(-2).UpperBound(100)
+	y := prob.AddVariable("y").SetCoeff(-1)
+	z := prob.AddVariable("z").SetCoeff(-1).IsInteger()
+	w := prob.AddFreeVariable("w")
+
+	// 2x + 3y <= 12 bounds x by 6 and y by 4
+	prob.AddConstraint("").AddExpression(2, x).AddExpression(3, y).SmallerThanOrEqualTo(12)
+
+	// 2z - y <= 1 bounds z by 2.5, which is rounded down to 2, but only once the bound of y is known
+	prob.AddConstraint("").AddExpression(2, z).AddExpression(-1, y).SmallerThanOrEqualTo(1)
+
+	// w = x - 7 bounds w by [-7, -1]
+	prob.AddConstraint("").AddExpression(1, w).AddExpression(-1, x).EqualTo(-7)
+
+	tightened := newPreprocessor().tightenBounds(copyProblem(prob))
+
+	bounds := func(v *Variable) []float64 {
+		return []float64{v.lower, v.upper}
+	}
+	assert.Equal(t, []float64{0, 6}, bounds(tightened.variables[0]))
+	assert.Equal(t, []float64{0, 4}, bounds(tightened.variables[1]))
+	assert.Equal(t, []float64{0, 2}, bounds(tightened.variables[2]))
+	assert.Equal(t, []float64{-7, -1}, bounds(tightened.variables[3]))
+
+	// the original problem should be left untouched
+	assert.Equal(t, float64(100), x.upper)
+	assert.True(t, math.IsInf(w.lower, -1))
+
+	// the tightened bounds do not change the optimal solution
+	soln, err := prob.Solve(context.Background())
+	assert.NoError(t, err)
+	for name, want := range map[string]float64{"x": 6, "y": 0, "z": 0, "w": -1} {
+		got, err := soln.GetValueFor(name)
+		assert.NoError(t, err)
+		assert.InDelta(t, want, got, 1e-9, name)
+	}
+}
+
+func Test_preProcessor_probeBinaryVariables(t *testing.T) {
+	getProblem := func() Problem {
+		prob := NewProblem()
+		a := prob.AddBinaryVariable("a").SetCoeff(-1)
+		b := prob.AddBinaryVariable("b").SetCoeff(1)
+		c := prob.AddBinaryVariable("c").SetCoeff(-1)
+		y := prob.AddVariable("y").SetCoeff(1)
+		z := prob.AddVariable("z").SetCoeff(1)
+
+		// y + z >= 1.5 cannot be satisfied if a = 1
+		prob.AddConstraint("").AddExpression(1, y).AddExpression(1, z).GreaterThanOrEqualTo(1.5)
+		prob.AddConstraint("").AddExpression(1, y).AddExpression(1, a).SmallerThanOrEqualTo(1)
+		prob.AddConstraint("").AddExpression(1, z).AddExpression(1, a).SmallerThanOrEqualTo(1)
+
+		// y + z + b >= 2.5 cannot be satisfied if b = 0
+		prob.AddConstraint("").AddExpression(1, y).AddExpression(1, z).AddExpression(1, b).GreaterThanOrEqualTo(2.5)
+
+		// c can take on either value
+		prob.AddConstraint("").AddExpression(1, c).AddExpression(1, y).SmallerThanOrEqualTo(2)
+		return prob
+	}
+
+	bounds := func(p Problem) [][]float64 {
+		var b [][]float64
+		for _, v := range p.variables[:3] {
+			b = append(b, []float64{v.lower, v.upper})
+		}
+		return b
+	}
+
+	// probing is enabled by default
+	original := getProblem()
+	probed := newPreprocessor().probeBinaryVariables(copyProblem(original))
+	assert.Equal(t, [][]float64{{0, 0}, {1, 1}, {0, 1}}, bounds(probed))
+
+	// the original problem should be left untouched
+	assert.Equal(t, [][]float64{{0, 1}, {0, 1}, {0, 1}}, bounds(original))
+
+	// the number of probed variables is limited
+	limited := copyProblem(original)
+	limited.config.ProbingLimit = 1
+	assert.Equal(t, [][]float64{{0, 0}, {0, 1}, {0, 1}}, bounds(newPreprocessor().probeBinaryVariables(limited)))
+
+	// a negative limit disables probing
+	disabled := copyProblem(original)
+	disabled.config.ProbingLimit = -1
+	assert.Equal(t, [][]float64{{0, 1}, {0, 1}, {0, 1}}, bounds(newPreprocessor().probeBinaryVariables(disabled)))
+
+	// the fixed variables are restored in the solution
+	soln, err := original.Solve(context.Background())
+	assert.NoError(t, err)
+	for name, want := range map[string]float64{"a": 0, "b": 1, "c": 1} {
+		got, err := soln.GetValueFor(name)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, name)
+	}
+}
+
+// Probing is part of the presolve procedure of a problem solved with the default configuration.
+func TestProblem_Solve_ProbesByDefault(t *testing.T) {
+	getProblem := func() Problem {
+		prob := NewProblem()
+		a := prob.AddBinaryVariable("a").SetCoeff(-1)
+		y := prob.AddVariable("y").SetCoeff(1)
+		z := prob.AddVariable("z").SetCoeff(1)
+
+		// only the combination of both constraints shows that a = 1 is infeasible, so bound tightening cannot fix a
+		prob.AddConstraint("").AddExpression(1, y).AddExpression(1, z).GreaterThanOrEqualTo(1.5)
+		prob.AddConstraint("").AddExpression(1, y).AddExpression(1, z).AddExpression(1, a).SmallerThanOrEqualTo(2)
+		return prob
+	}
+
+	prob := getProblem()
+	soln, err := prob.Solve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, soln.Stats.PresolveStats.ProbingFixings)
+	assert.InDelta(t, 1.5, soln.Objective, 1e-9)
+	a, err := soln.GetValueFor("a")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, a)
+
+	// a negative limit disables probing
+	prob = getProblem()
+	prob.SetSolverConfig(SolverConfig{ProbingLimit: -1})
+	soln, err = prob.Solve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, soln.Stats.PresolveStats.ProbingFixings)
+	assert.InDelta(t, 1.5, soln.Objective, 1e-9)
+}
+
+func TestPreSolve_Stats(t *testing.T) {
+	prob := NewProblem()
+	v1 := prob.AddVariable("v1").SetCoeff(-1)
+	v2 := prob.AddVariable("v2").SetCoeff(-1)
+	v3 := prob.AddVariable("v3").SetCoeff(-1).LowerBound(2).UpperBound(2)
+	v4 := prob.AddVariable("v4").SetCoeff(-1)
+	v5 := prob.AddVariable("v5").SetCoeff(-1)
+
+	// v3 is fixed, which bounds v1 by 3
+	prob.AddConstraint("").AddExpression(1, v1).AddExpression(1, v3).SmallerThanOrEqualTo(5)
+
+	// v2 is implicitly fixed at zero. As v2 appears twice, the bounds are not tightened using this constraint.
+	prob.AddConstraint("").AddExpression(1, v2).AddExpression(1, v2).SmallerThanOrEqualTo(0)
+
+	// a duplicate constraint with a larger right-hand side, which bounds v4 and v5 by 4
+	prob.AddConstraint("").AddExpression(1, v4).AddExpression(1, v5).SmallerThanOrEqualTo(4)
+	prob.AddConstraint("").AddExpression(1, v5).AddExpression(1, v4).SmallerThanOrEqualTo(6)
+
+	// bounds v2 by 5, and turns into a duplicate of the first constraint once v2 and v3 are removed
+	prob.AddConstraint("").AddExpression(1, v2).AddExpression(1, v1).AddExpression(1, v3).SmallerThanOrEqualTo(7)
+
+	// pass 1 removes v3, finds v2 to be zero, removes the duplicate of the third constraint, and the first constraint which is implied by the bound of v1.
+	// pass 2 removes v2, the then-empty second constraint and the last constraint, which is implied by the bound of v1 as well. Pass 3 finds nothing left to do.
+	prepped, stats := newPreprocessor().preSolve(prob)
+	assert.Len(t, prepped.variables, 3)
+	assert.Len(t, prepped.constraints, 1)
+
+	assert.True(t, stats.Duration > 0)
+	stats.Duration = 0
+	assert.Equal(t, PresolveStats{
+		OriginalVariables:           5,
+		ReducedVariables:            3,
+		OriginalConstraints:         5,
+		ReducedConstraints:          1,
+		FixedVariablesRemoved:       2,
+		ImpliedZeroVariables:        1,
+		EmptyConstraintsRemoved:     1,
+		DuplicateConstraintsRemoved: 1,
+		RedundantConstraintsRemoved: 2,
+		BoundsTightened:             4,
+		Passes:                      3,
+	}, stats)
+
+	// the statistics are reported along with the solution
+	soln, err := prob.Solve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, soln.Stats.ReducedVariables)
+	assert.Equal(t, 3, soln.Stats.Passes)
+
+	soln, err = prob.Solve(context.Background(), WithPresolve(false))
+	assert.NoError(t, err)
+	assert.Equal(t, PresolveStats{}, soln.Stats.PresolveStats)
+}
+
+func TestPreSolve_substituteFixedSingletons(t *testing.T) {
+	// x4 is free and only appears in the first constraint, so it is determined by x1 and x2: x4 = 10 - x1 - x2
+	prob := NewProblem()
+	x1 := prob.AddVariable("x1").SetCoeff(-1)
+	x2 := prob.AddVariable("x2").SetCoeff(-2).UpperBound(5)
+	x3 := prob.AddVariable("x3").SetCoeff(-1).IsInteger()
+	x4 := prob.AddFreeVariable("x4").SetCoeff(1)
+	prob.AddConstraint("").AddExpression(1, x1).AddExpression(1, x2).AddExpression(1, x4).EqualTo(10)
+	prob.AddConstraint("").AddExpression(1, x1).AddExpression(1, x2).AddExpression(1, x3).SmallerThanOrEqualTo(8)
+
+	prepper := newPreprocessor()
+	prepped := prepper.substituteFixedSingletons(copyProblem(prob))
+	assert.Len(t, prepped.variables, 3)
+	assert.Len(t, prepped.constraints, 1)
+	assert.Equal(t, 1, prepper.stats.SubstitutedVariables)
+	assert.Equal(t, float64(10), prepped.objectiveOffset)
+	var coefs []float64
+	for _, v := range prepped.variables {
+		coefs = append(coefs, v.coefficient)
+	}
+	assert.Equal(t, []float64{-2, -3, -1}, coefs)
+
+	// the problem of the user is left untouched
+	assert.Equal(t, float64(-1), x1.coefficient)
+
+	// the postprocessed solution includes the substituted variable
+	want, err := prob.Solve(context.Background(), WithPresolve(false))
+	if !assert.NoError(t, err) {
+		return
+	}
+	got, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, got.Stats.SubstitutedVariables)
+	assert.InDelta(t, -11, got.Objective, 1e-9)
+	assert.InDelta(t, want.Objective, got.Objective, 1e-9)
+	for _, name := range []string{"x1", "x2", "x3", "x4"} {
+		wantValue, err := want.GetValueFor(name)
+		assert.NoError(t, err)
+		gotValue, err := got.GetValueFor(name)
+		assert.NoError(t, err)
+		assert.InDelta(t, wantValue, gotValue, 1e-9, name)
+	}
+
+	// variables with bounds are not substituted, as the constraint would no longer enforce them
+	x4.LowerBound(0)
+	assert.Len(t, newPreprocessor().substituteFixedSingletons(copyProblem(prob)).variables, 4)
+}
+
+func Test_preProcessor_detectRedundantConstraints(t *testing.T) {
+	getProblem := func() (Problem, *Variable) {
+		prob := NewProblem()
+		prob.BranchingHeuristic(BRANCH_MOST_INFEASIBLE)
+		x := prob.AddVariable("x").SetCoeff(-4).UpperBound(4).IsInteger()
+		y := prob.AddVariable("y").SetCoeff(-2).IsInteger()
+		z := prob.AddVariable("z").SetCoeff(-8).IsInteger()
+		prob.AddConstraint("").AddExpression(8, x).AddExpression(6, y).AddExpression(1, z).SmallerThanOrEqualTo(33.5)
+		prob.AddConstraint("").AddExpression(3, x).AddExpression(4, y).AddExpression(9, z).SmallerThanOrEqualTo(25.5)
+		prob.AddConstraint("").AddExpression(1, y).SmallerThanOrEqualTo(3)
+		return prob, x
+	}
+
+	// the constraint on x is implied by its upper bound, unlike the constraint on y
+	prob, x := getProblem()
+	redundant := prob.AddConstraint("").AddExpression(1, x).SmallerThanOrEqualTo(5)
+	prepped := newPreprocessor().detectRedundantConstraints(copyProblem(prob))
+	assert.Len(t, prepped.constraints, 3)
+	for _, c := range prepped.constraints {
+		assert.NotEqual(t, redundant.String(), c.String())
+	}
+
+	// removing the constraint leaves the optimal solution unchanged
+	plain, _ := getProblem()
+	want, err := plain.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	got, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, want.byName, got.byName)
+	assert.Equal(t, want.Objective, got.Objective)
+	assert.True(t, got.Stats.RedundantConstraintsRemoved > 0)
+}
+
+func Test_removeDuplicateConstraints(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x")
+	y := prob.AddVariable("y")
+	prob.AddConstraint("larger").AddExpression(1, x).AddExpression(2, y).SmallerThanOrEqualTo(6)
+	smaller := prob.AddConstraint("smaller").AddExpression(2, y).AddExpression(1, x).SmallerThanOrEqualTo(4)
+	unique := prob.AddConstraint("unique").AddExpression(1, x).AddExpression(1, y).SmallerThanOrEqualTo(4)
+
+	// the duplicate with the smallest right-hand side is retained in place of the first, regardless of the order of the expressions
+	got := removeDuplicateConstraints(prob)
+	assert.Equal(t, []*Constraint{smaller, unique}, got.constraints)
+}
+
+// Regression test: duplicates with equal right-hand sides used to be removed altogether, and groups of more than two duplicates retained several of them.
+func Test_removeDuplicateConstraints_Retained(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x")
+	y := prob.AddVariable("y")
+	first := prob.AddConstraint("first").AddExpression(1, x).AddExpression(1, y).SmallerThanOrEqualTo(4)
+	prob.AddConstraint("second").AddExpression(1, x).AddExpression(1, y).SmallerThanOrEqualTo(4)
+	prob.AddConstraint("high").AddExpression(1, x).SmallerThanOrEqualTo(3)
+	prob.AddConstraint("higher").AddExpression(1, x).SmallerThanOrEqualTo(2)
+	lowest := prob.AddConstraint("lowest").AddExpression(1, x).SmallerThanOrEqualTo(1)
+
+	got := removeDuplicateConstraints(prob)
+	assert.Equal(t, []*Constraint{first, lowest}, got.constraints)
+}
+
+// Regression test: repeated expressions of the same variable used to be collapsed, which made x + x <= 4 a duplicate of x <= 4.
+func Test_removeDuplicateConstraints_RepeatedExpressions(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x")
+	twice := prob.AddConstraint("twice").AddExpression(1, x).AddExpression(1, x).SmallerThanOrEqualTo(4)
+	once := prob.AddConstraint("once").AddExpression(1, x).SmallerThanOrEqualTo(3)
+
+	got := removeDuplicateConstraints(prob)
+	assert.Equal(t, []*Constraint{twice, once}, got.constraints)
+}
+
+// Regression test: the type of the constraints used to be ignored, which made x >= 1 a duplicate of x <= 4 that replaced it.
+func Test_removeDuplicateConstraints_Types(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x")
+	smaller := prob.AddConstraint("smaller").AddExpression(1, x).SmallerThanOrEqualTo(4)
+	prob.AddConstraint("lower").AddExpression(1, x).GreaterThanOrEqualTo(1)
+	higher := prob.AddConstraint("higher").AddExpression(1, x).GreaterThanOrEqualTo(2)
+	equal := prob.AddConstraint("equal").AddExpression(1, x).EqualTo(3)
+
+	// the most restrictive constraint of each type is retained
+	got := removeDuplicateConstraints(prob)
+	assert.Equal(t, []*Constraint{smaller, higher, equal}, got.constraints)
+}
+
+// Regression test: x = 5 used to be a duplicate of x = 3, so that the conflicting equality constraints were merged into a feasible one.
+func Test_removeDuplicateConstraints_Equalities(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x")
+	five := prob.AddConstraint("five").AddExpression(1, x).EqualTo(5)
+	three := prob.AddConstraint("three").AddExpression(1, x).EqualTo(3)
+	prob.AddConstraint("again").AddExpression(1, x).EqualTo(5)
+
+	got := removeDuplicateConstraints(prob)
+	assert.Equal(t, []*Constraint{five, three}, got.constraints)
+}
+
+func Benchmark_removeDuplicateConstraints(b *testing.B) {
+	// 200 constraints over 20 variables, of which every fourth duplicates the one before it
+	prob := NewProblem()
+	var vars []*Variable
+	for i := 0; i < 20; i++ {
+		vars = append(vars, prob.AddVariable(fmt.Sprintf("x%d", i)))
+	}
+	for i := 0; i < 200; i++ {
+		row := i - i%4/3
+		c := prob.AddConstraint("")
+		for j, v := range vars {
+			if (row+j)%3 == 0 {
+				c.AddExpression(float64(row%7+j), v)
+			}
+		}
+		c.SmallerThanOrEqualTo(float64(i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		removeDuplicateConstraints(prob)
+	}
+}
@@ -0,0 +1,79 @@
+package ilp
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Print writes a human-readable summary of the problem to the writer for debugging purposes: the objective function,
+// the constraints, the variables with their bounds and integrality constraints, and the branching heuristic.
+func (p *Problem) Print(w io.Writer) {
+	sense := "Minimize"
+	if p.maximize {
+		sense = "Maximize"
+	}
+
+	var terms []string
+	for _, v := range p.variables {
+		if v.coefficient != 0 {
+			terms = append(terms, fmt.Sprintf("%v*%v", v.coefficient, v.name))
+		}
+	}
+	if p.objectiveOffset != 0 || len(terms) == 0 {
+		terms = append(terms, fmt.Sprint(p.objectiveOffset))
+	}
+	fmt.Fprintf(w, "%v\n  %v\n", sense, strings.Join(terms, " + "))
+
+	fmt.Fprintln(w, "Subject to")
+	for _, c := range p.constraints {
+		kind := "equality"
+		if c.inequality {
+			kind = "inequality"
+		}
+		if c.bigMIndicator != nil {
+			kind = "big-M " + kind
+		}
+		fmt.Fprintf(w, "  %v (%v)\n", c, kind)
+	}
+	for _, s := range p.sos1 {
+		var names []string
+		for _, v := range s.variables {
+			names = append(names, v.name)
+		}
+		fmt.Fprintf(w, "  SOS1: %v\n", strings.Join(names, ", "))
+	}
+
+	fmt.Fprintln(w, "Variables")
+	for _, v := range p.variables {
+		fmt.Fprintf(w, "  %v\n", v)
+	}
+
+	if p.branchingStrategy != nil {
+		fmt.Fprintf(w, "Branching strategy: %T\n", p.branchingStrategy)
+	} else {
+		fmt.Fprintf(w, "Branching heuristic: %v\n", p.branchingHeuristic)
+	}
+}
+
+// Print writes the numeric form of the problem to the writer for debugging purposes.
+func (p milpProblem) Print(w io.Writer) {
+	fmt.Fprintf(w, "c = %v\n", p.c)
+	printMatrix(w, "A", p.A)
+	fmt.Fprintf(w, "b = %v\n", p.b)
+	printMatrix(w, "G", p.G)
+	fmt.Fprintf(w, "h = %v\n", p.h)
+	fmt.Fprintf(w, "integralityConstraints = %v\n", p.integralityConstraints)
+}
+
+// write the matrix, which may be nil, to the writer.
+func printMatrix(w io.Writer, name string, m ConstraintMatrix) {
+	if m == nil {
+		fmt.Fprintf(w, "%v = <nil>\n", name)
+		return
+	}
+	prefix := strings.Repeat(" ", len(name)+3)
+	fmt.Fprintf(w, "%v = %v\n", name, mat.Formatted(m.ToDense(), mat.Prefix(prefix), mat.Squeeze()))
+}
@@ -0,0 +1,38 @@
+package ilp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblemPrint(t *testing.T) {
+	prob := NewProblem()
+	prob.Maximize()
+	prob.BranchingHeuristic(BRANCH_MOST_INFEASIBLE)
+	x := prob.AddVariable("x").SetCoeff(4).UpperBound(3).IsInteger()
+	y := prob.AddVariable("y").SetCoeff(2)
+	prob.AddConstraint("capacity").AddExpression(8, x).AddExpression(6, y).SmallerThanOrEqualTo(33.5)
+	prob.AddConstraint("").AddExpression(1, x).AddExpression(-1, y).EqualTo(1)
+
+	var buf bytes.Buffer
+	prob.Print(&buf)
+	out := buf.String()
+
+	assert.Contains(t, out, "Maximize\n  4*x + 2*y\n")
+	assert.Contains(t, out, "capacity: 8*x + 6*y <= 33.5 (inequality)")
+	assert.Contains(t, out, "1*x + -1*y = 1 (equality)")
+	assert.Contains(t, out, "x [0, 3] int=true coef=4")
+	assert.Contains(t, out, "y [0, +Inf] int=false coef=2")
+	assert.Contains(t, out, "Branching heuristic: BRANCH_MOST_INFEASIBLE")
+
+	milp := prob.toSolveable()
+	buf.Reset()
+	milp.Print(&buf)
+	out = buf.String()
+
+	for _, field := range []string{"c = ", "A = ", "b = ", "G = ", "h = ", "integralityConstraints = [true false]"} {
+		assert.Contains(t, out, field)
+	}
+}
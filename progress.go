@@ -0,0 +1,168 @@
+package ilp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// ProgressMiddleware is a BnbMiddleware that periodically writes a line describing the progress of the branch-and-bound procedure, e.g.
+//
+//	Nodes: 1000 | Incumbent: -8.000 | BestBound: -8.500 | Gap: 6.25% | Time: 5.2s
+//
+// The objective values are those of the minimization problem solved by the branch-and-bound procedure,
+// so the objective values of a maximization problem are negated. The best bound is the lowest bound of the subProblems that have not been decided on yet.
+// The output is buffered, and flushed after each line.
+type ProgressMiddleware struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+
+	// receives the progress each time a line would be written instead of the writer, if set (see Problem.SolveAsync)
+	events func(SolveProgress)
+
+	// the minimum time between two lines
+	interval time.Duration
+
+	start      time.Time
+	lastReport time.Time
+
+	// the number of subProblems decided on
+	nodes int64
+
+	// the objective value of the incumbent, if any
+	incumbent    float64
+	hasIncumbent bool
+
+	// the bounds of the subProblems that have not been decided on yet, keyed by id
+	open map[int64]float64
+
+	// the first error returned by the writer, after which nothing is written
+	err error
+}
+
+// NewProgressMiddleware creates a ProgressMiddleware that writes a line to w at most once per interval.
+// The first line is written once the interval has passed since the creation of the middleware.
+func NewProgressMiddleware(w io.Writer, interval time.Duration) *ProgressMiddleware {
+	now := time.Now()
+	return &ProgressMiddleware{
+		w:          bufio.NewWriter(w),
+		interval:   interval,
+		start:      now,
+		lastReport: now,
+		open:       make(map[int64]float64),
+	}
+}
+
+// SolveProgress describes the progress of the branch-and-bound procedure at a point in time.
+type SolveProgress struct {
+	// the number of subProblems decided on
+	NodesExplored int64
+
+	// the objective value of the incumbent, if any
+	CurrentIncumbent float64
+	HasIncumbent     bool
+
+	// the lowest bound of the subProblems that have not been decided on yet, or the incumbent if none are left. Infinite if unknown.
+	BestBound float64
+
+	Timestamp time.Time
+}
+
+// create a ProgressMiddleware that passes the progress to the events function at most once per interval, rather than writing a line.
+func newProgressEvents(interval time.Duration, events func(SolveProgress)) *ProgressMiddleware {
+	now := time.Now()
+	return &ProgressMiddleware{
+		events:     events,
+		interval:   interval,
+		start:      now,
+		lastReport: now,
+		open:       make(map[int64]float64),
+	}
+}
+
+func (m *ProgressMiddleware) NewSubProblem(s subProblem) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// the bound of the root problem is unknown until it is solved
+	bound := s.bound
+	if s.id == s.parent {
+		bound = math.Inf(-1)
+	}
+	m.open[s.id] = bound
+}
+
+func (m *ProgressMiddleware) ProcessDecision(s solution, d bnbDecision) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nodes++
+	delete(m.open, s.problem.id)
+	if d == BETTER_THAN_INCUMBENT_FEASIBLE || d == INITIAL_RX_FEASIBLE_FOR_IP {
+		m.incumbent = s.z
+		m.hasIncumbent = true
+	}
+
+	if time.Since(m.lastReport) >= m.interval {
+		m.report()
+	}
+}
+
+// Flush writes a line describing the current progress, regardless of the interval, e.g. once the procedure has finished.
+// Returns the first error encountered while writing, if any.
+func (m *ProgressMiddleware) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.report()
+	return m.err
+}
+
+// write a line describing the current progress and flush it, or pass the progress to the events function if set.
+func (m *ProgressMiddleware) report() {
+	m.lastReport = time.Now()
+	if m.err != nil {
+		return
+	}
+
+	// the best bound is the lowest bound of the open subProblems, or the incumbent if none are left
+	bound := math.Inf(1)
+	for _, b := range m.open {
+		bound = math.Min(bound, b)
+	}
+	if m.hasIncumbent {
+		bound = math.Min(bound, m.incumbent)
+	}
+
+	if m.events != nil {
+		m.events(SolveProgress{
+			NodesExplored:    m.nodes,
+			CurrentIncumbent: m.incumbent,
+			HasIncumbent:     m.hasIncumbent,
+			BestBound:        bound,
+			Timestamp:        m.lastReport,
+		})
+		return
+	}
+
+	incumbent, gap := "-", "-"
+	if m.hasIncumbent {
+		incumbent = fmt.Sprintf("%.3f", m.incumbent)
+		gap = fmt.Sprintf("%.2f%%", 100*relativeGap(m.incumbent, bound))
+	}
+
+	bestBound := "-"
+	if !math.IsInf(bound, 0) {
+		bestBound = fmt.Sprintf("%.3f", bound)
+	}
+
+	elapsed := m.lastReport.Sub(m.start).Seconds()
+	if _, err := fmt.Fprintf(m.w, "Nodes: %v | Incumbent: %v | BestBound: %v | Gap: %v | Time: %.1fs\n", m.nodes, incumbent, bestBound, gap, elapsed); err != nil {
+		m.err = err
+		return
+	}
+	m.err = m.w.Flush()
+}
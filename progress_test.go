@@ -0,0 +1,73 @@
+package ilp
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressMiddleware(t *testing.T) {
+	prob := getTestKnapsackMILP()
+
+	// a zero interval reports each decision
+	var buf bytes.Buffer
+	progress := NewProgressMiddleware(&buf, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	result, err := prob.solve(ctx, 1, progress)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, progress.Flush())
+
+	line := regexp.MustCompile(`^Nodes: (\d+) \| Incumbent: (-|-?\d+\.\d{3}) \| BestBound: (-|-?\d+\.\d{3}) \| Gap: (-|\d+\.\d{2}%) \| Time: \d+\.\ds$`)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for _, l := range lines {
+		assert.Regexp(t, line, l)
+	}
+
+	// one line per decision, followed by the line written by Flush
+	assert.Equal(t, int(result.Stats.NodesExplored)+1, len(lines))
+
+	// the incumbent only improves, and the best bound only tightens, while the node count increases by one with each decision
+	incumbent, bound := math.Inf(1), math.Inf(-1)
+	for i, l := range lines[:len(lines)-1] {
+		fields := line.FindStringSubmatch(l)
+		assert.Equal(t, strconv.Itoa(i+1), fields[1])
+		if fields[2] != "-" {
+			z, _ := strconv.ParseFloat(fields[2], 64)
+			assert.True(t, z <= incumbent, "incumbent %v worsened to %v", incumbent, z)
+			incumbent = z
+		}
+		if fields[3] != "-" {
+			b, _ := strconv.ParseFloat(fields[3], 64)
+			assert.True(t, b >= bound, "best bound %v loosened to %v", bound, b)
+			bound = b
+		}
+	}
+	assert.Equal(t, -24.0, incumbent)
+
+	// the search starts without an incumbent, and ends with the optimum without a gap
+	assert.Equal(t, "-", line.FindStringSubmatch(lines[0])[2])
+	last := line.FindStringSubmatch(lines[len(lines)-1])
+	assert.Equal(t, "-24.000", last[2])
+	assert.Equal(t, "-24.000", last[3])
+	assert.Equal(t, "0.00%", last[4])
+
+	// with a long interval, only Flush writes a line
+	buf.Reset()
+	progress = NewProgressMiddleware(&buf, time.Hour)
+	_, err = prob.solve(context.Background(), 1, progress)
+	assert.NoError(t, err)
+	assert.Equal(t, "", buf.String())
+	assert.NoError(t, progress.Flush())
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+}
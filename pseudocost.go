@@ -0,0 +1,194 @@
+package ilp
+
+import "math"
+
+// number of observations after which the pseudocost of a variable in a particular direction is considered reliable.
+const pseudocostReliability = 1
+
+// minimum objective change used when scoring branching candidates, preventing a zero pseudocost in one direction from masking the other.
+const pseudocostEpsilon = 1e-6
+
+// PseudocostTable keeps track of the objective change per unit of rounding observed when branching on each variable.
+// The history is accumulated over the course of the branch-and-bound procedure and is used to predict the effect of future branching decisions.
+type PseudocostTable struct {
+	down map[int]*runningMean
+	up   map[int]*runningMean
+}
+
+type runningMean struct {
+	mean         float64
+	observations int
+}
+
+func NewPseudocostTable() *PseudocostTable {
+	return &PseudocostTable{
+		down: make(map[int]*runningMean),
+		up:   make(map[int]*runningMean),
+	}
+}
+
+// register the objective change per unit of rounding observed after branching on a variable in a particular direction.
+func (t *PseudocostTable) observe(variable int, up bool, perUnitChange float64) {
+	table := t.down
+	if up {
+		table = t.up
+	}
+
+	m, ok := table[variable]
+	if !ok {
+		m = &runningMean{}
+		table[variable] = m
+	}
+
+	m.observations++
+	m.mean += (perUnitChange - m.mean) / float64(m.observations)
+}
+
+// get the mean objective change per unit of rounding of a variable in a particular direction.
+// The second return value is false if not enough observations have been made for the estimate to be reliable.
+func (t *PseudocostTable) estimate(variable int, up bool) (float64, bool) {
+	table := t.down
+	if up {
+		table = t.up
+	}
+
+	m, ok := table[variable]
+	if !ok || m.observations < pseudocostReliability {
+		return 0, false
+	}
+	return m.mean, true
+}
+
+// a branching decision of which the effect on the objective value is yet to be observed.
+type pendingBranching struct {
+	variable int
+	up       bool
+
+	// objective value of the branched solution
+	parentZ float64
+
+	// the distance over which the variable was rounded
+	distance float64
+}
+
+// Choose the fractional variable with the largest predicted objective change according to its pseudocosts.
+// Candidates are scored by the product of the predicted changes of the down and up branch.
+// As long as some of the fractional variables have no reliable pseudocosts, we branch on the most infeasible of those to build up the history.
+func pseudocostBranchPoint(x []float64, integralityConstraints []bool, pseudocosts *PseudocostTable) int {
+	if len(x) != len(integralityConstraints) {
+		panic("number of variables not equal to number of integrality constraints")
+	}
+
+	if pseudocosts == nil {
+		return mostInfeasibleBranchPoint(x, integralityConstraints, nil)
+	}
+
+	unreliable := make([]bool, len(x))
+	anyUnreliable := false
+
+	bestScore := math.Inf(-1)
+	currentCandidate := -1
+
+	for i, v := range x {
+		// ignore variables that are only fractional due to numerical noise, as branching on them teaches us nothing
+		f := fractional(v)
+		if !integralityConstraints[i] || f < cutTolerance || f > 1-cutTolerance {
+			continue
+		}
+
+		down, downOK := pseudocosts.estimate(i, false)
+		up, upOK := pseudocosts.estimate(i, true)
+		if !downOK || !upOK {
+			unreliable[i] = true
+			anyUnreliable = true
+			continue
+		}
+
+		score := math.Max(f*down, pseudocostEpsilon) * math.Max((1-f)*up, pseudocostEpsilon)
+		if score > bestScore {
+			bestScore = score
+			currentCandidate = i
+		}
+	}
+
+	if anyUnreliable {
+		return mostInfeasibleBranchPoint(x, unreliable, nil)
+	}
+
+	// fall back to the most infeasible variable if no fractional variables are present
+	if currentCandidate == -1 {
+		return mostInfeasibleBranchPoint(x, integralityConstraints, nil)
+	}
+
+	return currentCandidate
+}
+
+// Estimate the objective value of the best integer-feasible solution in the subtree of a child of the parent solution.
+// Each fractional variable of the parent solution is predicted to be rounded in its cheapest direction according to its pseudocosts,
+// except for the variable that was branched on to create the child, which is rounded in the direction of the child.
+// Variables without a pseudocost in a direction are assumed to be rounded for free.
+func (t *PseudocostTable) childEstimate(parent solution, child subProblem) float64 {
+	decision := child.bnbConstraints[len(child.bnbConstraints)-1]
+	branched := decision.branchedVariable
+
+	estimate := parent.z
+	for i, v := range parent.x {
+		f := fractional(v)
+		if !parent.problem.integralityConstraints[i] || f < cutTolerance || f > 1-cutTolerance {
+			continue
+		}
+
+		down, _ := t.estimate(i, false)
+		up, _ := t.estimate(i, true)
+		downCost := f * down
+		upCost := (1 - f) * up
+
+		switch {
+		case i == branched && decision.gsharp[i] < 0:
+			estimate += upCost
+		case i == branched:
+			estimate += downCost
+		default:
+			estimate += math.Min(downCost, upCost)
+		}
+	}
+
+	return estimate
+}
+
+// register the branching decisions that created the child subProblems, so that their effect can be observed once the children are solved.
+func (p *enumerationTree) registerBranching(parent solution, children ...subProblem) {
+	for _, child := range children {
+		decision := child.bnbConstraints[len(child.bnbConstraints)-1]
+		i := decision.branchedVariable
+		up := decision.gsharp[i] < 0
+
+		distance := fractional(parent.x[i])
+		if up {
+			distance = 1 - distance
+		}
+
+		p.pendingBranchings[child.id] = pendingBranching{
+			variable: i,
+			up:       up,
+			parentZ:  parent.z,
+			distance: distance,
+		}
+	}
+}
+
+// update the pseudocost of the variable that was branched on to create the subProblem of the candidate solution.
+func (p *enumerationTree) observeBranching(candidate solution) {
+	branching, ok := p.pendingBranchings[candidate.problem.id]
+	if !ok {
+		return
+	}
+	delete(p.pendingBranchings, candidate.problem.id)
+
+	// infeasible children do not tell us anything about the objective change
+	if candidate.err != nil || branching.distance < cutTolerance {
+		return
+	}
+
+	p.pseudocosts.observe(branching.variable, branching.up, (candidate.z-branching.parentZ)/branching.distance)
+}
@@ -0,0 +1,103 @@
+package ilp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPseudocostTable(t *testing.T) {
+	table := NewPseudocostTable()
+
+	_, ok := table.estimate(2, false)
+	assert.False(t, ok)
+
+	table.observe(2, false, 1)
+	table.observe(2, false, 3)
+	table.observe(2, true, 5)
+
+	down, ok := table.estimate(2, false)
+	assert.True(t, ok)
+	assert.Equal(t, float64(2), down)
+
+	up, ok := table.estimate(2, true)
+	assert.True(t, ok)
+	assert.Equal(t, float64(5), up)
+
+	// observations of one variable do not affect the others
+	_, ok = table.estimate(1, false)
+	assert.False(t, ok)
+}
+
+func Test_pseudocostBranchPoint(t *testing.T) {
+	x := []float64{1.5, 2.2, 3.9, 4}
+	integralityConstraints := []bool{true, true, true, true}
+
+	// without any history, we fall back to the most infeasible variable
+	assert.Equal(t, 0, pseudocostBranchPoint(x, integralityConstraints, nil))
+	assert.Equal(t, 0, pseudocostBranchPoint(x, integralityConstraints, NewPseudocostTable()))
+
+	// variables without reliable history are branched on first
+	table := NewPseudocostTable()
+	table.observe(0, false, 1)
+	table.observe(0, true, 1)
+	table.observe(2, false, 1)
+	assert.Equal(t, 1, pseudocostBranchPoint(x, integralityConstraints, table))
+
+	// once all fractional variables have a history, the variable with the highest score is picked
+	table.observe(1, false, 10)
+	table.observe(1, true, 10)
+	table.observe(2, true, 1)
+	assert.Equal(t, 1, pseudocostBranchPoint(x, integralityConstraints, table))
+}
+
+func TestPseudocostTable_childEstimate(t *testing.T) {
+	table := NewPseudocostTable()
+	table.observe(0, false, 2)
+	table.observe(0, true, 4)
+	table.observe(1, false, 1)
+
+	parent := solution{
+		problem: &subProblem{
+			c:                      []float64{1, 1, 1},
+			integralityConstraints: []bool{true, true, true},
+		},
+		x: []float64{1.5, 2.25, 3},
+		z: -10,
+	}
+	down, up := parent.problem.branchOn(0, parent.x[0])
+
+	// the branched variable is rounded in the direction of the child, the other fractional variable in its cheapest (unknown, so free) direction.
+	assert.Equal(t, float64(-9), table.childEstimate(parent, down))
+	assert.Equal(t, float64(-8), table.childEstimate(parent, up))
+}
+
+func TestEnumerationTree_pseudocostHistory(t *testing.T) {
+	prob := getTestKnapsackMILP()
+	prob.branchingHeuristic = BRANCH_PSEUDOCOST
+
+	tree := newEnumerationTree(prob.toInitialSubproblem(), dummyMiddleware{}, prob.config, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	got, err := tree.startSearch(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, float64(-24), got.z)
+
+	// all branching decisions have been checked
+	assert.Empty(t, tree.pendingBranchings)
+
+	// the history has been accumulated across nodes
+	var observations int
+	for _, table := range []map[int]*runningMean{tree.pseudocosts.down, tree.pseudocosts.up} {
+		for i, m := range table {
+			assert.True(t, prob.integralityConstraints[i])
+			assert.True(t, m.mean >= 0, "branching cannot improve the objective of a minimization problem")
+			observations += m.observations
+		}
+	}
+	assert.True(t, observations > 2, "expected history of multiple branching decisions, got %v observations", observations)
+}
@@ -0,0 +1,163 @@
+package ilp
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+// ErrFeasibilityPumpFailed is returned by FeasibilityPump if it did not find an integer-feasible solution within the maximum number of iterations.
+var ErrFeasibilityPumpFailed = errors.New("feasibility pump did not find an integer-feasible solution")
+
+// the maximum number of integer variables of which the target is moved to break a cycle of the feasibility pump.
+const pumpFlips = 10
+
+// FeasibilityPump searches for an integer-feasible solution of the problem without branching (see Fischetti et al. 2005, The feasibility pump).
+// Starting from the solution to the LP relaxation, it alternates between rounding the integer-constrained variables of the LP solution to obtain a target,
+// and solving the LP that minimizes the L1 distance between the integer-constrained variables and the target.
+// It stops as soon as a target is feasible for the problem, or returns ErrFeasibilityPumpFailed after maxIter iterations.
+// If the LP solution rounds to the previous target, the pump is cycling, so the target of the variables that are furthest from it is moved by one.
+// The solution is expressed in terms of the columns of c, and its objective value excludes the constant term of the objective function.
+func FeasibilityPump(p milpProblem, maxIter int) (*solution, error) {
+	relaxation := p.toInitialSubproblem().solve()
+	if relaxation.err != nil {
+		return nil, relaxation.err
+	}
+	x := relaxation.x[:len(p.c)]
+
+	var target []float64
+	for iter := 0; iter < maxIter; iter++ {
+		next := p.roundIntegers(x)
+		if p.feasibleSolution(next) {
+			return &solution{x: next, z: p.EvalObjective(next)}, nil
+		}
+
+		if target != nil && floats.Equal(next, target) {
+			p.perturbTarget(next, x)
+		}
+		target = next
+
+		var err error
+		x, err = p.closestTo(target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, ErrFeasibilityPumpFailed
+}
+
+// round the integer-constrained variables of the solution vector to the nearest integer.
+func (p milpProblem) roundIntegers(x []float64) []float64 {
+	rounded := append([]float64(nil), x...)
+	for i, integer := range p.integralityConstraints {
+		if integer {
+			rounded[i] = math.Round(x[i])
+		}
+	}
+	return rounded
+}
+
+// move the target of the integer-constrained variables that are furthest from their target in the solution vector by one towards their value,
+// e.g. flip binary variables, to break a cycle of the feasibility pump.
+func (p milpProblem) perturbTarget(target, x []float64) {
+	var fractional []int
+	for i, integer := range p.integralityConstraints {
+		if integer && x[i] != target[i] {
+			fractional = append(fractional, i)
+		}
+	}
+	sort.SliceStable(fractional, func(a, b int) bool {
+		return math.Abs(x[fractional[a]]-target[fractional[a]]) > math.Abs(x[fractional[b]]-target[fractional[b]])
+	})
+	if len(fractional) > pumpFlips {
+		fractional = fractional[:pumpFlips]
+	}
+
+	for _, i := range fractional {
+		if x[i] > target[i] {
+			target[i]++
+		} else {
+			target[i] = math.Max(0, target[i]-1)
+		}
+	}
+}
+
+// solve the LP that minimizes the L1 distance between the integer-constrained variables and the target, subject to the constraints of the problem.
+// The distance of each integer-constrained variable x_i is expressed by an additional variable d_i subject to d_i >= x_i - t_i and d_i >= t_i - x_i.
+// Returns the solution vector expressed in terms of the columns of c.
+func (p milpProblem) closestTo(target []float64) ([]float64, error) {
+	n := len(p.c)
+
+	var integers []int
+	for i, integer := range p.integralityConstraints {
+		if integer {
+			integers = append(integers, i)
+		}
+	}
+	columns := n + len(integers)
+
+	c := make([]float64, columns)
+	for k := range integers {
+		c[n+k] = 1
+	}
+
+	// the constraints of the problem do not involve the distances
+	var gRows int
+	if p.G != nil {
+		gRows, _ = p.G.Dims()
+	}
+	G := make([]float64, 0, (gRows+2*len(integers))*columns)
+	h := make([]float64, 0, gRows+2*len(integers))
+	for i := 0; i < gRows; i++ {
+		G = append(G, extendRow(p.G, i, columns)...)
+		h = append(h, p.h[i])
+	}
+	for k, i := range integers {
+		// x_i - d_i <= t_i
+		row := make([]float64, columns)
+		row[i], row[n+k] = 1, -1
+		G = append(G, row...)
+		h = append(h, target[i])
+
+		// -x_i - d_i <= -t_i
+		row = make([]float64, columns)
+		row[i], row[n+k] = -1, -1
+		G = append(G, row...)
+		h = append(h, -target[i])
+	}
+
+	distance := milpProblem{
+		c:                      c,
+		G:                      NewDenseConstraints(len(h), columns, G),
+		h:                      h,
+		integralityConstraints: make([]bool, columns),
+	}
+	if p.A != nil {
+		aRows, _ := p.A.Dims()
+		A := make([]float64, 0, aRows*columns)
+		for i := 0; i < aRows; i++ {
+			A = append(A, extendRow(p.A, i, columns)...)
+		}
+		distance.A = NewDenseConstraints(aRows, columns, A)
+		distance.b = p.b
+	}
+
+	s := distance.toInitialSubproblem().solve()
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.x[:n], nil
+}
+
+// a row of the matrix, padded with zeroes to the provided number of columns.
+func extendRow(m ConstraintMatrix, i, columns int) []float64 {
+	_, c := m.Dims()
+	row := make([]float64, columns)
+	for j := 0; j < c; j++ {
+		row[j] = m.At(i, j)
+	}
+	return row
+}
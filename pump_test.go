@@ -0,0 +1,50 @@
+package ilp
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/floats"
+)
+
+func TestFeasibilityPump(t *testing.T) {
+	prob := getTestKnapsackMILP()
+
+	cold, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, cold.Stats.NodesExplored > 10)
+
+	pumped, err := FeasibilityPump(prob, 20)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, prob.feasibleSolution(pumped.x))
+	for _, xi := range pumped.x {
+		assert.InDelta(t, math.Round(xi), xi, 1e-9)
+	}
+	assert.InDelta(t, floats.Dot(prob.c, pumped.x), pumped.z, 1e-9)
+	assert.True(t, pumped.z >= cold.BestIntegerSolution.z-1e-9)
+
+	// the solution found by the pump is the initial incumbent of the search
+	prob.config.FeasibilityPumpIterations = 20
+	warm, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.InDelta(t, cold.BestIntegerSolution.z, warm.BestIntegerSolution.z, 1e-9)
+	assert.True(t, warm.Stats.NodesExplored < cold.Stats.NodesExplored, "pump explored %v nodes, cold start %v", warm.Stats.NodesExplored, cold.Stats.NodesExplored)
+
+	// 2x = 1 has no integer solution, so the pump cycles until the iterations are exhausted
+	infeasible := milpProblem{
+		c:                      []float64{1},
+		A:                      NewDenseConstraints(1, 1, []float64{2}),
+		b:                      []float64{1},
+		integralityConstraints: []bool{true},
+	}
+	_, err = FeasibilityPump(infeasible, 5)
+	assert.Equal(t, ErrFeasibilityPumpFailed, err)
+}
@@ -0,0 +1,120 @@
+package ilp
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// AddRangeConstraint adds the constraint lower <= sum(terms) <= upper as a pair of linked, unnamed constraints (see Constraint.Range).
+// Nothing is added if an error is returned, e.g. because a term refers to a variable that is not part of the problem.
+func (p *Problem) AddRangeConstraint(terms []Term, lower, upper float64) (*Constraint, *Constraint, error) {
+	done, err := p.modify()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer done()
+	for i, t := range terms {
+		if !p.checkExpression(expression{coef: t.Coef, variable: t.Variable}) {
+			return nil, nil, fmt.Errorf("range constraint: the variable of term %v is not part of the problem", i)
+		}
+	}
+	if err := checkRangeBounds(lower, upper); err != nil {
+		return nil, nil, fmt.Errorf("range constraint: %w", err)
+	}
+
+	c := p.AddConstraint("")
+	for _, t := range terms {
+		c.AddExpression(t.Coef, t.Variable)
+	}
+	partner, err := c.Range(lower, upper)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, partner, nil
+}
+
+// Range turns the constraint into the range constraint lower <= sum(expressions) <= upper, e.g.
+//
+//	lower, err := prob.AddConstraint("r").AddExpression(2, x).Range(1, 3)
+//
+// The constraint becomes the 'greater than or equal to' side of the range and keeps its name, and an unnamed 'smaller than or equal to' side
+// with a copy of its expressions is added to the problem and returned. The two share a range ID (see Constraint.RangeID),
+// so that removing the range by name removes both, AllConstraintViolations reports them as one, and ExportMPS writes them as a single row with a RANGES entry.
+// Returns an error if a bound is not finite, if the lower bound exceeds the upper bound, or if the constraint is already part of a range.
+func (c *Constraint) Range(lower, upper float64) (*Constraint, error) {
+	done, err := c.problem.modify()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	if err := checkRangeBounds(lower, upper); err != nil {
+		return nil, fmt.Errorf("range constraint %v: %w", c.name, err)
+	}
+	if c.rangeID != 0 {
+		return nil, fmt.Errorf("range constraint %v: already part of range %v", c.name, c.rangeID)
+	}
+
+	id := c.problem.newRangeID()
+
+	c.GreaterThanOrEqualTo(lower)
+	c.rangeID = id
+
+	partner := c.problem.AddConstraint("").SmallerThanOrEqualTo(upper)
+	partner.expressions = append([]expression(nil), c.expressions...)
+	partner.rangeID = id
+
+	return partner, nil
+}
+
+// check that the bounds of a range constraint are finite and ordered.
+func checkRangeBounds(lower, upper float64) error {
+	if math.IsInf(lower, 0) || math.IsInf(upper, 0) || math.IsNaN(lower) || math.IsNaN(upper) {
+		return fmt.Errorf("bounds [%v, %v] are not finite", lower, upper)
+	}
+	if lower > upper {
+		return fmt.Errorf("lower bound %v exceeds upper bound %v", lower, upper)
+	}
+	return nil
+}
+
+// RangeID returns the ID shared by the two sides of a range constraint (see Constraint.Range), or 0 if the constraint is not part of a range.
+func (c *Constraint) RangeID() int {
+	return c.rangeID
+}
+
+// pair the 'greater than or equal to' side of each range constraint with its 'smaller than or equal to' side, if they can be written as a single ranged row.
+// This is not the case if either side has been modified since the range was added, e.g. by adding an expression to only one of them.
+func (p *Problem) rangePartners() map[*Constraint]*Constraint {
+	sides := make(map[int][]*Constraint)
+	for _, c := range p.constraints {
+		if c.rangeID != 0 {
+			sides[c.rangeID] = append(sides[c.rangeID], c)
+		}
+	}
+
+	partners := make(map[*Constraint]*Constraint)
+	for _, pair := range sides {
+		if len(pair) != 2 {
+			continue
+		}
+		lower, upper := pair[0], pair[1]
+		if !lower.greaterThanOrEqual {
+			lower, upper = upper, lower
+		}
+
+		ranged := lower.inequality && lower.greaterThanOrEqual && upper.inequality && !upper.greaterThanOrEqual &&
+			lower.bigMIndicator == nil && upper.bigMIndicator == nil &&
+			lower.rhs <= upper.rhs && reflect.DeepEqual(lower.expressions, upper.expressions)
+		if ranged {
+			partners[lower] = upper
+		}
+	}
+	return partners
+}
+
+// draw a new range ID, e.g. for a ranged row of an MPS file.
+func (p *Problem) newRangeID() int {
+	p.rangeConstraints++
+	return p.rangeConstraints
+}
@@ -0,0 +1,118 @@
+package ilp
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblem_AddRangeConstraint(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(1)
+	lower, upper, err := prob.AddRangeConstraint([]Term{{Coef: 2, Variable: x}}, 1, 3)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "", lower.Name())
+	assert.Equal(t, "", upper.Name())
+	assert.NotZero(t, lower.RangeID())
+	assert.Equal(t, lower.RangeID(), upper.RangeID())
+
+	// the range yields the same rows as two one-sided constraints
+	want := NewProblem()
+	wx := want.AddVariable("x").SetCoeff(1)
+	want.AddConstraint("").AddExpression(2, wx).GreaterThanOrEqualTo(1)
+	want.AddConstraint("").AddExpression(2, wx).SmallerThanOrEqualTo(3)
+	assert.Equal(t, want.toSolveable(), prob.toSolveable())
+	assert.Empty(t, prob.Validate())
+
+	// terms with variables that are not part of the problem are rejected, and nothing is added
+	foreign := NewProblem()
+	other := foreign.AddVariable("x")
+	_, _, err = prob.AddRangeConstraint([]Term{{Coef: 1, Variable: x}, {Coef: 1, Variable: other}}, 0, 1)
+	assert.EqualError(t, err, "range constraint: the variable of term 1 is not part of the problem")
+	_, _, err = prob.AddRangeConstraint([]Term{{Coef: 1}}, 0, 1)
+	assert.Error(t, err)
+	_, _, err = prob.AddRangeConstraint([]Term{{Coef: 1, Variable: x}}, 2, 1)
+	assert.EqualError(t, err, "range constraint: lower bound 2 exceeds upper bound 1")
+	_, _, err = prob.AddRangeConstraint([]Term{{Coef: 1, Variable: x}}, 0, math.Inf(1))
+	assert.Error(t, err)
+	assert.Equal(t, 2, len(prob.constraints))
+}
+
+func TestConstraint_Range(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(1)
+	partner, err := prob.AddConstraint("r").AddExpression(2, x).Range(1, 3)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Equal(t, 2, len(prob.constraints)) {
+		return
+	}
+	upper := prob.constraints[1]
+	assert.Equal(t, "r", prob.constraints[0].Name())
+	assert.Equal(t, "", upper.Name())
+	assert.Equal(t, upper, partner)
+	assert.NotZero(t, upper.RangeID())
+	assert.Equal(t, prob.constraints[0].RangeID(), upper.RangeID())
+
+	// a constraint can only be part of a single range
+	_, err = prob.constraints[0].Range(0, 1)
+	assert.EqualError(t, err, "range constraint r: already part of range 1")
+
+	// the two sides are reported as one
+	violations, err := prob.AllConstraintViolations([]float64{2.5})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"r": 2}, violations)
+	violations, err = prob.AllConstraintViolations([]float64{0})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"r": 1}, violations)
+
+	// a second range draws a new ID, and removing a range removes both of its sides
+	other, err := prob.AddConstraint("s").AddExpression(1, x).Range(0, 1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, upper.RangeID(), other.RangeID())
+	assert.NoError(t, prob.RemoveConstraint("r"))
+	assert.Equal(t, 2, len(prob.constraints))
+	for _, c := range prob.constraints {
+		assert.Equal(t, other.RangeID(), c.RangeID())
+	}
+
+	_, err = prob.AddConstraint("t").AddExpression(1, x).Range(2, 1)
+	assert.EqualError(t, err, "range constraint t: lower bound 2 exceeds upper bound 1")
+}
+
+func TestExportMPS_Ranges(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(1)
+	y := prob.AddVariable("y").SetCoeff(1)
+	_, err := prob.AddConstraint("lim").AddExpression(1, x).AddExpression(1, y).Range(1, 3.5)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, prob.ExportMPS(&buf)) {
+		return
+	}
+
+	// the range is written as a single row
+	assert.Equal(t, 1, strings.Count(buf.String(), " G  lim"))
+	assert.NotContains(t, buf.String(), " L  ")
+	assert.Contains(t, buf.String(), "RANGES\n    RNG       lim                2.5\n")
+
+	// the parsed problem links the sides of the range again
+	parsed, err := ParseMPS(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, prob.toSolveable(), parsed.toSolveable())
+	if assert.Equal(t, 2, len(parsed.constraints)) {
+		assert.NotZero(t, parsed.constraints[0].RangeID())
+		assert.Equal(t, parsed.constraints[0].RangeID(), parsed.constraints[1].RangeID())
+	}
+}
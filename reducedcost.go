@@ -0,0 +1,46 @@
+package ilp
+
+import "math"
+
+// Reduced-cost fixing: the reduced cost d_j of a nonbasic variable of an LP solution is a lower bound on the increase of the objective value
+// per unit that the variable is increased by. If the reduced cost of an integer-constrained variable at zero exceeds the gap between the incumbent
+// and the LP solution, each solution in the subtree of the subProblem in which the variable is at least one is worse than the incumbent.
+// Hence, the variable can be fixed at zero in the subtree without cutting off any solution that improves on the incumbent.
+// The basis of the LP solution is reconstructed from the solution (see newTableau).
+// Returns the constraints x_j <= 0 fixing these variables, excluding the variables that are fixed at zero already.
+// Returns nil if the solution has an error, there is no incumbent, or the basis cannot be reconstructed.
+func reducedCostFixing(sol solution, incumbentZ float64) []bnbConstraint {
+	if sol.err != nil || math.IsInf(incumbentZ, 1) {
+		return nil
+	}
+
+	tab, _, _, err := sol.tableau()
+	if err != nil {
+		return nil
+	}
+	c, _, _ := sol.problem.standardForm()
+	reduced := tab.reducedCosts(c)
+	gap := incumbentZ - sol.z
+
+	var fixings []bnbConstraint
+	for j, integer := range sol.problem.integralityConstraints {
+		if !integer || tab.basic[j] || sol.x[j] > cutTolerance || reduced[j] <= gap || sol.problem.fixedAtZero(j) {
+			continue
+		}
+
+		gsharp := make([]float64, len(sol.problem.c))
+		gsharp[j] = 1
+		fixings = append(fixings, bnbConstraint{branchedVariable: j, hsharp: 0, gsharp: gsharp})
+	}
+	return fixings
+}
+
+// whether a bnbConstraint of the subProblem bounds the variable with index i from above by zero.
+func (p subProblem) fixedAtZero(i int) bool {
+	for _, c := range p.bnbConstraints {
+		if variable, upper, ok := c.bound(); ok && upper && variable == i && c.hsharp <= 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package ilp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_reducedCostFixing(t *testing.T) {
+	prob := getTestKnapsackMILP()
+
+	// x = (4, 0, 1.5) with objective value -28, in which y is nonbasic with a reduced cost of 58/23
+	root := prob.toInitialSubproblem().solve()
+	if !assert.NoError(t, root.err) {
+		return
+	}
+	assert.InDelta(t, -28, root.z, 1e-9)
+
+	// the reduced cost of y does not exceed a gap of 4
+	assert.Empty(t, reducedCostFixing(root, -24))
+	assert.Empty(t, reducedCostFixing(root, root.z+10))
+
+	// but it exceeds a gap of 2, so y is fixed at zero
+	fixings := reducedCostFixing(root, -26)
+	if !assert.Len(t, fixings, 1) {
+		return
+	}
+	assert.Equal(t, bnbConstraint{branchedVariable: 1, hsharp: 0, gsharp: []float64{0, 1, 0, 0, 0}}, fixings[0])
+
+	// fixing y does not change the LP solution
+	fixed := root.withCuts(fixings)
+	resolved := fixed.problem.solve()
+	if !assert.NoError(t, resolved.err) {
+		return
+	}
+	assert.InDelta(t, root.z, resolved.z, 1e-9)
+	assert.InDeltaSlice(t, root.x, resolved.x, 1e-9)
+
+	// variables that are fixed already are not fixed again
+	assert.Empty(t, reducedCostFixing(fixed, -26))
+
+	// the optimal solution is not cut off
+	want, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	prob.config.ReducedCostFixing = true
+	got, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.InDelta(t, want.BestIntegerSolution.z, got.BestIntegerSolution.z, 1e-9)
+	assert.Equal(t, want.BestIntegerSolution.x, got.BestIntegerSolution.x)
+}
@@ -0,0 +1,126 @@
+package ilp
+
+import (
+	"math"
+)
+
+// the tolerance used to check whether the incumbent of a previous solve is still feasible.
+const warmStartTolerance = 1e-9
+
+// CutManager holds the cuts that were added to a Problem since it was last solved, along with the incumbent of that solve.
+// If cuts were added, the next solve re-optimizes the problem: it skips the presolve procedure and,
+// if the previous incumbent satisfies the cuts, starts the branch-and-bound procedure with its objective value as cutoff.
+type CutManager struct {
+	// the cuts added since the last solve
+	pending []*Constraint
+
+	// the best integer-feasible solution of the last solve keyed by variable name, or nil if none was found
+	incumbent rawSolution
+}
+
+// Pending returns the cuts that were added since the problem was last solved.
+func (m *CutManager) Pending() []*Constraint {
+	return append([]*Constraint(nil), m.pending...)
+}
+
+// AddCut adds a constraint, created by AddConstraint, as a cut that tightens a problem that has been solved before.
+// The next solve re-optimizes the problem, using the previous incumbent as a warm start. The constraint must belong to this problem. If not, this call will panic.
+func (p *Problem) AddCut(c *Constraint) {
+	defer p.mustModify()()
+	if c.problem != p {
+		panic("the cut does not belong to this problem")
+	}
+	if p.cuts == nil {
+		p.cuts = &CutManager{}
+	}
+	p.cuts.pending = append(p.cuts.pending, c)
+}
+
+// whether the next solve should re-optimize the problem, i.e. cuts were added after an integer-feasible solution was found.
+func (m *CutManager) reoptimize() bool {
+	return m != nil && len(m.pending) > 0 && m.incumbent != nil
+}
+
+// inject the cutoff of the previous incumbent into the converted problem, if that incumbent is feasible for the problem including the cuts.
+// Returns the previous incumbent if it was injected, or nil otherwise.
+func (m *CutManager) inject(milp *milpProblem, p Problem) rawSolution {
+	if !m.reoptimize() || !p.satisfiedBy(m.incumbent) {
+		return nil
+	}
+
+	objective, err := p.GetObjectiveValue(m.incumbent)
+	if err != nil {
+		return nil
+	}
+	if p.maximize {
+		objective = -objective
+	}
+
+	// the objective value of the milpProblem excludes the constant introduced by shifting the variables
+	milp.cutoff = objective - milp.objectiveConstant()
+	milp.hasCutoff = true
+
+	incumbent := make(rawSolution, len(m.incumbent))
+	for name, val := range m.incumbent {
+		incumbent[name] = val
+	}
+	return incumbent
+}
+
+// record the outcome of a solve, after which the cuts are no longer pending.
+func (m *CutManager) solved(soln *Solution) {
+	if m == nil {
+		return
+	}
+	m.pending = nil
+	m.incumbent = soln.byName
+}
+
+// whether the assignment satisfies the bounds and integrality constraints of the variables, and all constraints of the problem.
+func (p Problem) satisfiedBy(assignment rawSolution) bool {
+	for _, v := range p.variables {
+		val, ok := assignment[v.name]
+		if !ok || val < v.lower-warmStartTolerance || val > v.upper+warmStartTolerance {
+			return false
+		}
+		if v.integer && math.Abs(val-math.Round(val)) > warmStartTolerance {
+			return false
+		}
+	}
+
+	for _, s := range p.sos1 {
+		nonzero := 0
+		for _, v := range s.variables {
+			if math.Abs(assignment[v.name]) > sosTolerance {
+				nonzero++
+			}
+		}
+		if nonzero > 1 {
+			return false
+		}
+	}
+
+	for _, c := range p.constraints {
+		lhs, err := p.GetConstraintLHS(c, assignment)
+		if err != nil {
+			return false
+		}
+		tolerance := warmStartTolerance * math.Max(1, math.Abs(c.rhs))
+		switch {
+		case !c.inequality:
+			if math.Abs(lhs-c.rhs) > tolerance {
+				return false
+			}
+		case c.greaterThanOrEqual:
+			if lhs < c.rhs-tolerance {
+				return false
+			}
+		default:
+			if lhs > c.rhs+tolerance {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,72 @@
+package ilp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getReoptimizationProblem() (Problem, []*Variable) {
+	prob := NewProblem()
+	vars := []*Variable{
+		prob.AddVariable("x").SetCoeff(-6).IsInteger().UpperBound(6),
+		prob.AddVariable("y").SetCoeff(-7).IsInteger().UpperBound(6),
+		prob.AddVariable("z").SetCoeff(-3).IsInteger().UpperBound(6),
+	}
+	prob.AddConstraint("").AddExpression(3, vars[0]).AddExpression(5, vars[1]).AddExpression(7, vars[2]).SmallerThanOrEqualTo(10.5)
+	prob.AddConstraint("").AddExpression(8, vars[0]).AddExpression(9, vars[1]).AddExpression(5, vars[2]).SmallerThanOrEqualTo(24.5)
+	return prob, vars
+}
+
+func TestProblem_AddCut(t *testing.T) {
+	opt := WithBranchHeuristic(BRANCH_MOST_INFEASIBLE)
+
+	objective := func(p *Problem, soln *Solution) float64 {
+		obj, err := p.GetObjectiveValue(soln.byName)
+		assert.NoError(t, err)
+		return obj
+	}
+
+	// the optimum is x = 3, y = 0, z = 0
+	prob, vars := getReoptimizationProblem()
+	soln, err := prob.Solve(context.Background(), opt)
+	if !assert.NoError(t, err) {
+		return
+	}
+	original := objective(&prob, soln)
+	assert.Equal(t, float64(-18), original)
+
+	// the incumbent satisfies the cut, which serves as a cutoff that prunes the search
+	cut := prob.AddConstraint("cut").AddExpression(1, vars[0]).AddExpression(2, vars[1]).AddExpression(4, vars[2]).SmallerThanOrEqualTo(8.5)
+	prob.AddCut(cut)
+	assert.Equal(t, []*Constraint{cut}, prob.cuts.Pending())
+
+	warm, err := prob.Solve(context.Background(), opt)
+	assert.NoError(t, err)
+	assert.Equal(t, STATUS_OPTIMAL, warm.Status)
+	assert.Equal(t, original, objective(&prob, warm))
+	assert.Empty(t, prob.cuts.Pending())
+
+	// solving the same problem from scratch requires exploring more subProblems
+	cold, _ := getReoptimizationProblem()
+	coldVars := cold.variables
+	cold.AddConstraint("cut").AddExpression(1, coldVars[0]).AddExpression(2, coldVars[1]).AddExpression(4, coldVars[2]).SmallerThanOrEqualTo(8.5)
+	coldSoln, err := cold.Solve(context.Background(), opt, WithPresolve(false))
+	assert.NoError(t, err)
+	assert.Equal(t, original, objective(&cold, coldSoln))
+	assert.True(t, warm.Stats.NodesExplored < coldSoln.Stats.NodesExplored, "warm start explored %v subProblems, cold start %v", warm.Stats.NodesExplored, coldSoln.Stats.NodesExplored)
+
+	// the incumbent violates the next cut, so the objective value can only get worse
+	prob.AddCut(prob.AddConstraint("").AddExpression(1, vars[0]).AddExpression(3, vars[1]).AddExpression(3, vars[2]).SmallerThanOrEqualTo(2.5))
+	tightened, err := prob.Solve(context.Background(), opt)
+	assert.NoError(t, err)
+	assert.True(t, objective(&prob, tightened) >= original)
+	x, err := tightened.GetValueFor("x")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), x)
+
+	// cuts must belong to the problem
+	other := NewProblem()
+	assert.Panics(t, func() { prob.AddCut(other.AddConstraint("")) })
+}
@@ -0,0 +1,80 @@
+package ilp
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrRoundingFailed is returned by RoundingHeuristic if rounding the LP relaxation did not lead to an integer-feasible solution.
+var ErrRoundingFailed = errors.New("rounding heuristic did not find an integer-feasible solution")
+
+// RoundingHeuristic searches for an integer-feasible solution of the problem by greedily rounding the solution to its LP relaxation.
+// The fractional integer-constrained variable that is closest to an integer is fixed to that integer, after which the LP relaxation is solved again
+// to propagate the effect of the rounding to the other variables, until all integer-constrained variables are integral.
+// If fixing a variable to the nearest integer makes the LP relaxation infeasible, the rounding is backtracked and the variable is rounded in the other direction instead.
+// If that is infeasible as well, ErrRoundingFailed is returned.
+// The solution vector includes the slack variables of the standard form, and its objective value excludes the constant term of the objective function.
+func RoundingHeuristic(p milpProblem) (*solution, error) {
+	relaxation := p.toInitialSubproblem().solve()
+	if relaxation.err != nil {
+		return nil, relaxation.err
+	}
+	return roundingDive(relaxation)
+}
+
+// fix the fractional integer-constrained variables of the solution one at a time, starting with the one closest to an integer, re-solving after each fixation.
+func roundingDive(s solution) (*solution, error) {
+	fixed := make([]bool, len(s.x))
+	for {
+		i := s.mostIntegralFractional(fixed)
+		if i < 0 {
+			break
+		}
+		fixed[i] = true
+
+		nearest := math.Round(s.x[i])
+		next := s.problem.fix(i, nearest).solve()
+		if next.err != nil {
+			// backtrack, and round in the other direction instead
+			other := nearest + 1
+			if s.x[i] < nearest {
+				other = nearest - 1
+			}
+			if other < 0 {
+				return nil, ErrRoundingFailed
+			}
+			next = s.problem.fix(i, other).solve()
+		}
+		if next.err != nil {
+			return nil, ErrRoundingFailed
+		}
+		s = next
+	}
+
+	if !s.problem.sos1Feasible(s.x) {
+		return nil, ErrRoundingFailed
+	}
+	return &s, nil
+}
+
+// the index of the fractional integer-constrained variable that is closest to an integer, ignoring the variables that have been fixed already.
+// Returns -1 if there is none.
+func (s solution) mostIntegralFractional(fixed []bool) int {
+	best := -1
+	bestDistance := math.Inf(1)
+	for i, integer := range s.problem.integralityConstraints {
+		if !integer || fixed[i] || isAllIntegerWithTol(s.x[i], s.problem.integralityTol) {
+			continue
+		}
+		if distance := math.Abs(s.x[i] - math.Round(s.x[i])); distance < bestDistance {
+			best = i
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+// create a child of the subProblem in which the variable is fixed to the value.
+func (p subProblem) fix(i int, value float64) subProblem {
+	return p.getChild(i, 1, value).getChild(i, -1, -value)
+}
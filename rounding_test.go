@@ -0,0 +1,80 @@
+package ilp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/floats"
+)
+
+func TestRoundingHeuristic(t *testing.T) {
+	prob := getTestKnapsackMILP()
+
+	rounded, err := RoundingHeuristic(prob)
+	if !assert.NoError(t, err) {
+		return
+	}
+	x := rounded.x[:len(prob.c)]
+	assert.True(t, prob.feasibleSolution(prob.roundIntegers(x)))
+	assert.InDelta(t, floats.Dot(prob.c, x), rounded.z, 1e-9)
+
+	cold, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, rounded.z >= cold.BestIntegerSolution.z-1e-9)
+
+	// the rounded solution is the initial incumbent of the search
+	prob.config.RoundingHeuristic = true
+	warm, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.InDelta(t, cold.BestIntegerSolution.z, warm.BestIntegerSolution.z, 1e-9)
+	assert.True(t, warm.Stats.NodesExplored < cold.Stats.NodesExplored, "rounding explored %v nodes, cold start %v", warm.Stats.NodesExplored, cold.Stats.NodesExplored)
+
+	// rounding 2x = 1 is infeasible in both directions
+	infeasible := milpProblem{
+		c:                      []float64{1},
+		A:                      NewDenseConstraints(1, 1, []float64{2}),
+		b:                      []float64{1},
+		integralityConstraints: []bool{true},
+	}
+	_, err = RoundingHeuristic(infeasible)
+	assert.Equal(t, ErrRoundingFailed, err)
+}
+
+// stops the search as soon as the first integer-feasible solution is found
+type firstIncumbentMiddleware struct {
+	cancel context.CancelFunc
+}
+
+func (m firstIncumbentMiddleware) ProcessDecision(s solution, d bnbDecision) {
+	if d == BETTER_THAN_INCUMBENT_FEASIBLE || d == INITIAL_RX_FEASIBLE_FOR_IP {
+		m.cancel()
+	}
+}
+
+func (m firstIncumbentMiddleware) NewSubProblem(s subProblem) {}
+
+// Compare the time it takes to find the first integer-feasible solution by branching and by the rounding heuristic.
+func BenchmarkRoundingHeuristic(b *testing.B) {
+	prob := getTestKnapsackMILP()
+
+	b.Run("branch-and-bound", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ctx, cancel := context.WithCancel(context.Background())
+			prob.solve(ctx, 1, firstIncumbentMiddleware{cancel: cancel})
+			cancel()
+		}
+	})
+
+	b.Run("rounding", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := RoundingHeuristic(prob); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
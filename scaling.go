@@ -0,0 +1,199 @@
+package ilp
+
+import (
+	"errors"
+	"math"
+)
+
+// EquilibrationScale scales the rows and columns of the constraint matrix such that the absolute values of its entries are close to 1.
+// This improves the numerical stability of the simplex algorithm for problems with coefficients that span many orders of magnitude.
+// The problem is modified in-place: each constraint i is multiplied by rowScales[i],
+// and each variable j is substituted by x_j = colScales[j] * y_j, which scales its objective coefficient, constraint coefficients and bounds.
+// A solution y of the scaled problem can be converted back to a solution of the original problem by x_j = colScales[j] * y_j.
+// Integer-constrained variables are never scaled, as that would break their integrality.
+// All scale factors are rounded to powers of two, so that scaling does not introduce rounding errors.
+func (p *Problem) EquilibrationScale() (rowScales, colScales []float64, err error) {
+	if err := p.checkScalable(); err != nil {
+		return nil, nil, err
+	}
+
+	// scale each row by the inverse of its largest absolute coefficient
+	rowScales = make([]float64, len(p.constraints))
+	for i, c := range p.constraints {
+		var rowMax float64
+		for _, e := range c.lhsExpressions() {
+			rowMax = math.Max(rowMax, math.Abs(e.coef))
+		}
+		rowScales[i] = powerOfTwoScale(rowMax)
+	}
+
+	// scale each continuous column by the inverse of its largest absolute coefficient in the row-scaled matrix
+	colMax := make(map[*Variable]float64)
+	for i, c := range p.constraints {
+		for _, e := range c.lhsExpressions() {
+			colMax[e.variable] = math.Max(colMax[e.variable], math.Abs(e.coef*rowScales[i]))
+		}
+	}
+
+	colScales = make([]float64, len(p.variables))
+	for j, v := range p.variables {
+		colScales[j] = 1
+		if !v.integer {
+			colScales[j] = powerOfTwoScale(colMax[v])
+		}
+	}
+
+	p.applyScaling(rowScales, colScales)
+
+	return rowScales, colScales, nil
+}
+
+// the number of alternating passes over the rows and columns of geometric mean scaling
+const geometricScalingPasses = 4
+
+// ScalingInfo contains the scale factors that were applied to a problem,
+// which are needed to convert a solution of the scaled problem back to a solution of the original problem.
+type ScalingInfo struct {
+	// each constraint i was multiplied by RowScales[i]
+	RowScales []float64
+
+	// each variable j was substituted by x_j = ColScales[j] * y_j
+	ColScales []float64
+
+	// the names of the scaled variables, in the same order as ColScales
+	variables []string
+}
+
+// convert the values of the variables of the scaled problem back to the values of the original variables.
+func (s ScalingInfo) unscale(sol rawSolution) rawSolution {
+	for j, name := range s.variables {
+		if val, ok := sol[name]; ok {
+			sol[name] = val * s.ColScales[j]
+		}
+	}
+	return sol
+}
+
+// GeometricMeanScale scales the rows and columns of the constraint matrix such that the geometric mean of the absolute nonzero coefficients
+// of each row and column is close to 1. Compared to equilibration (see Problem.EquilibrationScale), this reduces the spread of coefficients
+// that span many orders of magnitude within the same row or column, rather than only bounding the largest ones.
+// The rows and columns are scaled in a few alternating passes. Like equilibration, the problem is modified in-place,
+// integer-constrained variables are never scaled and all scale factors are powers of two.
+func (p *Problem) GeometricMeanScale() (ScalingInfo, error) {
+	if err := p.checkScalable(); err != nil {
+		return ScalingInfo{}, err
+	}
+
+	rowScales := make([]float64, len(p.constraints))
+	for i := range rowScales {
+		rowScales[i] = 1
+	}
+	colScales := make([]float64, len(p.variables))
+	colIndex := make(map[*Variable]int, len(p.variables))
+	for j, v := range p.variables {
+		colScales[j] = 1
+		colIndex[v] = j
+	}
+
+	for pass := 0; pass < geometricScalingPasses; pass++ {
+		// scale each row by the inverse of the geometric mean of its scaled coefficients
+		for i, c := range p.constraints {
+			var logSum float64
+			var n int
+			for _, e := range c.lhsExpressions() {
+				if e.coef != 0 {
+					logSum += math.Log2(math.Abs(e.coef * rowScales[i] * colScales[colIndex[e.variable]]))
+					n++
+				}
+			}
+			if n > 0 {
+				rowScales[i] *= powerOfTwoScale(math.Exp2(logSum / float64(n)))
+			}
+		}
+
+		// scale each continuous column by the inverse of the geometric mean of its scaled coefficients
+		logSums := make([]float64, len(p.variables))
+		counts := make([]int, len(p.variables))
+		for i, c := range p.constraints {
+			for _, e := range c.lhsExpressions() {
+				if e.coef != 0 {
+					j := colIndex[e.variable]
+					logSums[j] += math.Log2(math.Abs(e.coef * rowScales[i] * colScales[j]))
+					counts[j]++
+				}
+			}
+		}
+		for j, v := range p.variables {
+			if !v.integer && counts[j] > 0 {
+				colScales[j] *= powerOfTwoScale(math.Exp2(logSums[j] / float64(counts[j])))
+			}
+		}
+	}
+
+	p.applyScaling(rowScales, colScales)
+
+	return p.scalingInfo(rowScales, colScales), nil
+}
+
+// describe the provided scale factors of the problem
+func (p *Problem) scalingInfo(rowScales, colScales []float64) ScalingInfo {
+	names := make([]string, len(p.variables))
+	for j, v := range p.variables {
+		names[j] = v.name
+	}
+	return ScalingInfo{RowScales: rowScales, ColScales: colScales, variables: names}
+}
+
+// check whether all coefficients of the problem are finite, which is required to scale it.
+func (p *Problem) checkScalable() error {
+	for _, v := range p.variables {
+		if math.IsNaN(v.coefficient) || math.IsInf(v.coefficient, 0) {
+			return errors.New("cannot scale a problem with a non-finite objective coefficient")
+		}
+	}
+
+	for _, c := range p.constraints {
+		for _, e := range c.lhsExpressions() {
+			if math.IsNaN(e.coef) || math.IsInf(e.coef, 0) {
+				return errors.New("cannot scale a problem with a non-finite constraint coefficient")
+			}
+		}
+	}
+
+	return nil
+}
+
+// multiply each constraint i by rowScales[i] and substitute each variable j by x_j = colScales[j] * y_j.
+func (p *Problem) applyScaling(rowScales, colScales []float64) {
+	scaleOf := make(map[*Variable]float64, len(p.variables))
+	for j, v := range p.variables {
+		scaleOf[v] = colScales[j]
+	}
+
+	// apply the scaling to the constraints
+	for i, c := range p.constraints {
+		for k, e := range c.expressions {
+			c.expressions[k].coef = e.coef * rowScales[i] * scaleOf[e.variable]
+		}
+		if c.bigMIndicator != nil {
+			c.bigM = c.bigM * rowScales[i] * scaleOf[c.bigMIndicator]
+		}
+		c.rhs = c.rhs * rowScales[i]
+	}
+
+	// apply the column scaling to the objective function and the variable bounds
+	for j, v := range p.variables {
+		v.coefficient = v.coefficient * colScales[j]
+		v.lower = v.lower / colScales[j]
+		v.upper = v.upper / colScales[j]
+	}
+}
+
+// get the power of two closest to the inverse of the provided absolute value.
+// A value of zero (e.g. an empty row or column) is not scaled.
+func powerOfTwoScale(absValue float64) float64 {
+	if absValue == 0 {
+		return 1
+	}
+	return math.Exp2(math.Round(-math.Log2(absValue)))
+}
@@ -0,0 +1,142 @@
+package ilp
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// build a problem with coefficients that span several orders of magnitude
+func getBadlyScaledProblem() (Problem, *Variable, *Variable, *Variable) {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(-1)
+	y := prob.AddVariable("y").SetCoeff(-1)
+	z := prob.AddVariable("z").SetCoeff(-1).IsInteger().UpperBound(10)
+
+	prob.AddConstraint("").AddExpression(1000, x).AddExpression(2000, y).SmallerThanOrEqualTo(4000)
+	prob.AddConstraint("").AddExpression(0.001, x).SmallerThanOrEqualTo(0.003)
+	prob.AddConstraint("").AddExpression(0.5, z).SmallerThanOrEqualTo(2.5)
+
+	return prob, x, y, z
+}
+
+func TestProblem_EquilibrationScale(t *testing.T) {
+	prob, x, y, z := getBadlyScaledProblem()
+
+	rowScales, colScales, err := prob.EquilibrationScale()
+	assert.NoError(t, err)
+	assert.Len(t, rowScales, 3)
+	assert.Len(t, colScales, 3)
+
+	// all scale factors should be powers of two
+	for _, s := range append(rowScales, colScales...) {
+		_, exp := math.Frexp(s)
+		assert.Equal(t, math.Ldexp(0.5, exp), s)
+	}
+
+	// integer-constrained variables should not be scaled
+	assert.Equal(t, float64(1), colScales[2])
+	assert.Equal(t, float64(10), z.upper)
+
+	// after scaling, all constraint coefficients should be close to 1 in magnitude
+	for _, c := range prob.constraints {
+		for _, e := range c.expressions {
+			assert.True(t, math.Abs(e.coef) >= 0.25 && math.Abs(e.coef) <= 4, "coefficient %v not equilibrated", e.coef)
+		}
+	}
+
+	// the objective coefficients are scaled along with the columns
+	assert.Equal(t, -colScales[0], x.coefficient)
+	assert.Equal(t, -colScales[1], y.coefficient)
+}
+
+func TestProblem_Solve_Equilibration(t *testing.T) {
+	unscaled, _, _, _ := getBadlyScaledProblem()
+	want, err := unscaled.Solve(context.Background())
+	assert.NoError(t, err)
+
+	scaled, _, _, _ := getBadlyScaledProblem()
+	scaled.EnableEquilibration()
+	got, err := scaled.Solve(context.Background())
+	assert.NoError(t, err)
+
+	for _, name := range []string{"x", "y", "z"} {
+		wantVal, err := want.GetValueFor(name)
+		assert.NoError(t, err)
+		gotVal, err := got.GetValueFor(name)
+		assert.NoError(t, err)
+		assert.InDelta(t, wantVal, gotVal, 1e-9, name)
+	}
+
+	x, _ := got.GetValueFor("x")
+	y, _ := got.GetValueFor("y")
+	assert.InDelta(t, 3, x, 1e-9)
+	assert.InDelta(t, 0.5, y, 1e-9)
+}
+
+// build a problem with coefficients ranging from 1e-6 to 1e6
+func getWidelyScaledProblem() Problem {
+	prob := NewProblem()
+	x := prob.AddVariable("x").SetCoeff(-1)
+	y := prob.AddVariable("y").SetCoeff(-1)
+	z := prob.AddVariable("z").SetCoeff(-1).IsInteger()
+
+	prob.AddConstraint("").AddExpression(1e6, x).AddExpression(2e6, y).SmallerThanOrEqualTo(4e6)
+	prob.AddConstraint("").AddExpression(1e-6, x).SmallerThanOrEqualTo(3e-6)
+	prob.AddConstraint("").AddExpression(1e-6, y).AddExpression(1e3, z).SmallerThanOrEqualTo(5.5e3)
+
+	return prob
+}
+
+func TestProblem_GeometricMeanScale(t *testing.T) {
+	prob := getWidelyScaledProblem()
+
+	info, err := prob.GeometricMeanScale()
+	assert.NoError(t, err)
+	assert.Len(t, info.RowScales, 3)
+	assert.Len(t, info.ColScales, 3)
+
+	// all scale factors should be powers of two
+	for _, s := range append(info.RowScales, info.ColScales...) {
+		_, exp := math.Frexp(s)
+		assert.Equal(t, math.Ldexp(0.5, exp), s)
+	}
+
+	// integer-constrained variables should not be scaled
+	assert.Equal(t, float64(1), info.ColScales[2])
+
+	// the spread of the coefficients should be reduced from 12 to at most 6 orders of magnitude
+	min, max := math.Inf(1), float64(0)
+	for _, c := range prob.constraints {
+		for _, e := range c.expressions {
+			min = math.Min(min, math.Abs(e.coef))
+			max = math.Max(max, math.Abs(e.coef))
+		}
+	}
+	assert.True(t, max/min <= 1e6, "coefficients span %v to %v", min, max)
+
+	// solution values of the scaled problem are converted back to the original variables
+	assert.Equal(t, rawSolution{"x": 2 * info.ColScales[0], "y": info.ColScales[1], "z": 3}, info.unscale(rawSolution{"x": 2, "y": 1, "z": 3}))
+}
+
+func TestProblem_Solve_CoefficientScaling(t *testing.T) {
+	unscaled := getWidelyScaledProblem()
+	want, err := unscaled.Solve(context.Background())
+	assert.NoError(t, err)
+
+	scaled := getWidelyScaledProblem()
+	scaled.EnableCoefficientScaling()
+	got, err := scaled.Solve(context.Background())
+	assert.NoError(t, err)
+
+	for name, value := range map[string]float64{"x": 3, "y": 0.5, "z": 5} {
+		wantVal, err := want.GetValueFor(name)
+		assert.NoError(t, err)
+		gotVal, err := got.GetValueFor(name)
+		assert.NoError(t, err)
+		assert.InDelta(t, wantVal, gotVal, 1e-6, name)
+		assert.InDelta(t, value, gotVal, 1e-6, name)
+	}
+}
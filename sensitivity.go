@@ -0,0 +1,121 @@
+package ilp
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ObjRange is the interval within which an objective coefficient can vary while the basis of an LP solution remains optimal.
+// Either end may be infinite.
+type ObjRange struct {
+	Lower, Upper float64
+}
+
+// Objective ranging of the LP solution of a subProblem: for each variable of the subProblem, the interval within which its coefficient in c can vary
+// (keeping the other coefficients fixed) while the basis of the solution remains optimal. The ranges refer to the minimization form of the subProblem.
+// Gonum's simplex implementation does not expose the final basis, so it is reconstructed from the solution (see newTableau).
+// Returns nil if the solution has an error or its basis cannot be reconstructed.
+func sensAnalysisObjective(sol solution) []ObjRange {
+	if sol.err != nil {
+		return nil
+	}
+
+	tab, _, _, err := sol.tableau()
+	if err != nil {
+		return nil
+	}
+
+	c, _, _ := sol.problem.standardForm()
+	reduced := tab.reducedCosts(c)
+
+	ranges := make([]ObjRange, len(sol.problem.c))
+	for j := range ranges {
+		cj := c[j]
+
+		// increasing the coefficient of a nonbasic variable never makes it attractive, decreasing it by more than its reduced cost does.
+		if !tab.basic[j] {
+			ranges[j] = ObjRange{Lower: cj - reduced[j], Upper: math.Inf(1)}
+			continue
+		}
+
+		ranges[j] = ObjRange{Lower: math.Inf(-1), Upper: math.Inf(1)}
+	}
+
+	// changing the coefficient of the basic variable of row r by delta changes the reduced cost of each nonbasic variable k by -delta * rows[r, k].
+	// The basis remains optimal as long as none of these reduced costs turn negative.
+	_, n := tab.rows.Dims()
+	for r, j := range tab.basis {
+		if j >= len(ranges) {
+			// the slack variables of the branch-and-bound constraints are not variables of the subProblem
+			continue
+		}
+
+		for k := 0; k < n; k++ {
+			alpha := tab.rows.At(r, k)
+			if tab.basic[k] || math.Abs(alpha) < cutTolerance {
+				continue
+			}
+
+			limit := c[j] + math.Max(reduced[k], 0)/alpha
+			if alpha > 0 {
+				ranges[j].Upper = math.Min(ranges[j].Upper, limit)
+			} else {
+				ranges[j].Lower = math.Max(ranges[j].Lower, limit)
+			}
+		}
+	}
+
+	return ranges
+}
+
+// the reduced costs c_j - c_B * B^-1 * A_j of all variables, given the objective coefficients c of the standard-form problem.
+func (t *tableau) reducedCosts(c []float64) []float64 {
+	reduced := make([]float64, len(c))
+	copy(reduced, c)
+	for r, j := range t.basis {
+		for k := range reduced {
+			reduced[k] -= c[j] * t.rows.At(r, k)
+		}
+	}
+	return reduced
+}
+
+// Compute the dual values y = c_B * B^-1 of the optimal basic solution x of the standard-form problem min c * x s.t. A * x = b, x >= 0.
+// If the solution is not degenerate, the basis consists of the nonzero variables. Otherwise, it is completed as in newTableau.
+// Returns nil if the basis cannot be determined.
+func dualValues(c []float64, A *mat.Dense, x []float64) []float64 {
+	if A == nil {
+		return nil
+	}
+	m, _ := A.Dims()
+
+	var basis []int
+	for j, v := range x {
+		if v > cutTolerance {
+			basis = append(basis, j)
+		}
+	}
+	if len(basis) != m {
+		var err error
+		if basis, err = findBasis(A, x); err != nil {
+			return nil
+		}
+	}
+
+	B := mat.NewDense(m, m, nil)
+	cB := make([]float64, m)
+	col := make([]float64, m)
+	for i, j := range basis {
+		mat.Col(col, j, A)
+		B.SetCol(i, col)
+		cB[i] = c[j]
+	}
+
+	// solve B^T * y = c_B
+	var y mat.VecDense
+	if err := y.SolveVec(B.T(), mat.NewVecDense(m, cB)); err != nil {
+		return nil
+	}
+	return y.RawVector().Data
+}
@@ -0,0 +1,45 @@
+package ilp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sensAnalysisObjective(t *testing.T) {
+	// maximize 3x + 5y subject to x <= 4, 2y <= 12 and 3x + 2y <= 18, of which the optimum is x = 2, y = 6.
+	prob := NewProblem()
+	prob.Maximize()
+	x := prob.AddVariable("x").SetCoeff(3)
+	y := prob.AddVariable("y").SetCoeff(5)
+	prob.AddConstraint("").AddExpression(1, x).SmallerThanOrEqualTo(4)
+	prob.AddConstraint("").AddExpression(2, y).SmallerThanOrEqualTo(12)
+	prob.AddConstraint("").AddExpression(3, x).AddExpression(2, y).SmallerThanOrEqualTo(18)
+
+	sol := prob.toSolveable().toInitialSubproblem().solve()
+	assert.NoError(t, sol.err)
+	assert.InDeltaSlice(t, []float64{2, 6}, sol.x[:2], 1e-9)
+
+	// the basis remains optimal for objective coefficients 0 <= c_x <= 7.5 and c_y >= 2, which are negated in the minimization form
+	ranges := sensAnalysisObjective(sol)
+	if !assert.Len(t, ranges, len(sol.problem.c)) {
+		return
+	}
+	assert.InDelta(t, -7.5, ranges[0].Lower, 1e-9)
+	assert.InDelta(t, 0, ranges[0].Upper, 1e-9)
+	assert.True(t, math.IsInf(ranges[1].Lower, -1))
+	assert.InDelta(t, -2, ranges[1].Upper, 1e-9)
+
+	// the slacks of the binding constraints are nonbasic, and can only decrease by their reduced cost (the shadow price) before entering the basis
+	assert.InDelta(t, -1.5, ranges[3].Lower, 1e-9)
+	assert.InDelta(t, -1, ranges[4].Lower, 1e-9)
+	assert.True(t, math.IsInf(ranges[4].Upper, 1))
+
+	// the dual values of the binding constraints are their (negated) shadow prices
+	assert.InDeltaSlice(t, []float64{0, -1.5, -1}, sol.dual, 1e-9)
+
+	// no ranges are available for failed solutions
+	sol.err = INITIAL_RELAXATION_NOT_FEASIBLE
+	assert.Nil(t, sensAnalysisObjective(sol))
+}
@@ -0,0 +1,116 @@
+package ilp
+
+import (
+	"fmt"
+	"math"
+)
+
+// values smaller than this in absolute value are considered to be zero when checking SOS constraints.
+const sosTolerance = 1e-9
+
+// SOSConstraint is a special ordered set of type 1 (SOS1): at most one of its variables may take on a nonzero value.
+// The order of the variables is meaningful, e.g. the breakpoints of a piecewise linear function.
+// As a violated SOS1 constraint is resolved by forcing variables to zero, all its variables should be nonnegative.
+type SOSConstraint struct {
+	variables []*Variable
+
+	// the positions of the variables in the solution vector, which are only set on the constraints passed to the branch-and-bound procedure.
+	indices []int
+}
+
+// AddSOS1 adds a constraint that allows at most one of the provided variables to be nonzero, and returns a reference to that constraint.
+// Returns an error if a variable does not belong to the problem, or if its lower bound is negative, in which case the problem is left untouched.
+func (p *Problem) AddSOS1(vars []*Variable) (*SOSConstraint, error) {
+	done, err := p.modify()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	for _, v := range vars {
+		if !p.checkExpression(expression{variable: v}) {
+			return nil, fmt.Errorf("variable %v does not belong to the problem", v.name)
+		}
+		if v.lower < 0 {
+			return nil, fmt.Errorf("variable %v: lower bound %v is negative", v.name, v.lower)
+		}
+	}
+
+	sos := &SOSConstraint{
+		variables: append([]*Variable(nil), vars...),
+	}
+	p.sos1 = append(p.sos1, sos)
+
+	return sos, nil
+}
+
+// whether at most one of the variables of the set is nonzero in the solution vector.
+func (s SOSConstraint) feasible(x []float64) bool {
+	nonzero := 0
+	for _, i := range s.indices {
+		if math.Abs(x[i]) > sosTolerance {
+			nonzero++
+		}
+	}
+	return nonzero <= 1
+}
+
+// SelectVariable selects the breakpoint of a violated SOS1 constraint, i.e. the variable of the set at which the cumulative value of the
+// nonzero variables that precede it reaches half of their total value.
+// At least one nonzero variable precedes the breakpoint, such that both children of the branching exclude the current solution.
+func (s SOSConstraint) SelectVariable(state BranchingState) int {
+	var nonzero []int
+	total := 0.0
+	for _, i := range s.indices {
+		if v := math.Abs(state.X[i]); v > sosTolerance {
+			nonzero = append(nonzero, i)
+			total += v
+		}
+	}
+
+	if len(nonzero) < 2 {
+		panic("cannot select a breakpoint of a feasible SOS1 constraint")
+	}
+
+	cumulative := 0.0
+	for k, i := range nonzero {
+		if k > 0 && cumulative >= total/2 {
+			return i
+		}
+		cumulative += math.Abs(state.X[i])
+	}
+
+	return nonzero[len(nonzero)-1]
+}
+
+// whether all SOS1 constraints of the subProblem are satisfied by the solution vector.
+func (p subProblem) sos1Feasible(x []float64) bool {
+	for _, s := range p.sos1Constraints {
+		if !s.feasible(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// create the two children of the subProblem that result from branching on the SOS1 constraint at the provided breakpoint.
+// The first child forces the variables of the set that precede the breakpoint to zero, the second child forces the breakpoint and the variables that follow it to zero.
+func (p subProblem) branchOnSOS(s SOSConstraint, breakpoint int) (p1, p2 subProblem) {
+	for k, i := range s.indices {
+		if i == breakpoint {
+			return p.forbid(s.indices[:k]), p.forbid(s.indices[k:])
+		}
+	}
+	panic("breakpoint is not a variable of the SOS1 constraint")
+}
+
+// create a child of the subProblem in which the variables with the provided indices are forced to zero.
+// As these variables are nonnegative, a single constraint bounding their sum by zero suffices.
+func (p subProblem) forbid(indices []int) subProblem {
+	child := p.getChild(indices[0], 1, 0)
+	decision := child.bnbConstraints[len(child.bnbConstraints)-1]
+	for _, i := range indices[1:] {
+		decision.gsharp[i] = 1
+	}
+	return child
+}
@@ -0,0 +1,115 @@
+package ilp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Build a piecewise linear cost model with breakpoints t = 0, 1, ..., 4, of which exactly one should be selected.
+// The LP relaxation interpolates between the breakpoints 1 and 4 to satisfy t >= 2.2, whereas the cheapest single breakpoint is t = 3.
+func getPiecewiseProblem() (Problem, []*Variable) {
+	prob := NewProblem()
+	costs := []float64{0, 1, 5, 6, 7}
+
+	var lambdas []*Variable
+	weights := prob.AddConstraint("")
+	position := prob.AddConstraint("")
+	for k, cost := range costs {
+		lambda := prob.AddVariable(fmt.Sprintf("lambda%d", k)).SetCoeff(cost)
+		weights.AddExpression(1, lambda)
+		position.AddExpression(float64(k), lambda)
+		lambdas = append(lambdas, lambda)
+	}
+	weights.EqualTo(1)
+	position.GreaterThanOrEqualTo(2.2)
+
+	if _, err := prob.AddSOS1(lambdas); err != nil {
+		panic(err)
+	}
+
+	return prob, lambdas
+}
+
+func TestSOSConstraint_SelectVariable(t *testing.T) {
+	prob, _ := getPiecewiseProblem()
+	milp := prob.toSolveable()
+	root := milp.toInitialSubproblem().solve()
+	assert.NoError(t, root.err)
+	assert.InDelta(t, 3.4, root.z, 1e-9)
+
+	sos := milp.sos1Constraints[0]
+	assert.False(t, sos.feasible(root.x))
+
+	// the relaxation puts 0.6 on breakpoint 1 and 0.4 on breakpoint 4, so the cumulative value reaches half at breakpoint 4
+	breakpoint := sos.SelectVariable(newBranchingState(root))
+	assert.Equal(t, 4, breakpoint)
+
+	// the first child forbids breakpoints 0 to 3, the second child forbids breakpoint 4
+	p1, p2 := root.problem.branchOnSOS(sos, breakpoint)
+	assert.Equal(t, []float64{1, 1, 1, 1, 0}, p1.bnbConstraints[0].gsharp[:5])
+	assert.Equal(t, []float64{0, 0, 0, 0, 1}, p2.bnbConstraints[0].gsharp[:5])
+
+	down := p1.solve()
+	assert.NoError(t, down.err)
+	assert.InDelta(t, 7, down.z, 1e-9)
+
+	up := p2.solve()
+	assert.NoError(t, up.err)
+	assert.InDelta(t, 4, up.z, 1e-9)
+}
+
+func TestProblem_AddSOS1(t *testing.T) {
+	prob := NewProblem()
+	x := prob.AddVariable("x")
+	y := prob.AddVariable("y").LowerBound(-1)
+	other := NewProblem()
+	z := other.AddVariable("z")
+
+	_, err := prob.AddSOS1([]*Variable{x, y})
+	assert.EqualError(t, err, "variable y: lower bound -1 is negative")
+	_, err = prob.AddSOS1([]*Variable{x, z})
+	assert.EqualError(t, err, "variable z does not belong to the problem")
+	assert.Empty(t, prob.sos1)
+
+	y.LowerBound(0)
+	sos, err := prob.AddSOS1([]*Variable{x, y})
+	assert.NoError(t, err)
+	assert.Equal(t, []*SOSConstraint{sos}, prob.sos1)
+}
+
+func TestProblem_Solve_SOS1(t *testing.T) {
+	prob, lambdas := getPiecewiseProblem()
+
+	soln, err := prob.Solve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, STATUS_OPTIMAL, soln.Status)
+	assertSelected(t, soln, lambdas, 3)
+
+	// fixing a variable of the set at a nonzero value during presolve forces the others to zero
+	prob, lambdas = getPiecewiseProblem()
+	lambdas[4].LowerBound(1).UpperBound(1)
+
+	prepper := newPreprocessor()
+	filtered := prepper.filterFixedVars(copyProblem(prob))
+	assert.Empty(t, filtered.variables)
+	assert.Empty(t, filtered.sos1[0].variables)
+
+	postsolved := prepper.postSolve(rawSolution{})
+	assertSelected(t, &postsolved, lambdas, 4)
+}
+
+// assert that the selected variable is one and all others are zero in the solution.
+func assertSelected(t *testing.T, soln *Solution, lambdas []*Variable, selected int) {
+	for k, lambda := range lambdas {
+		want := 0.0
+		if k == selected {
+			want = 1
+		}
+		got, err := soln.GetValueFor(lambda.name)
+		assert.NoError(t, err)
+		assert.InDelta(t, want, got, 1e-9, lambda.name)
+	}
+}
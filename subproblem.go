@@ -2,11 +2,11 @@ package ilp
 
 import (
 	"errors"
-	"fmt"
 	"math"
+	"sync"
+	"sync/atomic"
 
 	"gonum.org/v1/gonum/mat"
-	"gonum.org/v1/gonum/optimize/convex/lp"
 )
 
 type subProblem struct {
@@ -19,7 +19,7 @@ type subProblem struct {
 
 	// These variables represent the same as in the MILPproblem and should not be modified.
 	c []float64
-	A *mat.Dense
+	A ConstraintMatrix
 	b []float64
 
 	// integrality constraints, inherited from parent problem and should not be modified.
@@ -28,6 +28,47 @@ type subProblem struct {
 	// heuristic to determine variable to branch on. Inherited from parent and should not be modified.
 	branchHeuristic BranchHeuristic
 
+	// maximum number of candidate variables evaluated by strong branching. Inherited from parent and should not be modified.
+	maxCandidates int
+
+	// whether to create the two children of a branched subProblem concurrently (see SolverConfig.ParallelBranching).
+	// Inherited from parent and should not be modified.
+	parallelBranching bool
+
+	// values within this distance of an integer satisfy the integrality constraints. Inherited from parent and should not be modified.
+	integralityTol float64
+
+	// custom strategy to determine the variable to branch on. Takes precedence over branchHeuristic if not nil.
+	// Inherited from parent and should not be modified.
+	branchingStrategy BranchingStrategy
+
+	// the direction to explore first when branching on each variable. Variables without an entry are rounded down first.
+	// Inherited from parent and should not be modified.
+	branchDirections []BranchDirection
+
+	// which variables are binary. Variables without an entry are general integers.
+	// Inherited from parent and should not be modified.
+	binaryVariables []bool
+
+	// the branching priority of each variable, which breaks ties between the variables preferred by the branching heuristic. Nil if none are set.
+	// Inherited from parent and should not be modified.
+	branchingPriorities []int
+
+	// SOS1 constraints, which are enforced by branching once the integrality constraints are satisfied.
+	// Inherited from parent and should not be modified.
+	sos1Constraints []SOSConstraint
+
+	// lower bound on the objective value of the subProblem, i.e. the objective value of the LP relaxation of its parent.
+	// Set when the parent is branched on. Zero for the initial subProblem.
+	bound float64
+
+	// estimated objective value of the best integer-feasible solution in the subtree of the subProblem, based on pseudocosts.
+	// Set when the parent is branched on. Zero for the initial subProblem.
+	estimate float64
+
+	// counter of the LP relaxations solved, shared by all subProblems of the enumeration tree. Ignored if nil.
+	lpCounter *int64
+
 	// additional inequality constraints for branch-and-bound.
 	// Each step down in the search procedure adds a constraint.
 	bnbConstraints []bnbConstraint
@@ -48,37 +89,69 @@ type solution struct {
 	x       []float64
 	z       float64
 	err     error
+
+	// the dual values y = c_B * B^-1 of the constraints of the standard form of the subProblem, i.e. of its equality constraints followed by its branch-and-bound constraints.
+	// Nil if the LP solve failed or the optimal basis could not be determined.
+	dual []float64
+
+	// the best known lower bound on the optimal objective value, as determined by the branch-and-bound procedure.
+	// Only set on the solution returned by the procedure.
+	bestBound float64
+}
+
+// The relative gap (z - bestBound) / |z| between the objective value of the solution and the best bound on the optimal objective value.
+// A gap of zero means the solution is proven to be optimal.
+func (s solution) mipGap() float64 {
+	return relativeGap(s.z, s.bestBound)
+}
+
+func relativeGap(z, bound float64) float64 {
+	gap := z - bound
+	if gap <= 0 {
+		return 0
+	}
+	if z == 0 {
+		return math.Inf(1)
+	}
+	return gap / math.Abs(z)
+}
+
+// Depth of the subProblem in the enumeration tree, i.e. the number of branch-and-bound constraints added to the initial subProblem.
+// Note that cutting planes also count towards the depth, whereas dominated branching constraints do not (see pruneConstraints).
+func (p subProblem) Depth() int {
+	return len(p.bnbConstraints)
 }
 
-// Retrieve all inequalities pertaining to this subProblem as a single G matrix and h vector.
-// That means the inequalities of the original problem description and the ones added during the branch-and-bound procedure.
-func (p subProblem) combineInequalities() (*mat.Dense, []float64) {
+// Retrieve the inequalities added to this subProblem during the branch-and-bound procedure as a single G matrix and h vector.
+// The inequalities of the original problem description are part of A, which is shared by all subProblems and never copied (see standardFormIn).
+// As each branch-and-bound constraint only involves a few variables, the matrix is accumulated in a sparse format.
+// Returns nil if no constraints were added.
+func (p subProblem) combineInequalities() (ConstraintMatrix, []float64) {
 
 	if len(p.bnbConstraints) > 0 {
 		// get the 'right sides'
 		var h []float64
 
 		// build a matrix of all constraints originating from the branch-and-bound procedure
-		var bnbGvects []float64
+		bnbG := NewSparseConstraints(len(p.c))
 		for _, constr := range p.bnbConstraints {
-			bnbGvects = append(bnbGvects, constr.gsharp...)
+			bnbG.AppendRow(constr.gsharp)
 
 			// add each hsharp value to the h vector
 			h = append(h, constr.hsharp)
 		}
-		bnbG := mat.NewDense(len(p.bnbConstraints), len(p.c), bnbGvects)
 
 		return bnbG, h
 
 	}
 
-	// if no constraints need to be added, return nil
 	return nil, nil
 
 }
 
-// Convert a problem with inequalities (G and h) to a problem with only nonnegative equalities (represented by matrix aNew and vector bNew) using slack variables
-func convertToEqualities(c []float64, A *mat.Dense, b []float64, G *mat.Dense, h []float64) (cNew []float64, aNew *mat.Dense, bNew []float64) {
+// Convert a problem with inequalities (G and h) to a problem with only nonnegative equalities (represented by matrix aNew and vector bNew) using slack variables.
+// If either of the constraint matrices is sparse, the new constraint matrix is accumulated in a sparse format as well.
+func convertToEqualities(c []float64, A ConstraintMatrix, b []float64, G ConstraintMatrix, h []float64) (cNew []float64, aNew ConstraintMatrix, bNew []float64) {
 
 	//sanity checks
 	// A may be nil (if it is, we can initiate a new one),
@@ -118,63 +191,169 @@ func convertToEqualities(c []float64, A *mat.Dense, b []float64, G *mat.Dense, h
 	copy(bNew, b)
 	copy(bNew[nCons:], h)
 
+	if isSparse(A) || isSparse(G) {
+		aNew = sparseEqualities(A, G)
+		return
+	}
+
 	// construct the new A matrix
-	aNew = mat.NewDense(nNewCons, nNewVar, nil)
+	dense := mat.NewDense(nNewCons, nNewVar, nil)
 
 	// if A is not nil, embed the original A matrix in the top left part of aNew, thus setting the original constraints
 	if A != nil {
-		aNew.Slice(0, nCons, 0, nVar).(*mat.Dense).Copy(A)
+		embedConstraints(dense, A)
 	}
 
 	// embed the G matrix into the new A, below the view of the old A.
-	aNew.Slice(nCons, nNewCons, 0, nVar).(*mat.Dense).Copy(G)
+	embedConstraints(dense.Slice(nCons, nNewCons, 0, nVar).(*mat.Dense), G)
 
 	// diagonally fill the bottom-left part (next to G) with binary indicators of the slack variables
-	bottomRight := aNew.Slice(nCons, nNewCons, nVar, nVar+nIneq).(*mat.Dense)
+	bottomRight := dense.Slice(nCons, nNewCons, nVar, nVar+nIneq).(*mat.Dense)
 	for i := 0; i < nIneq; i++ {
 		bottomRight.Set(i, i, 1)
 	}
 
+	aNew = DenseConstraints{dense}
 	return
 }
 
-func (p subProblem) solve() solution {
-
-	// get the inequality constraints from the BnB procedure as a G matrix and h vector.
-	G, h := p.combineInequalities()
+// Build the constraint matrix of convertToEqualities row by row in a sparse format: the rows of A (if any),
+// followed by the rows of G that each get their own slack variable.
+func sparseEqualities(A ConstraintMatrix, G ConstraintMatrix) *SparseConstraints {
+	nIneq, nVar := G.Dims()
+
+	aNew := NewSparseConstraints(nVar + nIneq)
+	row := make([]float64, nVar+nIneq)
+
+	appendRows := func(m ConstraintMatrix, slacks bool) {
+		r, _ := m.Dims()
+		for i := 0; i < r; i++ {
+			rowOf(m, i, row[:nVar])
+			if slacks {
+				row[nVar+i] = 1
+			}
+			aNew.AppendRow(row)
+			if slacks {
+				row[nVar+i] = 0
+			}
+		}
+	}
 
-	var z float64
-	var x []float64
-	var err error
+	if A != nil {
+		appendRows(A, false)
+	}
+	appendRows(G, true)
 
-	// if inequality constraints are presented, amend the problem with these.
-	if G != nil {
-		c, A, b := convertToEqualities(p.c, p.A, p.b, G, h)
+	return aNew
+}
 
-		z, x, err = lp.Simplex(c, A, b, 0, nil)
+// Get the standard-form representation of this subProblem.
+// The constraints of the root problem are already in standard form, so we only need to convert the inequality constraints added during the branch-and-bound procedure.
+// These are added directly as equality constraints, using one slack variable each. The slack variables are appended to the variables of the subProblem.
+func (p subProblem) standardForm() (c []float64, A *mat.Dense, b []float64) {
+	return p.standardFormIn(&standardFormBuffers{})
+}
 
-		// take only the variables from the result that are present in the definition of the standard-form root problem.
-		if err == nil && len(x) != len(p.c) {
-			x = x[:len(p.c)]
+// like standardForm, but the parts of the standard form that are not shared with the subProblem reuse the memory of the buffers.
+// The standard form is only valid until the buffers are reused.
+func (p subProblem) standardFormIn(buf *standardFormBuffers) (c []float64, A *mat.Dense, b []float64) {
+	nBnb := len(p.bnbConstraints)
+	if nBnb == 0 {
+		if p.A == nil {
+			return p.c, nil, p.b
 		}
+		return p.c, p.A.ToDense(), p.b
+	}
 
-	} else {
-		//TODO: REMOVEME
-		fmt.Println("A:")
-		fmt.Println(mat.Formatted(p.A))
-		r, c := p.A.Dims()
-		fmt.Printf("A dims: %v rows and %v cols \n", r, c)
-		fmt.Printf("Linearly independent columns in A: %v out of %v \n", c, len(findLinearlyIndependent(p.A)))
-		fmt.Println("b:")
-		fmt.Println(p.b)
-		fmt.Println("c:")
-		fmt.Println(p.c)
-		z, x, err = lp.Simplex(p.c, p.A, p.b, 0, nil)
-		if err != nil {
-			fmt.Println("PANICED")
-			panic(err)
-		}
+	// number of variables and constraints of the root problem
+	nVar := len(p.c)
+	nCons := len(p.b)
+
+	buf.c = resize(buf.c, nVar+nBnb)
+	c = buf.c
+	copy(c, p.c)
 
+	buf.b = resize(buf.b, nCons+nBnb)
+	b = buf.b
+	copy(b, p.b)
+
+	buf.a = resize(buf.a, (nCons+nBnb)*(nVar+nBnb))
+	A = mat.NewDense(nCons+nBnb, nVar+nBnb, buf.a)
+	if p.A != nil {
+		embedConstraints(A, p.A)
+	}
+
+	// each branch-and-bound constraint gsharp * x <= hsharp becomes gsharp * x + s = hsharp
+	for i, constr := range p.bnbConstraints {
+		row := A.RawRowView(nCons + i)
+		copy(row, constr.gsharp)
+		row[nVar+i] = 1
+		b[nCons+i] = constr.hsharp
+	}
+
+	return c, A, b
+}
+
+// buffers that hold the standard form of a subProblem while its LP relaxation is solved (see subProblem.standardFormIn).
+// As every node of the enumeration tree needs a fresh standard form, which is discarded once it is solved, the buffers are pooled to reduce the pressure on the garbage collector.
+// A set of buffers is used by a single goroutine at a time: it is taken from the pool before the standard form is built and only put back once the LP solver has returned.
+type standardFormBuffers struct {
+	c []float64
+	b []float64
+	a []float64
+}
+
+var standardFormPool = sync.Pool{
+	New: func() interface{} {
+		return &standardFormBuffers{}
+	},
+}
+
+// get standard-form buffers from the pool, which should be released once the standard form is no longer used.
+func acquireStandardForm() *standardFormBuffers {
+	return standardFormPool.Get().(*standardFormBuffers)
+}
+
+func (buf *standardFormBuffers) release() {
+	standardFormPool.Put(buf)
+}
+
+// resize the slice to length n, reusing its array if it has the capacity, and set all of its elements to zero.
+func resize(s []float64, n int) []float64 {
+	if cap(s) < n {
+		return make([]float64, n)
+	}
+	s = s[:n]
+	for i := range s {
+		s[i] = 0
+	}
+	return s
+}
+
+func (p subProblem) solve() solution {
+
+	// The LP solver does not retain its arguments (see LPSolver) and the dual values are derived before the buffers are released,
+	// so nothing in the returned solution refers to the standard form.
+	buf := acquireStandardForm()
+	defer buf.release()
+
+	c, A, b := p.standardFormIn(buf)
+
+	z, x, err := DefaultLPSolver.Solve(c, A, b)
+
+	if p.lpCounter != nil {
+		atomic.AddInt64(p.lpCounter, 1)
+	}
+
+	// derive the dual values from the full standard-form solution, including the slack variables of the branch-and-bound constraints
+	var dual []float64
+	if err == nil {
+		dual = dualValues(c, A, x)
+	}
+
+	// take only the variables from the result that are present in the definition of the standard-form root problem.
+	if err == nil && len(x) != len(p.c) {
+		x = x[:len(p.c)]
 	}
 
 	return solution{
@@ -182,6 +361,7 @@ func (p subProblem) solve() solution {
 		x:       x,
 		z:       z,
 		err:     err,
+		dual:    dual,
 	}
 
 }
@@ -190,36 +370,115 @@ func (p subProblem) solve() solution {
 // Which variable we branch on is controlled using the variable index specified in the branchOn argument.
 // The integer value on which to branch is inferred from the parent solution.
 // e.g. if this is the first time the problem has branched: create two new problems with new constraints on variable x1, etc.
-func (s solution) branch() (p1, p2 subProblem) {
-
-	// select variable to branch on based on the provided heuristic method
-	branchOn := 0
-	switch s.problem.branchHeuristic {
-	case BRANCH_MAXFUN:
-		branchOn = maxFunBranchPoint(s.problem.c, s.problem.integralityConstraints)
-
-	case BRANCH_MOST_INFEASIBLE:
-		branchOn = mostInfeasibleBranchPoint(s.problem.c, s.problem.integralityConstraints)
+// The pseudocosts are only used by the BRANCH_PSEUDOCOST heuristic and may be nil.
+func (s solution) branch(pseudocosts *PseudocostTable) (p1, p2 subProblem) {
+
+	// once the integrality constraints are satisfied, the solution can only be infeasible due to a violated SOS1 constraint
+	if feasibleForIP(s.problem.integralityConstraints, s.x, s.problem.integralityTol) {
+		for _, sos := range s.problem.sos1Constraints {
+			if !sos.feasible(s.x) {
+				return s.problem.branchOnSOS(sos, sos.SelectVariable(newBranchingState(s)))
+			}
+		}
+	}
 
-	case BRANCH_NAIVE:
-		branchOn = s.naiveBranchPoint()
+	// select variable to branch on based on the custom strategy, or else the provided heuristic method
+	strategy := s.problem.branchingStrategy
+	if strategy == nil {
+		strategy = s.problem.branchHeuristic.strategy(pseudocosts)
+	}
 
-	default:
-		panic("provided branching heuristic config variable unknown")
+	branchOn := strategy.SelectVariable(newBranchingState(s))
+	if branchOn < 0 || branchOn >= len(s.x) {
+		panic("branching strategy selected a variable index that is out of range")
 	}
 
 	// Formulate the right constraints for this variable, based on its coefficient estimated by the current solution.
-	currentCoeff := s.x[branchOn]
+	if s.problem.parallelBranching {
+		return s.problem.branchOnConcurrently(branchOn, s.x[branchOn])
+	}
+	return s.problem.branchOn(branchOn, s.x[branchOn])
+}
+
+// create the two children of the subProblem that result from branching on the variable with index i, which has the provided value in the current solution.
+func (p subProblem) branchOn(i int, currentCoeff float64) (p1, p2 subProblem) {
+	return p.branchChild(i, currentCoeff, true), p.branchChild(i, currentCoeff, false)
+}
+
+// like branchOn, but creates the two children in separate goroutines.
+// This is safe because getChild only reads the parent, and copies the bnbConstraints that it extends.
+// The children do not have an ID yet, which is assigned once they are added to the enumeration tree.
+func (p subProblem) branchOnConcurrently(i int, currentCoeff float64) (p1, p2 subProblem) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		p1 = p.branchChild(i, currentCoeff, true)
+	}()
+	go func() {
+		defer wg.Done()
+		p2 = p.branchChild(i, currentCoeff, false)
+	}()
+	wg.Wait()
+	return
+}
+
+// create the child that explores the rounded-down branch of the variable with index i if down is set, or the rounded-up branch otherwise.
+func (p subProblem) branchChild(i int, currentCoeff float64, down bool) subProblem {
+	// a binary variable is fixed to 0 in one branch and to 1 in the other, regardless of its current value
+	if i < len(p.binaryVariables) && p.binaryVariables[i] {
+		if down {
+			return p.getChild(i, 1, 0)
+		}
+		return p.getChild(i, -1, -1)
+	}
 
 	// build the subproblem that will explore the 'smaller or equal than' branch
-	p1 = s.problem.getChild(branchOn, 1, math.Floor(currentCoeff))
+	if down {
+		return p.getChild(i, 1, math.Floor(currentCoeff))
+	}
 
 	// formulate 'larger than' constraints of the branchpoint as 'smaller or equal than' by inverting the sign
-	p2 = s.problem.getChild(branchOn, -1, -(math.Floor(currentCoeff) + 1))
+	return p.getChild(i, -1, -(math.Floor(currentCoeff) + 1))
+}
+
+// solve the LP relaxations of two subProblems, concurrently if parallel is set.
+func solveBoth(p1, p2 subProblem, parallel bool) (s1, s2 solution) {
+	if !parallel {
+		return p1.solve(), p2.solve()
+	}
 
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s1 = p1.solve()
+	}()
+	go func() {
+		defer wg.Done()
+		s2 = p2.solve()
+	}()
+	wg.Wait()
 	return
 }
 
+// whether the child that rounds variable i up should be explored before the child that rounds it down, given its value in the current solution.
+func (p subProblem) upFirst(i int, value float64) bool {
+	direction := BRANCH_DOWN_FIRST
+	if i < len(p.branchDirections) {
+		direction = p.branchDirections[i]
+	}
+
+	switch direction {
+	case BRANCH_UP_FIRST:
+		return true
+	case BRANCH_AUTO:
+		return fractional(value) > 0.5
+	default:
+		return false
+	}
+}
+
 // inherit everything from the parent problem, but append a new bnb constraint using a variable index and a max value for this variable.
 // Note that we also provide a multiplication factor for the to allow for sign changes.
 // Creating child subProblems like this has non-trivial memory implications.
@@ -237,6 +496,16 @@ func (p subProblem) getChild(branchOn int, factor float64, smallerOrEqualThan fl
 		b:                      p.b,
 		bnbConstraints:         make([]bnbConstraint, len(p.bnbConstraints)),
 		integralityConstraints: p.integralityConstraints,
+		branchHeuristic:        p.branchHeuristic,
+		maxCandidates:          p.maxCandidates,
+		parallelBranching:      p.parallelBranching,
+		integralityTol:         p.integralityTol,
+		branchingStrategy:      p.branchingStrategy,
+		branchDirections:       p.branchDirections,
+		binaryVariables:        p.binaryVariables,
+		branchingPriorities:    p.branchingPriorities,
+		sos1Constraints:        p.sos1Constraints,
+		lpCounter:              p.lpCounter,
 	}
 
 	// As the bnbConstraints slice is modified with each branch-and-bound node, we copy it to prevent race conditions occurring in subProblems further downstream
@@ -251,15 +520,69 @@ func (p subProblem) getChild(branchOn int, factor float64, smallerOrEqualThan fl
 	// point to the index of the variable to branch on
 	newConstraint.gsharp[branchOn] = float64(factor)
 
-	// add the constraint
+	// add the constraint, which may dominate a constraint added by an earlier branching on the same variable
 	child.bnbConstraints = append(child.bnbConstraints, newConstraint)
 
-	return child
+	return child.pruneConstraints()
+
+}
+
+// the variable bounded by a bnbConstraint of the form factor * x_i <= hsharp, i.e. a constraint that involves a single variable, along with the direction of the bound.
+// Returns false for other constraints, e.g. cutting planes.
+func (c bnbConstraint) bound() (variable int, upper bool, ok bool) {
+	if c.branchedVariable == noBranchedVariable || c.gsharp[c.branchedVariable] == 0 {
+		return 0, false, false
+	}
+	for j, coef := range c.gsharp {
+		if coef != 0 && j != c.branchedVariable {
+			return 0, false, false
+		}
+	}
+	return c.branchedVariable, c.gsharp[c.branchedVariable] > 0, true
+}
+
+// remove the bnbConstraints that bound a variable in the same direction as a tighter bnbConstraint, which dominates them.
+// E.g. x1 <= 3 is removed once x1 <= 2 is added by branching on x1 again. Equally tight constraints are all kept,
+// such that branching on a variable of which the value is just outside its bound does not yield a child with the same relaxation as its parent.
+// The last constraint, which distinguishes the subProblem from its parent, is always kept.
+func (p subProblem) pruneConstraints() subProblem {
+	type boundKey struct {
+		variable int
+		upper    bool
+	}
+
+	// the bound implied by the constraint factor * x_i <= hsharp, which is tighter if it is lower
+	limit := func(c bnbConstraint) float64 {
+		return c.hsharp / math.Abs(c.gsharp[c.branchedVariable])
+	}
+
+	tightest := make(map[boundKey]float64)
+	for _, c := range p.bnbConstraints {
+		variable, upper, ok := c.bound()
+		if !ok {
+			continue
+		}
+		key := boundKey{variable, upper}
+		if bound, seen := tightest[key]; !seen || limit(c) < bound {
+			tightest[key] = limit(c)
+		}
+	}
+
+	last := len(p.bnbConstraints) - 1
+	pruned := make([]bnbConstraint, 0, len(p.bnbConstraints))
+	for i, c := range p.bnbConstraints {
+		if variable, upper, ok := c.bound(); ok && i != last && limit(c) > tightest[boundKey{variable, upper}] {
+			continue
+		}
+		pruned = append(pruned, c)
+	}
 
+	p.bnbConstraints = pruned
+	return p
 }
 
 // Sanity check for the problems dimensions
-func sanityCheckDimensions(c []float64, A *mat.Dense, b []float64, G *mat.Dense, h []float64) error {
+func sanityCheckDimensions(c []float64, A ConstraintMatrix, b []float64, G ConstraintMatrix, h []float64) error {
 	// Either G or A needs to be provided
 	if G == nil && A == nil {
 		return errors.New("No constraint matrices provided")
@@ -1,9 +1,12 @@
 package ilp
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"math/rand"
 	"reflect"
+	"sync"
 	"testing"
 
 	"gonum.org/v1/gonum/mat"
@@ -43,9 +46,9 @@ func TestExampleSimplex(t *testing.T) {
 func Test_subProblem_combineInequalities(t *testing.T) {
 	type fields struct {
 		c              []float64
-		A              *mat.Dense
+		A              ConstraintMatrix
 		b              []float64
-		G              *mat.Dense
+		G              ConstraintMatrix
 		h              []float64
 		bnbConstraints []bnbConstraint
 	}
@@ -59,7 +62,7 @@ func Test_subProblem_combineInequalities(t *testing.T) {
 			name: "no bnb constraints",
 			fields: fields{
 				c: []float64{-1, -2, 0, 0},
-				A: mat.NewDense(2, 4, []float64{
+				A: NewDenseConstraints(2, 4, []float64{
 					-1, 2, 1, 0,
 					3, 1, 0, 1,
 				}),
@@ -72,7 +75,7 @@ func Test_subProblem_combineInequalities(t *testing.T) {
 			name: "One bnb constraint",
 			fields: fields{
 				c: []float64{-1, -2, 0, 0},
-				A: mat.NewDense(2, 4, []float64{
+				A: NewDenseConstraints(2, 4, []float64{
 					-1, 2, 1, 0,
 					3, 1, 0, 1,
 				}),
@@ -92,7 +95,7 @@ func Test_subProblem_combineInequalities(t *testing.T) {
 			name: "Two bnb constraints",
 			fields: fields{
 				c: []float64{-1, -2, 0, 0},
-				A: mat.NewDense(2, 4, []float64{
+				A: NewDenseConstraints(2, 4, []float64{
 					-1, 2, 1, 0,
 					3, 1, 0, 1,
 				}),
@@ -125,7 +128,13 @@ func Test_subProblem_combineInequalities(t *testing.T) {
 				b:              tt.fields.b,
 				bnbConstraints: tt.fields.bnbConstraints,
 			}
-			got, got1 := p.combineInequalities()
+			combined, got1 := p.combineInequalities()
+
+			// compare the dense representations, as the matrix is accumulated in a sparse format
+			var got *mat.Dense
+			if combined != nil {
+				got = combined.ToDense()
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("subProblem.getInequalities() got = %v, want %v", got, tt.want)
 			}
@@ -136,6 +145,85 @@ func Test_subProblem_combineInequalities(t *testing.T) {
 	}
 }
 
+func Test_subProblem_pruneConstraints(t *testing.T) {
+	root := subProblem{
+		c: []float64{-1, -2, 0, 0},
+		A: NewDenseConstraints(2, 4, []float64{
+			-1, 2, 1, 0,
+			3, 1, 0, 1,
+		}),
+		b:              []float64{4, 9},
+		bnbConstraints: []bnbConstraint{},
+	}
+
+	tests := []struct {
+		name  string
+		child subProblem
+		want  *mat.Dense
+		want1 []float64
+	}{
+		{
+			name:  "x0 <= 3, then x0 <= 2",
+			child: root.getChild(0, 1, 3).getChild(0, 1, 2),
+			want:  mat.NewDense(1, 4, []float64{1, 0, 0, 0}),
+			want1: []float64{2},
+		},
+		{
+			name:  "x0 >= 1, then x0 >= 2",
+			child: root.getChild(0, -1, -1).getChild(0, -1, -2),
+			want:  mat.NewDense(1, 4, []float64{-1, 0, 0, 0}),
+			want1: []float64{-2},
+		},
+		{
+			name:  "equally tight constraints are kept",
+			child: root.getChild(0, 1, 3).getChild(0, 1, 3),
+			want: mat.NewDense(2, 4, []float64{
+				1, 0, 0, 0,
+				1, 0, 0, 0,
+			}),
+			want1: []float64{3, 3},
+		},
+		{
+			name:  "opposite directions are not dominated",
+			child: root.getChild(0, 1, 3).getChild(0, -1, -1),
+			want: mat.NewDense(2, 4, []float64{
+				1, 0, 0, 0,
+				-1, 0, 0, 0,
+			}),
+			want1: []float64{3, -1},
+		},
+		{
+			name:  "other variables are not dominated",
+			child: root.getChild(0, 1, 3).getChild(1, 1, 4).getChild(0, 1, 2),
+			want: mat.NewDense(2, 4, []float64{
+				0, 1, 0, 0,
+				1, 0, 0, 0,
+			}),
+			want1: []float64{4, 2},
+		},
+		{
+			name:  "constraints on multiple variables are not pruned",
+			child: root.forbid([]int{0, 1}).getChild(0, 1, 0),
+			want: mat.NewDense(2, 4, []float64{
+				1, 1, 0, 0,
+				1, 0, 0, 0,
+			}),
+			want1: []float64{0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			combined, got1 := tt.child.combineInequalities()
+			if got := combined.ToDense(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("subProblem.combineInequalities() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("subProblem.combineInequalities() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
 func Test_solution_branch(t *testing.T) {
 	type fields struct {
 		problem *subProblem
@@ -155,7 +243,7 @@ func Test_solution_branch(t *testing.T) {
 					id:     0,
 					parent: 0,
 					c:      []float64{-1, -2, 0, 0},
-					A: mat.NewDense(2, 4, []float64{
+					A: NewDenseConstraints(2, 4, []float64{
 						-1, 2, 1, 0,
 						3, 1, 0, 1,
 					}),
@@ -170,7 +258,7 @@ func Test_solution_branch(t *testing.T) {
 				id:     0,
 				parent: 0,
 				c:      []float64{-1, -2, 0, 0},
-				A: mat.NewDense(2, 4, []float64{
+				A: NewDenseConstraints(2, 4, []float64{
 					-1, 2, 1, 0,
 					3, 1, 0, 1,
 				}),
@@ -188,7 +276,7 @@ func Test_solution_branch(t *testing.T) {
 				id:     0,
 				parent: 0,
 				c:      []float64{-1, -2, 0, 0},
-				A: mat.NewDense(2, 4, []float64{
+				A: NewDenseConstraints(2, 4, []float64{
 					-1, 2, 1, 0,
 					3, 1, 0, 1,
 				}),
@@ -209,7 +297,7 @@ func Test_solution_branch(t *testing.T) {
 				problem: &subProblem{
 					id: 1,
 					c:  []float64{-1, -2, 0, 0},
-					A: mat.NewDense(2, 4, []float64{
+					A: NewDenseConstraints(2, 4, []float64{
 						-1, 2, 1, 0,
 						3, 1, 0, 1,
 					}),
@@ -231,7 +319,7 @@ func Test_solution_branch(t *testing.T) {
 				id:     0,
 				parent: 1,
 				c:      []float64{-1, -2, 0, 0},
-				A: mat.NewDense(2, 4, []float64{
+				A: NewDenseConstraints(2, 4, []float64{
 					-1, 2, 1, 0,
 					3, 1, 0, 1,
 				}),
@@ -254,7 +342,7 @@ func Test_solution_branch(t *testing.T) {
 				id:     0,
 				parent: 1,
 				c:      []float64{-1, -2, 0, 0},
-				A: mat.NewDense(2, 4, []float64{
+				A: NewDenseConstraints(2, 4, []float64{
 					-1, 2, 1, 0,
 					3, 1, 0, 1,
 				}),
@@ -282,7 +370,7 @@ func Test_solution_branch(t *testing.T) {
 				x:       tt.fields.x,
 				z:       tt.fields.z,
 			}
-			gotP1, gotP2 := s.branch()
+			gotP1, gotP2 := s.branch(nil)
 			if !reflect.DeepEqual(gotP1, tt.wantP1) {
 				t.Errorf("solution.branch() gotP1 = %v, want %v", gotP1, tt.wantP1)
 			}
@@ -296,35 +384,35 @@ func Test_solution_branch(t *testing.T) {
 func Test_convertToEqualities(t *testing.T) {
 	type args struct {
 		c []float64
-		A *mat.Dense
+		A ConstraintMatrix
 		b []float64
-		G *mat.Dense
+		G ConstraintMatrix
 		h []float64
 	}
 	tests := []struct {
 		name     string
 		args     args
 		wantCNew []float64
-		wantANew *mat.Dense
+		wantANew ConstraintMatrix
 		wantBNew []float64
 	}{
 		{
 			name: "simple case",
 			args: args{
 				c: []float64{-1, -2, 0, 0},
-				A: mat.NewDense(2, 4, []float64{
+				A: NewDenseConstraints(2, 4, []float64{
 					-1, 2, 1, 0,
 					3, 1, 0, 1,
 				}),
 				b: []float64{4, 9},
 				h: []float64{2, 5, 8},
-				G: mat.NewDense(3, 4, []float64{
+				G: NewDenseConstraints(3, 4, []float64{
 					0, 0, 0, 1,
 					0, 0, 1, 0,
 					0, 1, 0, 0}),
 			},
 			wantCNew: []float64{-1, -2, 0, 0, 0, 0, 0},
-			wantANew: mat.NewDense(5, 7, []float64{
+			wantANew: NewDenseConstraints(5, 7, []float64{
 				-1, 2, 1, 0, 0, 0, 0,
 				3, 1, 0, 1, 0, 0, 0,
 				0, 0, 0, 1, 1, 0, 0,
@@ -339,7 +427,7 @@ func Test_convertToEqualities(t *testing.T) {
 			fmt.Println("Cnew:")
 			fmt.Println(gotCNew)
 			fmt.Println("ANew:")
-			fmt.Println(mat.Formatted(gotANew))
+			fmt.Println(mat.Formatted(gotANew.ToDense()))
 			fmt.Println("BNew:")
 			fmt.Println(gotBNew)
 
@@ -386,3 +474,366 @@ func TestSingularityReproduction(t *testing.T) {
 		// lp: A is singular
 	}
 }
+
+func Test_solution_gomoryMixedIntegerCuts(t *testing.T) {
+	// minimize -x2 s.t. 3x1 + 2x2 <= 6, -3x1 + 2x2 <= 0, with x2 integer-constrained.
+	// The LP optimum is (1, 1.5), for which the GMIC of the x2 row is equivalent to x2 <= 1.
+	prob := milpProblem{
+		c: []float64{0, -1},
+		G: NewDenseConstraints(2, 2, []float64{
+			3, 2,
+			-3, 2,
+		}),
+		h:                      []float64{6, 0},
+		integralityConstraints: []bool{false, true},
+	}
+	root := prob.toInitialSubproblem()
+	s := root.solve()
+	if s.err != nil {
+		t.Fatal(s.err)
+	}
+
+	cuts := s.gomoryMixedIntegerCuts()
+	if len(cuts) != 1 {
+		t.Fatalf("expected a single cut, got %v", cuts)
+	}
+
+	// in terms of the slack variables s1 and s2 of the original inequalities, the cut reads -0.5 * s1 - 0.5 * s2 <= -1
+	want := bnbConstraint{
+		branchedVariable: noBranchedVariable,
+		hsharp:           -1,
+		gsharp:           []float64{0, 0, -0.5, -0.5},
+	}
+	if !reflect.DeepEqual(cuts[0], want) {
+		t.Errorf("solution.gomoryMixedIntegerCuts() = %v, want %v", cuts[0], want)
+	}
+
+	// the integer-feasible points (0, 0) and (1, 1) should satisfy the cut.
+	// Note that the slack variables follow from the original inequalities.
+	for _, x := range [][]float64{{0, 0, 6, 0}, {1, 1, 1, 1}} {
+		if lhs := mat.Dot(mat.NewVecDense(4, want.gsharp), mat.NewVecDense(4, x)); lhs > want.hsharp {
+			t.Errorf("cut removes integer-feasible point %v", x)
+		}
+	}
+
+	// solving the problem with the cut should yield an integer-feasible solution
+	tightened := s.tightenWithCuts(cuts)
+	if !feasibleForIP(root.integralityConstraints, tightened.x, root.integralityTol) {
+		t.Errorf("expected integer-feasible solution after applying cut, got %v", tightened.x)
+	}
+}
+
+func Test_subProblem_standardForm(t *testing.T) {
+	p := subProblem{
+		c: []float64{-1, -2, 0, 0},
+		A: NewDenseConstraints(2, 4, []float64{
+			-1, 2, 1, 0,
+			3, 1, 0, 1,
+		}),
+		b: []float64{4, 9},
+		bnbConstraints: []bnbConstraint{
+			{
+				branchedVariable: 3,
+				hsharp:           1,
+				gsharp:           []float64{0, 0, 0, 1},
+			},
+			{
+				branchedVariable: 1,
+				hsharp:           -3,
+				gsharp:           []float64{0, -1, 0, 0},
+			},
+		},
+	}
+
+	gotC, gotA, gotB := p.standardForm()
+
+	wantC := []float64{-1, -2, 0, 0, 0, 0}
+	wantA := mat.NewDense(4, 6, []float64{
+		-1, 2, 1, 0, 0, 0,
+		3, 1, 0, 1, 0, 0,
+		0, 0, 0, 1, 1, 0,
+		0, -1, 0, 0, 0, 1,
+	})
+	wantB := []float64{4, 9, 1, -3}
+
+	if !reflect.DeepEqual(gotC, wantC) {
+		t.Errorf("subProblem.standardForm() gotC = %v, want %v", gotC, wantC)
+	}
+	if !reflect.DeepEqual(gotA, wantA) {
+		t.Errorf("subProblem.standardForm() gotA = %v, want %v", gotA, wantA)
+	}
+	if !reflect.DeepEqual(gotB, wantB) {
+		t.Errorf("subProblem.standardForm() gotB = %v, want %v", gotB, wantB)
+	}
+
+	// the standard form of a subProblem without bnb constraints is the subProblem itself
+	p.bnbConstraints = nil
+	gotC, gotA, gotB = p.standardForm()
+	if gotA != p.A.ToDense() || !reflect.DeepEqual(gotC, p.c) || !reflect.DeepEqual(gotB, p.b) {
+		t.Errorf("subProblem.standardForm() should not modify a subProblem without bnb constraints")
+	}
+}
+
+func Test_subProblem_standardFormIn(t *testing.T) {
+	p := subProblem{
+		c: []float64{-1, -2, 0, 0},
+		A: NewDenseConstraints(2, 4, []float64{
+			-1, 2, 1, 0,
+			3, 1, 0, 1,
+		}),
+		b: []float64{4, 9},
+		bnbConstraints: []bnbConstraint{
+			{
+				branchedVariable: 3,
+				hsharp:           1,
+				gsharp:           []float64{0, 0, 0, 1},
+			},
+			{
+				branchedVariable: 1,
+				hsharp:           -3,
+				gsharp:           []float64{0, -1, 0, 0},
+			},
+		},
+	}
+	buf := &standardFormBuffers{}
+
+	gotC, gotA, gotB := p.standardFormIn(buf)
+	wantC, wantA, wantB := p.standardForm()
+	if !reflect.DeepEqual(gotC, wantC) || !mat.Equal(gotA, wantA) || !reflect.DeepEqual(gotB, wantB) {
+		t.Errorf("subProblem.standardFormIn() = %v, %v, %v, want %v, %v, %v", gotC, gotA, gotB, wantC, wantA, wantB)
+	}
+	a, c, b := &buf.a[0], &buf.c[0], &buf.b[0]
+
+	// a smaller standard form reuses the arrays of the buffers, without leaking the values of the previous one
+	p.bnbConstraints = p.bnbConstraints[1:]
+	gotC, gotA, gotB = p.standardFormIn(buf)
+	wantC, wantA, wantB = p.standardForm()
+	if !reflect.DeepEqual(gotC, wantC) || !mat.Equal(gotA, wantA) || !reflect.DeepEqual(gotB, wantB) {
+		t.Errorf("subProblem.standardFormIn() = %v, %v, %v, want %v, %v, %v", gotC, gotA, gotB, wantC, wantA, wantB)
+	}
+	if &gotC[0] != c || &gotA.RawMatrix().Data[0] != a || &gotB[0] != b {
+		t.Errorf("subProblem.standardFormIn() should reuse the arrays of the buffers")
+	}
+}
+
+// Solving subProblems concurrently with pooled standard-form buffers gives the same solutions as solving them one at a time.
+// Run with -race to check that the buffers are never shared by two solves.
+func Test_subProblem_solve_PooledConcurrently(t *testing.T) {
+	root := getTestKnapsackMILP().toInitialSubproblem()
+	var problems []subProblem
+	for i := 0; i < 3; i++ {
+		down := root.getChild(i, 1, 1)
+		up := root.getChild(i, -1, -2)
+		problems = append(problems, down, up, down.getChild((i+1)%3, 1, 0))
+	}
+
+	want := make([]solution, len(problems))
+	for i, p := range problems {
+		want[i] = p.solve()
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := 0; r < 10; r++ {
+				for i, p := range problems {
+					got := p.solve()
+					if got.err != want[i].err || got.z != want[i].z || !reflect.DeepEqual(got.x, want[i].x) {
+						t.Errorf("subProblem.solve() = %v, %v, %v, want %v, %v, %v", got.z, got.x, got.err, want[i].z, want[i].x, want[i].err)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func Test_subProblem_upFirst(t *testing.T) {
+	p := subProblem{
+		branchDirections: []BranchDirection{BRANCH_DOWN_FIRST, BRANCH_UP_FIRST, BRANCH_AUTO},
+	}
+
+	tests := []struct {
+		name     string
+		variable int
+		value    float64
+		want     bool
+	}{
+		{name: "down first", variable: 0, value: 1.8, want: false},
+		{name: "up first", variable: 1, value: 1.2, want: true},
+		{name: "auto, fractional part below 1/2", variable: 2, value: 1.2, want: false},
+		{name: "auto, fractional part above 1/2", variable: 2, value: 1.8, want: true},
+		{name: "no preference", variable: 3, value: 1.8, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.upFirst(tt.variable, tt.value); got != tt.want {
+				t.Errorf("subProblem.upFirst() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_generateGomoryCuts(t *testing.T) {
+	// minimize -5x1 - 4x2 s.t. 6x1 + 4x2 <= 24, x1 + 2x2 <= 6, with x1 and x2 integer-constrained.
+	// As all data is integer, the slack variables are integer as well and Gomory fractional cuts can be derived.
+	prob := milpProblem{
+		c: []float64{-5, -4},
+		G: NewDenseConstraints(2, 2, []float64{
+			6, 4,
+			1, 2,
+		}),
+		h:                      []float64{24, 6},
+		integralityConstraints: []bool{true, true},
+	}
+	root := prob.toInitialSubproblem()
+	s := root.solve()
+	if s.err != nil {
+		t.Fatal(s.err)
+	}
+
+	cuts := generateGomoryCuts(s)
+	if len(cuts) == 0 {
+		t.Fatalf("expected cuts for fractional solution %v", s.x)
+	}
+
+	// none of the integer-feasible points should be cut off
+	for x1 := 0.0; x1 <= 4; x1++ {
+		for x2 := 0.0; x2 <= 3; x2++ {
+			x := []float64{x1, x2, 24 - 6*x1 - 4*x2, 6 - x1 - 2*x2}
+			if x[2] < 0 || x[3] < 0 {
+				continue
+			}
+			for _, cut := range cuts {
+				if cut.violatedBy(x) {
+					t.Errorf("cut %v removes integer-feasible point %v", cut, x)
+				}
+			}
+		}
+	}
+
+	// cuts cannot be derived if the slack variables are not integer
+	prob.h = []float64{24.5, 6}
+	if cuts := generateGomoryCuts(prob.toInitialSubproblem().solve()); len(cuts) != 0 {
+		t.Errorf("expected no cuts with fractional right-hand side, got %v", cuts)
+	}
+}
+
+func Test_subProblem_branchOnBinary(t *testing.T) {
+	p := subProblem{
+		c:                      []float64{1, 1},
+		integralityConstraints: []bool{true, true},
+		binaryVariables:        []bool{true, false},
+	}
+
+	tests := []struct {
+		name     string
+		variable int
+		value    float64
+		wantDown bnbConstraint
+		wantUp   bnbConstraint
+	}{
+		{
+			name:     "binary variable is fixed to 0 and 1",
+			variable: 0,
+			value:    0.7,
+			wantDown: bnbConstraint{branchedVariable: 0, hsharp: 0, gsharp: []float64{1, 0}},
+			wantUp:   bnbConstraint{branchedVariable: 0, hsharp: -1, gsharp: []float64{-1, 0}},
+		},
+		{
+			name:     "general integer variable is rounded down and up",
+			variable: 1,
+			value:    2.5,
+			wantDown: bnbConstraint{branchedVariable: 1, hsharp: 2, gsharp: []float64{0, 1}},
+			wantUp:   bnbConstraint{branchedVariable: 1, hsharp: -3, gsharp: []float64{0, -1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			down, up := p.branchOn(tt.variable, tt.value)
+			if !reflect.DeepEqual(down.bnbConstraints, []bnbConstraint{tt.wantDown}) {
+				t.Errorf("down branch constraints = %v, want %v", down.bnbConstraints, tt.wantDown)
+			}
+			if !reflect.DeepEqual(up.bnbConstraints, []bnbConstraint{tt.wantUp}) {
+				t.Errorf("up branch constraints = %v, want %v", up.bnbConstraints, tt.wantUp)
+			}
+			if !reflect.DeepEqual(down.binaryVariables, p.binaryVariables) {
+				t.Errorf("binary variables are not inherited by the children")
+			}
+
+			// creating the children concurrently yields the same children
+			concurrentDown, concurrentUp := p.branchOnConcurrently(tt.variable, tt.value)
+			if !reflect.DeepEqual(concurrentDown, down) || !reflect.DeepEqual(concurrentUp, up) {
+				t.Errorf("concurrently created children differ: got %v and %v, want %v and %v", concurrentDown, concurrentUp, down, up)
+			}
+		})
+	}
+}
+
+// Measure the heap allocations per node of the enumeration tree. Note that most of the allocations are made by the LP solver itself.
+func BenchmarkSolveAllocationsPerNode(b *testing.B) {
+	b.ReportAllocs()
+	var nodes int64
+	for i := 0; i < b.N; i++ {
+		prob := getKnapsackMILP(rand.New(rand.NewSource(1)), 6, 14)
+		prob.branchingHeuristic = BRANCH_STRONG
+		prob.config.NodeLimit = 50
+		res, _ := prob.solve(context.Background(), 1, dummyMiddleware{})
+		nodes += res.Stats.NodesExplored
+	}
+	if nodes > 0 {
+		b.ReportMetric(float64(nodes)/float64(b.N), "nodes/op")
+	}
+}
+
+// Measure the allocations needed to build the standard form of the subProblems of a problem with 50 variables.
+// The root subProblem shares its constraint matrix, so only the subProblems with bnb constraints allocate.
+func Benchmark_subProblem_standardForm(b *testing.B) {
+	const (
+		nVar  = 50
+		nCons = 30
+	)
+
+	rnd := rand.New(rand.NewSource(1))
+	data := make([]float64, nCons*nVar)
+	for i := range data {
+		data[i] = rnd.Float64()
+	}
+	root := subProblem{
+		c: make([]float64, nVar),
+		A: NewDenseConstraints(nCons, nVar, data),
+		b: make([]float64, nCons),
+	}
+
+	child := root
+	for i := 0; i < 5; i++ {
+		child = child.getChild(i, 1, 1)
+	}
+
+	for _, bm := range []struct {
+		name string
+		p    subProblem
+	}{
+		{"root", root},
+		{"depth 5", child},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bm.p.standardForm()
+			}
+		})
+	}
+
+	// the buffers taken from the pool by subProblem.solve are reused, so building the standard form allocates almost nothing
+	b.Run("depth 5 pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := acquireStandardForm()
+			child.standardFormIn(buf)
+			buf.release()
+		}
+	})
+}
@@ -4,12 +4,11 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sync"
 	"sync/atomic"
-
-	"gonum.org/v1/gonum/optimize/convex/lp"
+	"time"
 )
 
-// TODO: these decisions are currently unused. Use them to implement logging.
 // Branch-and-bound decisions that can be made by the algorithm
 type bnbDecision string
 
@@ -20,105 +19,300 @@ const (
 	BETTER_THAN_INCUMBENT_BRANCHING bnbDecision = "better than incumbent but not integer feasible, so branching"
 	BETTER_THAN_INCUMBENT_FEASIBLE  bnbDecision = "better than incumbent and integer feasible, so replacing incumbent"
 	INITIAL_RX_FEASIBLE_FOR_IP      bnbDecision = "initial relaxation is feasible for IP"
+	VIOLATES_LAZY_CONSTRAINTS       bnbDecision = "integer feasible but violates lazy constraints, so solving again with these constraints"
+	MAX_DEPTH_REACHED               bnbDecision = "better than incumbent but not integer feasible at the maximum depth, so pruning"
 )
 
+// LazyConstraintCallback generates the constraints that are violated by the solution, out of a set of constraints that is too large to add up front.
+// E.g. the subtour elimination constraints of a travelling salesman problem. The constraints are expressed in terms of the columns of the subProblem of the solution.
+// It should only return constraints that are violated by the solution, and return none once the solution satisfies all of them.
+type LazyConstraintCallback func(sol solution) []bnbConstraint
+
+// the node ID recorded for an incumbent that is not the solution of a subProblem, e.g. a warm start.
+const noIncumbentNode int64 = -1
+
+// an integer-feasible solution that improved on the incumbent at the time it was found.
+type incumbentRecord struct {
+	solution solution
+
+	// the id of the subProblem of the solution, or noIncumbentNode if it was not found at a subProblem, e.g. a warm start
+	nodeID int64
+
+	// the number of subProblems checked when the solution was found, including its own
+	nodesExplored int64
+
+	timestamp time.Time
+}
+
 type enumerationTree struct {
 	active     chan subProblem
-	toSolve    chan subProblem
 	incumbent  *solution
 	candidates chan solution
 
+	// the solutions that replaced the incumbent during the search, in the order in which they were found
+	incumbentHistory []incumbentRecord
+
 	// track the number of jobs (solving + checking) currently in progress
 	workInProgress int64
 
+	// the number of subProblems checked so far, and the number after which the search is stopped (0 = unlimited)
+	nodesChecked int64
+	nodeLimit    int64
+
+	// subProblems at this depth are not branched on (0 = unlimited).
+	// The lowest objective value of the subProblems pruned for this reason remains a bound on the optimal objective value.
+	maxDepth          int64
+	depthLimited      bool
+	depthLimitedBound float64
+
+	// the number of subProblems created and the number of LP relaxations solved so far
+	nodesCreated        int64
+	lpRelaxationsSolved int64
+
+	// solution to the initial relaxation
+	rootSolution solution
+
 	// the root problem
 	rootProblem subProblem
 
+	// subProblems of which the objective value is not lower than the cutoff are pruned, even if no incumbent has been found yet.
+	cutoff float64
+
+	// generates the lazy constraints violated by the root relaxation and by integer-feasible candidates, if set
+	lazyConstraints LazyConstraintCallback
+
 	// any instrumentation for e.g. logging or tree visualisation purposes
 	instrumentation BnbMiddleware
 
-	// id source
+	// assigns the IDs of the subProblems in the order in which they are added to the tree
 	idGenerator idSource
+
+	// configuration of the branch-and-bound procedure
+	config SolverConfig
+
+	// history of the objective changes caused by branching, keyed by variable.
+	pseudocosts *PseudocostTable
+
+	// branching decisions of which the resulting subProblems have not been checked yet, keyed by subProblem id.
+	pendingBranchings map[int64]pendingBranching
+
+	// lower bounds on the objective value of the subProblems that have not been checked yet, keyed by subProblem id.
+	// The bound of each subProblem is the objective value of its parent.
+	openBounds map[int64]float64
+
+	// the best known lower bound on the optimal objective value, i.e. the lowest bound of the incumbent and all open subProblems.
+	// Updated each time a subProblem is checked.
+	dualBound float64
+
+	// the queue of subProblems waiting to be solved, which determines the order in which the tree is explored.
+	// Only accessed by the goroutine running the search.
+	queue NodeQueue
+
+	// the subProblems handed to the workers of which the solution has not been checked yet, keyed by id.
+	dispatched map[int64]subProblem
+
+	// the open subProblems of a previous search to resume (see LoadState)
+	restored []subProblem
+
+	// snapshot of the statistics of the search, which is updated each time a subProblem is checked so that it can be read while the search is running (see LiveStats)
+	live atomic.Value
 }
 
+// idSource generates sequential IDs. The root problem has ID 0, so the first ID handed out is 1.
 type idSource struct {
+	mu      sync.Mutex
 	current int64
 }
 
 func (s *idSource) Next() int64 {
-	return atomic.AddInt64(&s.current, 1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current++
+	return s.current
+}
+
+// assign consecutive IDs to the subProblems in the order in which they are provided.
+// The lock is held throughout, so the IDs of the subProblems are not interleaved with those drawn concurrently.
+func (s *idSource) assign(probs []*subProblem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, prob := range probs {
+		s.current++
+		prob.id = s.current
+	}
+}
+
+// the last ID handed out.
+func (s *idSource) Last() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current
 }
 
-func newEnumerationTree(rootProblem subProblem, instrumentation BnbMiddleware) *enumerationTree {
-	return &enumerationTree{
-		// do not build buffered channels: buffering is managed by a separate goroutine.
+// continue the sequence after the provided ID, e.g. that of a restored search.
+func (s *idSource) Reset(last int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current = last
+}
+
+// If nodeSelection is nil, the subProblems are explored in FIFO order.
+func newEnumerationTree(rootProblem subProblem, instrumentation BnbMiddleware, config SolverConfig, nodeSelection NodeQueueFactory) *enumerationTree {
+	if nodeSelection == nil {
+		nodeSelection = NewFIFOQueue
+	}
+
+	tree := &enumerationTree{
+		// do not build buffered channels: buffering is managed by the node queue.
 		active:     make(chan subProblem),
-		toSolve:    make(chan subProblem),
 		candidates: make(chan solution),
 
 		rootProblem:     rootProblem,
 		instrumentation: instrumentation,
 
 		idGenerator: idSource{},
+		config:      config,
+		nodeLimit:   config.NodeLimit,
+		maxDepth:    config.MaxDepth,
+
+		pseudocosts:       NewPseudocostTable(),
+		pendingBranchings: make(map[int64]pendingBranching),
+		openBounds:        make(map[int64]float64),
+		dispatched:        make(map[int64]subProblem),
+		dualBound:         math.Inf(-1),
+		depthLimitedBound: math.Inf(1),
+		cutoff:            math.Inf(1),
+
+		queue: nodeSelection(),
 	}
+
+	// all descendants of the root problem share its LP counter
+	tree.rootProblem.lpCounter = &tree.lpRelaxationsSolved
+
+	return tree
 }
 
-func (p *enumerationTree) startSearch(ctx context.Context, nworkers int) *solution {
+// Search the enumeration tree for the optimal integer-feasible solution.
+// If the search is stopped early due to cancellation of the context or the node limit, the incumbent (if any) is returned along with the reason.
+func (p *enumerationTree) startSearch(ctx context.Context, nworkers int) (*solution, error) {
 
 	// pass the initial relaxation subProblem to the instrumentation
 	p.instrumentation.NewSubProblem(p.rootProblem)
 
 	// solve the initial relaxation
+	atomic.AddInt64(&p.nodesCreated, 1)
 	initialRelaxationSolution := p.rootProblem.solve()
 
+	// tighten the root relaxation with the lazy constraints it violates, which are inherited by the entire tree
+	if p.lazyConstraints != nil {
+		for initialRelaxationSolution.err == nil {
+			lazy := p.lazyConstraints(initialRelaxationSolution)
+			if len(lazy) == 0 {
+				break
+			}
+			initialRelaxationSolution = initialRelaxationSolution.withCuts(lazy).problem.solve()
+		}
+	}
+
+	// override the error message in case of an unsolvable initial relaxation for easier debugging
 	if initialRelaxationSolution.err != nil {
+		initialRelaxationSolution.err = translateRootFailure(initialRelaxationSolution)
+	}
+	p.rootSolution = initialRelaxationSolution
 
-		// override the error message in case of infeasible initial relaxation for easier debugging
-		if initialRelaxationSolution.err == lp.ErrInfeasible {
-			initialRelaxationSolution.err = INITIAL_RELAXATION_NOT_FEASIBLE
-		}
+	if initialRelaxationSolution.err != nil {
+		getLogger().Info("initial relaxation not solved", "error", initialRelaxationSolution.err.Error())
 
 		p.instrumentation.ProcessDecision(initialRelaxationSolution, SUBPROBLEM_NOT_FEASIBLE)
 
-		return &initialRelaxationSolution
+		return &initialRelaxationSolution, nil
 	}
+	getLogger().Info("initial relaxation solved", "objective", initialRelaxationSolution.z)
 
 	// If no integrality constraints are present, we can return the initial solution as-is if it is feasible.
 	// moreover, if the solution to the initial relaxation already satisfies all integrality constraints, we can present it as-is.
-	if feasibleForIP(p.rootProblem.integralityConstraints, initialRelaxationSolution.x) {
+	if p.feasible(initialRelaxationSolution) {
 
 		p.instrumentation.ProcessDecision(initialRelaxationSolution, INITIAL_RX_FEASIBLE_FOR_IP)
-		return &initialRelaxationSolution
+		initialRelaxationSolution.bestBound = initialRelaxationSolution.z
+		p.dualBound = initialRelaxationSolution.z
+		p.publishStatistics()
+		return &initialRelaxationSolution, nil
+	}
+
+	// try to find an incumbent by rounding the initial relaxation before branching, if none is known yet
+	if p.config.RoundingHeuristic && p.incumbent == nil {
+		if rounded, err := roundingDive(initialRelaxationSolution); err == nil && p.acceptableIncumbent(*rounded) {
+			p.setIncumbent(*rounded)
+			p.updateDualBound()
+		}
 	}
 
-	// start the buffer pump that manages transfers of subProblems from the buffer to the worker pool
-	go p.bufferManager()
+	// check the initial relaxation solution
+	p.checkSolution(initialRelaxationSolution)
 
+	return p.search(ctx, nworkers)
+}
+
+// Solve the queued subProblems and the subProblems created by checking their solutions, until the search space is exhausted or the search is stopped.
+// Returns the incumbent (if any), along with the reason the search was stopped early (if so).
+func (p *enumerationTree) search(ctx context.Context, nworkers int) (*solution, error) {
 	// start the solve workers
 	for j := 0; j < nworkers; j++ {
 		go p.solveWorker()
 	}
 
-	// check the initial relaxation solution
-	p.checkSolution(initialRelaxationSolution)
-
 	// listen for new candidates to check but also keep an eye out for any cancellation signals.
+	var stopped error
 mainWait:
 	for atomic.LoadInt64(&p.workInProgress) > 0 {
+		if p.nodeLimitReached() {
+			stopped = ErrNodeLimitExceeded
+			break mainWait
+		}
+
+		// a cancellation takes precedence over candidates that are ready, so that the search stops as soon as it is noticed
+		if err := ctx.Err(); err != nil {
+			stopped = err
+			break mainWait
+		}
+
+		// Only hand out subProblems to idle workers, so that each one is taken from the queue as late as possible.
+		// This way, the order of the queue also accounts for the subProblems created by checking the solutions that were received last.
+		for len(p.dispatched) < nworkers && p.queue.Len() > 0 {
+			prob := p.queue.Dequeue().problem
+			p.active <- prob
+			p.dispatched[prob.id] = prob
+		}
+
 		select {
 		case candidate := <-p.candidates:
+			delete(p.dispatched, candidate.problem.id)
 			p.checkSolution(candidate)
 			p.workDone()
+			if p.gapClosed() {
+				break mainWait
+			}
 		case <-ctx.Done():
+			stopped = ctx.Err()
 			break mainWait
 		}
 	}
 
-	// close the channels feeding the buffer pump, which will cause the downstream goroutines to return.
-	close(p.toSolve)
+	// close the channel feeding the workers, which will cause them to return.
+	close(p.active)
+
+	// the search space was not exhausted if any subProblems that could improve on the incumbent were pruned at the maximum depth
+	improvable := p.incumbent == nil || p.depthLimitedBound < p.incumbent.z
+	if stopped == nil && p.depthLimited && improvable && !p.gapClosed() {
+		stopped = ErrMaxDepthExceeded
+	}
 
 	// The incumbent can still be nil. This can happen for instance when the context stops the search early.
-	return p.incumbent
+	if p.incumbent != nil {
+		p.incumbent.bestBound = p.dualBound
+	}
+	return p.incumbent, stopped
 
 }
 
@@ -126,19 +320,38 @@ func (p *enumerationTree) postCandidate(s solution) {
 	p.candidates <- s
 }
 
-func (p *enumerationTree) addNewProblems(probs ...subProblem) {
+// Assign IDs to the subProblems and enqueue them in the order in which they are provided.
+// The IDs are assigned as the subProblems are added to the tree rather than as they are solved, so a subProblem always has a higher ID than its parent,
+// and the IDs of a search with a single worker are reproducible. The bound of each subProblem should be set, as it is registered as an open bound.
+func (p *enumerationTree) addNewProblems(probs ...*subProblem) {
+	p.idGenerator.assign(probs)
+
 	for _, s := range probs {
 
 		p.workAdded()
+		atomic.AddInt64(&p.nodesCreated, 1)
 
-		p.toSolve <- s
+		p.openBounds[s.id] = s.bound
+		p.queue.Enqueue(newOpenNode(*s))
 
 		// pass the problem to the instrumentation layer
-		p.instrumentation.NewSubProblem(s)
+		p.instrumentation.NewSubProblem(*s)
 
 	}
 }
 
+// enqueue a child of the subProblem of the candidate that carries the additional constraints.
+func (p *enumerationTree) requeue(candidate solution, constraints []bnbConstraint) {
+	child := *candidate.withCuts(constraints).problem
+	child.parent = candidate.problem.id
+	child.bound = candidate.z
+
+	// the candidate is integer feasible, so the estimated objective value of the best integer-feasible solution in the subtree of the child is its own
+	child.estimate = candidate.z
+
+	p.addNewProblems(&child)
+}
+
 func (p *enumerationTree) workAdded() {
 	atomic.AddInt64(&p.workInProgress, 1)
 }
@@ -147,52 +360,6 @@ func (p *enumerationTree) workDone() {
 	atomic.AddInt64(&p.workInProgress, -1)
 }
 
-// bufferManager should run in a separate goroutine to prevent blocking of the communication between the solvers and the checker
-func (p *enumerationTree) bufferManager() {
-	var buffer []subProblem
-	var next subProblem
-
-	// key feature of the statement below is the exploitation of nil channels. Select skips over these.
-	var output chan subProblem
-
-loopy:
-	for {
-
-		select {
-
-		// if presented, store the piece of work in the buffer.
-		case msg, open := <-p.toSolve:
-			if !open {
-				// if the buffer channel is closed, we exit the loop
-				break loopy
-			}
-			buffer = append(buffer, msg)
-
-		// try to send a buffered job to the workers
-		// note that when next is nil, so is the output channel. A nil channel causes select to skip over this case.
-		case output <- next:
-			// pop the buffered job that we just sent (only if it WAS sent, ofcourse)
-			if len(buffer) > 1 {
-				buffer = buffer[1:]
-			} else {
-				buffer = nil
-			}
-
-		}
-
-		if len(buffer) > 0 {
-			next = buffer[0]
-			output = p.active
-		} else {
-			output = nil
-		}
-
-	}
-
-	// After the loop has been broken, we close the buffer channels.
-	close(p.active)
-}
-
 func (p *enumerationTree) solveWorker() {
 	for prob := range p.active {
 		// solve the subproblem
@@ -210,19 +377,43 @@ func (p *enumerationTree) checkSolution(candidate solution) {
 	// var decision bnbDecision
 
 	// retrieve the objective function value of the incumbent
-	// if no incumbent is set, return +Inf
-	incumbentZ := math.Inf(1)
+	// if no incumbent is set, return the cutoff
+	incumbentZ := p.cutoff
 	if p.incumbent != nil {
 		incumbentZ = p.incumbent.z
 	}
 
 	var decision bnbDecision
 
+	atomic.AddInt64(&p.nodesChecked, 1)
+
+	// learn from the effect of the branching decision that created this candidate
+	p.observeBranching(candidate)
+
+	// the candidate is no longer open, which may raise the dual bound.
+	// Deferred functions run in reverse order, so the snapshot of the statistics includes the updated dual bound.
+	delete(p.openBounds, candidate.problem.id)
+	defer p.publishStatistics()
+	defer p.updateDualBound()
+
+	// tighten the LP relaxation of promising but fractional candidates with cutting planes before deciding on them.
+	// The descendants of the candidate inherit these cuts.
+	if p.config.EnableGomoryMixedIntegerCuts && p.worthCutting(candidate, incumbentZ) {
+		candidate = candidate.tightenWithCuts(candidate.gomoryMixedIntegerCuts())
+	}
+	for round := 0; round < p.config.GomoryRounds && p.worthCutting(candidate, incumbentZ); round++ {
+		cuts := generateGomoryCuts(candidate)
+		if len(cuts) == 0 {
+			break
+		}
+		candidate = candidate.tightenWithCuts(cuts)
+	}
+
 	switch {
 
 	case candidate.err != nil:
-		failure := translateSolverFailure(candidate.err)
-		decision = failure
+		candidate.err = translateSolverFailure(candidate)
+		decision = failureDecision(candidate.err)
 
 	// Note that the objective is always minimization.
 	case incumbentZ <= candidate.z:
@@ -230,23 +421,63 @@ func (p *enumerationTree) checkSolution(candidate solution) {
 		decision = WORSE_THAN_INCUMBENT
 
 	case incumbentZ > candidate.z:
-		if feasibleForIP(p.rootProblem.integralityConstraints, candidate.x) {
+		integral := feasibleForIP(p.rootProblem.integralityConstraints, candidate.x, p.rootProblem.integralityTol)
+		if integral && p.rootProblem.sos1Feasible(candidate.x) && p.lazyConstraints != nil {
+			if lazy := p.lazyConstraints(candidate); len(lazy) > 0 {
+				// the candidate is not feasible after all. Solve it again with the violated constraints, which its descendants inherit.
+				decision = VIOLATES_LAZY_CONSTRAINTS
+				p.requeue(candidate, lazy)
+				break
+			}
+		}
+
+		if integral && p.rootProblem.sos1Feasible(candidate.x) {
 			// Candidate is an improvement over the incumbent
-			p.incumbent = &candidate
+			p.setIncumbent(candidate)
 			decision = BETTER_THAN_INCUMBENT_FEASIBLE
 
+		} else if p.maxDepth > 0 && int64(candidate.problem.Depth()) >= p.maxDepth {
+
+			// the candidate is too deep in the tree to branch on, so its subtree is not explored.
+			decision = MAX_DEPTH_REACHED
+			p.depthLimited = true
+			p.depthLimitedBound = math.Min(p.depthLimitedBound, candidate.z)
+
 		} else {
 
 			//candidate is an improvement over the incumbent, but not feasible.
 			//branch and add the descendants of this candidate to the queue
 			decision = BETTER_THAN_INCUMBENT_BRANCHING
-			p1, p2 := candidate.branch()
 
-			// assign IDs to the daughter subProblems
-			p1.id = p.idGenerator.Next()
-			p2.id = p.idGenerator.Next()
+			// the variables that cannot be increased without the subtree becoming worse than the incumbent are fixed before branching
+			if p.config.ReducedCostFixing {
+				candidate = candidate.withCuts(reducedCostFixing(candidate, incumbentZ))
+			}
 
-			p.addNewProblems(p1, p2)
+			p1, p2 := candidate.branch(p.pseudocosts)
+
+			p1.bound = candidate.z
+			p2.bound = candidate.z
+			p1.estimate = p.pseudocosts.childEstimate(candidate, p1)
+			p2.estimate = p.pseudocosts.childEstimate(candidate, p2)
+
+			// an integral candidate is branched on a violated SOS1 constraint, which does not round a variable up or down.
+			// Hence, it neither informs the pseudocosts nor has a preferred direction.
+			if integral {
+				p.addNewProblems(&p1, &p2)
+				break
+			}
+
+			// enqueue the child that should be explored first before its sibling
+			branchedVariable := p1.bnbConstraints[len(p1.bnbConstraints)-1].branchedVariable
+			if candidate.problem.upFirst(branchedVariable, candidate.x[branchedVariable]) {
+				p.addNewProblems(&p2, &p1)
+			} else {
+				p.addNewProblems(&p1, &p2)
+			}
+
+			// the pending branchings are keyed by the IDs assigned to the children
+			p.registerBranching(candidate, p1, p2)
 
 		}
 
@@ -257,29 +488,142 @@ func (p *enumerationTree) checkSolution(candidate solution) {
 
 	}
 
+	if decision.prunes() {
+		getLogger().Debug("node pruned", "node", candidate.problem.id, "reason", string(decision))
+	}
+
 	// pass the solution candidate and the corresponding decision to the instrumentation layer.
 	p.instrumentation.ProcessDecision(candidate, decision)
 
 }
 
-// takes a solver failure and determines whether it warrants a panic or whether it is expected.
-func translateSolverFailure(err error) bnbDecision {
-	for failure, decision := range expectedFailures {
-		if failure == err {
-			return decision
-		}
+// get the statistics of the search so far. May only be called by the goroutine running the search, or once it has stopped.
+func (p *enumerationTree) statistics() SolveStats {
+	gap := math.Inf(1)
+	if p.incumbent != nil {
+		gap = relativeGap(p.incumbent.z, p.dualBound)
 	}
-	panic(err)
+
+	return SolveStats{
+		NodesCreated:        atomic.LoadInt64(&p.nodesCreated),
+		NodesExplored:       atomic.LoadInt64(&p.nodesChecked),
+		LPRelaxationsSolved: atomic.LoadInt64(&p.lpRelaxationsSolved),
+		RootLPBound:         p.rootSolution.z,
+		BestBound:           p.dualBound,
+		Gap:                 gap,
+	}
+}
+
+// update the snapshot of the statistics returned by LiveStats.
+func (p *enumerationTree) publishStatistics() {
+	p.live.Store(p.statistics())
+}
+
+// LiveStats returns the statistics of the search as of the last checked subProblem. Unlike the other methods of the tree,
+// it may be called from any goroutine while the search is running, e.g. to report progress periodically.
+// The objective values refer to the minimization problem solved by the branch-and-bound procedure.
+func (p *enumerationTree) LiveStats() SolveStats {
+	if stats, ok := p.live.Load().(SolveStats); ok {
+		return stats
+	}
+	return SolveStats{BestBound: math.Inf(-1), Gap: math.Inf(1)}
+}
+
+// BestBound returns the best known lower bound on the optimal objective value as of the last checked subProblem. It may be called while the search is running.
+func (p *enumerationTree) BestBound() float64 {
+	return p.LiveStats().BestBound
+}
+
+// Gap returns the relative gap (incumbent - bestBound) / |incumbent| as of the last checked subProblem, or +Inf if there is no incumbent.
+// It may be called while the search is running.
+func (p *enumerationTree) Gap() float64 {
+	return p.LiveStats().Gap
+}
+
+// replace the incumbent by the solution and record it in the history of the incumbent.
+// All updates of the incumbent go through here, including those found by heuristics or provided as a warm start.
+func (p *enumerationTree) setIncumbent(s solution) {
+	// a warm start is not the solution of any subProblem
+	nodeID := noIncumbentNode
+	if s.problem != nil {
+		nodeID = s.problem.id
+	}
+
+	getLogger().Info("new incumbent", "objective", s.z, "node", nodeID)
+	p.incumbent = &s
+	p.incumbentHistory = append(p.incumbentHistory, incumbentRecord{
+		solution:      s,
+		nodeID:        nodeID,
+		nodesExplored: atomic.LoadInt64(&p.nodesChecked),
+		timestamp:     time.Now(),
+	})
+}
+
+// whether an integer-feasible solution found by a heuristic improves on the cutoff and satisfies the lazy constraints, if any.
+func (p *enumerationTree) acceptableIncumbent(s solution) bool {
+	if s.z >= p.cutoff {
+		return false
+	}
+	return p.lazyConstraints == nil || len(p.lazyConstraints(s)) == 0
+}
+
+// whether the number of checked subProblems has reached the node limit, if any.
+func (p *enumerationTree) nodeLimitReached() bool {
+	return p.nodeLimit > 0 && atomic.LoadInt64(&p.nodesChecked) >= p.nodeLimit
+}
+
+// recompute the best known lower bound on the optimal objective value from the incumbent and the open subProblems.
+// As each bound is a valid lower bound, the published bound never decreases, which would otherwise happen due to rounding errors in the bounds of the subProblems.
+// It never exceeds the incumbent either.
+func (p *enumerationTree) updateDualBound() {
+	bound := p.depthLimitedBound
+	for _, b := range p.openBounds {
+		bound = math.Min(bound, b)
+	}
+	bound = math.Max(p.dualBound, bound)
+	if p.incumbent != nil {
+		bound = math.Min(bound, p.incumbent.z)
+	}
+	p.dualBound = bound
 }
 
-// check whether the solution vector is feasible in light of the integrality constraints for each variable
-func feasibleForIP(constraints []bool, solution []float64) bool {
+// whether the gap between the incumbent and the dual bound is within the configured tolerances.
+func (p *enumerationTree) gapClosed() bool {
+	if p.incumbent == nil {
+		return false
+	}
+
+	absoluteGap := p.incumbent.z - p.dualBound
+	if p.config.AbsoluteGapTolerance > 0 && absoluteGap <= p.config.AbsoluteGapTolerance {
+		return true
+	}
+
+	return p.config.RelativeGapTolerance > 0 && relativeGap(p.incumbent.z, p.dualBound) <= p.config.RelativeGapTolerance
+}
+
+// whether the candidate is promising but fractional, so that tightening its LP relaxation with cutting planes may pay off.
+func (p *enumerationTree) worthCutting(candidate solution, incumbentZ float64) bool {
+	return candidate.err == nil && candidate.z < incumbentZ && !feasibleForIP(p.rootProblem.integralityConstraints, candidate.x, p.rootProblem.integralityTol)
+}
+
+// whether the solution satisfies both the integrality constraints and the SOS1 constraints of the problem.
+func (p *enumerationTree) feasible(s solution) bool {
+	return feasibleForIP(p.rootProblem.integralityConstraints, s.x, p.rootProblem.integralityTol) && p.rootProblem.sos1Feasible(s.x)
+}
+
+// DefaultIntegralityTolerance is the default maximum distance between the value of an integer-constrained variable and the nearest integer
+// at which the variable is considered to be integral.
+const DefaultIntegralityTolerance = 1e-6
+
+// check whether the solution vector is feasible in light of the integrality constraints for each variable,
+// allowing each integer-constrained value to deviate from the nearest integer by the tolerance.
+func feasibleForIP(constraints []bool, solution []float64, tol float64) bool {
 	if len(constraints) != len(solution) {
 		panic(fmt.Sprint("constraints vector and solution vector not of equal size: ", constraints, solution))
 	}
 	for i := range solution {
 		if constraints[i] {
-			if !isAllInteger(solution[i]) {
+			if !isAllIntegerWithTol(solution[i], tol) {
 				return false
 			}
 		}
@@ -287,6 +631,11 @@ func feasibleForIP(constraints []bool, solution []float64) bool {
 	return true
 }
 
+// whether the value deviates at most tol from the nearest integer.
+func isAllIntegerWithTol(v float64, tol float64) bool {
+	return math.Abs(v-math.Round(v)) <= tol
+}
+
 func isAllInteger(in ...float64) bool {
 	for _, k := range in {
 		if !(k == math.Trunc(k)) {
@@ -1,9 +1,13 @@
 package ilp
 
 import (
+	"context"
+	"errors"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/floats"
 )
 
 func TestFeasibleForIP(t *testing.T) {
@@ -36,6 +40,264 @@ func TestFeasibleForIP(t *testing.T) {
 	}
 
 	for _, testd := range testdata {
-		assert.Equal(t, testd.shouldPass, feasibleForIP(testd.constraints, testd.solution))
+		assert.Equal(t, testd.shouldPass, feasibleForIP(testd.constraints, testd.solution, 0))
 	}
 }
+
+func TestFeasibleForIP_Tolerance(t *testing.T) {
+	// an artefact of the LP solver, which is integral for all practical purposes
+	x := []float64{2.9999999998, 1}
+	constraints := []bool{true, true}
+
+	assert.True(t, feasibleForIP(constraints, x, DefaultIntegralityTolerance))
+	assert.False(t, feasibleForIP(constraints, x, 0))
+	assert.False(t, feasibleForIP(constraints, []float64{2.9999, 1}, DefaultIntegralityTolerance))
+
+	// the tolerance of the problem is passed on to the subProblems
+	prob := NewProblem()
+	v := prob.AddVariable("x").SetCoeff(-1).IsInteger()
+	prob.AddConstraint("").AddExpression(1, v).SmallerThanOrEqualTo(2.5)
+	assert.Equal(t, DefaultIntegralityTolerance, prob.toSolveable().integralityTol)
+
+	prob.SetIntegralityTolerance(1e-3)
+	root := prob.toSolveable().toInitialSubproblem()
+	assert.Equal(t, 1e-3, root.integralityTol)
+	assert.Equal(t, 1e-3, root.getChild(0, 1, 2).integralityTol)
+
+	// the rounding errors are removed from the values of the integer-constrained variables of the reported solution
+	milp := milpProblem{c: []float64{1, 1}, integralityConstraints: []bool{true, false}}
+	snapped := milp.snapToIntegers(solution{x: []float64{2.9999999998, 0.5}})
+	assert.Equal(t, []float64{3, 0.5}, snapped.x)
+}
+
+// counts the number of times each decision is made by the branch-and-bound procedure
+type decisionTally map[bnbDecision]int
+
+func (d decisionTally) ProcessDecision(s solution, decision bnbDecision) {
+	d[decision]++
+}
+
+func (d decisionTally) NewSubProblem(s subProblem) {}
+
+func TestEnumerationTree_LazyConstraints(t *testing.T) {
+	// maximize the sum of four binary variables subject to a sum of at most 3.5.
+	// The lazy constraint limits the sum to 2, but is only checked for integer-feasible solutions.
+	prob := milpProblem{
+		c: []float64{-1, -1, -1, -1},
+		G: NewDenseConstraints(5, 4, []float64{
+			1, 1, 1, 1,
+			1, 0, 0, 0,
+			0, 1, 0, 0,
+			0, 0, 1, 0,
+			0, 0, 0, 1,
+		}),
+		h:                      []float64{3.5, 1, 1, 1, 1},
+		integralityConstraints: []bool{true, true, true, true},
+		branchingHeuristic:     BRANCH_MOST_INFEASIBLE,
+	}
+
+	calls := 0
+	prob.lazyConstraints = func(sol solution) []bnbConstraint {
+		calls++
+		if !feasibleForIP(sol.problem.integralityConstraints, sol.x, 0) {
+			return nil
+		}
+		if sol.x[0]+sol.x[1]+sol.x[2]+sol.x[3] <= 2 {
+			return nil
+		}
+
+		// the solution vector includes the slack variables of the standard form
+		gsharp := make([]float64, len(sol.x))
+		copy(gsharp, []float64{1, 1, 1, 1})
+		return []bnbConstraint{{branchedVariable: noBranchedVariable, hsharp: 2, gsharp: gsharp}}
+	}
+
+	tally := decisionTally{}
+	result, err := prob.solve(context.Background(), 1, tally)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the callback is invoked at the root relaxation and for each integer-feasible candidate
+	assert.True(t, calls > 1)
+	assert.True(t, tally[VIOLATES_LAZY_CONSTRAINTS] > 0)
+	assert.InDelta(t, -2, result.BestIntegerSolution.z, 1e-9)
+	assert.InDelta(t, 2, floats.Sum(result.BestIntegerSolution.x), 1e-9)
+
+	// violated lazy constraints at the root relaxation are added before branching
+	prob.lazyConstraints = func(sol solution) []bnbConstraint {
+		if sol.x[0]+sol.x[1]+sol.x[2]+sol.x[3] <= 2+1e-9 {
+			return nil
+		}
+		gsharp := make([]float64, len(sol.x))
+		copy(gsharp, []float64{1, 1, 1, 1})
+		return []bnbConstraint{{branchedVariable: noBranchedVariable, hsharp: 2, gsharp: gsharp}}
+	}
+	result, err = prob.solve(context.Background(), 1, dummyMiddleware{})
+	assert.NoError(t, err)
+	assert.InDelta(t, -2, result.LPRelaxation.z, 1e-9)
+	assert.InDelta(t, -2, result.BestIntegerSolution.z, 1e-9)
+}
+
+func TestEnumerationTree_MaxDepth(t *testing.T) {
+	getProblem := func(maxDepth int64) milpProblem {
+		prob := getTestKnapsackMILP()
+		prob.config.MaxDepth = maxDepth
+		return prob
+	}
+
+	// without a limit, the optimum is found deeper in the tree
+	stats := NewStatisticsMiddleware()
+	result, err := getProblem(0).solve(context.Background(), 1, stats)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.InDelta(t, -24, result.BestIntegerSolution.z, 1e-9)
+	deepest := 0
+	for d := range stats.DepthHistogram() {
+		if d > deepest {
+			deepest = d
+		}
+	}
+	assert.True(t, deepest > 3)
+
+	for _, maxDepth := range []int64{1, 3} {
+		stats := NewStatisticsMiddleware()
+		tally := decisionTally{}
+		result, err := getProblem(maxDepth).solve(context.Background(), 1, Compose(stats, tally))
+
+		// no subProblem has more branch-and-bound constraints than the maximum depth
+		for d := range stats.DepthHistogram() {
+			assert.True(t, int64(d) <= maxDepth, "subProblem at depth %v exceeds maximum depth %v", d, maxDepth)
+		}
+		assert.True(t, tally[MAX_DEPTH_REACHED] > 0)
+		assert.True(t, errors.Is(err, ErrMaxDepthExceeded))
+
+		// the pruned subProblems still bound the optimal objective value
+		if result.BestIntegerSolution != nil {
+			assert.True(t, result.BestIntegerSolution.bestBound <= -24+1e-9)
+			assert.Equal(t, STATUS_FEASIBLE_NOT_OPTIMAL, result.Status)
+		}
+	}
+}
+
+// reads the live statistics of the tree each time a decision is made, like a progress reporter running in another goroutine would.
+type liveStatsRecorder struct {
+	tree  *enumerationTree
+	stats []SolveStats
+}
+
+func (r *liveStatsRecorder) ProcessDecision(solution, bnbDecision) {
+	r.stats = append(r.stats, r.tree.LiveStats())
+}
+
+func (r *liveStatsRecorder) NewSubProblem(subProblem) {}
+
+func TestEnumerationTree_LiveStats(t *testing.T) {
+	prob := getTestKnapsackMILP()
+
+	recorder := &liveStatsRecorder{}
+	tree := newEnumerationTree(prob.toInitialSubproblem(), recorder, SolverConfig{}, nil)
+	recorder.tree = tree
+
+	before := tree.LiveStats()
+	assert.True(t, math.IsInf(tree.BestBound(), -1))
+	assert.True(t, math.IsInf(tree.Gap(), 1))
+	assert.Equal(t, int64(0), before.NodesExplored)
+
+	// poll the statistics concurrently with the search
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			tree.LiveStats()
+		}
+	}()
+	incumbent, err := tree.startSearch(context.Background(), 2)
+	<-done
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the bound only increases and the gap only decreases as the search progresses
+	for i := 1; i < len(recorder.stats); i++ {
+		assert.True(t, recorder.stats[i].BestBound >= recorder.stats[i-1].BestBound-1e-9, "the bound decreased from %v to %v", recorder.stats[i-1].BestBound, recorder.stats[i].BestBound)
+		assert.True(t, recorder.stats[i].Gap <= recorder.stats[i-1].Gap+1e-9, "the gap increased from %v to %v", recorder.stats[i-1].Gap, recorder.stats[i].Gap)
+		assert.True(t, recorder.stats[i].NodesExplored >= recorder.stats[i-1].NodesExplored)
+	}
+
+	// once the search is finished, the optimum is proven
+	final := tree.LiveStats()
+	assert.Equal(t, tree.statistics(), final)
+	assert.InDelta(t, incumbent.z, tree.BestBound(), 1e-9)
+	assert.Equal(t, float64(0), tree.Gap())
+}
+
+// records the IDs of the subProblems in the order in which they are added to the tree, along with the IDs of their parents.
+type idRecorder struct {
+	dummyMiddleware
+	ids     []int64
+	parents map[int64]int64
+}
+
+func (r *idRecorder) NewSubProblem(s subProblem) {
+	r.ids = append(r.ids, s.id)
+	r.parents[s.id] = s.parent
+}
+
+func TestEnumerationTree_SequentialIDs(t *testing.T) {
+	prob := getTestKnapsackMILP()
+
+	search := func() []int64 {
+		recorder := &idRecorder{parents: make(map[int64]int64)}
+		tree := newEnumerationTree(prob.toInitialSubproblem(), recorder, SolverConfig{}, nil)
+		_, err := tree.startSearch(context.Background(), 1)
+		assert.NoError(t, err)
+
+		// the IDs are assigned in the order in which the subProblems are created, starting with the root
+		for i, id := range recorder.ids {
+			assert.Equal(t, int64(i), id)
+		}
+
+		// each child has a higher ID than its parent
+		for _, id := range recorder.ids[1:] {
+			assert.True(t, id > recorder.parents[id], "subProblem %v has parent %v", id, recorder.parents[id])
+		}
+		return recorder.ids
+	}
+
+	first := search()
+	assert.True(t, len(first) > 1)
+
+	// the IDs of a search with a single worker are reproducible
+	assert.Equal(t, first, search())
+}
+
+func TestEnumerationTree_SetIncumbent(t *testing.T) {
+	prob := getTestKnapsackMILP()
+
+	// the solution of the rounding heuristic is the first record in the history of the incumbent
+	rounded, err := RoundingHeuristic(prob)
+	if !assert.NoError(t, err) {
+		return
+	}
+	prob.config.RoundingHeuristic = true
+	tree := newEnumerationTree(prob.toInitialSubproblem(), dummyMiddleware{}, prob.config, nil)
+	_, err = tree.startSearch(context.Background(), 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NotEmpty(t, tree.incumbentHistory) {
+		return
+	}
+	assert.InDelta(t, rounded.z, tree.incumbentHistory[0].solution.z, 1e-9)
+
+	// a warm start is not the solution of any subProblem
+	warm := newEnumerationTree(prob.toInitialSubproblem(), dummyMiddleware{}, SolverConfig{}, nil)
+	warm.setIncumbent(solution{x: []float64{4, 0, 1}, z: -24})
+	if !assert.Len(t, warm.incumbentHistory, 1) {
+		return
+	}
+	assert.Equal(t, noIncumbentNode, warm.incumbentHistory[0].nodeID)
+	assert.Equal(t, -24.0, warm.incumbent.z)
+}
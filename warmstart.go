@@ -0,0 +1,101 @@
+package ilp
+
+import (
+	"fmt"
+	"math"
+)
+
+// express the values of the original variables in terms of the columns of the problem, i.e. the inverse of postprocess:
+// shifted variables are shifted by their offset, and free variables are split into their positive and negative part.
+func (p milpProblem) toColumns(original []float64) []float64 {
+	x := make([]float64, len(p.c))
+	copy(x, original)
+
+	if p.offsets != nil {
+		for i, offset := range p.offsets {
+			x[i] -= offset
+		}
+	}
+
+	n := len(p.c) - len(p.freeVariables)
+	for k, i := range p.freeVariables {
+		if x[i] < 0 {
+			x[n+k] = -x[i]
+			x[i] = 0
+		}
+	}
+	return x
+}
+
+// whether the solution vector, expressed in terms of the columns of the problem, is nonnegative and satisfies the constraints,
+// the integrality constraints and the SOS1 constraints of the problem.
+func (p milpProblem) feasibleSolution(x []float64) bool {
+	if len(x) != len(p.c) {
+		return false
+	}
+
+	tol := math.Max(p.integralityTol, warmStartTolerance)
+	if !feasibleForIP(p.integralityConstraints, x, tol) {
+		return false
+	}
+	for _, s := range p.sos1Constraints {
+		if !s.feasible(x) {
+			return false
+		}
+	}
+
+	for _, val := range x {
+		if val < -warmStartTolerance {
+			return false
+		}
+	}
+
+	// the value of the left-hand side of the constraint in each row of the matrix
+	lhs := func(m ConstraintMatrix, row int) float64 {
+		var sum float64
+		for j, val := range x {
+			sum += m.At(row, j) * val
+		}
+		return sum
+	}
+
+	for i, bound := range p.h {
+		if lhs(p.G, i) > bound+warmStartTolerance*math.Max(1, math.Abs(bound)) {
+			return false
+		}
+	}
+	for i, bound := range p.b {
+		if math.Abs(lhs(p.A, i)-bound) > warmStartTolerance*math.Max(1, math.Abs(bound)) {
+			return false
+		}
+	}
+	return true
+}
+
+// the incumbent with which to start the branch-and-bound procedure, based on the initial solution of the problem.
+// Returns nil and a warning if the initial solution is not feasible, in which case it is ignored.
+func (p milpProblem) initialIncumbent() (*solution, string) {
+	if p.initialSolution == nil {
+		return nil, ""
+	}
+	if !p.feasibleSolution(p.initialSolution) {
+		return nil, "the initial solution is not feasible for the problem, so it is ignored"
+	}
+
+	x := p.snapToIntegers(solution{x: p.initialSolution}).x
+	return &solution{x: x, z: p.EvalObjective(x)}, ""
+}
+
+// express the initial solution keyed by variable name in terms of the columns of the converted problem.
+// Returns a warning if it does not assign a value to each variable of the problem.
+func (p Problem) initialColumns(milp *milpProblem, initial map[string]float64) ([]float64, string) {
+	original := make([]float64, len(p.variables))
+	for i, v := range p.variables {
+		val, ok := initial[v.name]
+		if !ok {
+			return nil, fmt.Sprintf("the initial solution does not assign a value to variable %v, so it is ignored", v.name)
+		}
+		original[i] = val
+	}
+	return milp.toColumns(original), ""
+}
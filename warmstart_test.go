@@ -0,0 +1,76 @@
+package ilp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMilpProblem_Solve_InitialSolution(t *testing.T) {
+	prob := getTestKnapsackMILP()
+
+	cold, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.InDelta(t, -24, cold.BestIntegerSolution.z, 1e-9)
+
+	// starting with the optimal solution as incumbent prunes the subProblems that cannot improve on it
+	prob.initialSolution = cold.BestIntegerSolution.x[:len(prob.c)]
+	warm, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Empty(t, warm.Warnings)
+	assert.Equal(t, STATUS_OPTIMAL, warm.Status)
+	assert.InDelta(t, -24, warm.BestIntegerSolution.z, 1e-9)
+	assert.True(t, warm.Stats.NodesExplored < cold.Stats.NodesExplored, "warm start explored %v nodes, cold start %v", warm.Stats.NodesExplored, cold.Stats.NodesExplored)
+
+	// an initial solution that is not integer-feasible is ignored
+	prob.initialSolution = []float64{0.5, 0, 0}
+	ignored, err := prob.solve(context.Background(), 1, dummyMiddleware{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, ignored.Warnings, 1)
+	assert.Equal(t, cold.Stats.NodesExplored, ignored.Stats.NodesExplored)
+}
+
+func TestProblem_Solve_WithInitialSolution(t *testing.T) {
+	// the initial solution is shifted along with the variable with a negative lower bound
+	prob, x, _, _ := getTestKnapsackProblem()
+	x.LowerBound(-2)
+
+	cold, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	warm, err := prob.Solve(context.Background(), WithInitialSolution(cold.byName))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Empty(t, warm.Warnings)
+	assert.InDelta(t, cold.Objective, warm.Objective, 1e-9)
+	assert.True(t, warm.Stats.NodesExplored <= cold.Stats.NodesExplored)
+
+	tests := []struct {
+		name    string
+		initial map[string]float64
+	}{
+		{name: "infeasible", initial: map[string]float64{"x": 5, "y": 5, "z": 5}},
+		{name: "fractional", initial: map[string]float64{"x": 0.5, "y": 0, "z": 0}},
+		{name: "missing variable", initial: map[string]float64{"x": 0, "y": 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			soln, err := prob.Solve(context.Background(), WithInitialSolution(tt.initial))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Len(t, soln.Warnings, 1)
+			assert.InDelta(t, cold.Objective, soln.Objective, 1e-9)
+		})
+	}
+}
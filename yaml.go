@@ -0,0 +1,45 @@
+package ilp
+
+import (
+	"io"
+	"math"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExportYAML writes the problem as YAML, of which the document has the structure of ProblemDTO like the JSON encoding of the problem.
+// The infinite bounds are written as the YAML floats .inf and -.inf.
+func (p Problem) ExportYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(p.toDTO()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// ParseYAML reads a problem that was written by Problem.ExportYAML.
+// The omitted bounds of a variable default to those of Problem.AddVariable.
+func ParseYAML(r io.Reader) (*Problem, error) {
+	var dto ProblemDTO
+	if err := yaml.NewDecoder(r).Decode(&dto); err != nil {
+		return nil, err
+	}
+
+	prob := NewProblem()
+	if err := prob.fromDTO(dto); err != nil {
+		return nil, err
+	}
+	return &prob, nil
+}
+
+func (v *VariableDTO) UnmarshalYAML(node *yaml.Node) error {
+	// the alias type prevents infinite recursion, as it does not have this method
+	type alias VariableDTO
+	decoded := alias{Upper: jsonFloat(math.Inf(1))}
+	if err := node.Decode(&decoded); err != nil {
+		return err
+	}
+	*v = VariableDTO(decoded)
+	return nil
+}
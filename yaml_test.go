@@ -0,0 +1,109 @@
+package ilp
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblem_YAML(t *testing.T) {
+	prob := getLPTestProblem()
+	if _, err := prob.AddSOS1(prob.variables[:2]); !assert.NoError(t, err) {
+		return
+	}
+	prob.BranchingHeuristic(BRANCH_MOST_INFEASIBLE)
+	prob.SetObjectiveOffset(-4)
+
+	// the infinite bounds are written as YAML floats
+	orphan := prob.variables[len(prob.variables)-1]
+	orphan.LowerBound(math.Inf(-1))
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, prob.ExportYAML(&buf)) {
+		return
+	}
+
+	parsed, err := ParseYAML(bytes.NewReader(buf.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the constraints refer to the variables and the problem they were parsed into
+	assert.Equal(t, prob.toSolveable(), parsed.toSolveable())
+	assert.Equal(t, BRANCH_MOST_INFEASIBLE, parsed.branchingHeuristic)
+	assert.Equal(t, prob.maximize, parsed.maximize)
+	assert.Empty(t, parsed.Validate())
+	for _, c := range parsed.constraints {
+		assert.True(t, c.problem == parsed)
+	}
+
+	assert.Contains(t, buf.String(), "lower: -.inf")
+
+	// exporting the parsed problem yields the same YAML
+	var again bytes.Buffer
+	assert.NoError(t, parsed.ExportYAML(&again))
+	assert.Equal(t, buf.String(), again.String())
+}
+
+func TestParseYAML(t *testing.T) {
+	data := `
+maximize: true
+variables:
+  - {name: x, coefficient: 1, upper: 2.5}
+  - {name: y, coefficient: 2, integer: true, lower: -.inf}
+constraints:
+  - name: total
+    expressions:
+      - {variable: x, coef: 1}
+      - {variable: y, coef: 1}
+    rhs: 4
+    inequality: true
+`
+
+	prob, err := ParseYAML(strings.NewReader(data))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the omitted bounds default to those of Problem.AddVariable
+	want := NewProblem()
+	want.Maximize()
+	x := want.AddVariable("x").SetCoeff(1).UpperBound(2.5)
+	y := want.AddFreeVariable("y").SetCoeff(2).IsInteger()
+	want.AddConstraint("").AddExpression(1, x).AddExpression(1, y).SmallerThanOrEqualTo(4)
+	assert.Equal(t, want.toSolveable(), prob.toSolveable())
+
+	// the expressions should refer to declared variables
+	_, err = ParseYAML(strings.NewReader("variables: [{name: x}]\nconstraints: [{name: c1, expressions: [{variable: z, coef: 1}]}]"))
+	assert.EqualError(t, err, "constraint c1: unknown variable z")
+}
+
+func TestParseYAML_Solve(t *testing.T) {
+	prob, _, _, _ := getTestKnapsackProblem()
+	prob.SetObjectiveOffset(1.5)
+	want, err := prob.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the parsed problem yields the same optimum
+	var buf bytes.Buffer
+	if !assert.NoError(t, prob.ExportYAML(&buf)) {
+		return
+	}
+	parsed, err := ParseYAML(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	got, err := parsed.Solve(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, want.Objective, got.Objective)
+	assert.InDelta(t, 25.5, got.Objective, 1e-9)
+	assert.Equal(t, want.ToMap(), got.ToMap())
+}